@@ -23,7 +23,10 @@ require (
 	resty.dev/v3 v3.0.0-beta.3
 )
 
-require github.com/difyz9/go-analysis-client v0.0.2
+require (
+	github.com/difyz9/go-analysis-client v0.0.2
+	github.com/ggerganov/whisper.cpp/bindings/go v0.0.0-20251120123511-19ceec8eac98
+)
 
 require (
 	cloud.google.com/go v0.115.0 // indirect
@@ -40,7 +43,6 @@ require (
 	github.com/ebitengine/purego v0.9.0 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.8 // indirect
-	github.com/ggerganov/whisper.cpp/bindings/go v0.0.0-20251120123511-19ceec8eac98 // indirect
 	github.com/gin-contrib/sse v1.1.0 // indirect
 	github.com/go-logr/logr v1.4.1 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
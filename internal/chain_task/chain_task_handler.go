@@ -11,6 +11,7 @@ import (
 	models2 "github.com/difyz9/ytb2bili/internal/core/models"
 	"github.com/difyz9/ytb2bili/internal/core/services"
 	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/source"
 	"github.com/difyz9/ytb2bili/pkg/store/model"
 
 	"sync"
@@ -82,7 +83,14 @@ func (h *ChainTaskHandler) SetUp() {
 			return
 		}
 
-		// 2. 处理新的视频任务
+		// 2. 唤醒到达检查时间的等待视频（首播/直播）
+		if woken, err := h.SavedVideoService.WakeParkedVideos(time.Now()); err != nil {
+			h.App.Logger.Errorf("唤醒等待中的视频失败: %v", err)
+		} else if woken > 0 {
+			h.App.Logger.Infof("⏰ %d 个等待中的视频已重新进入待处理队列", woken)
+		}
+
+		// 3. 处理新的视频任务
 		// 查询状态为 '001' 的任务
 		pendingTasks, err := h.getPendingTasks()
 		if err != nil {
@@ -98,6 +106,7 @@ func (h *ChainTaskHandler) SetUp() {
 		// 状态流转
 
 		// 001 (待处理) → 002 (处理中) → 100 (完成) 或 999 (失败)
+		// 源视频不可下载时: 003 (等待首播/直播结束) 或 991-995 (删除/私享/地区限制/需登录/直播跳过)
 
 		// 执行第一个待处理任务
 		task := pendingTasks[0]
@@ -194,38 +203,87 @@ func (h *ChainTaskHandler) RunTaskChain(video models2.TbVideo) {
 	downloadTask := handlers.NewDownloadVideo("下载视频", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
 	chain.AddTask(h.wrapTaskWithStepTracking(downloadTask, video.VideoId))
 
+	// 重复检测: 疑似重复时暂停上传，等待人工确认
+	duplicateTask := handlers.NewDuplicateCheck("视频查重", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(duplicateTask, video.VideoId))
+
+	// 转码: 按配置档转码（源文件已符合时跳过）
+	transcodeTask := handlers.NewTranscodeVideo("转码", h.App, stateManager, h.App.CosClient)
+	chain.AddTask(h.wrapTaskWithStepTracking(transcodeTask, video.VideoId))
+
 	// 任务2: 生成字幕文件
 	extractAudioTask := handlers.NewExtractAudio("分离音频", h.App, stateManager, h.App.CosClient)
 	chain.AddTask(h.wrapTaskWithStepTracking(extractAudioTask, video.VideoId))
 
-	// 任务3: 使用 Whisper 转录生成字幕（如果启用）
-	if h.App.Config.WhisperConfig != nil && h.App.Config.WhisperConfig.Enabled {
-		h.App.Logger.Info("✓ Whisper 已启用，将使用 Whisper 进行语音转录")
-		whisperTask := handlers.NewWhisperHandler(
-			"Whisper转录",
-			h.App,
-			stateManager,
-			h.App.CosClient,
-			h.App.Config.WhisperConfig.ModelPath,
-			h.App.Config.WhisperConfig.Language,
-			h.App.Config.WhisperConfig.Threads,
-		)
-		chain.AddTask(h.wrapTaskWithStepTracking(whisperTask, video.VideoId))
-	} else {
-		// 备用方案：使用原有的字幕生成方法
-		h.App.Logger.Info("使用默认字幕生成方法")
-		subtitleTask := handlers.NewGenerateSubtitles("生成字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
-		chain.AddTask(h.wrapTaskWithStepTracking(subtitleTask, video.VideoId))
+	// 检测原视频语言: 决定获取哪种语言的平台字幕、语音识别语言以及是否需要翻译
+	detectLanguageTask := handlers.NewDetectLanguage("检测语言", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(detectLanguageTask, video.VideoId))
+
+	// 任务3: 获取字幕
+	// 优先级: 浏览器插件提交的字幕 > 平台人工字幕 > 平台自动字幕 > 语音识别（启用时）
+	// 后面的步骤在已有字幕时跳过；插件没有提交字幕时生成字幕步骤直接跳过
+	subtitleTask := handlers.NewGenerateSubtitles("生成字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(subtitleTask, video.VideoId))
+
+	captionTask := handlers.NewFetchCaptions("获取平台字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(captionTask, video.VideoId))
+
+	// 语音识别生成字幕（如果启用，且没有可用的平台字幕）
+	if h.App.Config.TranscriptionEnabled() {
+		h.App.Logger.Info("✓ 语音识别已启用，没有可用平台字幕时将转录音频生成字幕")
+		asrTask := handlers.NewTranscribeAudio("语音识别", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+		chain.AddTask(h.wrapTaskWithStepTracking(asrTask, video.VideoId))
+	} else if err := h.TaskStepService.UpdateTaskStepStatus(video.VideoId, "语音识别", model.TaskStepStatusSkipped); err != nil {
+		// 未启用时标记为跳过，避免步骤停留在待执行状态被当作重试步骤反复执行
+		h.App.Logger.Errorf("更新任务步骤状态失败: %v", err)
 	}
+	// 字幕强制对齐（插件字幕时间不准时，按语音识别的词级时间戳重新计算时间）
+	alignTask := handlers.NewAlignSubtitles("字幕对齐", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(alignTask, video.VideoId))
+	// 原文字幕断句（翻译前按可读性重新切分、合并字幕）
+	sourceSegmentTask := handlers.NewResegmentSubtitles("字幕断句", h.App, stateManager, h.App.CosClient, h.SavedVideoService, false)
+	chain.AddTask(h.wrapTaskWithStepTracking(sourceSegmentTask, video.VideoId))
 	chain.AddTask(handlers.NewDownloadImgHandler("下载封面", h.App, stateManager, h.App.CosClient))
 	// 任务3: 翻译字幕（动态检查配置）
-	translateTask := handlers.NewTranslateSubtitle("翻译字幕", h.App, stateManager, h.App.CosClient, h.Db, "")
+	translateTask := handlers.NewTranslateSubtitle("翻译字幕", h.App, stateManager, h.App.CosClient, h.Db, "", h.SavedVideoService)
 	chain.AddTask(h.wrapTaskWithStepTracking(translateTask, video.VideoId))
+	// 译文字幕断句（按中文可读性限制重新切分）
+	targetSegmentTask := handlers.NewResegmentSubtitles("译文断句", h.App, stateManager, h.App.CosClient, h.SavedVideoService, true)
+	chain.AddTask(h.wrapTaskWithStepTracking(targetSegmentTask, video.VideoId))
 
 	// 任务4: 生成视频标题和描述（动态检查配置）
 	metadataTask := handlers.NewGenerateMetadata("生成视频元数据", h.App, stateManager, h.App.CosClient, "", h.Db, h.SavedVideoService)
 	chain.AddTask(h.wrapTaskWithStepTracking(metadataTask, video.VideoId))
 
+	// 生成封面（没有平台封面或频道优先使用生成封面时，需在生成元数据之后以使用AI标题）
+	coverTask := handlers.NewGenerateCover("生成封面", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(coverTask, video.VideoId))
+
+	// 后期处理: 片头片尾与水印（字幕按片头时长后移）
+	brandingTask := handlers.NewApplyBranding("片头片尾", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(brandingTask, video.VideoId))
+
+	// 后期处理: 音频响度标准化（EBU R128，在片头片尾之后处理整条音轨）
+	loudnormTask := handlers.NewNormalizeLoudness("响度标准化", h.App, stateManager, h.App.CosClient)
+	chain.AddTask(h.wrapTaskWithStepTracking(loudnormTask, video.VideoId))
+
+	// 后期处理: 烧录硬字幕（输出单独的投稿文件，预览与故事板仍使用无字幕视频）
+	burnTask := handlers.NewBurnSubtitles("烧录字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(burnTask, video.VideoId))
+
+	// 上传前预览: 低码率 HLS + 各语言 WebVTT 字幕
+	previewTask := handlers.NewGeneratePreview("生成预览", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(previewTask, video.VideoId))
+
+	// 故事板: 雪碧图缩略图 + 缩略图总览
+	storyboardTask := handlers.NewGenerateStoryboard("生成故事板", h.App, stateManager, h.App.CosClient)
+	chain.AddTask(h.wrapTaskWithStepTracking(storyboardTask, video.VideoId))
+
+	// 媒体检查: 对照B站投稿限制检查实际投稿文件的编码、分辨率、大小等，可选自动转码
+	// 放在最后，片头片尾、响度标准化、烧录字幕都会重新编码，之后不再有步骤改动投稿文件
+	inspectTask := handlers.NewInspectMedia("媒体检查", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	chain.AddTask(h.wrapTaskWithStepTracking(inspectTask, video.VideoId))
+
 	// 注意: 上传任务已移至 UploadScheduler 定时执行
 	// - 视频上传: 每小时上传一个视频
 	// - 字幕上传: 视频上传后1小时再上传字幕
@@ -239,6 +297,14 @@ func (h *ChainTaskHandler) RunTaskChain(video models2.TbVideo) {
 	duration := time.Since(startTime)
 	h.App.Logger.Infof("任务链执行完成, 耗时: %v", duration)
 
+	// 记录字幕来源
+	h.saveSubtitleSource(video.Id, result)
+
+	// 源视频暂不可下载或不可用时，设置对应状态
+	if h.applySourceStatus(video.Id, result) {
+		return
+	}
+
 	// 检查任务链是否成功执行（如果context中有错误信息，则认为失败）
 	success := true
 	if errorMsg, exists := result["error"]; exists && errorMsg != nil {
@@ -247,7 +313,17 @@ func (h *ChainTaskHandler) RunTaskChain(video models2.TbVideo) {
 	}
 
 	// 根据执行结果更新任务状态
-	if success {
+	if success && result["duplicate_of"] != nil {
+		// 疑似重复，暂停上传（210），等待人工放行
+		if err := h.updateSavedVideoStatus(video.Id, "210"); err != nil {
+			h.App.Logger.Errorf("更新任务状态为疑似重复时出错: %v", err)
+		} else {
+			h.App.Logger.Warnf("任务 %s 疑似与 %v 重复，已暂停上传", video.VideoId, result["duplicate_of"])
+		}
+		if err := h.TaskStepService.HoldUploadStep(video.VideoId); err != nil {
+			h.App.Logger.Errorf("暂停上传步骤失败: %v", err)
+		}
+	} else if success {
 		// 任务成功完成，更新状态为完成
 		if err := h.updateSavedVideoStatus(video.Id, "200"); err != nil {
 			h.App.Logger.Errorf("更新任务状态为完成时出错: %v", err)
@@ -295,6 +371,14 @@ func (h *ChainTaskHandler) RunSingleTaskStep(videoID, stepName string) error {
 	// 创建状态管理器
 	stateManager := manager.NewStateManager(video.Id, video.VideoId, currentDir, video.CreatedAt)
 
+	// 语音识别未启用时标记为跳过，不进入执行中状态
+	if (stepName == "语音识别" || stepName == "Whisper转录") && !h.App.Config.TranscriptionEnabled() {
+		if err := h.TaskStepService.UpdateTaskStepStatus(videoID, stepName, model.TaskStepStatusSkipped); err != nil {
+			h.App.Logger.Errorf("更新任务步骤状态失败: %v", err)
+		}
+		return fmt.Errorf("语音识别未启用")
+	}
+
 	// 重置步骤状态
 	if err := h.TaskStepService.ResetTaskStep(videoID, stepName); err != nil {
 		h.App.Logger.Errorf("重置任务步骤失败: %v", err)
@@ -313,31 +397,46 @@ func (h *ChainTaskHandler) RunSingleTaskStep(videoID, stepName string) error {
 	switch stepName {
 	case "下载视频":
 		task = handlers.NewDownloadVideo("下载视频", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "视频查重":
+		task = handlers.NewDuplicateCheck("视频查重", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "转码":
+		task = handlers.NewTranscodeVideo("转码", h.App, stateManager, h.App.CosClient)
+	case "媒体检查":
+		task = handlers.NewInspectMedia("媒体检查", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
 	case "分离音频":
 		task = handlers.NewExtractAudio("分离音频", h.App, stateManager, h.App.CosClient)
-	case "Whisper转录":
-		// 从配置中读取 Whisper 参数
-		if h.App.Config.WhisperConfig != nil && h.App.Config.WhisperConfig.Enabled {
-			task = handlers.NewWhisperHandler(
-				"Whisper转录",
-				h.App,
-				stateManager,
-				h.App.CosClient,
-				h.App.Config.WhisperConfig.ModelPath,
-				h.App.Config.WhisperConfig.Language,
-				h.App.Config.WhisperConfig.Threads,
-			)
-		} else {
-			return fmt.Errorf("Whisper 未启用或配置不完整")
-		}
+	case "检测语言":
+		task = handlers.NewDetectLanguage("检测语言", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "语音识别", "Whisper转录":
+		task = handlers.NewTranscribeAudio("语音识别", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
 	case "生成字幕":
 		task = handlers.NewGenerateSubtitles("生成字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "获取平台字幕":
+		task = handlers.NewFetchCaptions("获取平台字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
 	case "翻译字幕":
 		// 不再在这里检查配置，让任务运行时动态检查最新配置
-		task = handlers.NewTranslateSubtitle("翻译字幕", h.App, stateManager, h.App.CosClient, h.Db, "")
+		task = handlers.NewTranslateSubtitle("翻译字幕", h.App, stateManager, h.App.CosClient, h.Db, "", h.SavedVideoService)
+	case "字幕对齐":
+		task = handlers.NewAlignSubtitles("字幕对齐", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "字幕断句":
+		task = handlers.NewResegmentSubtitles("字幕断句", h.App, stateManager, h.App.CosClient, h.SavedVideoService, false)
+	case "译文断句":
+		task = handlers.NewResegmentSubtitles("译文断句", h.App, stateManager, h.App.CosClient, h.SavedVideoService, true)
 	case "生成元数据":
 		// 不再在这里检查配置，让任务运行时动态检查最新配置
 		task = handlers.NewGenerateMetadata("生成元数据", h.App, stateManager, h.App.CosClient, "", h.Db, h.SavedVideoService)
+	case "生成封面":
+		task = handlers.NewGenerateCover("生成封面", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "片头片尾":
+		task = handlers.NewApplyBranding("片头片尾", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "响度标准化":
+		task = handlers.NewNormalizeLoudness("响度标准化", h.App, stateManager, h.App.CosClient)
+	case "烧录字幕":
+		task = handlers.NewBurnSubtitles("烧录字幕", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "生成预览":
+		task = handlers.NewGeneratePreview("生成预览", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
+	case "生成故事板":
+		task = handlers.NewGenerateStoryboard("生成故事板", h.App, stateManager, h.App.CosClient)
 	case "上传到Bilibili":
 		task = handlers.NewUploadToBilibili("上传到Bilibili", h.App, stateManager, h.App.CosClient, h.SavedVideoService)
 	case "上传字幕到Bilibili":
@@ -367,6 +466,10 @@ func (h *ChainTaskHandler) RunSingleTaskStep(videoID, stepName string) error {
 
 	// 更新步骤状态
 	if success {
+		h.saveSubtitleSource(video.Id, result)
+		if stepName == "视频查重" {
+			h.applyDuplicateHold(video, result)
+		}
 		if err := h.TaskStepService.UpdateTaskStepStatus(videoID, stepName, "completed"); err != nil {
 			h.App.Logger.Errorf("更新任务步骤状态失败: %v", err)
 		}
@@ -375,6 +478,7 @@ func (h *ChainTaskHandler) RunSingleTaskStep(videoID, stepName string) error {
 		}
 		h.App.Logger.Infof("任务步骤 %s 执行成功", stepName)
 	} else {
+		h.applySourceStatus(video.Id, result)
 		if err := h.TaskStepService.UpdateTaskStepStatus(videoID, stepName, "failed", errorMsg); err != nil {
 			h.App.Logger.Errorf("更新任务步骤状态失败: %v", err)
 		}
@@ -386,6 +490,60 @@ func (h *ChainTaskHandler) RunSingleTaskStep(videoID, stepName string) error {
 }
 
 
+// sourceStatusCodes 源视频不可用时的终态
+var sourceStatusCodes = map[source.Outcome]string{
+	source.OutcomeRemoved:       "991", // 已删除/不可用
+	source.OutcomePrivate:       "992", // 私享视频
+	source.OutcomeGeoBlocked:    "993", // 地区限制
+	source.OutcomeLoginRequired: "994", // 需要登录/会员/年龄验证
+	source.OutcomeLive:          "995", // 正在直播（按配置跳过）
+}
+
+// applySourceStatus 根据下载任务写入的 source_status 设置视频状态，已处理时返回 true
+func (h *ChainTaskHandler) applySourceStatus(id uint, result map[string]interface{}) bool {
+	outcome, ok := result["source_status"].(string)
+	if !ok || outcome == "" {
+		return false
+	}
+	reason := fmt.Sprintf("%v", result["error"])
+
+	// 首播/直播未结束: 暂停处理，到达检查时间后自动重试
+	if wakeAt, ok := result["wake_at"].(time.Time); ok {
+		if err := h.SavedVideoService.ParkVideo(id, wakeAt, reason); err != nil {
+			h.App.Logger.Errorf("更新任务状态为等待中时出错: %v", err)
+		}
+		return true
+	}
+
+	status, ok := sourceStatusCodes[source.Outcome(outcome)]
+	if !ok {
+		return false
+	}
+	if err := h.SavedVideoService.MarkUnavailable(id, status, reason); err != nil {
+		h.App.Logger.Errorf("更新任务状态为不可用时出错: %v", err)
+	}
+	return true
+}
+
+// applyDuplicateHold 单独重跑查重步骤后，根据结果暂停或恢复上传
+func (h *ChainTaskHandler) applyDuplicateHold(video models2.TbVideo, result map[string]interface{}) {
+	if result["duplicate_of"] != nil && video.Status == "200" {
+		if err := h.updateSavedVideoStatus(video.Id, "210"); err != nil {
+			h.App.Logger.Errorf("更新任务状态为疑似重复时出错: %v", err)
+		}
+		if err := h.TaskStepService.HoldUploadStep(video.VideoId); err != nil {
+			h.App.Logger.Errorf("暂停上传步骤失败: %v", err)
+		}
+	} else if result["duplicate_of"] == nil && video.Status == "210" {
+		if err := h.updateSavedVideoStatus(video.Id, "200"); err != nil {
+			h.App.Logger.Errorf("恢复任务状态时出错: %v", err)
+		}
+		if err := h.TaskStepService.ReleaseUploadStep(video.VideoId); err != nil {
+			h.App.Logger.Errorf("恢复上传步骤失败: %v", err)
+		}
+	}
+}
+
 // wrapTaskWithStepTracking 包装任务以添加步骤跟踪
 func (h *ChainTaskHandler) wrapTaskWithStepTracking(task types.Task, videoID string) types.Task {
 	return &TaskStepWrapper{
@@ -457,6 +615,20 @@ func (w *TaskStepWrapper) Execute(context map[string]interface{}) bool {
 	return success
 }
 
+// saveSubtitleSource 将任务上下文中的字幕来源保存到数据库
+func (h *ChainTaskHandler) saveSubtitleSource(id uint, result map[string]interface{}) {
+	subtitleSource, ok := result["subtitle_source"].(string)
+	if !ok || subtitleSource == "" {
+		return
+	}
+	lang, _ := result["subtitle_lang"].(string)
+	if err := h.SavedVideoService.UpdateSubtitleSource(id, subtitleSource, lang); err != nil {
+		h.App.Logger.Errorf("保存字幕来源失败: %v", err)
+		return
+	}
+	h.App.Logger.Infof("✓ 字幕来源: %s (%s)", subtitleSource, lang)
+}
+
 // updateSavedVideoStatus 更新 SavedVideo 的状态
 func (h *ChainTaskHandler) updateSavedVideoStatus(id uint, status string) error {
 	return h.SavedVideoService.UpdateStatus(id, status)
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/asr"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// AlignSubtitles 字幕强制对齐
+// 插件提交的字幕文本准确但时间轴不准（或没有时间）时，对音频做语音识别，
+// 将字幕文本与识别出的词级时间戳对齐，重新计算每条字幕的时间，并将每条字幕的置信度写入 alignment.json
+// 音频提取或语音识别失败时保留原时间，只有字幕文件读写失败时任务失败
+type AlignSubtitles struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewAlignSubtitles(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *AlignSubtitles {
+	return &AlignSubtitles{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+// alignmentReport 对齐结果（alignment.json）
+type alignmentReport struct {
+	Source     string         `json:"source"`     // 字幕来源
+	Provider   string         `json:"provider"`   // 语音识别提供商
+	Language   string         `json:"language"`   // 识别语言
+	Confidence float64        `json:"confidence"` // 整体置信度（按字数加权）
+	Applied    bool           `json:"applied"`    // 是否采用对齐后的时间
+	Cues       []alignmentCue `json:"cues"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// alignmentCue 单条字幕的对齐结果（时间单位: 秒）
+type alignmentCue struct {
+	Index         int     `json:"index"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	OriginalStart float64 `json:"original_start"`
+	OriginalEnd   float64 `json:"original_end"`
+	Text          string  `json:"text"`
+	Confidence    float64 `json:"confidence"`
+}
+
+func (t *AlignSubtitles) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.AlignConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  字幕强制对齐未启用，跳过")
+		return true
+	}
+
+	// 1. 只对齐配置的字幕来源（语音识别生成的字幕本身就是识别时间）
+	source, _ := taskContext["subtitle_source"].(string)
+	if source == "" {
+		if savedVideo, err := t.SavedVideoService.GetVideoByVideoID(t.StateManager.VideoID); err == nil {
+			source = savedVideo.SubtitleSource
+		}
+	}
+	if !slices.Contains(cfg.Sources, source) {
+		t.App.Logger.Infof("⏭️  字幕来源 %q 不需要对齐，跳过", source)
+		return true
+	}
+
+	cues, err := subtitle.ReadSRT(t.StateManager.SourceSRT)
+	if err != nil {
+		if os.IsNotExist(err) {
+			t.App.Logger.Info("⏭️  原文字幕不存在，跳过对齐")
+			return true
+		}
+		t.App.Logger.Errorf("❌ 读取字幕文件失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("读取字幕文件失败: %v", err)
+		return false
+	}
+	if len(cues) == 0 {
+		t.App.Logger.Info("⏭️  字幕为空，跳过对齐")
+		return true
+	}
+
+	// 2. 准备 16kHz 单声道 WAV
+	wavPath := t.StateManager.OriginalWAV
+	if _, err := os.Stat(wavPath); err != nil {
+		t.App.Logger.Info("🎵 提取 WAV 音频用于字幕对齐")
+		if err := utils.ExtractWaveAudio(t.StateManager.InputVideoPath, wavPath); err != nil {
+			// 对齐只是优化时间轴，失败时保留原时间继续后续步骤
+			t.App.Logger.Warnf("⚠️  提取 WAV 音频失败，保留原字幕时间: %v", err)
+			return true
+		}
+	}
+
+	// 3. 语音识别获取词级时间戳
+	asrManager := asr.NewTranscriberManager(t.App.Config)
+	defer asrManager.Close()
+	provider := cfg.Provider
+	if provider == "" {
+		provider = asrManager.DefaultProvider()
+	}
+	language := subtitleLanguage(t.SavedVideoService, taskContext, t.StateManager.VideoID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Hour)
+	defer cancel()
+
+	t.App.Logger.Infof("🎯 字幕强制对齐: %d 条字幕 (来源: %s, 提供商: %s, 语言: %s)", len(cues), source, provider, language)
+	start := time.Now()
+	req := &asr.TranscriptionRequest{AudioPath: wavPath, Language: language}
+	var result *asr.TranscriptionResult
+	if chunked := newChunkedTranscriber(ctx, t.App, t.StateManager, asrManager, provider, wavPath); chunked != nil {
+		result, err = chunked.Transcribe(ctx, req)
+	} else {
+		result, err = asrManager.TranscribeWithProvider(ctx, provider, req)
+	}
+	if err != nil {
+		t.App.Logger.Warnf("⚠️  字幕对齐语音识别失败，保留原字幕时间: %v", err)
+		return true
+	}
+
+	// 4. 对齐
+	aligned := subtitle.Align(cues, subtitle.TimedWords(asr.Cues(result.Segments)), subtitle.AlignOptions{
+		MinSimilarity: cfg.MinSimilarity,
+		MaxDrift:      seconds(cfg.MaxDrift),
+	})
+
+	report := alignmentReport{
+		Source:    source,
+		Provider:  result.Provider,
+		Language:  language,
+		CreatedAt: time.Now(),
+	}
+	var weighted, chars float64
+	low := 0
+	for i, cue := range aligned {
+		n := float64(subtitle.CharCount(cue.Text))
+		weighted += cue.Confidence * n
+		chars += n
+		if cue.Confidence < cfg.MinConfidence {
+			low++
+		}
+		report.Cues = append(report.Cues, alignmentCue{
+			Index:         i + 1,
+			Start:         cue.Start.Seconds(),
+			End:           cue.End.Seconds(),
+			OriginalStart: cues[i].Start.Seconds(),
+			OriginalEnd:   cues[i].End.Seconds(),
+			Text:          cue.Text,
+			Confidence:    cue.Confidence,
+		})
+	}
+	if chars > 0 {
+		report.Confidence = weighted / chars
+	}
+	report.Applied = report.Confidence >= cfg.MinConfidence
+
+	if data, err := json.MarshalIndent(report, "", "  "); err == nil {
+		if err := os.WriteFile(t.StateManager.AlignmentJSON, data, 0644); err != nil {
+			t.App.Logger.Warnf("⚠️  写入对齐结果失败: %v", err)
+		}
+	}
+	taskContext["alignment"] = map[string]interface{}{
+		"confidence":     report.Confidence,
+		"applied":        report.Applied,
+		"cues":           len(aligned),
+		"low_confidence": low,
+	}
+
+	if !report.Applied {
+		t.App.Logger.Warnf("⚠️  对齐置信度 %.2f 低于 %.2f，字幕文本可能与音频不符，保留原时间", report.Confidence, cfg.MinConfidence)
+		return true
+	}
+
+	// 5. 写回原文字幕，词级时间戳供字幕断句使用
+	alignedCues := make([]subtitle.Cue, len(aligned))
+	for i, cue := range aligned {
+		alignedCues[i] = cue.Cue
+	}
+	if err := subtitle.WriteSRT(t.StateManager.SourceSRT, alignedCues); err != nil {
+		t.App.Logger.Errorf("❌ 写入字幕文件失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("写入字幕文件失败: %v", err)
+		return false
+	}
+	if data, err := json.Marshal(alignedCues); err == nil {
+		if err := os.WriteFile(t.StateManager.SourceWords, data, 0644); err != nil {
+			t.App.Logger.Warnf("⚠️  写入词级时间戳失败: %v", err)
+		}
+	}
+	if err := utils.CopyFile(t.StateManager.SourceSRT, t.StateManager.SubtitlePath(language)); err != nil {
+		t.App.Logger.Warnf("⚠️  复制字幕文件失败: %v", err)
+	}
+
+	t.App.Logger.Infof("✅ 字幕对齐完成: 置信度 %.2f，%d 条低于 %.2f (耗时 %s)",
+		report.Confidence, low, cfg.MinConfidence, time.Since(start).Round(time.Second))
+	return true
+}
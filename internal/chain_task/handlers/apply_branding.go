@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// ApplyBranding 拼接片头片尾并叠加水印，之后将所有字幕按片头时长后移
+// 处理结果保存在 branding.json 中，重试时不会重复处理；视频重新下载后由下载步骤清除（resetBranding）
+type ApplyBranding struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewApplyBranding(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *ApplyBranding {
+	return &ApplyBranding{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *ApplyBranding) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.BrandingConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  片头片尾/水印未启用，跳过")
+		return true
+	}
+
+	result, err := media.LoadBrandingResult(t.StateManager.BrandingJSON)
+	if err == nil && !result.Pending {
+		t.App.Logger.Infof("⏭️  已处理过片头片尾/水印（片头 %.1fs），跳过", result.IntroDuration)
+		return true
+	}
+	if err != nil {
+		if result, err = t.brandVideo(cfg); err != nil {
+			t.App.Logger.Errorf("❌ %v", err)
+			taskContext["error"] = err.Error()
+			return false
+		}
+		if result == nil {
+			return true
+		}
+	} else {
+		t.App.Logger.Infof("♻️  视频已添加片头片尾/水印，继续平移字幕（片头 %.1fs）", result.IntroDuration)
+	}
+
+	// 3. 字幕按片头时长后移（已平移的文件记录在 branding.json 中，不会重复平移）
+	if result.SubtitleOffset() > 0 {
+		files, _ := filepath.Glob(filepath.Join(t.StateManager.CurrentDir, "*.srt"))
+		for _, file := range files {
+			name := filepath.Base(file)
+			if !result.NeedsShift(name) {
+				continue
+			}
+			if err := shiftSRTFile(file, result.SubtitleOffset()); err != nil {
+				t.App.Logger.Warnf("⚠️  平移字幕失败 %s: %v", name, err)
+				continue
+			}
+			result.MarkShifted(name)
+			if err := media.SaveBrandingResult(t.StateManager.BrandingJSON, result); err != nil {
+				t.App.Logger.Errorf("❌ 保存处理结果失败: %v", err)
+				taskContext["error"] = fmt.Sprintf("保存片头片尾处理结果失败: %v", err)
+				return false
+			}
+			t.App.Logger.Infof("✓ 字幕已后移 %.1fs: %s", result.IntroDuration, name)
+		}
+		// bilingual.ass 不在平移范围内，按平移后的字幕重新生成
+		updateBilingualSubtitles(t.App, t.SavedVideoService, t.StateManager, taskContext)
+	}
+
+	result.Pending = false
+	if err := media.SaveBrandingResult(t.StateManager.BrandingJSON, result); err != nil {
+		t.App.Logger.Warnf("⚠️  保存处理结果失败: %v", err)
+	}
+
+	t.App.Logger.Infof("✅ 片头片尾/水印处理完成 (片头 %.1fs, 片尾 %.1fs)", result.IntroDuration, result.OutroDuration)
+	taskContext["intro_duration"] = result.IntroDuration
+	taskContext["outro_duration"] = result.OutroDuration
+	return true
+}
+
+// brandVideo 拼接片头片尾、叠加水印并替换视频，替换后立即保存处理结果
+// 没有可用配置时返回 nil
+func (t *ApplyBranding) brandVideo(cfg *types.BrandingConfig) (*media.BrandingResult, error) {
+	// 1. 按来源频道选择配置
+	var keys []string
+	if meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID); err == nil {
+		keys = append(keys, meta.ChannelID, meta.Channel, meta.Uploader)
+	}
+	profile := cfg.ProfileFor(keys...)
+	if profile == nil || (profile.Intro == "" && profile.Outro == "" && profile.Watermark == "") {
+		t.App.Logger.Info("⏭️  没有配置片头片尾或水印，跳过")
+		return nil, nil
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		return nil, fmt.Errorf("视频文件不存在: %s", filepath.Base(videoPath))
+	}
+
+	// 2. 拼接与叠加水印
+	ctx, cancel := context.WithTimeout(context.Background(), 6*time.Hour)
+	defer cancel()
+
+	tmpPath := filepath.Join(t.StateManager.CurrentDir, t.StateManager.VideoID+".branding.mp4")
+	t.App.Logger.Infof("🎬 添加片头片尾/水印 (片头: %s, 片尾: %s, 水印: %s)",
+		displayPath(profile.Intro), displayPath(profile.Outro), displayPath(profile.Watermark))
+	result, err := media.ApplyBranding(ctx, videoPath, tmpPath, media.BrandingOptions{
+		Intro:          profile.Intro,
+		Outro:          profile.Outro,
+		Watermark:      profile.Watermark,
+		Position:       profile.Position,
+		Opacity:        profile.Opacity,
+		Margin:         profile.Margin,
+		WatermarkScale: profile.WatermarkScale,
+		Width:          profile.Width,
+		Height:         profile.Height,
+		FPS:            profile.FPS,
+	})
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("添加片头片尾/水印失败: %v", err)
+	}
+
+	if err := replaceInputVideo(t.StateManager, videoPath, tmpPath); err != nil {
+		return nil, err
+	}
+	result.Pending = true
+	if err := media.SaveBrandingResult(t.StateManager.BrandingJSON, result); err != nil {
+		return nil, fmt.Errorf("保存片头片尾处理结果失败: %v", err)
+	}
+	return result, nil
+}
+
+// resetBranding 视频重新下载后清除片头片尾处理结果：已平移的字幕移回原时间轴，删除 branding.json 与分P清单，
+// 之后片头片尾步骤按新视频重新处理，分P按新视频重新切分
+func resetBranding(app *core.AppServer, sm *manager.StateManager) {
+	if result, err := media.LoadBrandingResult(sm.BrandingJSON); err == nil {
+		for _, name := range result.Shifted {
+			file := filepath.Join(sm.CurrentDir, name)
+			if _, err := os.Stat(file); err != nil {
+				continue
+			}
+			if err := shiftSRTFile(file, -result.SubtitleOffset()); err != nil {
+				app.Logger.Warnf("⚠️  还原字幕时间失败 %s: %v", name, err)
+			}
+		}
+		if err := os.Remove(sm.BrandingJSON); err != nil {
+			app.Logger.Warnf("⚠️  删除片头片尾处理结果失败: %v", err)
+		} else {
+			app.Logger.Info("♻️  视频已重新下载，片头片尾/水印将重新处理")
+		}
+	}
+	os.Remove(sm.PartsJSON)
+}
+
+// displayPath 日志中显示的文件名
+func displayPath(path string) string {
+	if path == "" {
+		return "无"
+	}
+	return filepath.Base(path)
+}
+
+// shiftSRTFile 将 SRT 文件中所有字幕平移 offset，原地写回
+func shiftSRTFile(path string, offset time.Duration) error {
+	cues, err := subtitle.ReadSRT(path)
+	if err != nil {
+		return fmt.Errorf("读取字幕文件失败: %w", err)
+	}
+	if err := subtitle.WriteSRT(path, subtitle.Shift(cues, offset)); err != nil {
+		return fmt.Errorf("写入 SRT 文件失败: %w", err)
+	}
+	return nil
+}
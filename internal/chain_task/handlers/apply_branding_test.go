@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+func TestResetBranding(t *testing.T) {
+	sm := manager.NewStateManager(1, "v1", t.TempDir(), time.Now())
+	app := newTestApp(&types.AppConfig{})
+
+	// zh.srt 已按 5 秒片头平移，en.srt 尚未平移
+	cues := []subtitle.Cue{{Start: time.Second, End: 2 * time.Second, Text: "一"}}
+	shifted := subtitle.Shift(cues, 5*time.Second)
+	write := func(name string, cues []subtitle.Cue) {
+		if err := subtitle.WriteSRT(filepath.Join(sm.CurrentDir, name), cues); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("zh.srt", shifted)
+	write("en.srt", cues)
+	result := &media.BrandingResult{IntroDuration: 5, Shifted: []string{"zh.srt", "removed.srt"}}
+	if err := media.SaveBrandingResult(sm.BrandingJSON, result); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sm.PartsJSON, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resetBranding(app, sm)
+
+	for _, name := range []string{"zh.srt", "en.srt"} {
+		got, err := subtitle.ReadSRT(filepath.Join(sm.CurrentDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0].Start != cues[0].Start || got[0].End != cues[0].End {
+			t.Errorf("%s = %v-%v, want %v-%v", name, got[0].Start, got[0].End, cues[0].Start, cues[0].End)
+		}
+	}
+	for _, path := range []string{sm.BrandingJSON, sm.PartsJSON} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", filepath.Base(path))
+		}
+	}
+
+	// 没有处理结果时不改动字幕
+	resetBranding(app, sm)
+	if got, _ := subtitle.ReadSRT(filepath.Join(sm.CurrentDir, "zh.srt")); got[0].Start != cues[0].Start {
+		t.Errorf("zh.srt shifted again: %v", got[0].Start)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// updateBilingualSubtitles 合并主目标语言译文与原文字幕，写入双语字幕 bilingual.srt（启用第二行样式时另写 bilingual.ass）
+// 翻译、译文断句、片头平移后都会重新生成，保证与译文字幕时间轴一致；失败只记录警告
+func updateBilingualSubtitles(app *core.AppServer, videos *services.SavedVideoService, sm *manager.StateManager, taskContext map[string]interface{}) {
+	cfg := app.Config.BilingualConfig
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	targetPath := primaryTranslatedSRT(app, videos, sm)
+	if _, err := os.Stat(targetPath); err != nil {
+		return
+	}
+
+	count, err := writeBilingualSubtitles(cfg, sm, targetPath)
+	if err != nil {
+		app.Logger.Warnf("⚠️  生成双语字幕失败: %v", err)
+		return
+	}
+	app.Logger.Infof("✓ 双语字幕已生成: %d 条 (%s)", count, displayPath(sm.BilingualSRT))
+	taskContext["bilingual_srt_path"] = sm.BilingualSRT
+}
+
+// writeBilingualSubtitles 由 targetPath 的译文与原文生成双语字幕文件，返回字幕条数
+func writeBilingualSubtitles(cfg *types.BilingualConfig, sm *manager.StateManager, targetPath string) (int, error) {
+	target, err := subtitle.ReadSRT(targetPath)
+	if err != nil {
+		return 0, fmt.Errorf("读取译文字幕失败: %w", err)
+	}
+	source, err := subtitle.ReadSRT(sm.SourceSRT)
+	if err != nil {
+		return 0, fmt.Errorf("读取原文字幕失败: %w", err)
+	}
+	if len(target) == 0 || len(source) == 0 {
+		return 0, fmt.Errorf("字幕为空")
+	}
+
+	cues := subtitle.Bilingual(target, source, cfg.SourceFirst)
+	if err := subtitle.WriteSRT(sm.BilingualSRT, subtitle.BilingualCues(cues)); err != nil {
+		return 0, fmt.Errorf("写入双语字幕失败: %w", err)
+	}
+
+	if cfg.StyleSecondary {
+		opts, err := bilingualASSOptions(cfg, subtitle.DefaultASSOptions())
+		if err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(sm.BilingualASS, []byte(subtitle.FormatBilingualASS(cues, opts)), 0644); err != nil {
+			return 0, fmt.Errorf("写入双语 ASS 字幕失败: %w", err)
+		}
+	} else {
+		os.Remove(sm.BilingualASS)
+	}
+	return len(cues), nil
+}
+
+// bilingualASSOptions 双语 ASS 样式：第一行使用 base 的默认样式，第二行按比例缩小字号并使用单独颜色
+func bilingualASSOptions(cfg *types.BilingualConfig, base subtitle.ASSOptions) (subtitle.ASSOptions, error) {
+	opts := base
+	opts.Styles = opts.Styles[:1:1]
+	secondary := opts.Styles[0]
+	secondary.Name = "Secondary"
+	if cfg.SecondaryScale > 0 {
+		secondary.FontSize = int(math.Round(float64(secondary.FontSize) * cfg.SecondaryScale))
+	}
+	if cfg.SecondaryColor != "" {
+		color, err := subtitle.ASSColor(cfg.SecondaryColor)
+		if err != nil {
+			return opts, err
+		}
+		secondary.PrimaryColour = color
+	}
+	opts.Styles = append(opts.Styles, secondary)
+	return opts, nil
+}
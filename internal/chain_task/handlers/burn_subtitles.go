@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// BurnSubtitles 将字幕烧录到画面中（硬字幕）
+// 输出单独的 <id>.burned.mp4 作为投稿文件，InputVideoPath 保持无字幕，供预览与故事板使用
+type BurnSubtitles struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewBurnSubtitles(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *BurnSubtitles {
+	return &BurnSubtitles{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *BurnSubtitles) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.BurnInConfig
+	if cfg == nil || !cfg.Enabled {
+		// 清理之前烧录的文件，避免上传时误用
+		os.Remove(t.StateManager.BurnedVideo)
+		t.App.Logger.Info("⏭️  烧录字幕未启用，跳过")
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Errorf("❌ 视频文件不存在: %s", videoPath)
+		taskContext["error"] = "视频文件不存在"
+		return false
+	}
+
+	// 1. 读取要烧录的字幕轨
+	track, cues, err := t.loadTrack(cfg, taskContext)
+	if err != nil {
+		t.App.Logger.Warnf("⚠️  %v，跳过烧录", err)
+		os.Remove(t.StateManager.BurnedVideo)
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Hour)
+	defer cancel()
+
+	// 2. 按视频分辨率生成 ASS 字幕
+	info, err := media.Probe(ctx, videoPath)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 读取视频信息失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("读取视频信息失败: %v", err)
+		return false
+	}
+	stream := info.VideoStream()
+	if stream == nil {
+		t.App.Logger.Error("❌ 视频没有视频流")
+		taskContext["error"] = "视频没有视频流"
+		return false
+	}
+
+	opts, err := burnInASSOptions(cfg, stream.Width, stream.Height)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 硬字幕样式配置错误: %v", err)
+		taskContext["error"] = fmt.Sprintf("硬字幕样式配置错误: %v", err)
+		return false
+	}
+
+	var content string
+	if track == "bilingual" {
+		if bilingual := t.App.Config.BilingualConfig; bilingual != nil && bilingual.StyleSecondary {
+			if opts, err = bilingualASSOptions(bilingual, opts); err != nil {
+				t.App.Logger.Errorf("❌ 双语字幕样式配置错误: %v", err)
+				taskContext["error"] = fmt.Sprintf("双语字幕样式配置错误: %v", err)
+				return false
+			}
+		}
+		content = subtitle.FormatBilingualASS(subtitle.SplitBilingual(cues), opts)
+	} else {
+		content = subtitle.FormatASS(cues, opts)
+	}
+	if err := os.WriteFile(t.StateManager.BurnInASS, []byte(content), 0644); err != nil {
+		t.App.Logger.Errorf("❌ 写入 ASS 字幕失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("写入 ASS 字幕失败: %v", err)
+		return false
+	}
+
+	// 3. 烧录（先写临时文件，成功后替换，避免中断时留下不完整的投稿文件）
+	fontsDir := cfg.FontsDir
+	if fontsDir == "" {
+		if font := media.FindCJKFont(); font != "" {
+			fontsDir = filepath.Dir(font)
+		}
+	}
+	t.App.Logger.Infof("🔥 烧录字幕: %s，%d 条，%dx%d，字体目录 %s", track, len(cues), stream.Width, stream.Height, displayPath(fontsDir))
+
+	start := time.Now()
+	tmpPath := filepath.Join(t.StateManager.CurrentDir, t.StateManager.VideoID+".burning.mp4")
+	err = media.BurnSubtitles(ctx, videoPath, tmpPath, media.BurnInOptions{
+		Subtitle: t.StateManager.BurnInASS,
+		FontsDir: fontsDir,
+		Preset:   cfg.Preset,
+		CRF:      cfg.CRF,
+	})
+	if err != nil {
+		os.Remove(tmpPath)
+		t.App.Logger.Errorf("❌ 烧录字幕失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("烧录字幕失败: %v", err)
+		return false
+	}
+	if err := os.Rename(tmpPath, t.StateManager.BurnedVideo); err != nil {
+		os.Remove(tmpPath)
+		t.App.Logger.Errorf("❌ 保存烧录视频失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("保存烧录视频失败: %v", err)
+		return false
+	}
+
+	t.App.Logger.Infof("✅ 硬字幕烧录完成，耗时 %s: %s", time.Since(start).Round(time.Second), filepath.Base(t.StateManager.BurnedVideo))
+	taskContext["burn_in"] = map[string]interface{}{
+		"track":  track,
+		"cues":   len(cues),
+		"output": t.StateManager.BurnedVideo,
+	}
+	return true
+}
+
+// loadTrack 按配置读取要烧录的字幕，返回实际使用的字幕轨名称
+// translated 使用主目标语言的译文，原文即主目标语言时直接使用原文字幕；双语字幕不存在时退回译文
+func (t *BurnSubtitles) loadTrack(cfg *types.BurnInConfig, taskContext map[string]interface{}) (string, []subtitle.Cue, error) {
+	track := cfg.Track
+	if track == "bilingual" {
+		if _, err := os.Stat(t.StateManager.BilingualSRT); err != nil {
+			t.App.Logger.Warn("⚠️  未找到双语字幕（需启用 BilingualConfig），改为烧录译文字幕")
+			track = "translated"
+		}
+	}
+
+	var path string
+	switch track {
+	case "bilingual":
+		path = t.StateManager.BilingualSRT
+	case "source":
+		path = t.StateManager.SourceSRT
+	default:
+		track = "translated"
+		primary := primaryTarget(t.App, t.SavedVideoService, t.StateManager.VideoID)
+		path = t.StateManager.TranslatedSRT(primary)
+		if source := subtitleLanguage(t.SavedVideoService, taskContext, t.StateManager.VideoID); source == primary || lang.IsChinese(source) && lang.IsChinese(primary) {
+			path = t.StateManager.SourceSRT
+		}
+	}
+
+	cues, err := subtitle.ReadSRT(path)
+	if err != nil {
+		return track, nil, fmt.Errorf("读取字幕失败 (%s): %v", filepath.Base(path), err)
+	}
+	if len(cues) == 0 {
+		return track, nil, fmt.Errorf("字幕为空 (%s)", filepath.Base(path))
+	}
+	return track, cues, nil
+}
+
+// burnInASSOptions 硬字幕 ASS 样式：画布与视频分辨率一致，字号与边距按 1080p 配置等比缩放
+func burnInASSOptions(cfg *types.BurnInConfig, width, height int) (subtitle.ASSOptions, error) {
+	opts := subtitle.DefaultASSOptions()
+	if width <= 0 || height <= 0 {
+		width, height = opts.PlayResX, opts.PlayResY
+	}
+	opts.PlayResX, opts.PlayResY = width, height
+	// 竖屏视频按短边计算，避免字幕过大
+	scale := float64(min(width, height)) / 1080
+
+	style := &opts.Styles[0]
+	if cfg.FontName != "" {
+		style.FontName = cfg.FontName
+	}
+	if cfg.FontSize > 0 {
+		style.FontSize = cfg.FontSize
+	}
+	style.FontSize = int(math.Round(float64(style.FontSize) * scale))
+	style.Bold = cfg.Bold
+	for _, c := range []struct {
+		hex    string
+		colour *string
+	}{
+		{cfg.PrimaryColor, &style.PrimaryColour},
+		{cfg.OutlineColor, &style.OutlineColour},
+	} {
+		if c.hex == "" {
+			continue
+		}
+		colour, err := subtitle.ASSColor(c.hex)
+		if err != nil {
+			return opts, err
+		}
+		*c.colour = colour
+	}
+	if cfg.Outline > 0 {
+		style.Outline = cfg.Outline
+	}
+	style.Outline *= scale
+	style.Shadow = cfg.Shadow * scale
+	if cfg.MarginV > 0 {
+		style.MarginV = cfg.MarginV
+	}
+	style.MarginV = int(math.Round(float64(style.MarginV) * scale))
+	if cfg.Position == "top" {
+		style.Alignment = 8
+	}
+	return opts, nil
+}
+
+// burnedVideo 返回已烧录硬字幕的投稿文件（未启用烧录或文件不存在时返回 false）
+func burnedVideo(app *core.AppServer, sm *manager.StateManager) (string, bool) {
+	if cfg := app.Config.BurnInConfig; cfg == nil || !cfg.Enabled {
+		return "", false
+	}
+	if _, err := os.Stat(sm.BurnedVideo); err != nil {
+		return "", false
+	}
+	return sm.BurnedVideo, true
+}
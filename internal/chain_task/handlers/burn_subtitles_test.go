@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+)
+
+func TestBurnInASSOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           types.BurnInConfig
+		width, height int
+		wantResX      int
+		wantResY      int
+		wantFontSize  int
+		wantOutline   float64
+		wantShadow    float64
+		wantMarginV   int
+		wantAlignment int
+		wantPrimary   string
+		wantErr       bool
+	}{
+		{
+			name: "1080p 默认样式", width: 1920, height: 1080,
+			wantResX: 1920, wantResY: 1080, wantFontSize: 56, wantOutline: 2.5, wantMarginV: 48, wantAlignment: 2, wantPrimary: "&H00FFFFFF",
+		},
+		{
+			name: "720p 按比例缩小", width: 1280, height: 720,
+			cfg:      types.BurnInConfig{FontSize: 60, Outline: 3, Shadow: 1.5, MarginV: 60},
+			wantResX: 1280, wantResY: 720, wantFontSize: 40, wantOutline: 2, wantShadow: 1, wantMarginV: 40, wantAlignment: 2, wantPrimary: "&H00FFFFFF",
+		},
+		{
+			name: "竖屏按短边缩放", width: 1080, height: 1920,
+			wantResX: 1080, wantResY: 1920, wantFontSize: 56, wantOutline: 2.5, wantMarginV: 48, wantAlignment: 2, wantPrimary: "&H00FFFFFF",
+		},
+		{
+			name: "4K 放大并放在顶部", width: 3840, height: 2160,
+			cfg:      types.BurnInConfig{Position: "top", PrimaryColor: "#FF8800"},
+			wantResX: 3840, wantResY: 2160, wantFontSize: 112, wantOutline: 5, wantMarginV: 96, wantAlignment: 8, wantPrimary: "&H000088FF",
+		},
+		{
+			name: "未知分辨率使用默认画布", width: 0, height: 0,
+			wantResX: 1920, wantResY: 1080, wantFontSize: 56, wantOutline: 2.5, wantMarginV: 48, wantAlignment: 2, wantPrimary: "&H00FFFFFF",
+		},
+		{
+			name: "无效颜色", width: 1920, height: 1080,
+			cfg:     types.BurnInConfig{OutlineColor: "black"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := burnInASSOptions(&tt.cfg, tt.width, tt.height)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("burnInASSOptions() should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("burnInASSOptions() error = %v", err)
+			}
+			if opts.PlayResX != tt.wantResX || opts.PlayResY != tt.wantResY {
+				t.Errorf("PlayRes = %dx%d, want %dx%d", opts.PlayResX, opts.PlayResY, tt.wantResX, tt.wantResY)
+			}
+			style := opts.Styles[0]
+			if style.FontSize != tt.wantFontSize {
+				t.Errorf("FontSize = %d, want %d", style.FontSize, tt.wantFontSize)
+			}
+			if math.Abs(style.Outline-tt.wantOutline) > 1e-9 || math.Abs(style.Shadow-tt.wantShadow) > 1e-9 {
+				t.Errorf("Outline/Shadow = %v/%v, want %v/%v", style.Outline, style.Shadow, tt.wantOutline, tt.wantShadow)
+			}
+			if style.MarginV != tt.wantMarginV {
+				t.Errorf("MarginV = %d, want %d", style.MarginV, tt.wantMarginV)
+			}
+			if style.Alignment != tt.wantAlignment {
+				t.Errorf("Alignment = %d, want %d", style.Alignment, tt.wantAlignment)
+			}
+			if style.PrimaryColour != tt.wantPrimary {
+				t.Errorf("PrimaryColour = %q, want %q", style.PrimaryColour, tt.wantPrimary)
+			}
+		})
+	}
+}
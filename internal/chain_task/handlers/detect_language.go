@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/asr"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// DetectLanguage 检测原视频的语音语言并保存到视频记录
+// 按配置顺序依次尝试插件字幕语言、yt-dlp 元数据和 Whisper 试听检测，结果供获取字幕、语音识别、翻译和字幕上传使用
+type DetectLanguage struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewDetectLanguage(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *DetectLanguage {
+	return &DetectLanguage{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *DetectLanguage) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.LanguageConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  语言检测未启用，跳过")
+		return true
+	}
+
+	savedVideo, err := t.SavedVideoService.GetVideoByVideoID(t.StateManager.VideoID)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 查询视频信息失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("查询视频信息失败: %v", err)
+		return false
+	}
+
+	// 1. 按顺序尝试各检测来源
+	detected, from := "", ""
+	for _, source := range cfg.Sources {
+		var code string
+		switch source {
+		case model.LanguageFromSubtitle:
+			code = t.subtitleLanguage(savedVideo)
+		case model.LanguageFromMetadata:
+			if meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID); err == nil {
+				code = meta.Language
+			}
+		case model.LanguageFromWhisper:
+			code = t.probeLanguage(cfg.ProbeSeconds, cfg.ProbeStart)
+		default:
+			t.App.Logger.Warnf("⚠️  未知的语言检测来源: %s", source)
+			continue
+		}
+
+		if code = lang.Normalize(code); code != "" && code != "auto" {
+			detected, from = code, source
+			break
+		}
+	}
+
+	// 2. 检测失败时使用默认语言
+	if detected == "" {
+		detected, from = lang.Normalize(cfg.Default), model.LanguageFromDefault
+		if detected == "" {
+			detected = "en"
+		}
+		t.App.Logger.Warnf("⚠️  未能检测到原视频语言，使用默认语言: %s", detected)
+	}
+
+	if err := t.SavedVideoService.UpdateSourceLang(savedVideo.ID, detected, from); err != nil {
+		t.App.Logger.Warnf("⚠️  保存原视频语言失败: %v", err)
+	}
+
+	t.App.Logger.Infof("✅ 原视频语言: %s (%s, 来源: %s)", detected, lang.Name(detected), from)
+	taskContext["source_lang"] = detected
+	taskContext["source_lang_from"] = from
+	return true
+}
+
+// subtitleLanguage 浏览器插件提交的字幕语言
+func (t *DetectLanguage) subtitleLanguage(savedVideo *model.SavedVideo) string {
+	if savedVideo.Subtitles == "" || savedVideo.Subtitles == "null" {
+		return ""
+	}
+	var subtitles []model.SavedVideoSubtitle
+	if err := json.Unmarshal([]byte(savedVideo.Subtitles), &subtitles); err != nil {
+		return ""
+	}
+	for _, subtitle := range subtitles {
+		if subtitle.Lang != "" {
+			return subtitle.Lang
+		}
+	}
+	return ""
+}
+
+// probeLanguage 截取一段音频交给语音识别提供商自动检测语言
+func (t *DetectLanguage) probeLanguage(probeSeconds int, probeStart float64) string {
+	if !t.App.Config.TranscriptionEnabled() {
+		return ""
+	}
+	if probeSeconds <= 0 {
+		probeSeconds = 30
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// 从视频中部偏前截取，避开片头音乐和静音
+	start := 0.0
+	if duration, err := media.ProbeDuration(ctx, t.StateManager.InputVideoPath); err == nil && duration > float64(probeSeconds) {
+		start = min(duration*probeStart, duration-float64(probeSeconds))
+	}
+
+	clip := filepath.Join(t.StateManager.CurrentDir, "language_probe.wav")
+	defer os.Remove(clip)
+	if err := utils.ExtractWaveClip(ctx, t.StateManager.InputVideoPath, clip, start, float64(probeSeconds)); err != nil {
+		t.App.Logger.Warnf("⚠️  截取试听音频失败: %v", err)
+		return ""
+	}
+
+	provider := ""
+	if cfg := t.App.Config.AsrConfig; cfg != nil {
+		var channel, uploader, channelID string
+		if meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID); err == nil {
+			channel, uploader, channelID = meta.Channel, meta.Uploader, meta.ChannelID
+		}
+		provider = cfg.ProviderFor(channelID, channel, uploader)
+	}
+
+	t.App.Logger.Infof("🎧 试听 %.0fs-%.0fs 检测语言", start, start+float64(probeSeconds))
+	asrManager := asr.NewTranscriberManager(t.App.Config)
+	defer asrManager.Close()
+	result, err := asrManager.TranscribeWithProvider(ctx, provider, &asr.TranscriptionRequest{
+		AudioPath: clip,
+		Language:  "auto",
+	})
+	if err != nil {
+		t.App.Logger.Warnf("⚠️  语言检测失败: %v", err)
+		return ""
+	}
+	return result.Language
+}
+
+// spokenLanguage 原视频语音语言（优先任务上下文，其次视频记录），未检测时返回空字符串
+func spokenLanguage(videos *services.SavedVideoService, taskContext map[string]interface{}, videoID string) string {
+	if code, ok := taskContext["source_lang"].(string); ok && code != "" {
+		return code
+	}
+	if videos == nil {
+		return ""
+	}
+	if sourceLang, _, err := videos.GetLanguages(videoID); err == nil {
+		return lang.Normalize(sourceLang)
+	}
+	return ""
+}
+
+// subtitleLanguage 原文字幕语言（平台字幕可能与语音语言不同），依次回退到语音语言和英语
+func subtitleLanguage(videos *services.SavedVideoService, taskContext map[string]interface{}, videoID string) string {
+	if code, ok := taskContext["subtitle_lang"].(string); ok && lang.Normalize(code) != "" && code != "auto" {
+		return lang.Normalize(code)
+	}
+	if videos != nil {
+		if sourceLang, subtitleLang, err := videos.GetLanguages(videoID); err == nil {
+			for _, code := range []string{subtitleLang, sourceLang} {
+				if code = lang.Normalize(code); code != "" && code != "auto" {
+					return code
+				}
+			}
+		}
+	}
+	// 视频记录中没有语言时只检查任务上下文，避免重复查询
+	if code := spokenLanguage(nil, taskContext, videoID); code != "" {
+		return code
+	}
+	return "en"
+}
@@ -2,19 +2,26 @@ package handlers
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	stdcontext "context"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/difyz9/ytb2bili/internal/chain_task/base"
 	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/source"
 	"github.com/difyz9/ytb2bili/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -24,6 +31,13 @@ type DownloadVideo struct {
 	App               *core.AppServer
 	DB                *gorm.DB
 	SavedVideoService *services.SavedVideoService
+
+	// 下载前获取的元数据，下载完成后复用
+	metadata *source.Metadata
+	rawInfo  []byte
+
+	// 下载的文件未通过完整性校验（已删除），需要重新下载
+	corrupted bool
 }
 
 func NewDownloadVideo(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *DownloadVideo {
@@ -61,31 +75,13 @@ func (t *DownloadVideo) findYtDlp() (string, error) {
 
 // getVideoURL 根据 VideoID 构建完整的视频 URL
 func (t *DownloadVideo) getVideoURL() string {
-	videoID := t.StateManager.VideoID
-
-	// 如果已经是完整 URL，直接返回
-	if strings.HasPrefix(videoID, "http://") || strings.HasPrefix(videoID, "https://") {
-		return videoID
-	}
-
-	// YouTube 短 ID 格式
-	if len(videoID) == 11 && !strings.Contains(videoID, "/") {
-		return fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
-	}
-
-	// Bilibili BV 号
-	if strings.HasPrefix(videoID, "BV") {
-		return fmt.Sprintf("https://www.bilibili.com/video/%s", videoID)
-	}
-
-	// 默认作为 YouTube ID 处理
-	return fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
+	return sourceVideoURL(t.SavedVideoService, t.StateManager.VideoID)
 }
 
 func (t *DownloadVideo) Execute(context map[string]interface{}) bool {
 	t.App.Logger.Info("========================================")
 	t.App.Logger.Info("DownloadVideo Handler Version: with-cookies-support-v3") // 版本标记
-	t.App.Logger.Infof("开始下载视频: %s", t.StateManager.VideoID)
+	t.App.Logger.Infof("开始下载视频: %s (平台: %s)", t.StateManager.VideoID, source.DefaultRegistry().ForID(t.StateManager.VideoID).Name())
 	t.App.Logger.Info("========================================")
 
 	// 1. 查找 yt-dlp 可执行文件
@@ -103,7 +99,50 @@ func (t *DownloadVideo) Execute(context map[string]interface{}) bool {
 		return false
 	}
 
-	// 3. 尝试下载（先用代理，失败后不用代理重试）
+	// 3. 下载前检查直播/首播状态和可用性
+	metadata, rawInfo, err := t.getVideoMetadata(ytdlpPath)
+	if err != nil {
+		if availability, ok := source.ClassifyError(err.Error()); ok {
+			return t.handleUnavailable(availability, context)
+		}
+		t.App.Logger.Warnf("⚠️ 下载前获取视频元数据失败: %v，继续尝试下载", err)
+	} else {
+		if availability := source.ClassifyMetadata(metadata); availability.Outcome != source.OutcomeAvailable {
+			return t.handleUnavailable(availability, context)
+		}
+		t.metadata, t.rawInfo = metadata, rawInfo
+	}
+
+	// 4. 下载并校验，文件损坏时删除后重新下载一次
+	return t.downloadWithRetry(context, func() bool {
+		return t.download(ytdlpPath, context)
+	})
+}
+
+// downloadWithRetry 执行下载，文件未通过校验时重新下载一次
+// 下载成功时清除之前尝试（代理下载失败、文件损坏、代理出口地区受限）留下的错误和源视频状态，
+// 避免已下载的视频被标记为失败或暂停
+func (t *DownloadVideo) downloadWithRetry(context map[string]interface{}, download func() bool) bool {
+	for attempt := 1; attempt <= 2; attempt++ {
+		t.corrupted = false
+		if download() {
+			delete(context, "error")
+			delete(context, "source_status")
+			delete(context, "wake_at")
+			return true
+		}
+		if !t.corrupted {
+			return false
+		}
+		if attempt == 1 {
+			t.App.Logger.Warn("🔁 下载文件未通过校验，重新下载...")
+		}
+	}
+	return false
+}
+
+// download 尝试下载（先用代理，失败后不用代理重试）
+func (t *DownloadVideo) download(ytdlpPath string, context map[string]interface{}) bool {
 	videoURL := t.getVideoURL()
 	useProxy := t.App.Config != nil && t.App.Config.ProxyConfig != nil && 
 		t.App.Config.ProxyConfig.UseProxy && t.App.Config.ProxyConfig.ProxyHost != ""
@@ -114,6 +153,10 @@ func (t *DownloadVideo) Execute(context map[string]interface{}) bool {
 		if t.executeDownload(ytdlpPath, videoURL, true, context) {
 			return true
 		}
+		// 下载成功但文件损坏时由外层统一重试
+		if t.corrupted {
+			return false
+		}
 		t.App.Logger.Warn("⚠️ 代理下载失败，尝试不使用代理重试...")
 	}
 
@@ -128,7 +171,7 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 	command := []string{
 		ytdlpPath,
 		"-P", t.StateManager.CurrentDir,
-		"-o", "%(id)s.%(ext)s",
+		"-o", t.StateManager.VideoID + ".%(ext)s",
 		"--merge-output-format", "mp4",
 	}
 
@@ -162,14 +205,17 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 		t.App.Logger.Info("🌐 不使用代理")
 	}
 
-	// 添加视频标识符和URL
-	command = append(command, "--", t.StateManager.VideoID)
-	command = append(command, videoURL)
+	// 添加视频URL
+	command = append(command, "--", videoURL)
 
 	t.App.Logger.Infof("执行命令: %s", strings.Join(command, " "))
 	t.App.Logger.Infof("下载目录: %s", t.StateManager.CurrentDir)
 	t.App.Logger.Infof("视频URL: %s", videoURL)
 
+	// 记录已有的下载文件：yt-dlp 发现文件已存在时不会重新下载，该文件可能已被后续步骤处理过（如加了片头片尾）
+	existingFile := t.findDownloadedFile()
+	existingInfo, _ := os.Stat(existingFile)
+
 	// 创建命令并设置输出管道
 	cmd := exec.Command(command[0], command[1:]...)
 	cmd.Dir = t.StateManager.CurrentDir
@@ -196,13 +242,26 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 		return false
 	}
 
-	// 实时读取输出
-	go t.logOutput(stdout, "INFO")
-	go t.logOutput(stderr, "ERROR")
+	// 实时读取输出，同时保留错误输出用于判断失败原因
+	var stderrBuf bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		t.logOutput(stdout, "INFO")
+	}()
+	go func() {
+		defer wg.Done()
+		t.logOutput(io.TeeReader(stderr, &stderrBuf), "ERROR")
+	}()
+	wg.Wait()
 
 	// 等待命令完成
 	if err := cmd.Wait(); err != nil {
 		t.App.Logger.Errorf("❌ 视频下载失败: %v", err)
+		if availability, ok := source.ClassifyError(stderrBuf.String()); ok {
+			return t.handleUnavailable(availability, context)
+		}
 		context["error"] = fmt.Sprintf("下载失败: %v", err)
 		return false
 	}
@@ -216,13 +275,28 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 		return false
 	}
 
-	// 11. 保存文件信息到 context
+	// 11. 校验文件完整性（文件已存在、本次未重新下载时跳过，避免按源视频时长校验并删除处理过的文件）
+	if info, err := os.Stat(downloadedFile); err == nil && existingInfo != nil && downloadedFile == existingFile &&
+		info.Size() == existingInfo.Size() && info.ModTime().Equal(existingInfo.ModTime()) {
+		t.App.Logger.Infof("✓ 视频文件已存在，跳过校验: %s", filepath.Base(downloadedFile))
+	} else {
+		if !t.verifyDownload(downloadedFile, context) {
+			return false
+		}
+		// 重新下载的视频没有片头片尾，清除旧的处理结果
+		resetBranding(t.App, t.StateManager)
+	}
+
+	// 12. 保存文件信息到 context
 	context["downloaded_file"] = downloadedFile
 	t.App.Logger.Infof("✓ 视频下载成功: %s", downloadedFile)
 
-	// 12. 获取视频元数据（标题、描述等）
-	t.App.Logger.Info("📋 获取视频元数据...")
-	metadata, err := t.getVideoMetadata(ytdlpPath)
+	// 13. 获取视频元数据（标题、描述等），下载前已获取时直接复用
+	metadata, rawInfo := t.metadata, t.rawInfo
+	if metadata == nil {
+		t.App.Logger.Info("📋 获取视频元数据...")
+		metadata, rawInfo, err = t.getVideoMetadata(ytdlpPath)
+	}
 	if err != nil {
 		t.App.Logger.Warnf("⚠️ 获取视频元数据失败: %v，将使用默认值", err)
 	} else {
@@ -233,6 +307,16 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 			t.App.Logger.Infof("✓ 原始描述: %s", t.truncateString(metadata.Description, 100))
 		}
 
+		// 保存完整的 info JSON
+		infoPath := t.StateManager.InfoJSON
+		if err := os.WriteFile(infoPath, rawInfo, 0644); err != nil {
+			t.App.Logger.Warnf("⚠️ 保存 info JSON 失败: %v", err)
+			infoPath = ""
+		} else {
+			context["info_json_path"] = infoPath
+			t.App.Logger.Infof("✓ info JSON 已保存: %s", infoPath)
+		}
+
 		// 保存到数据库
 		if t.SavedVideoService != nil {
 			savedVideo, err := t.SavedVideoService.GetVideoByVideoID(t.StateManager.VideoID)
@@ -245,6 +329,14 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 					t.App.Logger.Info("✅ 原始元数据已保存到数据库")
 				}
 			}
+
+			sourceMeta := metadata.ToSourceMeta(t.StateManager.VideoID, t.StateManager.Id, infoPath)
+			if err := t.SavedVideoService.SaveSourceMeta(sourceMeta); err != nil {
+				t.App.Logger.Errorf("❌ 保存源视频元数据失败: %v", err)
+			} else {
+				t.App.Logger.Infof("✅ 源视频元数据已保存 (上传者: %s, 时长: %.0fs, 章节: %d)",
+					sourceMeta.Uploader, sourceMeta.Duration, len(metadata.Chapters))
+			}
 		}
 	}
 
@@ -253,6 +345,60 @@ func (t *DownloadVideo) executeDownload(ytdlpPath, videoURL string, useProxy boo
 	return true
 }
 
+// handleUnavailable 记录源视频不可下载的原因，由任务链根据 source_status 设置视频状态
+// 首播/直播未结束时写入 wake_at（下次检查时间），私享、删除、地区限制等写入终态
+func (t *DownloadVideo) handleUnavailable(availability source.Availability, context map[string]interface{}) bool {
+	cfg := t.App.Config.LiveConfig
+	if cfg == nil {
+		cfg = &types.LiveConfig{LiveMode: "wait", PollInterval: 30, StartDelay: 10}
+	}
+	pollInterval := time.Duration(cfg.PollInterval) * time.Minute
+	if pollInterval <= 0 {
+		pollInterval = 30 * time.Minute
+	}
+
+	outcome := availability.Outcome
+	var message string
+	switch outcome {
+	case source.OutcomeUpcoming:
+		wakeAt := time.Now().Add(pollInterval)
+		if !availability.ReleaseTime.IsZero() {
+			wakeAt = availability.ReleaseTime.Add(time.Duration(cfg.StartDelay) * time.Minute)
+		}
+		context["wake_at"] = wakeAt
+		message = fmt.Sprintf("视频尚未开始（首播/直播预告），将于 %s 重新检查", wakeAt.Format("2006-01-02 15:04"))
+	case source.OutcomeLive, source.OutcomePostLive:
+		if outcome == source.OutcomeLive && cfg.LiveMode == "skip" {
+			message = "视频正在直播，已按配置跳过"
+			break
+		}
+		wakeAt := time.Now().Add(pollInterval)
+		context["wake_at"] = wakeAt
+		message = fmt.Sprintf("直播尚未结束或回放仍在处理，将于 %s 重新检查", wakeAt.Format("2006-01-02 15:04"))
+	case source.OutcomePrivate:
+		message = "视频为私享视频，无法下载"
+	case source.OutcomeRemoved:
+		message = "视频已被删除或不可用"
+	case source.OutcomeGeoBlocked:
+		message = "视频存在地区限制，无法下载"
+	case source.OutcomeLoginRequired:
+		message = "视频需要登录、会员或年龄验证，无法下载"
+	}
+
+	if availability.Reason != "" {
+		message += " (" + availability.Reason + ")"
+	}
+	if _, parked := context["wake_at"]; parked {
+		t.App.Logger.Warnf("⏸️  %s", message)
+	} else {
+		t.App.Logger.Errorf("🚫 %s", message)
+	}
+
+	context["source_status"] = string(outcome)
+	context["error"] = message
+	return false
+}
+
 // logOutput 实时输出日志
 func (t *DownloadVideo) logOutput(reader io.Reader, level string) {
 	scanner := bufio.NewScanner(reader)
@@ -284,52 +430,58 @@ func (t *DownloadVideo) logOutput(reader io.Reader, level string) {
 	}
 }
 
-// findDownloadedFile 查找下载的视频文件
-func (t *DownloadVideo) findDownloadedFile() string {
-	// 查找目录下的 mp4 文件
-	files, err := filepath.Glob(filepath.Join(t.StateManager.CurrentDir, "*.mp4"))
-	if err != nil || len(files) == 0 {
-		// 尝试查找其他视频格式
-		for _, ext := range []string{"*.webm", "*.mkv", "*.flv"} {
-			files, err = filepath.Glob(filepath.Join(t.StateManager.CurrentDir, ext))
-			if err == nil && len(files) > 0 {
-				break
-			}
-		}
+// verifyDownload 使用 ffprobe 校验下载的文件（容器、时长、音视频流，可选完整解码）
+// 校验失败时删除文件并标记 corrupted，由 Execute 重新下载
+func (t *DownloadVideo) verifyDownload(path string, context map[string]interface{}) bool {
+	cfg := t.App.Config.DownloadCheckConfig
+	if cfg == nil || !cfg.Enabled {
+		return true
 	}
 
-	if len(files) > 0 {
-		// 返回最新的文件
-		latestFile := files[0]
-		latestTime := int64(0)
+	opts := media.VerifyOptions{
+		RequireVideo: true,
+		RequireAudio: cfg.RequireAudio,
+		FullDecode:   cfg.FullDecode,
+	}
+	if t.metadata != nil && t.metadata.Duration > 0 {
+		opts.ExpectedDuration = t.metadata.Duration
+		// 容差至少为时长的 1%，兼容不同平台时长取整方式
+		opts.DurationTolerance = math.Max(cfg.DurationTolerance, t.metadata.Duration*0.01)
+	}
 
-		for _, file := range files {
-			info, err := os.Stat(file)
-			if err != nil {
-				continue
-			}
-			if info.ModTime().Unix() > latestTime {
-				latestTime = info.ModTime().Unix()
-				latestFile = file
-			}
-		}
+	t.App.Logger.Infof("🔎 校验下载文件: %s", filepath.Base(path))
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 30*time.Minute)
+	defer cancel()
 
-		return latestFile
+	info, err := media.Verify(ctx, path, opts)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 下载文件校验失败: %v", err)
+		if removeErr := os.Remove(path); removeErr != nil {
+			t.App.Logger.Warnf("⚠️ 删除损坏文件失败: %v", removeErr)
+		}
+		t.corrupted = true
+		context["error"] = fmt.Sprintf("下载文件校验失败: %v", err)
+		return false
 	}
 
-	return ""
+	t.App.Logger.Infof("✓ 文件校验通过 (时长: %.1fs, 容器: %s)", info.Duration, info.FormatName)
+	return true
 }
 
-// VideoMetadataInfo 视频元数据信息
-type VideoMetadataInfo struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Uploader    string `json:"uploader"`
-	Duration    int    `json:"duration"`
+// findDownloadedFile 查找下载的视频文件
+// 只认下载输出 <VideoID>.<ext>，任务目录中还有烧录、片头片尾等步骤生成的 mp4 及其临时文件
+func (t *DownloadVideo) findDownloadedFile() string {
+	stem := strings.TrimSuffix(t.StateManager.InputVideoPath, filepath.Ext(t.StateManager.InputVideoPath))
+	for _, ext := range []string{".mp4", ".webm", ".mkv", ".flv"} {
+		if _, err := os.Stat(stem + ext); err == nil {
+			return stem + ext
+		}
+	}
+	return ""
 }
 
-// getVideoMetadata 使用 yt-dlp 获取视频元数据（带代理回退）
-func (t *DownloadVideo) getVideoMetadata(ytdlpPath string) (*VideoMetadataInfo, error) {
+// getVideoMetadata 使用 yt-dlp 获取视频元数据（带代理回退），同时返回原始 info JSON
+func (t *DownloadVideo) getVideoMetadata(ytdlpPath string) (*source.Metadata, []byte, error) {
 	videoURL := t.getVideoURL()
 
 	// 构建基础命令参数
@@ -374,19 +526,28 @@ func (t *DownloadVideo) getVideoMetadata(ytdlpPath string) (*VideoMetadataInfo,
 		cmd = exec.Command(ytdlpPath, argsNoProxy...)
 		output, err = cmd.Output()
 		if err != nil {
-			return nil, fmt.Errorf("获取元数据失败: %v", err)
+			return nil, nil, fmt.Errorf("获取元数据失败: %v%s", err, exitErrorOutput(err))
 		}
 		t.App.Logger.Info("✓ 不使用代理成功获取元数据")
 	} else if err != nil {
-		return nil, fmt.Errorf("获取元数据失败: %v", err)
+		return nil, nil, fmt.Errorf("获取元数据失败: %v%s", err, exitErrorOutput(err))
 	}
 
-	var metadata VideoMetadataInfo
-	if err := json.Unmarshal(output, &metadata); err != nil {
-		return nil, fmt.Errorf("解析元数据失败: %v", err)
+	// 由来源平台映射为统一元数据
+	metadata, err := source.DefaultRegistry().ForID(t.StateManager.VideoID).MapMetadata(output)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return &metadata, nil
+	return metadata, output, nil
+}
+
+// exitErrorOutput 提取命令的错误输出（用于判断失败原因）
+func exitErrorOutput(err error) string {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return "\n" + strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return ""
 }
 
 // truncateString 截断字符串用于日志显示
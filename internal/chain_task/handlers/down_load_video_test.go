@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestDownloadWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  []string // 每次下载的结果: ok / corrupt / fail / unavailable-ok / unavailable-corrupt
+		want      bool
+		wantCalls int
+		wantError bool
+	}{
+		{"代理地区受限后直连成功", []string{"unavailable-ok"}, true, 1, false},
+		{"代理地区受限且文件损坏后重新下载成功", []string{"unavailable-corrupt", "ok"}, true, 2, false},
+		{"一次成功", []string{"ok"}, true, 1, false},
+		{"损坏后重新下载成功", []string{"corrupt", "ok"}, true, 2, false},
+		{"两次都损坏", []string{"corrupt", "corrupt"}, false, 2, true},
+		{"下载失败不重试", []string{"fail"}, false, 1, true},
+		{"损坏后下载失败", []string{"corrupt", "fail"}, false, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &DownloadVideo{App: &core.AppServer{Logger: zap.NewNop().Sugar()}}
+			context := map[string]interface{}{}
+			calls := 0
+			got := task.downloadWithRetry(context, func() bool {
+				result := tt.attempts[calls]
+				calls++
+				if strings.HasPrefix(result, "unavailable-") {
+					// 代理下载被识别为不可用（handleUnavailable），随后不使用代理重试
+					context["source_status"] = "geo_blocked"
+					context["wake_at"] = time.Now()
+					context["error"] = "视频存在地区限制，无法下载"
+					result = strings.TrimPrefix(result, "unavailable-")
+				}
+				switch result {
+				case "corrupt":
+					task.corrupted = true
+					context["error"] = "下载文件校验失败"
+					return false
+				case "fail":
+					context["error"] = "下载失败"
+					return false
+				}
+				return true
+			})
+
+			if got != tt.want || calls != tt.wantCalls {
+				t.Errorf("downloadWithRetry = %v after %d calls, want %v after %d", got, calls, tt.want, tt.wantCalls)
+			}
+			if _, hasError := context["error"]; hasError != tt.wantError {
+				t.Errorf("context error = %v, want present=%v", context["error"], tt.wantError)
+			}
+			if tt.want {
+				if _, ok := context["source_status"]; ok {
+					t.Errorf("source_status = %v left after successful download", context["source_status"])
+				}
+				if _, ok := context["wake_at"]; ok {
+					t.Errorf("wake_at = %v left after successful download", context["wake_at"])
+				}
+			}
+		})
+	}
+}
+
+func TestFindDownloadedFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string // 按写入顺序创建，后写入的修改时间更新
+		want  string
+	}{
+		{"只有下载文件", []string{"v1.mp4"}, "v1.mp4"},
+		{"忽略更新的处理结果与临时文件", []string{"v1.mp4", "v1.burned.mp4", "v1.branding.mp4", "p1.mp4"}, "v1.mp4"},
+		{"未合并为 mp4 时按其他容器查找", []string{"v1.burned.mp4", "v1.webm"}, "v1.webm"},
+		{"mp4 优先", []string{"v1.mkv", "v1.mp4"}, "v1.mp4"},
+		{"没有下载文件", []string{"v1.loudnorm.mp4", "other.mp4"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := manager.NewStateManager(1, "v1", t.TempDir(), time.Now())
+			for i, file := range tt.files {
+				path := filepath.Join(sm.CurrentDir, file)
+				if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+					t.Fatal(err)
+				}
+				modTime := time.Now().Add(time.Duration(i) * time.Minute)
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			task := &DownloadVideo{BaseTask: base.BaseTask{StateManager: sm}}
+			got := task.findDownloadedFile()
+			if got != "" {
+				got = filepath.Base(got)
+			}
+			if got != tt.want {
+				t.Errorf("findDownloadedFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
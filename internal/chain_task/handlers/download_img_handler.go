@@ -1,14 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"github.com/difyz9/ytb2bili/internal/chain_task/base"
 	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/internal/core/models"
 	"github.com/difyz9/ytb2bili/pkg/cos"
-	"github.com/difyz9/ytb2bili/pkg/utils"
-	"fmt"
+	"github.com/difyz9/ytb2bili/pkg/source"
 	"gorm.io/gorm"
+	"os"
 	"time"
 )
 
@@ -30,61 +31,41 @@ func NewDownloadImgHandler(name string, app *core.AppServer, stateManager *manag
 
 }
 
-func (t *DownloadImgHandler) Execute(context map[string]interface{}) bool {
+func (t *DownloadImgHandler) Execute(taskContext map[string]interface{}) bool {
+	provider := source.DefaultRegistry().ForID(t.StateManager.VideoID)
 
-	opt := utils.DownloadOptions{
-		SavePath:         t.StateManager.CurrentDir,
-		FilenameTemplate: "{quality}",
-		Timeout:          10 * time.Second,
-		MaxRetries:       3,
-		QualityFallback:  true,
-		CreateDirs:       true,
-		Overwrite:        false,
+	// 读取下载阶段保存的 info JSON，获取平台封面地址（YouTube 可直接拼接，其他平台依赖该文件）
+	var meta *source.Metadata
+	if raw, err := os.ReadFile(t.StateManager.InfoJSON); err == nil {
+		if m, err := provider.MapMetadata(raw); err == nil {
+			meta = m
+		}
 	}
 
-	//utils.QualityMax,
-	qualities := []utils.ImageQuality{utils.QualityMax, utils.QualityStandard}
-	results := utils.DownloadYouTubeThumbnail(t.StateManager.VideoID, qualities, opt, "").(map[string]utils.DownloadResult)
-
-	var maxQualityCoverPath string
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-	for k, v := range results {
-		if v.Success {
-			fmt.Printf("下载成功: %s - %s (%d bytes)\n", k, v.FilePath, v.FileSize)
-			cosKeyName, _ := t.Client.UploadImageToCOS(v.FilePath, "")
+	coverPath, err := provider.DownloadThumbnail(ctx, t.StateManager.VideoID, meta, t.StateManager.CurrentDir)
+	if err != nil {
+		// 封面下载失败不影响后续任务，上传时会使用B站默认封面
+		t.App.Logger.Warnf("⚠️ 下载封面失败 (平台: %s): %v", provider.Name(), err)
+		return true
+	}
 
-			// 如果是最高质量的封面，保存到context中供后续上传使用
-			if k == string(utils.QualityMax) {
-				maxQualityCoverPath = v.FilePath
-				context["cover_image_path"] = v.FilePath
-				t.App.Logger.Infof("✓ 最高质量封面已下载: %s", v.FilePath)
-			}
+	taskContext["cover_image_path"] = coverPath
+	t.App.Logger.Infof("✓ 封面已下载 (平台: %s): %s", provider.Name(), coverPath)
 
-			// 更新数据库记录
-			tbVideo := &models.TbVideo{
-				Id:      t.StateManager.Id,
-				VideoId: t.StateManager.VideoID,
-				ImgURL:  cosKeyName,
-				Status:  "img",
-			}
-			err := t.StateManager.UpdateTBVideo(tbVideo)
-			if err != nil {
+	cosKeyName, _ := t.Client.UploadImageToCOS(coverPath, "")
 
-			}
-		} else {
-			fmt.Printf("下载失败: %s - %s\n", k, v.ErrorMessage)
-		}
+	// 更新数据库记录
+	tbVideo := &models.TbVideo{
+		Id:      t.StateManager.Id,
+		VideoId: t.StateManager.VideoID,
+		ImgURL:  cosKeyName,
+		Status:  "img",
 	}
-
-	// 如果没有下载到最高质量的封面，使用其他质量的封面
-	if maxQualityCoverPath == "" {
-		for _, v := range results {
-			if v.Success {
-				context["cover_image_path"] = v.FilePath
-				t.App.Logger.Infof("✓ 备用质量封面已设置: %s", v.FilePath)
-				break
-			}
-		}
+	if err := t.StateManager.UpdateTBVideo(tbVideo); err != nil {
+		t.App.Logger.Debugf("更新封面记录失败: %v", err)
 	}
 
 	return true
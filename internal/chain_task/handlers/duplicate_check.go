@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"os"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/fingerprint"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+)
+
+// DuplicateCheck 计算视频指纹并与已处理的视频比较，识别重新上传、搬运频道等重复内容
+// 疑似重复时在 context 中写入 duplicate_of，由任务链将视频状态置为暂停上传
+type DuplicateCheck struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+// uploadHeld 疑似重复暂停上传（210）或确认重复（298）的视频不允许投稿
+func uploadHeld(status string) bool {
+	return status == "210" || status == "298"
+}
+
+func NewDuplicateCheck(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *DuplicateCheck {
+	return &DuplicateCheck{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *DuplicateCheck) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.DuplicateConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  重复检测未启用，跳过")
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Warnf("⚠️  视频文件不存在，跳过重复检测: %s", videoPath)
+		return true
+	}
+
+	t.App.Logger.Infof("🔍 计算视频指纹: %s", t.StateManager.VideoID)
+
+	// 1. 计算指纹
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	fp, err := fingerprint.Compute(ctx, videoPath, fingerprint.Options{
+		FrameSamples: cfg.FrameSamples,
+		AudioSeconds: cfg.AudioSeconds,
+	})
+	if err != nil {
+		// 指纹计算失败不影响后续流程
+		t.App.Logger.Warnf("⚠️  计算视频指纹失败，跳过重复检测: %v", err)
+		return true
+	}
+
+	record := &model.VideoFingerprint{
+		VideoID:      t.StateManager.VideoID,
+		SavedVideoID: t.StateManager.Id,
+		Duration:     fp.Duration,
+		FrameHashes:  fingerprint.EncodeFrameHashes(fp.FrameHashes),
+		AudioHash:    fingerprint.EncodeAudio(fp.AudioBits, fp.AudioLength),
+	}
+
+	// 2. 与时长相近的视频比较
+	best, bestVideoID := t.findBestMatch(fp, cfg.DurationTolerance)
+	if bestVideoID != "" {
+		record.Similarity = best.Score
+		record.FrameScore = best.FrameScore
+		record.AudioScore = best.AudioScore
+		if best.IsDuplicate(cfg.Threshold) {
+			record.DuplicateOf = bestVideoID
+		}
+	}
+
+	if err := t.SavedVideoService.SaveFingerprint(record); err != nil {
+		t.App.Logger.Errorf("❌ 保存视频指纹失败: %v", err)
+		return true
+	}
+
+	if record.DuplicateOf == "" {
+		t.App.Logger.Infof("✅ 未发现重复视频（最高相似度: %.2f）", record.Similarity)
+		return true
+	}
+
+	// 3. 已人工放行的视频不再暂停
+	if record.Overridden {
+		t.App.Logger.Infof("✅ 疑似与 %s 重复（相似度: %.2f），已人工放行", record.DuplicateOf, record.Similarity)
+		return true
+	}
+
+	t.App.Logger.Warnf("⚠️  疑似与 %s 重复（相似度: %.2f，帧: %.2f，音频: %.2f），上传前需人工确认",
+		record.DuplicateOf, record.Similarity, record.FrameScore, record.AudioScore)
+	taskContext["duplicate_of"] = record.DuplicateOf
+	taskContext["duplicate_similarity"] = record.Similarity
+	return true
+}
+
+// findBestMatch 查找相似度最高的候选视频
+func (t *DuplicateCheck) findBestMatch(fp *fingerprint.Fingerprint, tolerance float64) (fingerprint.Match, string) {
+	limit := math.Max(tolerance, fp.Duration*0.02)
+	candidates, err := t.SavedVideoService.FindFingerprintCandidates(t.StateManager.VideoID, fp.Duration-limit, fp.Duration+limit)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 查询查重候选失败: %v", err)
+		return fingerprint.Match{}, ""
+	}
+
+	var best fingerprint.Match
+	var bestVideoID string
+	for _, candidate := range candidates {
+		frames, err := fingerprint.DecodeFrameHashes(candidate.FrameHashes)
+		if err != nil {
+			continue
+		}
+		audio, audioLen, err := fingerprint.DecodeAudio(candidate.AudioHash)
+		if err != nil {
+			audio, audioLen = nil, 0
+		}
+
+		match := fingerprint.Compare(fp, &fingerprint.Fingerprint{
+			Duration:    candidate.Duration,
+			FrameHashes: frames,
+			AudioBits:   audio,
+			AudioLength: audioLen,
+		})
+		if bestVideoID == "" || match.Score > best.Score {
+			best = match
+			bestVideoID = candidate.VideoID
+		}
+	}
+	return best, bestVideoID
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/source"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// FetchCaptions 通过 yt-dlp 获取视频平台自带的字幕轨（人工字幕优先，其次自动字幕）
+type FetchCaptions struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+// captionKind 字幕轨类型
+type captionKind struct {
+	Flag   string // yt-dlp 参数
+	Source string // 字幕来源标识
+	Label  string // 日志显示名称
+}
+
+func NewFetchCaptions(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *FetchCaptions {
+	return &FetchCaptions{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *FetchCaptions) Execute(context map[string]interface{}) bool {
+	t.App.Logger.Info("========================================")
+	t.App.Logger.Info("开始获取平台字幕")
+	t.App.Logger.Info("========================================")
+
+	// 1. 已有字幕（浏览器插件提交）时跳过
+	if subtitleFile, ok := context["subtitle_file"].(string); ok && subtitleFile != "" {
+		t.App.Logger.Infof("⏭️  已存在字幕文件，跳过平台字幕获取: %s", subtitleFile)
+		return true
+	}
+
+	cfg := t.App.Config.CaptionConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  平台字幕获取未启用，跳过")
+		return true
+	}
+
+	// 2. 查找 yt-dlp
+	var installDir string
+	if t.App.Config.YtDlpPath != "" {
+		installDir = t.App.Config.YtDlpPath
+	}
+	ytdlp := utils.NewYtDlpManager(t.App.Logger, installDir)
+	if !ytdlp.IsInstalled() {
+		t.App.Logger.Warn("⚠️  未找到 yt-dlp，跳过平台字幕获取")
+		return true
+	}
+
+	captionDir := filepath.Join(t.StateManager.CurrentDir, "captions")
+	if err := os.MkdirAll(captionDir, 0755); err != nil {
+		t.App.Logger.Errorf("❌ 创建字幕目录失败: %v", err)
+		context["error"] = err.Error()
+		return false
+	}
+
+	languages := cfg.Languages
+	if len(languages) == 0 {
+		languages = []string{"en"}
+	}
+	// 优先获取原视频语言的字幕轨
+	if spoken := spokenLanguage(t.SavedVideoService, context, t.StateManager.VideoID); spoken != "" && !slices.Contains(languages, spoken) {
+		languages = append([]string{spoken}, languages...)
+	}
+
+	// 3. 依次尝试人工字幕、自动字幕
+	kinds := []captionKind{
+		{"--write-subs", model.SubtitleSourcePlatformManual, "人工字幕"},
+	}
+	if cfg.UseAutoCaptions {
+		kinds = append(kinds, captionKind{"--write-auto-subs", model.SubtitleSourcePlatformAuto, "自动字幕"})
+	}
+
+	srtFilePath := t.StateManager.SourceSRT
+	for _, kind := range kinds {
+		t.App.Logger.Infof("🔍 尝试获取%s (语言: %s)...", kind.Label, strings.Join(languages, ","))
+
+		captionFile, captionLang, err := t.downloadCaption(ytdlp.GetBinaryPath(), captionDir, kind.Flag, kind.Source, languages)
+		if err != nil {
+			t.App.Logger.Warnf("⚠️  获取%s失败: %v", kind.Label, err)
+			continue
+		}
+
+		// 4. 转换为 SRT（自动字幕需要去除滚动重复行）
+		dedupe := kind.Source == model.SubtitleSourcePlatformAuto
+		count, err := convertCaption(captionFile, srtFilePath, dedupe)
+		if err != nil {
+			t.App.Logger.Warnf("⚠️  转换%s失败: %v", kind.Label, err)
+			continue
+		}
+
+		minCues := cfg.MinCues
+		if count < minCues {
+			t.App.Logger.Warnf("⚠️  %s仅有 %d 条（最少 %d 条），视为不可用", kind.Label, count, minCues)
+			os.Remove(srtFilePath)
+			continue
+		}
+
+		// 同时按语言保存为 <lang>.srt，供字幕上传使用
+		if code := lang.Normalize(captionLang); code != "" && code != "auto" {
+			if err := utils.CopyFile(srtFilePath, t.StateManager.SubtitlePath(code)); err != nil {
+				t.App.Logger.Warnf("⚠️  复制%s字幕文件失败: %v", lang.Name(code), err)
+			}
+		}
+
+		context["subtitle_file"] = srtFilePath
+		context["subtitle_count"] = count
+		context["subtitle_source"] = kind.Source
+		context["subtitle_lang"] = captionLang
+
+		t.App.Logger.Infof("✅ 使用平台%s: %s (%s, %d 条)", kind.Label, filepath.Base(captionFile), captionLang, count)
+		t.App.Logger.Info("========================================")
+		return true
+	}
+
+	t.App.Logger.Info("ℹ️  没有可用的平台字幕轨")
+	t.App.Logger.Info("========================================")
+	return true
+}
+
+// downloadCaption 调用 yt-dlp 下载字幕轨，返回按语言优先级选中的字幕文件
+func (t *FetchCaptions) downloadCaption(ytdlpPath, captionDir, flag, outputName string, languages []string) (string, string, error) {
+	// 清理上次获取的残留文件，避免误用旧字幕
+	if oldFiles, err := filepath.Glob(filepath.Join(captionDir, outputName+".*")); err == nil {
+		for _, f := range oldFiles {
+			os.Remove(f)
+		}
+	}
+
+	args := []string{
+		"--skip-download",
+		flag,
+		"--sub-langs", strings.Join(languages, ","),
+		"--sub-format", strings.Join(source.CaptionExts, "/"),
+		"-o", filepath.Join(captionDir, outputName+".%(ext)s"),
+	}
+	args = append(args, t.ytdlpNetworkArgs()...)
+	args = append(args, sourceVideoURL(t.SavedVideoService, t.StateManager.VideoID))
+
+	cmd := exec.Command(ytdlpPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", "", fmt.Errorf("yt-dlp 执行失败: %v, 输出: %s", err, truncateString(string(output), 300))
+	}
+
+	// yt-dlp 输出文件名格式: {outputName}.{lang}.{ext}
+	path, captionLang := source.SelectCaption(captionDir, outputName, languages)
+	if path == "" {
+		return "", "", fmt.Errorf("没有匹配语言的字幕轨")
+	}
+	return path, captionLang, nil
+}
+
+// convertCaption 将平台字幕文件（vtt/srv3）转换为 SRT 文件，返回字幕条数
+// dedupe 为 true 时会去除自动字幕中滚动重复的行
+func convertCaption(inputPath, outputPath string, dedupe bool) (int, error) {
+	cues, err := subtitle.ReadFile(inputPath)
+	if err != nil {
+		return 0, fmt.Errorf("读取字幕文件失败: %w", err)
+	}
+	if dedupe {
+		cues = subtitle.DedupeRolling(cues)
+	}
+	if len(cues) == 0 {
+		return 0, fmt.Errorf("字幕文件中没有有效内容")
+	}
+	if err := subtitle.WriteSRT(outputPath, cues); err != nil {
+		return 0, fmt.Errorf("写入 SRT 文件失败: %w", err)
+	}
+	return len(cues), nil
+}
+
+// ytdlpNetworkArgs 构建 cookies 和代理参数
+func (t *FetchCaptions) ytdlpNetworkArgs() []string {
+	var args []string
+
+	configDir := filepath.Dir(t.App.Config.Path)
+	cookiesPath := filepath.Join(configDir, "cookies.txt")
+	if _, err := os.Stat(cookiesPath); err != nil {
+		cookiesPath = "cookies.txt"
+	}
+	if _, err := os.Stat(cookiesPath); err == nil {
+		absPath, _ := filepath.Abs(cookiesPath)
+		args = append(args, "--cookies", absPath)
+	}
+
+	if t.App.Config.ProxyConfig != nil && t.App.Config.ProxyConfig.UseProxy && t.App.Config.ProxyConfig.ProxyHost != "" {
+		args = append(args, "--proxy", t.App.Config.ProxyConfig.ProxyHost)
+	}
+	return args
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+)
+
+// GenerateCover 从视频中挑选最佳帧并绘制中文标题生成封面
+// 没有平台封面，或来源频道配置为优先使用生成封面时执行，结果保存为 cover.jpg
+type GenerateCover struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewGenerateCover(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *GenerateCover {
+	return &GenerateCover{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *GenerateCover) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.CoverConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  自动生成封面未启用，跳过")
+		return true
+	}
+
+	// 1. 判断是否需要生成
+	var channel, uploader, channelID string
+	if meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID); err == nil {
+		channel, uploader, channelID = meta.Channel, meta.Uploader, meta.ChannelID
+	}
+	thumbnail := findCoverImage(t.StateManager, taskContext)
+	if thumbnail != "" && thumbnail != t.StateManager.ImageCover && !cfg.PrefersGenerated(channelID, channel, uploader) {
+		t.App.Logger.Infof("⏭️  已有平台封面，跳过生成: %s", filepath.Base(thumbnail))
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Errorf("❌ 视频文件不存在: %s", videoPath)
+		taskContext["error"] = "视频文件不存在"
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// 2. 抽取候选帧并评分
+	candidateDir := filepath.Join(t.StateManager.CurrentDir, "cover_candidates")
+	defer os.RemoveAll(candidateDir)
+
+	t.App.Logger.Infof("🖼️  抽取 %d 个候选帧生成封面", cfg.Samples)
+	frames, err := media.SampleFrames(ctx, videoPath, candidateDir, cfg.Samples, cfg.Width, cfg.Height)
+	if err != nil {
+		// 生成失败不影响上传，继续使用平台封面或B站默认封面
+		t.App.Logger.Warnf("⚠️  抽取候选帧失败: %v", err)
+		return true
+	}
+	best := frames[0]
+	t.App.Logger.Infof("✓ 最佳帧 %.1fs (得分 %.2f: 清晰度 %.2f, 亮度 %.2f, 对比度 %.2f, 人物 %.2f)",
+		best.Time, best.Score, best.Sharpness, best.Brightness, best.Contrast, best.Skin)
+
+	// 3. 绘制标题
+	title := t.coverTitle(cfg.TitleTemplate, channel, uploader)
+	err = media.RenderCover(ctx, best.Path, t.StateManager.ImageCover, title, media.CoverStyle{
+		FontFile:     cfg.FontFile,
+		FontSize:     cfg.FontSize,
+		FontColor:    cfg.FontColor,
+		BorderColor:  cfg.BorderColor,
+		BorderWidth:  cfg.BorderWidth,
+		BandColor:    cfg.BandColor,
+		Position:     cfg.Position,
+		MaxLines:     cfg.MaxLines,
+		MaxLineChars: cfg.MaxLineChars,
+	})
+	if err != nil {
+		t.App.Logger.Warnf("⚠️  生成封面失败: %v", err)
+		return true
+	}
+
+	t.App.Logger.Infof("✅ 封面已生成: %s (标题: %s)", filepath.Base(t.StateManager.ImageCover), displayTitle(title))
+	taskContext["cover_image_path"] = t.StateManager.ImageCover
+	taskContext["cover_frame_time"] = best.Time
+	taskContext["cover_frame_score"] = best.Score
+	return true
+}
+
+// coverTitle 按模板生成封面文字，AI 标题为空时回退到原标题
+func (t *GenerateCover) coverTitle(template, channel, uploader string) string {
+	if template == "" {
+		return ""
+	}
+
+	var originalTitle, aiTitle string
+	if savedVideo, err := t.SavedVideoService.GetVideoByVideoID(t.StateManager.VideoID); err == nil {
+		originalTitle = regexp.MustCompile(`\s*#[^\s#]+`).ReplaceAllString(savedVideo.Title, "")
+		aiTitle = savedVideo.GeneratedTitle
+	}
+	if aiTitle == "" {
+		aiTitle = originalTitle
+	}
+	if channel == "" {
+		channel = uploader
+	}
+
+	title := strings.NewReplacer(
+		"{ai_title}", aiTitle,
+		"{original_title}", originalTitle,
+		"{channel}", channel,
+		"{uploader}", uploader,
+	).Replace(template)
+	return strings.TrimSpace(title)
+}
+
+// findCoverImage 查找可用的封面图片：任务上下文中的封面、生成的封面、下载的平台封面
+func findCoverImage(sm *manager.StateManager, taskContext map[string]interface{}) string {
+	if path, ok := taskContext["cover_image_path"].(string); ok && path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	if _, err := os.Stat(sm.ImageCover); err == nil {
+		return sm.ImageCover
+	}
+	// YouTube 封面按清晰度命名，其他平台为 thumbnail.*
+	for _, name := range []string{"maxresdefault", "sddefault", "hqdefault", "mqdefault", "default", "thumbnail"} {
+		for _, ext := range []string{".jpg", ".jpeg", ".png", ".webp"} {
+			path := filepath.Join(sm.CurrentDir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// displayTitle 日志中显示的标题
+func displayTitle(title string) string {
+	if title == "" {
+		return "无"
+	}
+	return fmt.Sprintf("%q", title)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/internal/core/services"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
 	"gorm.io/gorm"
 )
 
@@ -146,7 +147,7 @@ func (g *GenerateMetadata) executeWithDeepSeek(context map[string]interface{}) b
 
 	// 5. 调用 DeepSeek API 生成标题和描述
 	g.App.Logger.Info("🤖 调用 DeepSeek API 生成标题和描述...")
-	metadata, err := g.generateMetadataFromDeepSeek(subtitleText)
+	metadata, err := g.generateMetadataFromDeepSeek(g.buildSourceContext() + subtitleText)
 	if err != nil {
 		g.App.Logger.Errorf("❌ 生成标题和描述失败: %v", err)
 		g.App.Logger.Warn("⚠️  将使用默认标题和描述，不影响视频上传")
@@ -209,34 +210,55 @@ func (g *GenerateMetadata) executeWithDeepSeek(context map[string]interface{}) b
 
 // extractTextFromSRT 从SRT内容中提取纯文本
 func (g *GenerateMetadata) extractTextFromSRT(srtContent string) string {
-	lines := strings.Split(srtContent, "\n")
-	var textLines []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// 跳过空行、序号行、时间码行
-		if line == "" || isNumber(line) || strings.Contains(line, "-->") {
-			continue
-		}
-		textLines = append(textLines, line)
+	cues, err := subtitle.ParseSRT(srtContent)
+	if err != nil {
+		return ""
 	}
-
-	return strings.Join(textLines, " ")
+	return subtitle.Transcript(cues)
 }
 
-// isNumber 检查字符串是否为数字
-func isNumber(s string) bool {
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return false
+// buildSourceContext 构建源视频信息（频道、标签、章节等），作为 AI 生成元数据的参考
+func (g *GenerateMetadata) buildSourceContext() string {
+	if g.SavedVideoService == nil {
+		return ""
+	}
+	meta, err := g.SavedVideoService.GetSourceMeta(g.StateManager.VideoID)
+	if err != nil {
+		return ""
+	}
+
+	var lines []string
+	if channel := meta.Channel; channel != "" {
+		lines = append(lines, "频道: "+channel)
+	} else if meta.Uploader != "" {
+		lines = append(lines, "上传者: "+meta.Uploader)
+	}
+	if tags := meta.GetTags(); len(tags) > 0 {
+		if len(tags) > 10 {
+			tags = tags[:10]
 		}
+		lines = append(lines, "原视频标签: "+strings.Join(tags, ", "))
 	}
-	return len(s) > 0
+	if categories := meta.GetCategories(); len(categories) > 0 {
+		lines = append(lines, "原视频分类: "+strings.Join(categories, ", "))
+	}
+	if chapters := meta.GetChapters(); len(chapters) > 0 {
+		titles := make([]string, 0, len(chapters))
+		for _, chapter := range chapters {
+			titles = append(titles, chapter.Title)
+		}
+		lines = append(lines, "章节: "+strings.Join(titles, " / "))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "【源视频信息】\n" + strings.Join(lines, "\n") + "\n\n【字幕】\n"
 }
 
 // generateMetadataFromDeepSeek 调用 DeepSeek API 生成标题和描述
 func (g *GenerateMetadata) generateMetadataFromDeepSeek(subtitleText string) (*VideoMetadata, error) {
-	prompt := fmt.Sprintf(`请根据以下视频字幕内容，生成一个吸引人的视频标题、详细描述和3-5个相关标签。
+	prompt := fmt.Sprintf(`请根据以下视频字幕内容（可能附带源视频信息），生成一个吸引人的视频标题、详细描述和3-5个相关标签。
 
 字幕内容：
 %s
@@ -443,7 +465,7 @@ func (g *GenerateMetadata) executeWithGeminiText(taskContext map[string]interfac
 	defer cancel()
 
 	g.App.Logger.Info("🤖 调用 Gemini 生成元数据...")
-	metadata, err := client.GenerateMetadataFromText(ctx, subtitleText)
+	metadata, err := client.GenerateMetadataFromText(ctx, g.buildSourceContext()+subtitleText)
 	if err != nil {
 		g.App.Logger.Errorf("❌ 生成元数据失败: %v", err)
 		return false
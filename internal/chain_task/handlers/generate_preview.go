@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// GeneratePreview 生成低码率 HLS 预览和中文及原文 WebVTT 字幕，便于上传前在 Web 界面审核翻译效果
+type GeneratePreview struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewGeneratePreview(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *GeneratePreview {
+	return &GeneratePreview{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *GeneratePreview) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.PreviewConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  预览生成未启用，跳过")
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Errorf("❌ 视频文件不存在: %s", videoPath)
+		taskContext["error"] = "视频文件不存在"
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	info, err := media.Probe(ctx, videoPath)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 读取媒体信息失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("读取媒体信息失败: %v", err)
+		return false
+	}
+
+	// 1. 重新生成 HLS（视频或字幕可能在之前的步骤中被修改）
+	previewDir := t.StateManager.M3u8FileDir
+	os.RemoveAll(previewDir)
+
+	t.App.Logger.Infof("📺 生成 HLS 预览 (最大高度 %d, 视频码率 %s)", cfg.MaxHeight, cfg.VideoBitrate)
+	err = utils.ConvertToHLSWithOptions(ctx, videoPath, previewDir, utils.HLSOptions{
+		SegmentSeconds: cfg.SegmentSeconds,
+		MaxHeight:      cfg.MaxHeight,
+		VideoBitrate:   cfg.VideoBitrate,
+		AudioBitrate:   cfg.AudioBitrate,
+	})
+	if err != nil {
+		t.App.Logger.Errorf("❌ 生成 HLS 预览失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("生成 HLS 预览失败: %v", err)
+		return false
+	}
+
+	// 2. 字幕转换为 WebVTT（各目标语言译文按翻译顺序，主目标语言默认显示；原文字幕按原文语言命名）
+	type previewSubtitle struct {
+		path     string
+		name     string
+		language string
+	}
+	var subtitles []previewSubtitle
+	seen := make(map[string]bool)
+	for _, status := range translatedSubtitles(t.StateManager) {
+		subtitles = append(subtitles, previewSubtitle{filepath.Join(t.StateManager.CurrentDir, status.File), lang.Name(status.Lang), status.Lang})
+		seen[status.Lang] = true
+	}
+	if sourceLang := subtitleLanguage(t.SavedVideoService, taskContext, t.StateManager.VideoID); !seen[sourceLang] {
+		subtitles = append(subtitles, previewSubtitle{t.StateManager.SourceSRT, lang.Name(sourceLang), sourceLang})
+	}
+
+	var tracks []media.SubtitleTrack
+	for _, sub := range subtitles {
+		if _, err := os.Stat(sub.path); err != nil {
+			continue
+		}
+		vtt := sub.language + ".vtt"
+		cues, err := subtitle.ReadSRT(sub.path)
+		if err == nil && len(cues) == 0 {
+			err = fmt.Errorf("字幕文件中没有有效内容")
+		}
+		if err == nil {
+			err = subtitle.WriteVTT(filepath.Join(previewDir, vtt), cues, media.HLSTimestampMap)
+		}
+		if err != nil {
+			t.App.Logger.Warnf("⚠️  转换字幕失败 %s: %v", filepath.Base(sub.path), err)
+			continue
+		}
+		t.App.Logger.Infof("✓ 字幕轨 %s: %d 条", sub.name, len(cues))
+		tracks = append(tracks, media.SubtitleTrack{Name: sub.name, Language: sub.language, URI: vtt, Default: len(tracks) == 0})
+	}
+
+	// 3. 主播放列表
+	master := media.HLSMaster{
+		Playlist:  "output.m3u8",
+		Duration:  info.Duration,
+		Subtitles: tracks,
+	}
+	if v := info.VideoStream(); v != nil && v.Height > 0 {
+		master.Width, master.Height = v.Width, v.Height
+		if cfg.MaxHeight > 0 && v.Height > cfg.MaxHeight {
+			master.Height = cfg.MaxHeight
+			master.Width = v.Width * cfg.MaxHeight / v.Height / 2 * 2
+		}
+	}
+	if _, err := media.WriteHLSMaster(previewDir, master); err != nil {
+		t.App.Logger.Errorf("❌ %v", err)
+		taskContext["error"] = err.Error()
+		return false
+	}
+
+	t.App.Logger.Infof("✅ 预览已生成 (%dx%d, 字幕轨 %d 个)", master.Width, master.Height, len(tracks))
+	taskContext["preview_url"] = fmt.Sprintf("/api/v1/videos/%s/preview/master.m3u8", t.StateManager.VideoID)
+	return true
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+)
+
+// GenerateStoryboard 生成 WebVTT 故事板（雪碧图 + thumbnails.vtt）和缩略图总览
+// 在片头片尾处理之后执行，时间轴与预览和最终上传的视频一致
+type GenerateStoryboard struct {
+	base.BaseTask
+	App *core.AppServer
+}
+
+func NewGenerateStoryboard(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient) *GenerateStoryboard {
+	return &GenerateStoryboard{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App: app,
+	}
+}
+
+func (t *GenerateStoryboard) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.StoryboardConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  故事板生成未启用，跳过")
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Errorf("❌ 视频文件不存在: %s", videoPath)
+		taskContext["error"] = "视频文件不存在"
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// 视频可能在之前的步骤中被修改，每次重新生成
+	os.RemoveAll(t.StateManager.StoryboardDir)
+
+	t.App.Logger.Info("🎞️  生成故事板与缩略图总览")
+	result, err := media.GenerateStoryboard(ctx, videoPath, t.StateManager.StoryboardDir, media.StoryboardOptions{
+		Interval:       cfg.Interval,
+		TileWidth:      cfg.TileWidth,
+		Columns:        cfg.Columns,
+		MaxTiles:       cfg.MaxTiles,
+		SheetInterval:  cfg.SheetInterval,
+		SheetTileWidth: cfg.SheetTileWidth,
+		SheetColumns:   cfg.SheetColumns,
+		MaxSheetTiles:  cfg.MaxSheetTiles,
+		FontFile:       cfg.FontFile,
+	})
+	if err != nil {
+		t.App.Logger.Errorf("❌ 生成故事板失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("生成故事板失败: %v", err)
+		return false
+	}
+
+	t.App.Logger.Infof("✅ 故事板已生成: %d 张缩略图（每 %.0f 秒）, 总览 %d 张（每 %.0f 秒）",
+		result.Count, result.Interval, result.SheetCount, result.SheetInterval)
+	taskContext["storyboard"] = result
+	return true
+}
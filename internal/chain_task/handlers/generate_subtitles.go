@@ -6,13 +6,14 @@ import (
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/internal/core/services"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
 	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
 	"github.com/difyz9/ytb2bili/pkg/utils"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 type GenerateSubtitles struct {
@@ -33,35 +34,17 @@ func NewGenerateSubtitles(name string, app *core.AppServer, stateManager *manage
 	}
 }
 
-// formatTime 将秒数转换为 SRT 时间格式 (HH:MM:SS,mmm)
-func (t *GenerateSubtitles) formatTime(seconds float64) string {
-	hours := int(seconds / 3600)
-	minutes := int((seconds - float64(hours*3600)) / 60)
-	secs := int(seconds - float64(hours*3600) - float64(minutes*60))
-	milliseconds := int((seconds - float64(int(seconds))) * 1000)
-
-	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, secs, milliseconds)
-}
-
-// generateSRT 生成 SRT 格式字幕内容
-func (t *GenerateSubtitles) generateSRT(subtitles []model.SavedVideoSubtitle) string {
-	var srtContent strings.Builder
-
-	for i, subtitle := range subtitles {
-		// SRT 序号（从1开始）
-		srtContent.WriteString(fmt.Sprintf("%d\n", i+1))
-
-		// 时间轴
-		startTime := t.formatTime(subtitle.Offset)
-		endTime := t.formatTime(subtitle.Offset + subtitle.Duration)
-		srtContent.WriteString(fmt.Sprintf("%s --> %s\n", startTime, endTime))
-
-		// 字幕文本
-		srtContent.WriteString(subtitle.Text)
-		srtContent.WriteString("\n\n")
+// subtitleCues 将数据库中的字幕条目转换为字幕
+func (t *GenerateSubtitles) subtitleCues(subtitles []model.SavedVideoSubtitle) []subtitle.Cue {
+	cues := make([]subtitle.Cue, 0, len(subtitles))
+	for _, sub := range subtitles {
+		cues = append(cues, subtitle.Cue{
+			Start: seconds(sub.Offset),
+			End:   seconds(sub.Offset + sub.Duration),
+			Text:  sub.Text,
+		})
 	}
-
-	return srtContent.String()
+	return cues
 }
 
 func (t *GenerateSubtitles) Execute(context map[string]interface{}) bool {
@@ -106,7 +89,7 @@ func (t *GenerateSubtitles) Execute(context map[string]interface{}) bool {
 	t.App.Logger.Infof("📝 找到 %d 条字幕", len(subtitles))
 
 	// 4. 生成 SRT 内容
-	srtContent := t.generateSRT(subtitles)
+	srtContent := subtitle.FormatSRT(t.subtitleCues(subtitles))
 
 	// 5. 确保输出目录存在
 	if err := os.MkdirAll(t.StateManager.CurrentDir, 0755); err != nil {
@@ -134,17 +117,24 @@ func (t *GenerateSubtitles) Execute(context map[string]interface{}) bool {
 		return false
 	}
 
-	enSrtFileName := fmt.Sprintf("%s.srt", "en")
-	enSrtFilePath := filepath.Join(t.StateManager.CurrentDir, enSrtFileName)
-
-	if err := utils.CopyFile(srtFilePath, enSrtFilePath); err != nil {
-		t.App.Logger.Errorf("❌ 复制英文字幕文件失败: %v", err)
-		context["error"] = fmt.Sprintf("复制英文字幕文件失败: %v", err)
+	// 同时按语言保存为 <lang>.srt（插件未提供语言时使用检测到的原视频语言），供字幕上传使用
+	subtitleLang := lang.Normalize(subtitles[0].Lang)
+	if subtitleLang == "" || subtitleLang == "auto" {
+		subtitleLang = spokenLanguage(t.SavedVideoService, context, t.StateManager.VideoID)
+	}
+	if subtitleLang == "" {
+		subtitleLang = "en"
+	}
+	if err := utils.CopyFile(srtFilePath, t.StateManager.SubtitlePath(subtitleLang)); err != nil {
+		t.App.Logger.Errorf("❌ 复制%s字幕文件失败: %v", lang.Name(subtitleLang), err)
+		context["error"] = fmt.Sprintf("复制%s字幕文件失败: %v", lang.Name(subtitleLang), err)
 	}
 
 	// 9. 保存字幕文件路径到 context，供后续任务使用
 	context["subtitle_file"] = srtFilePath
 	context["subtitle_count"] = len(subtitles)
+	context["subtitle_source"] = model.SubtitleSourceExtension
+	context["subtitle_lang"] = subtitleLang
 
 	// 10. 显示字幕预览（前3条）
 	previewCount := 3
@@ -2,38 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"gorm.io/gorm"
-	"html"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 
 	"os"
 	"regexp"
-	"strconv"
-	"strings"
 	"github.com/difyz9/ytb2bili/internal/chain_task/base"
 	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
 )
 
-// XMLText XML 字幕中的文本元素
-type XMLText struct {
-	XMLName  xml.Name `xml:"text"`
-	Start    string   `xml:"start,attr"`
-	Duration string   `xml:"dur,attr"`
-	Content  string   `xml:",chardata"`
-}
-
-// XMLTranscript XML 字幕文档
-type XMLTranscript struct {
-	XMLName xml.Name  `xml:"transcript"`
-	Texts   []XMLText `xml:"text"`
-}
-
 // TextInfo 字幕信息
 type TextInfo struct {
 	StartTime float64 `json:"start_time"`
@@ -204,28 +187,22 @@ func (t *Task03Handler) fetchSrtContent(srtURL string, useProxy bool) (*Transcri
 		return nil, fmt.Errorf("请求失败，状态码: %d", resp.StatusCode)
 	}
 
-	var transcript XMLTranscript
-	if err := xml.NewDecoder(resp.Body).Decode(&transcript); err != nil {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
 		return nil, err
 	}
 
-	var textInfos []TextInfo
-	for _, text := range transcript.Texts {
-		startTime, err := strconv.ParseFloat(text.Start, 64)
-		if err != nil {
-			return nil, fmt.Errorf("无法解析起始时间: %v", err)
-		}
-
-		duration, err := strconv.ParseFloat(text.Duration, 64)
-		if err != nil {
-			return nil, fmt.Errorf("无法解析持续时间: %v", err)
-		}
+	cues, err := subtitle.ParseYouTubeXML(body)
+	if err != nil {
+		return nil, err
+	}
 
-		// 处理特殊字符
+	var textInfos []TextInfo
+	for _, cue := range cues {
 		textInfos = append(textInfos, TextInfo{
-			StartTime: startTime,
-			Duration:  duration,
-			Content:   strings.ReplaceAll(html.UnescapeString(text.Content), "\u00A0", " "),
+			StartTime: cue.Start.Seconds(),
+			Duration:  cue.Duration().Seconds(),
+			Content:   cue.Text,
 		})
 	}
 
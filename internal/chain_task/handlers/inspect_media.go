@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// InspectMedia 读取投稿文件的媒体信息并检查是否符合B站投稿限制
+// 在所有会重新编码视频的步骤之后执行，检查结果保存到数据库，可选在不符合时自动转码
+type InspectMedia struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewInspectMedia(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *InspectMedia {
+	return &InspectMedia{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *InspectMedia) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.MediaCheckConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  媒体检查未启用，跳过")
+		return true
+	}
+
+	videoPath := submissionVideo(t.App, t.StateManager)
+	if videoPath == "" {
+		t.App.Logger.Error("❌ 未找到视频文件，无法进行媒体检查")
+		taskContext["error"] = "未找到视频文件"
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	// 1. 读取媒体信息并检查
+	t.App.Logger.Infof("🔎 媒体检查: %s", filepath.Base(videoPath))
+	info, err := media.Probe(ctx, videoPath)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 读取媒体信息失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("读取媒体信息失败: %v", err)
+		return false
+	}
+
+	constraints := mediaConstraints(cfg)
+	problems := constraints.Check(videoPath, info)
+	transcoded := false
+
+	// 2. 不符合限制时自动转码
+	if len(problems) > 0 && cfg.AutoTranscode {
+		for _, p := range problems {
+			t.App.Logger.Warnf("⚠️  %s", p.Message)
+		}
+		newPath, err := t.transcode(ctx, videoPath, info, cfg)
+		if err != nil {
+			t.App.Logger.Errorf("❌ 自动转码失败: %v", err)
+		} else if newInfo, err := media.Probe(ctx, newPath); err != nil {
+			t.App.Logger.Errorf("❌ 读取转码后的媒体信息失败: %v", err)
+		} else {
+			videoPath, info, transcoded = newPath, newInfo, true
+			problems = constraints.Check(videoPath, info)
+		}
+	}
+
+	// 3. 保存检查结果
+	if err := t.SavedVideoService.SaveMediaInfo(buildMediaInfo(t.StateManager, videoPath, info, problems, transcoded)); err != nil {
+		t.App.Logger.Errorf("❌ 保存媒体信息失败: %v", err)
+	}
+
+	if len(problems) == 0 {
+		t.App.Logger.Infof("✅ 媒体检查通过 (%s)", describeMedia(info))
+		return true
+	}
+
+	for _, p := range problems {
+		t.App.Logger.Warnf("⚠️  %s", p.Message)
+	}
+	t.App.Logger.Warnf("⚠️  媒体检查发现 %d 个问题，上传可能被B站拒绝 (%s)", len(problems), describeMedia(info))
+	taskContext["media_problems"] = len(problems)
+	return true
+}
+
+// submissionVideo 查找实际投稿的文件：已烧录硬字幕时为烧录后的视频，否则为下载的 <VideoID>.<ext>
+// 不按目录扫描，避免片头片尾、烧录、响度标准化中断后留下的临时文件被当作投稿文件
+func submissionVideo(app *core.AppServer, sm *manager.StateManager) string {
+	if burned, ok := burnedVideo(app, sm); ok {
+		return burned
+	}
+	if _, err := os.Stat(sm.InputVideoPath); err == nil {
+		return sm.InputVideoPath
+	}
+	for _, ext := range []string{".webm", ".mkv", ".flv", ".mov"} {
+		path := strings.TrimSuffix(sm.InputVideoPath, ".mp4") + ext
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// minVideoBitrate 按大小上限自动转码时的最低视频码率（bit/s）
+const minVideoBitrate = 300000
+
+// sizeBudgetBitrate 超过大小上限时按时长计算目标视频码率（预留 5% 给容器开销，192 kbps 给音频），
+// 未超过上限或无法计算时返回 0（不限制码率）。
+// 视频过长时计算结果可能过低甚至为负，此时使用最低码率并返回 floored = true
+func sizeBudgetBitrate(info *media.Info, maxSizeMB int64) (bitrate int64, floored bool) {
+	if maxSizeMB <= 0 || info.Size <= maxSizeMB*1024*1024 || info.Duration <= 0 {
+		return 0, false
+	}
+	totalBits := float64(maxSizeMB*1024*1024*8) * 0.95
+	bitrate = int64(totalBits/info.Duration) - 192000
+	if bitrate < minVideoBitrate {
+		return minVideoBitrate, true
+	}
+	return bitrate, false
+}
+
+// transcode 转码为 H.264/AAC mp4 并替换原文件，返回新文件路径
+func (t *InspectMedia) transcode(ctx context.Context, videoPath string, info *media.Info, cfg *types.MediaCheckConfig) (string, error) {
+	opts := utils.TranscodeOptions{
+		MaxWidth:  cfg.MaxWidth,
+		MaxHeight: cfg.MaxHeight,
+		MaxFPS:    cfg.MaxFPS,
+		Duration:  info.Duration,
+	}
+	var floored bool
+	opts.VideoBitrate, floored = sizeBudgetBitrate(info, cfg.MaxSizeMB)
+	if floored {
+		t.App.Logger.Warnf("⚠️  按大小上限 %d MB 计算的视频码率过低，改用最低码率 %d kbps，转码后文件可能仍超过上限",
+			cfg.MaxSizeMB, minVideoBitrate/1000)
+	}
+
+	tmpPath := filepath.Join(t.StateManager.CurrentDir, t.StateManager.VideoID+".transcode.mp4")
+	t.App.Logger.Infof("🔄 自动转码: %s", filepath.Base(videoPath))
+	if err := utils.TranscodeVideoWithOptions(ctx, videoPath, tmpPath, opts, nil); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+
+	// 投稿文件是烧录后的视频时只替换该文件，InputVideoPath 仍供预览与故事板使用
+	if videoPath == t.StateManager.BurnedVideo {
+		if err := os.Rename(tmpPath, videoPath); err != nil {
+			os.Remove(tmpPath)
+			return "", fmt.Errorf("替换原文件失败: %w", err)
+		}
+		t.App.Logger.Info("✅ 自动转码完成")
+		return videoPath, nil
+	}
+	if err := replaceInputVideo(t.StateManager, videoPath, tmpPath); err != nil {
+		return "", err
+	}
+	t.App.Logger.Info("✅ 自动转码完成")
+	return t.StateManager.InputVideoPath, nil
+}
+
+// mediaConstraints 将配置转换为检查限制
+func mediaConstraints(cfg *types.MediaCheckConfig) media.Constraints {
+	return media.Constraints{
+		MaxSizeBytes:       cfg.MaxSizeMB * 1024 * 1024,
+		MaxDurationSeconds: float64(cfg.MaxDurationMinutes * 60),
+		MaxWidth:           cfg.MaxWidth,
+		MaxHeight:          cfg.MaxHeight,
+		MaxFPS:             cfg.MaxFPS,
+		Containers:         cfg.Containers,
+		VideoCodecs:        cfg.VideoCodecs,
+		AudioCodecs:        cfg.AudioCodecs,
+	}
+}
+
+// buildMediaInfo 构建媒体信息记录
+func buildMediaInfo(sm *manager.StateManager, path string, info *media.Info, problems []media.Problem, transcoded bool) *model.VideoMediaInfo {
+	record := &model.VideoMediaInfo{
+		VideoID:      sm.VideoID,
+		SavedVideoID: sm.Id,
+		FilePath:     path,
+		Container:    strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."),
+		FormatName:   info.FormatName,
+		Duration:     info.Duration,
+		Size:         info.Size,
+		BitRate:      info.BitRate,
+		Transcoded:   transcoded,
+		CheckedAt:    time.Now(),
+	}
+	if v := info.VideoStream(); v != nil {
+		record.VideoCodec = v.CodecName
+		record.Width = v.Width
+		record.Height = v.Height
+		record.FPS = v.FrameRate
+	}
+	if a := info.AudioStream(); a != nil {
+		record.AudioCodec = a.CodecName
+	}
+	if data, err := json.Marshal(info.Streams); err == nil {
+		record.Streams = string(data)
+	}
+
+	mediaProblems := make([]model.MediaProblem, 0, len(problems))
+	for _, p := range problems {
+		mediaProblems = append(mediaProblems, model.MediaProblem{Code: p.Code, Message: p.Message})
+	}
+	if data, err := json.Marshal(mediaProblems); err == nil {
+		record.Problems = string(data)
+	}
+	return record
+}
+
+// describeMedia 媒体信息摘要（用于日志）
+func describeMedia(info *media.Info) string {
+	desc := fmt.Sprintf("%.1f MB, %.0fs", float64(info.Size)/1024/1024, info.Duration)
+	if v := info.VideoStream(); v != nil {
+		desc += fmt.Sprintf(", %s %dx%d@%.2f", v.CodecName, v.Width, v.Height, v.FrameRate)
+	}
+	if a := info.AudioStream(); a != nil {
+		desc += ", " + a.CodecName
+	}
+	return desc
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/difyz9/ytb2bili/pkg/media"
+)
+
+func TestSizeBudgetBitrate(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name        string
+		size        int64
+		duration    float64
+		maxSizeMB   int64
+		want        int64
+		wantFloored bool
+	}{
+		{"不限制大小", 500 * mb, 600, 0, 0, false},
+		{"未超过大小上限", 80 * mb, 600, 100, 0, false},
+		{"恰好等于大小上限", 100 * mb, 600, 100, 0, false},
+		{"时长未知", 200 * mb, 0, 100, 0, false},
+		// 100 MB * 8 * 0.95 / 600 秒 - 192 kbps 音频
+		{"按时长计算码率", 200 * mb, 600, 100, 1136196, false},
+		{"视频过长使用最低码率", 2000 * mb, 7200, 100, minVideoBitrate, true},
+		{"计算结果略低于最低码率", 2000 * mb, 1700, 100, minVideoBitrate, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &media.Info{Size: tt.size, Duration: tt.duration}
+			got, floored := sizeBudgetBitrate(info, tt.maxSizeMB)
+			if got != tt.want || floored != tt.wantFloored {
+				t.Errorf("sizeBudgetBitrate() = %d, %v, want %d, %v", got, floored, tt.want, tt.wantFloored)
+			}
+		})
+	}
+}
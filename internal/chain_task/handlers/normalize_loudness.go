@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+)
+
+// NormalizeLoudness 按 EBU R128 标准化音频响度
+// 第一遍测量综合响度，第二遍线性标准化并重新封装，视频流不重新编码
+type NormalizeLoudness struct {
+	base.BaseTask
+	App *core.AppServer
+}
+
+func NewNormalizeLoudness(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient) *NormalizeLoudness {
+	return &NormalizeLoudness{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App: app,
+	}
+}
+
+func (t *NormalizeLoudness) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.LoudnormConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  响度标准化未启用，跳过")
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Errorf("❌ 视频文件不存在: %s", videoPath)
+		taskContext["error"] = "视频文件不存在"
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	target := media.LoudnessTarget{
+		Integrated: cfg.TargetI,
+		TruePeak:   cfg.TruePeak,
+		LRA:        cfg.LRA,
+	}
+
+	// 1. 第一遍：测量
+	t.App.Logger.Infof("🔊 测量响度 (目标 %.1f LUFS / %.1f dBTP / %.1f LU)", target.Integrated, target.TruePeak, target.LRA)
+	measured, err := media.MeasureLoudness(ctx, videoPath, target)
+	if errors.Is(err, media.ErrNoAudio) {
+		t.App.Logger.Info("⏭️  视频没有音轨或音频为静音，跳过响度标准化")
+		return true
+	}
+	if err != nil {
+		t.App.Logger.Errorf("❌ 测量响度失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("测量响度失败: %v", err)
+		return false
+	}
+	t.App.Logger.Infof("📏 原始响度: %.1f LUFS, 真峰值 %.1f dBTP, 响度范围 %.1f LU",
+		measured.InputI, measured.InputTP, measured.InputLRA)
+
+	// 测量值保存到步骤结果中
+	result := map[string]interface{}{
+		"target_i":   target.Integrated,
+		"target_tp":  target.TruePeak,
+		"target_lra": target.LRA,
+		"measured":   measured,
+		"normalized": false,
+	}
+	taskContext["loudnorm"] = result
+
+	// 已接近目标且未超过峰值上限时不处理（重试时也不会重复标准化）
+	if math.Abs(measured.InputI-target.Integrated) <= cfg.Tolerance && measured.InputTP <= target.TruePeak {
+		t.App.Logger.Infof("✅ 响度已符合目标（偏差 %.1f LU），跳过标准化", measured.InputI-target.Integrated)
+		return true
+	}
+
+	// 2. 第二遍：标准化
+	tmpPath := filepath.Join(t.StateManager.CurrentDir, t.StateManager.VideoID+".loudnorm.mp4")
+	output, err := media.NormalizeLoudness(ctx, videoPath, tmpPath, target, measured, cfg.AudioBitrate)
+	if err != nil {
+		os.Remove(tmpPath)
+		t.App.Logger.Errorf("❌ 响度标准化失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("响度标准化失败: %v", err)
+		return false
+	}
+
+	if err := replaceInputVideo(t.StateManager, videoPath, tmpPath); err != nil {
+		t.App.Logger.Errorf("❌ %v", err)
+		taskContext["error"] = err.Error()
+		return false
+	}
+
+	t.App.Logger.Infof("✅ 响度标准化完成: %.1f LUFS → %.1f LUFS, 真峰值 %.1f dBTP",
+		measured.InputI, output.InputI, output.InputTP)
+	result["normalized"] = true
+	result["output"] = output
+	return true
+}
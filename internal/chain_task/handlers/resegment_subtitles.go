@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// ResegmentSubtitles 按可读性限制重新断句
+// 原文模式在翻译前整理原文字幕（语音识别有词级时间戳时按词切分），译文模式在翻译后整理中文字幕
+type ResegmentSubtitles struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+	Target            bool // true=整理译文字幕，false=整理原文字幕
+}
+
+func NewResegmentSubtitles(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService, target bool) *ResegmentSubtitles {
+	return &ResegmentSubtitles{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+		Target:            target,
+	}
+}
+
+func (t *ResegmentSubtitles) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.SegmentConfig
+	if cfg == nil || !cfg.Enabled || (t.Target && !cfg.Target) || (!t.Target && !cfg.Source) {
+		t.App.Logger.Info("⏭️  字幕断句未启用，跳过")
+		return true
+	}
+
+	if !t.Target {
+		return t.resegment(t.StateManager.SourceSRT, taskContext)
+	}
+
+	// 译文: 每种目标语言的字幕分别断句
+	primaryPath := primaryTranslatedSRT(t.App, t.SavedVideoService, t.StateManager)
+	paths := []string{primaryPath}
+	for _, status := range translatedSubtitles(t.StateManager) {
+		if path := filepath.Join(t.StateManager.CurrentDir, status.File); path != primaryPath {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		if !t.resegment(path, taskContext) {
+			return false
+		}
+	}
+
+	// 译文时间轴已变化，重新生成双语字幕
+	updateBilingualSubtitles(t.App, t.SavedVideoService, t.StateManager, taskContext)
+	return true
+}
+
+// resegment 重新断句一个字幕文件并写回，文件不存在时跳过
+func (t *ResegmentSubtitles) resegment(path string, taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.SegmentConfig
+	if _, err := os.Stat(path); err != nil {
+		t.App.Logger.Infof("⏭️  字幕文件不存在，跳过断句: %s", path)
+		return true
+	}
+
+	// 1. 读取字幕
+	cues, err := subtitle.ReadSRT(path)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 读取字幕文件失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("读取字幕文件失败: %v", err)
+		return false
+	}
+	if len(cues) == 0 {
+		t.App.Logger.Infof("⏭️  字幕为空，跳过断句: %s", filepath.Base(path))
+		return true
+	}
+
+	// 2. 原文字幕来自语音识别时使用词级时间戳
+	words := false
+	if !t.Target {
+		if timed := t.loadWords(cues); timed != nil {
+			cues, words = timed, true
+		}
+	}
+
+	// 3. 重新断句并写回
+	result := subtitle.Resegment(cues, segmentOptions(cfg))
+	if err := subtitle.WriteSRT(path, result); err != nil {
+		t.App.Logger.Errorf("❌ 写入字幕文件失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("写入字幕文件失败: %v", err)
+		return false
+	}
+	if !t.Target {
+		code := subtitleLanguage(t.SavedVideoService, taskContext, t.StateManager.VideoID)
+		if err := utils.CopyFile(path, t.StateManager.SubtitlePath(code)); err != nil {
+			t.App.Logger.Warnf("⚠️  复制字幕文件失败: %v", err)
+		}
+	}
+
+	t.App.Logger.Infof("✅ 字幕断句完成 %s: %d 条 → %d 条 (词级时间戳: %v)", filepath.Base(path), len(cues), len(result), words)
+	results, _ := taskContext["resegment"].(map[string]interface{})
+	if results == nil {
+		results = map[string]interface{}{"target": t.Target}
+		taskContext["resegment"] = results
+	}
+	results[filepath.Base(path)] = map[string]interface{}{
+		"before": len(cues),
+		"after":  len(result),
+		"words":  words,
+	}
+	return true
+}
+
+// loadWords 读取语音识别的词级时间戳，文本与字幕不一致（字幕来自其他来源或已修改）时返回 nil
+func (t *ResegmentSubtitles) loadWords(cues []subtitle.Cue) []subtitle.Cue {
+	data, err := os.ReadFile(t.StateManager.SourceWords)
+	if err != nil {
+		return nil
+	}
+	var timed []subtitle.Cue
+	if err := json.Unmarshal(data, &timed); err != nil || len(timed) == 0 {
+		return nil
+	}
+	if compactText(timed) != compactText(cues) {
+		t.App.Logger.Info("ℹ️  词级时间戳与字幕文本不一致，按字数分配时间")
+		return nil
+	}
+	return timed
+}
+
+// compactText 拼接字幕文本并去掉空白，用于比较内容
+func compactText(cues []subtitle.Cue) string {
+	var sb strings.Builder
+	for _, cue := range cues {
+		for _, r := range cue.Text {
+			if !unicode.IsSpace(r) {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// segmentOptions 配置转换为断句参数，未配置的限制使用默认值
+func segmentOptions(cfg *types.SegmentConfig) subtitle.SegmentOptions {
+	opts := subtitle.DefaultSegmentOptions()
+	opts.MaxMergeGap = seconds(cfg.MaxMergeGap)
+	if cfg.CJK != nil {
+		opts.CJK = segmentLimits(cfg.CJK)
+	}
+	if cfg.Latin != nil {
+		opts.Latin = segmentLimits(cfg.Latin)
+	}
+	return opts
+}
+
+func segmentLimits(l *types.SegmentLimits) subtitle.Limits {
+	return subtitle.Limits{
+		MaxLineChars: l.MaxLineChars,
+		MaxLines:     l.MaxLines,
+		MinDuration:  seconds(l.MinDuration),
+		MaxDuration:  seconds(l.MaxDuration),
+		MaxCPS:       l.MaxCPS,
+	}
+}
+
+// seconds 秒数转换为时长
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
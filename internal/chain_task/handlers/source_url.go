@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/source"
+)
+
+// sourceVideoURL 获取视频在来源平台的地址（下载视频、获取平台字幕、补充元数据共用）
+func sourceVideoURL(videos *services.SavedVideoService, videoID string) string {
+	var savedURL string
+	if videos != nil {
+		if savedVideo, err := videos.GetVideoByVideoID(videoID); err == nil {
+			savedURL = savedVideo.URL
+		}
+	}
+	return resolveSourceURL(videoID, savedURL)
+}
+
+// resolveSourceURL 根据视频 ID 获取来源平台的视频地址
+// 通用站点的 ID、无法确定平台的历史 ID 无法还原为地址，使用提交时保存的原始 URL
+func resolveSourceURL(videoID, savedURL string) string {
+	if strings.HasPrefix(videoID, "http://") || strings.HasPrefix(videoID, "https://") {
+		return videoID
+	}
+	if videoURL := source.DefaultRegistry().ForID(videoID).VideoURL(videoID); videoURL != "" {
+		return videoURL
+	}
+	return savedURL
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// 人工审核字幕（HTTP 接口）使用的辅助函数
+
+// reviewContextSize 重新翻译单条字幕时前后各带的原文上下文条数
+const reviewContextSize = 3
+
+// SourceLanguage 视频的原文字幕语言
+func SourceLanguage(videos *services.SavedVideoService, videoID string) string {
+	return subtitleLanguage(videos, map[string]interface{}{}, videoID)
+}
+
+// RefreshBilingualSubtitles 人工编辑字幕后重新生成双语字幕（未启用时不处理）
+func RefreshBilingualSubtitles(app *core.AppServer, videos *services.SavedVideoService, sm *manager.StateManager) {
+	updateBilingualSubtitles(app, videos, sm, map[string]interface{}{})
+}
+
+// TranslateCue 按时间轴找到译文字幕对应的原文，带前后文将这一条重新翻译为 target 语言
+func TranslateCue(app *core.AppServer, videos *services.SavedVideoService, sm *manager.StateManager, source []subtitle.Cue, cue subtitle.Cue, target string) (string, error) {
+	first, last := -1, -1
+	for i, c := range source {
+		if c.Start < cue.End && c.End > cue.Start {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return "", fmt.Errorf("没有与该字幕时间重叠的原文")
+	}
+
+	text := ""
+	for _, c := range source[first : last+1] {
+		text = subtitle.JoinText(text, c.PlainText())
+	}
+	var prev, next []string
+	for _, c := range source[max(first-reviewContextSize, 0):first] {
+		prev = append(prev, c.PlainText())
+	}
+	for _, c := range source[last+1 : min(last+1+reviewContextSize, len(source))] {
+		next = append(next, c.PlainText())
+	}
+
+	t := NewTranslateSubtitle("翻译字幕", app, sm, app.CosClient, app.DB, "", videos)
+	t.SourceLang = SourceLanguage(videos, sm.VideoID)
+	t.TargetLang = target
+	translated, err := t.translateGroupWithContext([]string{text}, prev, next)
+	if err != nil {
+		return "", err
+	}
+	return translated[0], nil
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// TranscodeVideo 按配置档转码视频，转码结果替换 InputVideoPath
+// 源文件已符合配置档（H.264/AAC mp4，分辨率和帧率不超限）时跳过
+type TranscodeVideo struct {
+	base.BaseTask
+	App *core.AppServer
+}
+
+func NewTranscodeVideo(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient) *TranscodeVideo {
+	return &TranscodeVideo{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App: app,
+	}
+}
+
+func (t *TranscodeVideo) Execute(taskContext map[string]interface{}) bool {
+	cfg := t.App.Config.TranscodeConfig
+	if cfg == nil || !cfg.Enabled {
+		t.App.Logger.Info("⏭️  转码未启用，跳过")
+		return true
+	}
+
+	profile, ok := cfg.GetProfile(cfg.Profile)
+	if !ok {
+		t.App.Logger.Errorf("❌ 未知的转码配置档: %s", cfg.Profile)
+		taskContext["error"] = fmt.Sprintf("未知的转码配置档: %s", cfg.Profile)
+		return false
+	}
+	if profile.KeepOriginal {
+		t.App.Logger.Infof("⏭️  配置档 %s 保持原始文件，跳过转码", cfg.Profile)
+		return true
+	}
+
+	videoPath := t.StateManager.InputVideoPath
+	if _, err := os.Stat(videoPath); err != nil {
+		t.App.Logger.Errorf("❌ 视频文件不存在: %s", videoPath)
+		taskContext["error"] = "视频文件不存在"
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 6*time.Hour)
+	defer cancel()
+
+	info, err := media.Probe(ctx, videoPath)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 读取媒体信息失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("读取媒体信息失败: %v", err)
+		return false
+	}
+
+	reason := profileMismatch(videoPath, info, profile)
+	if reason == "" {
+		t.App.Logger.Infof("⏭️  源文件已符合配置档 %s，跳过转码", cfg.Profile)
+		taskContext["transcode_profile"] = cfg.Profile
+		taskContext["transcoded"] = false
+		return true
+	}
+	t.App.Logger.Infof("🔄 使用配置档 %s 转码（%s）", cfg.Profile, reason)
+
+	// 转码并报告进度（每 10% 输出一次）
+	tmpPath := filepath.Join(t.StateManager.CurrentDir, t.StateManager.VideoID+".transcode.mp4")
+	lastReported := -10.0
+	err = utils.TranscodeVideoWithOptions(ctx, videoPath, tmpPath, utils.TranscodeOptions{
+		Preset:       profile.Preset,
+		CRF:          profile.CRF,
+		AudioBitrate: profile.AudioBitrate,
+		MaxFPS:       profile.FPS,
+		MaxWidth:     profile.MaxWidth,
+		MaxHeight:    profile.MaxHeight,
+		Duration:     info.Duration,
+	}, func(p utils.TranscodeProgress) {
+		if p.Percent-lastReported >= 10 || p.Done {
+			lastReported = p.Percent
+			t.App.Logger.Infof("⏳ 转码进度: %.0f%% (%.0fs/%.0fs, 速度 %s)", p.Percent, p.OutTime, info.Duration, p.Speed)
+		}
+	})
+	if err != nil {
+		os.Remove(tmpPath)
+		t.App.Logger.Errorf("❌ 转码失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("转码失败: %v", err)
+		return false
+	}
+
+	if err := replaceInputVideo(t.StateManager, videoPath, tmpPath); err != nil {
+		t.App.Logger.Errorf("❌ %v", err)
+		taskContext["error"] = err.Error()
+		return false
+	}
+
+	t.App.Logger.Infof("✅ 转码完成: %s", filepath.Base(t.StateManager.InputVideoPath))
+	taskContext["transcode_profile"] = cfg.Profile
+	taskContext["transcoded"] = true
+	return true
+}
+
+// profileMismatch 返回源文件不符合配置档的原因，符合时返回空字符串
+func profileMismatch(path string, info *media.Info, profile *types.TranscodeProfile) string {
+	if !strings.EqualFold(filepath.Ext(path), ".mp4") {
+		return "容器不是 mp4"
+	}
+	video := info.VideoStream()
+	if video == nil {
+		return "没有视频流"
+	}
+	if video.CodecName != "h264" {
+		return fmt.Sprintf("视频编码为 %s", video.CodecName)
+	}
+	if audio := info.AudioStream(); audio != nil && audio.CodecName != "aac" {
+		return fmt.Sprintf("音频编码为 %s", audio.CodecName)
+	}
+	if (profile.MaxWidth > 0 && video.Width > profile.MaxWidth) || (profile.MaxHeight > 0 && video.Height > profile.MaxHeight) {
+		return fmt.Sprintf("分辨率 %dx%d 超过 %dx%d", video.Width, video.Height, profile.MaxWidth, profile.MaxHeight)
+	}
+	if profile.FPS > 0 && video.FrameRate > profile.FPS+0.01 {
+		return fmt.Sprintf("帧率 %.2f 超过 %.0f", video.FrameRate, profile.FPS)
+	}
+	return ""
+}
+
+// replaceInputVideo 用处理后的文件替换 InputVideoPath（原文件扩展名不同时一并删除）
+func replaceInputVideo(sm *manager.StateManager, videoPath, newPath string) error {
+	if videoPath != sm.InputVideoPath {
+		os.Remove(videoPath)
+	}
+	if err := os.Rename(newPath, sm.InputVideoPath); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("替换原文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/media"
+)
+
+func TestProfileMismatch(t *testing.T) {
+	h264 := func(width, height int, fps float64) media.Stream {
+		return media.Stream{CodecType: "video", CodecName: "h264", Width: width, Height: height, FrameRate: fps}
+	}
+	aac := media.Stream{CodecType: "audio", CodecName: "aac"}
+	profile := &types.TranscodeProfile{FPS: 30, MaxWidth: 1920, MaxHeight: 1080}
+
+	tests := []struct {
+		name    string
+		path    string
+		streams []media.Stream
+		profile *types.TranscodeProfile
+		want    string // 期望原因包含的文字，空字符串表示符合配置档
+	}{
+		{"符合配置档", "v.mp4", []media.Stream{h264(1920, 1080, 29.97), aac}, profile, ""},
+		{"扩展名大小写不敏感", "v.MP4", []media.Stream{h264(1280, 720, 30), aac}, profile, ""},
+		{"没有音频", "v.mp4", []media.Stream{h264(1280, 720, 30)}, profile, ""},
+		{"不限制分辨率与帧率", "v.mp4", []media.Stream{h264(3840, 2160, 60), aac}, &types.TranscodeProfile{}, ""},
+		{"容器不是 mp4", "v.webm", []media.Stream{h264(1280, 720, 30), aac}, profile, "容器"},
+		{"没有视频流", "v.mp4", []media.Stream{aac}, profile, "没有视频流"},
+		{"视频编码不是 h264", "v.mp4", []media.Stream{{CodecType: "video", CodecName: "vp9", Width: 1280, Height: 720}, aac}, profile, "vp9"},
+		{"音频编码不是 aac", "v.mp4", []media.Stream{h264(1280, 720, 30), {CodecType: "audio", CodecName: "opus"}}, profile, "opus"},
+		{"分辨率超过上限", "v.mp4", []media.Stream{h264(2560, 1440, 30), aac}, profile, "分辨率"},
+		{"帧率超过上限", "v.mp4", []media.Stream{h264(1920, 1080, 60), aac}, profile, "帧率"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := profileMismatch(tt.path, &media.Info{Streams: tt.streams}, tt.profile)
+			if tt.want == "" && got != "" {
+				t.Errorf("profileMismatch() = %q, want match", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("profileMismatch() = %q, want reason containing %q", got, tt.want)
+			}
+		})
+	}
+}
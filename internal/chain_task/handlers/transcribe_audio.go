@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/asr"
+	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// TranscribeAudio 语音识别生成原文字幕（没有可用平台字幕时执行）
+// 提供商按来源频道选择（AsrConfig.channels），失败时依次尝试备选提供商
+type TranscribeAudio struct {
+	base.BaseTask
+	App               *core.AppServer
+	SavedVideoService *services.SavedVideoService
+}
+
+func NewTranscribeAudio(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, savedVideoService *services.SavedVideoService) *TranscribeAudio {
+	return &TranscribeAudio{
+		BaseTask: base.BaseTask{
+			Name:         name,
+			StateManager: stateManager,
+			Client:       client,
+		},
+		App:               app,
+		SavedVideoService: savedVideoService,
+	}
+}
+
+func (t *TranscribeAudio) Execute(taskContext map[string]interface{}) bool {
+	// 已获取到可用字幕（插件提交或平台字幕轨）时不再转录
+	if source, ok := taskContext["subtitle_source"].(string); ok && source != "" {
+		t.App.Logger.Infof("⏭️  已有可用字幕（来源: %s），跳过语音识别", source)
+		return true
+	}
+
+	// 1. 准备 16kHz 单声道 WAV
+	wavPath := t.StateManager.OriginalWAV
+	if _, err := os.Stat(wavPath); err != nil {
+		t.App.Logger.Info("🎵 提取 WAV 音频用于语音识别")
+		if err := utils.ExtractWaveAudio(t.StateManager.InputVideoPath, wavPath); err != nil {
+			t.App.Logger.Errorf("❌ 提取 WAV 音频失败: %v", err)
+			taskContext["error"] = fmt.Sprintf("提取 WAV 音频失败: %v", err)
+			return false
+		}
+	}
+
+	// 2. 按来源频道选择提供商
+	cfg := t.App.Config.AsrConfig
+	provider, language, prompt := "", "", ""
+	if cfg != nil {
+		var channel, uploader, channelID string
+		if meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID); err == nil {
+			channel, uploader, channelID = meta.Channel, meta.Uploader, meta.ChannelID
+		}
+		provider = cfg.ProviderFor(channelID, channel, uploader)
+		language, prompt = cfg.Language, cfg.Prompt
+	}
+	// 未强制指定时使用检测到的原视频语言
+	if language == "" {
+		language = spokenLanguage(t.SavedVideoService, taskContext, t.StateManager.VideoID)
+	}
+	if language == "" && t.App.Config.WhisperConfig != nil {
+		language = t.App.Config.WhisperConfig.Language
+	}
+
+	asrManager := asr.NewTranscriberManager(t.App.Config)
+	defer asrManager.Close()
+	if provider == "" {
+		provider = asrManager.DefaultProvider()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Hour)
+	defer cancel()
+
+	// 3. 转录（长音频按静音分段并行识别）
+	t.App.Logger.Infof("📝 语音识别: %s (提供商: %s, 语言: %s)", wavPath, provider, language)
+	start := time.Now()
+	req := &asr.TranscriptionRequest{
+		AudioPath: wavPath,
+		Language:  language,
+		Prompt:    prompt,
+	}
+	var result *asr.TranscriptionResult
+	var err error
+	if chunked := newChunkedTranscriber(ctx, t.App, t.StateManager, asrManager, provider, wavPath); chunked != nil {
+		result, err = chunked.Transcribe(ctx, req)
+	} else {
+		result, err = asrManager.TranscribeWithProvider(ctx, provider, req)
+	}
+	if err != nil {
+		t.App.Logger.Errorf("❌ 语音识别失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("语音识别失败: %v", err)
+		return false
+	}
+	if len(result.Segments) == 0 {
+		t.App.Logger.Error("❌ 语音识别结果为空")
+		taskContext["error"] = "语音识别结果为空"
+		return false
+	}
+
+	// 4. 写入原文字幕（翻译输入），并按语言保存为 <lang>.srt 供字幕上传使用
+	if err := asr.WriteSRT(t.StateManager.SourceSRT, result.Segments); err != nil {
+		t.App.Logger.Errorf("❌ 写入字幕文件失败: %v", err)
+		taskContext["error"] = fmt.Sprintf("写入字幕文件失败: %v", err)
+		return false
+	}
+	// 词级时间戳保存为 JSON，供字幕断句使用
+	if data, err := json.Marshal(result.Segments); err == nil {
+		if err := os.WriteFile(t.StateManager.SourceWords, data, 0644); err != nil {
+			t.App.Logger.Warnf("⚠️  写入词级时间戳失败: %v", err)
+		}
+	}
+
+	code := lang.Normalize(result.Language)
+	if code == "" || code == "auto" {
+		code = lang.Normalize(language)
+	}
+	result.Language = code
+	if code != "" && code != "auto" {
+		if err := utils.CopyFile(t.StateManager.SourceSRT, t.StateManager.SubtitlePath(code)); err != nil {
+			t.App.Logger.Warnf("⚠️  复制%s字幕文件失败: %v", lang.Name(code), err)
+		}
+	}
+	t.App.Logger.Infof("✅ 语音识别完成 (提供商: %s, 语言: %s, %d 条, 耗时 %s)",
+		result.Provider, result.Language, len(result.Segments), time.Since(start).Round(time.Second))
+
+	taskContext["subtitle_file"] = t.StateManager.SourceSRT
+	taskContext["subtitle_source"] = model.SubtitleSourceWhisper
+	taskContext["subtitle_lang"] = result.Language
+	taskContext["asr"] = map[string]interface{}{
+		"provider": result.Provider,
+		"model":    result.Model,
+		"language": result.Language,
+		"segments": len(result.Segments),
+	}
+	return true
+}
+
+// newChunkedTranscriber 音频超过分段阈值时创建分段识别器，否则返回 nil（整段识别）
+func newChunkedTranscriber(ctx context.Context, app *core.AppServer, stateManager *manager.StateManager, asrManager *asr.TranscriberManager, provider, wavPath string) *asr.ChunkedTranscriber {
+	if app.Config.AsrConfig == nil {
+		return nil
+	}
+	cfg := app.Config.AsrConfig.ChunkConfig()
+	if !cfg.Enabled {
+		return nil
+	}
+	duration, err := media.ProbeDuration(ctx, wavPath)
+	if err != nil || duration <= float64(cfg.MinAudioSeconds) {
+		return nil
+	}
+
+	threadBudget := cfg.ThreadBudget
+	if threadBudget <= 0 && app.Config.WhisperConfig != nil {
+		threadBudget = app.Config.WhisperConfig.Threads
+	}
+
+	app.Logger.Infof("✂️  音频时长 %.0fs，按静音分段识别 (单段最长 %ds, 并行 %d)", duration, cfg.MaxChunkSeconds, cfg.Parallel)
+	return asr.NewChunkedTranscriber(asrManager, asr.ChunkOptions{
+		VADOptions: asr.VADOptions{
+			MaxChunk:   time.Duration(cfg.MaxChunkSeconds) * time.Second,
+			MinSilence: time.Duration(cfg.MinSilenceMs) * time.Millisecond,
+			Padding:    time.Duration(cfg.PaddingMs) * time.Millisecond,
+		},
+		Provider:      provider,
+		Parallel:      cfg.Parallel,
+		ThreadBudget:  threadBudget,
+		CheckpointDir: filepath.Join(stateManager.CurrentDir, "asr_chunks"),
+		OnChunk: func(done, total int, chunk asr.Span, cached bool) {
+			if cached {
+				app.Logger.Infof("♻️  [%d/%d] 从断点恢复 %s-%s", done, total, chunk.Start, chunk.End)
+				return
+			}
+			app.Logger.Infof("✓ [%d/%d] 已识别 %s-%s", done, total, chunk.Start, chunk.End)
+		},
+	})
+}
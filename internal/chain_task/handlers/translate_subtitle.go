@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/difyz9/ytb2bili/internal/chain_task/base"
 	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
 	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
 	"github.com/difyz9/ytb2bili/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -21,10 +26,14 @@ type TranslateSubtitle struct {
 	DB         *gorm.DB
 	APIKey     string
 	GroupSize  int
-	MaxWorkers int // 最大并发数
+	MaxWorkers int    // 最大并发数
+	SourceLang string // 原文字幕语言（用于翻译提示词）
+	TargetLang string // 目标语言（用于翻译提示词），为空时翻译为中文
+
+	SavedVideoService *services.SavedVideoService
 }
 
-func NewTranslateSubtitle(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, db *gorm.DB, apiKey string) *TranslateSubtitle {
+func NewTranslateSubtitle(name string, app *core.AppServer, stateManager *manager.StateManager, client *cos.CosClient, db *gorm.DB, apiKey string, savedVideoService *services.SavedVideoService) *TranslateSubtitle {
 	return &TranslateSubtitle{
 		BaseTask: base.BaseTask{
 			Name:         name,
@@ -36,6 +45,8 @@ func NewTranslateSubtitle(name string, app *core.AppServer, stateManager *manage
 		APIKey:     "", // 不再固化API Key，运行时动态获取
 		GroupSize:  25, // 每组25句，减少API调用次数
 		MaxWorkers: 3,  // 最多3个并发，避免API限制
+
+		SavedVideoService: savedVideoService,
 	}
 }
 
@@ -53,46 +64,31 @@ func (t *TranslateSubtitle) getCurrentAPIKey() (string, error) {
 	return apiKey, nil
 }
 
-// SRTEntry SRT字幕条目
-type SRTEntry struct {
-	Index    int
-	TimeCode string
-	Text     string
-}
-
 func (t *TranslateSubtitle) Execute(context map[string]interface{}) bool {
 	t.App.Logger.Info("========================================")
 	t.App.Logger.Infof("开始翻译字幕: VideoID=%s", t.StateManager.VideoID)
 	t.App.Logger.Info("========================================")
 
-	// 0. 动态获取最新的API Key配置
-	currentAPIKey, err := t.getCurrentAPIKey()
-	if err != nil {
-		t.App.Logger.Errorf("❌ %v", err)
-		context["error"] = t.getTranslationError(err)
-		return false
-	}
-
-	t.App.Logger.Infof("🔑 使用DeepSeek API Key: %s", maskAPIKey(currentAPIKey))
-	// 更新当前使用的API Key
-	t.APIKey = currentAPIKey
-
-	// 1. 检查英文字幕文件是否存在（由 GenerateSubtitles 任务生成）
-	enSRTPath := filepath.Join(t.StateManager.CurrentDir, fmt.Sprintf("%s.srt", t.StateManager.VideoID))
-	if _, err := os.Stat(enSRTPath); os.IsNotExist(err) {
-		t.App.Logger.Warn("⚠️  英文字幕文件不存在，跳过翻译")
+	// 0. 检查原文字幕文件是否存在（由生成字幕、获取平台字幕或语音识别任务生成）
+	srcSRTPath := t.StateManager.SourceSRT
+	if _, err := os.Stat(srcSRTPath); os.IsNotExist(err) {
+		t.App.Logger.Warn("⚠️  原文字幕文件不存在，跳过翻译")
 		return true // 没有字幕文件不算失败
 	}
 
-	// 2. 读取并解析英文字幕文件
-	srtContent, err := os.ReadFile(enSRTPath)
+	t.SourceLang = subtitleLanguage(t.SavedVideoService, context, t.StateManager.VideoID)
+	context["translate_from"] = t.SourceLang
+
+	// 1. 读取并解析原文字幕文件
+	t.App.Logger.Infof("🌐 原文字幕语言: %s", lang.Name(t.SourceLang))
+	srtContent, err := os.ReadFile(srcSRTPath)
 	if err != nil {
-		t.App.Logger.Errorf("❌ 读取英文字幕文件失败: %v", err)
+		t.App.Logger.Errorf("❌ 读取原文字幕文件失败: %v", err)
 		context["error"] = "字幕文件读取失败，请确认字幕生成步骤已完成"
 		return false
 	}
 
-	srtEntries, err := t.parseSRTContent(string(srtContent))
+	srtEntries, err := subtitle.ParseSRT(string(srtContent))
 	if err != nil {
 		t.App.Logger.Errorf("❌ 解析SRT文件失败: %v", err)
 		context["error"] = "字幕文件格式错误，无法解析SRT内容"
@@ -106,138 +102,168 @@ func (t *TranslateSubtitle) Execute(context map[string]interface{}) bool {
 
 	t.App.Logger.Infof("📝 找到 %d 条字幕", len(srtEntries))
 
-	// 3. 提取文本进行翻译
-	var texts []string
-	for _, entry := range srtEntries {
-		texts = append(texts, entry.Text)
+	// 2. 按目标语言逐个翻译（已完成且原文未变化的语言直接复用）
+	targets := translationTargets(t.App, t.SavedVideoService, t.StateManager.VideoID)
+	sourceHash := fmt.Sprintf("%x", sha256.Sum256(srtContent))
+	previous := make(map[string]TranslationStatus)
+	for _, status := range loadTranslationStatus(t.StateManager.TargetsJSON) {
+		previous[status.Lang] = status
+	}
+
+	statuses := make([]TranslationStatus, 0, len(targets))
+	for i, target := range targets {
+		t.App.Logger.Infof("🌐 [%d/%d] 翻译为%s (%s)", i+1, len(targets), lang.Name(target), target)
+		status := TranslationStatus{
+			Lang:      target,
+			File:      filepath.Base(t.StateManager.TranslatedSRT(target)),
+			Status:    TranslationRunning,
+			Source:    sourceHash,
+			UpdatedAt: time.Now(),
+		}
+		statuses = append(statuses, status)
+		t.saveStatus(statuses)
+
+		if prev, ok := previous[target]; ok && prev.Status == TranslationDone && prev.Source == sourceHash {
+			if _, err := os.Stat(t.StateManager.TranslatedSRT(target)); err == nil {
+				t.App.Logger.Infof("♻️  %s字幕已翻译，跳过", lang.Name(target))
+				statuses[i] = prev
+				continue
+			}
+		}
+		statuses[i] = t.translateTarget(context, status, srtEntries)
+		t.saveStatus(statuses)
 	}
+	context["translations"] = statuses
 
-	// 4. 执行并发翻译
-	totalGroups := (len(texts) + t.GroupSize - 1) / t.GroupSize
-	t.App.Logger.Infof("� 开始并发翻译，每组 %d 句，共 %d 组，并发数: %d", t.GroupSize, totalGroups, t.MaxWorkers)
-
-	translatedTexts, err := t.translateTextsInGroupsConcurrent(texts)
-	if err != nil {
-		t.App.Logger.Errorf("❌ 翻译失败: %v", err)
-		context["error"] = t.getTranslationError(err)
+	// 3. 主语言失败时任务失败；其他语言失败只记录，重试翻译步骤时重新翻译
+	if primary := statuses[0]; primary.Status == TranslationFailed {
+		context["error"] = primary.Error
 		return false
 	}
+	for _, status := range statuses[1:] {
+		if status.Status == TranslationFailed {
+			t.App.Logger.Warnf("⚠️  %s字幕翻译失败: %s", lang.Name(status.Lang), status.Error)
+		}
+	}
 
-	// 5. 生成中文字幕SRT
-	translatedSRT := t.generateTranslatedSRTContent(srtEntries, translatedTexts)
+	// 4. 生成双语字幕（未启用时跳过）
+	updateBilingualSubtitles(t.App, t.SavedVideoService, t.StateManager, context)
 
-	// 6. 保存中文字幕文件
-	zhSRTPath := filepath.Join(t.StateManager.CurrentDir, "zh.srt")
-	if err := os.WriteFile(zhSRTPath, []byte(translatedSRT), 0644); err != nil {
-		t.App.Logger.Errorf("❌ 保存中文字幕失败: %v", err)
-		context["error"] = "保存翻译字幕文件失败，请检查磁盘空间和文件权限"
-		return false
+	// 5. 保存文件路径到 context
+	context["source_srt_path"] = srcSRTPath
+	primaryPath := primaryTranslatedSRT(t.App, t.SavedVideoService, t.StateManager)
+	if _, err := os.Stat(primaryPath); err == nil {
+		context["translated_srt_path"] = primaryPath
 	}
 
-	// 7. 字幕质量校验和优化
-	optimizedPath, validationResult, err := t.validateAndOptimizeSubtitles(enSRTPath, zhSRTPath)
-	if err != nil {
-		t.App.Logger.Warnf("⚠️  字幕校验失败，使用原始翻译: %v", err)
-	} else {
-		if validationResult.MissingEntries > 0 {
-			t.App.Logger.Infof("🔧 检测到 %d 个问题条目，已尝试修复 %d 个",
-				validationResult.MissingEntries, len(validationResult.FixedEntries))
-
-			if optimizedPath != "" {
-				// 使用优化后的文件替换原文件
-				if err := os.Rename(optimizedPath, zhSRTPath); err == nil {
-					t.App.Logger.Info("✨ 已应用字幕优化结果")
-				}
-			}
+	t.App.Logger.Infof("✓ 翻译完成: %d 种目标语言", len(statuses))
+	t.App.Logger.Info("========================================")
+
+	return true
+}
+
+// translateTarget 翻译为一种目标语言并写入字幕文件，返回该语言的翻译状态
+func (t *TranslateSubtitle) translateTarget(context map[string]interface{}, status TranslationStatus, entries []subtitle.Cue) TranslationStatus {
+	path := t.StateManager.TranslatedSRT(status.Lang)
+	fail := func(err error, message string) TranslationStatus {
+		t.App.Logger.Errorf("❌ %s字幕翻译失败: %v", lang.Name(status.Lang), err)
+		status.Status, status.Error, status.UpdatedAt = TranslationFailed, message, time.Now()
+		return status
+	}
+
+	// 原文即目标语言（或同为中文且配置跳过）时直接使用原文字幕
+	skipChinese := lang.IsChinese(status.Lang) && lang.IsChinese(t.SourceLang) &&
+		t.App.Config.LanguageConfig != nil && t.App.Config.LanguageConfig.SkipChinese
+	if status.Lang == t.SourceLang || skipChinese {
+		if err := utils.CopyFile(t.StateManager.SourceSRT, path); err != nil {
+			return fail(err, "保存字幕文件失败，请检查磁盘空间和文件权限")
 		}
+		t.App.Logger.Infof("⏭️  原文字幕为%s，跳过翻译: %s", lang.Name(t.SourceLang), path)
+		status.Status, status.Cues, status.Copied, status.UpdatedAt = TranslationDone, len(entries), true, time.Now()
+		return status
+	}
+
+	// 动态获取最新的API Key配置
+	currentAPIKey, err := t.getCurrentAPIKey()
+	if err != nil {
+		return fail(err, t.getTranslationError(err))
 	}
+	t.App.Logger.Infof("🔑 使用DeepSeek API Key: %s", maskAPIKey(currentAPIKey))
+	t.APIKey = currentAPIKey
+	t.TargetLang = status.Lang
 
-	// 8. 保存文件路径到 context
-	context["en_srt_path"] = enSRTPath
-	context["zh_srt_path"] = zhSRTPath
-	context["translated_count"] = len(translatedTexts)
+	var texts []string
+	for _, entry := range entries {
+		texts = append(texts, entry.Text)
+	}
 
-	// 添加校验结果信息
-	if validationResult != nil {
-		context["validation_result"] = map[string]interface{}{
-			"total_entries":   validationResult.TotalEntries,
-			"valid_entries":   validationResult.ValidEntries,
-			"missing_entries": validationResult.MissingEntries,
-			"fixed_entries":   len(validationResult.FixedEntries),
-		}
+	// 执行并发翻译
+	totalGroups := (len(texts) + t.GroupSize - 1) / t.GroupSize
+	t.App.Logger.Infof("🚀 开始并发翻译，每组 %d 句，共 %d 组，并发数: %d", t.GroupSize, totalGroups, t.MaxWorkers)
+
+	translatedTexts, err := t.translateTextsInGroupsConcurrent(texts)
+	if err != nil {
+		return fail(err, t.getTranslationError(err))
+	}
+	if err := subtitle.WriteSRT(path, t.translatedCues(entries, translatedTexts)); err != nil {
+		return fail(err, "保存翻译字幕文件失败，请检查磁盘空间和文件权限")
 	}
 
-	t.App.Logger.Infof("✓ 中文字幕已保存: %s", zhSRTPath)
-	t.App.Logger.Infof("✓ 翻译完成: %d/%d 条字幕", len(translatedTexts), len(texts))
-	t.App.Logger.Info("========================================")
+	// 简体中文字幕质量校验和优化
+	if status.Lang == "zh" {
+		t.optimizeChinese(context, path)
+	}
 
-	return true
+	t.App.Logger.Infof("✓ %s字幕已保存: %s (%d/%d 条)", lang.Name(status.Lang), path, len(translatedTexts), len(texts))
+	status.Status, status.Cues, status.UpdatedAt = TranslationDone, len(translatedTexts), time.Now()
+	return status
 }
 
-// parseSRTContent 解析SRT文件内容
-func (t *TranslateSubtitle) parseSRTContent(content string) ([]SRTEntry, error) {
-	lines := strings.Split(content, "\n")
-	var entries []SRTEntry
-	var currentEntry SRTEntry
-	var textLines []string
-	stage := 0 // 0=等待序号, 1=等待时间码, 2=读取文本
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if line == "" {
-			// 空行表示一个条目结束
-			if stage == 2 && len(textLines) > 0 {
-				currentEntry.Text = strings.Join(textLines, "\n")
-				entries = append(entries, currentEntry)
-				textLines = nil
-				stage = 0
-			}
-			continue
-		}
+// optimizeChinese 校验中文译文，修复漏翻的条目并写回
+func (t *TranslateSubtitle) optimizeChinese(context map[string]interface{}, zhSRTPath string) {
+	optimizedPath, validationResult, err := t.validateAndOptimizeSubtitles(t.StateManager.SourceSRT, zhSRTPath)
+	if err != nil {
+		t.App.Logger.Warnf("⚠️  字幕校验失败，使用原始翻译: %v", err)
+		return
+	}
+	if validationResult.MissingEntries > 0 {
+		t.App.Logger.Infof("🔧 检测到 %d 个问题条目，已尝试修复 %d 个",
+			validationResult.MissingEntries, len(validationResult.FixedEntries))
 
-		switch stage {
-		case 0: // 读取序号
-			var index int
-			if _, err := fmt.Sscanf(line, "%d", &index); err == nil {
-				currentEntry = SRTEntry{Index: index}
-				stage = 1
+		if optimizedPath != "" {
+			// 使用优化后的文件替换原文件
+			if err := os.Rename(optimizedPath, zhSRTPath); err == nil {
+				t.App.Logger.Info("✨ 已应用字幕优化结果")
 			}
-		case 1: // 读取时间码
-			if strings.Contains(line, "-->") {
-				currentEntry.TimeCode = line
-				stage = 2
-			}
-		case 2: // 读取文本
-			textLines = append(textLines, line)
 		}
 	}
 
-	// 处理最后一个条目（如果文件末尾没有空行）
-	if stage == 2 && len(textLines) > 0 {
-		currentEntry.Text = strings.Join(textLines, "\n")
-		entries = append(entries, currentEntry)
+	// 添加校验结果信息
+	context["validation_result"] = map[string]interface{}{
+		"total_entries":   validationResult.TotalEntries,
+		"valid_entries":   validationResult.ValidEntries,
+		"missing_entries": validationResult.MissingEntries,
+		"fixed_entries":   len(validationResult.FixedEntries),
 	}
-
-	return entries, nil
 }
 
-// generateTranslatedSRTContent 生成翻译后的SRT内容（保持原时间轴）
-func (t *TranslateSubtitle) generateTranslatedSRTContent(entries []SRTEntry, translatedTexts []string) string {
-	var builder strings.Builder
-
-	for i, entry := range entries {
-		builder.WriteString(fmt.Sprintf("%d\n", entry.Index))
-		builder.WriteString(fmt.Sprintf("%s\n", entry.TimeCode))
+// saveStatus 保存各目标语言的翻译进度，失败只记录警告
+func (t *TranslateSubtitle) saveStatus(statuses []TranslationStatus) {
+	if err := saveTranslationStatus(t.StateManager.TargetsJSON, statuses); err != nil {
+		t.App.Logger.Warnf("⚠️  保存翻译进度失败: %v", err)
+	}
+}
 
+// translatedCues 生成翻译后的字幕（保持原时间轴，缺少译文的条目保留原文）
+func (t *TranslateSubtitle) translatedCues(cues []subtitle.Cue, translatedTexts []string) []subtitle.Cue {
+	translated := make([]subtitle.Cue, len(cues))
+	for i, cue := range cues {
+		translated[i] = subtitle.Cue{Start: cue.Start, End: cue.End, Text: cue.Text}
 		if i < len(translatedTexts) {
-			builder.WriteString(fmt.Sprintf("%s\n\n", translatedTexts[i]))
-		} else {
-			builder.WriteString(fmt.Sprintf("%s\n\n", entry.Text))
+			translated[i].Text = translatedTexts[i]
 		}
 	}
-
-	return builder.String()
+	return translated
 }
 
 // translateTextsInGroupsConcurrent 并发分组翻译文本
@@ -347,19 +373,19 @@ func (t *TranslateSubtitle) translateGroupSimple(texts []string) ([]string, erro
 	combinedText := strings.Join(texts, "\n###SENTENCE_BREAK###\n")
 
 	// 简化的系统提示
-	systemPrompt := fmt.Sprintf(`你是一个专业的视频字幕翻译专家。将给出的 %d 句英文字幕翻译成中文。
+	systemPrompt := fmt.Sprintf(`你是一个专业的视频字幕翻译专家。将给出的 %d 句%s字幕翻译成%s。
 
 翻译要求：
-1. 自然流畅：使用口语化表达，符合中文字幕习惯
+1. 自然流畅：使用口语化表达，符合%s字幕习惯
 2. 准确传神：忠实原文含义，保持语气和情感
 3. 简洁明了：字幕需要快速阅读，避免冗长
 4. 数量严格：必须输出 %d 句翻译，不多不少
 5. 分隔符：每句翻译用"###SENTENCE_BREAK###"分隔
 
 输入格式：句子用"###SENTENCE_BREAK###"分隔
-输出格式：只返回中文翻译，用"###SENTENCE_BREAK###"分隔
+输出格式：只返回%s翻译，用"###SENTENCE_BREAK###"分隔
 
-注意：只返回翻译的中文文本，不要添加序号、解释或其他内容。`, len(texts), len(texts))
+注意：只返回翻译的%s文本，不要添加序号、解释或其他内容。`, len(texts), t.sourceLangName(), t.targetLangName(), t.targetLangName(), len(texts), t.targetLangName(), t.targetLangName())
 
 	translatedText, err := t.callDeepSeekAPI(systemPrompt, combinedText)
 	if err != nil {
@@ -479,10 +505,10 @@ func (t *TranslateSubtitle) translateGroupWithContext(texts []string, prevContex
 			len(nextContext), targetStartIndex+1, targetEndIndex)
 	}
 
-	systemPrompt := fmt.Sprintf(`你是一个专业的视频字幕翻译专家。我将给你一段连续的英文字幕，其中包含 %d 句需要翻译的内容。%s
+	systemPrompt := fmt.Sprintf(`你是一个专业的视频字幕翻译专家。我将给你一段连续的%s字幕，其中包含 %d 句需要翻译的内容。%s
 
 翻译要求：
-1. 自然流畅：使用口语化表达，符合中文字幕习惯
+1. 自然流畅：使用口语化表达，符合%s字幕习惯
 2. 上下文连贯：理解整体语境，确保翻译前后呼应
 3. 准确传神：忠实原文含义，保持语气和情感
 4. 简洁明了：字幕需要快速阅读，避免冗长
@@ -490,9 +516,9 @@ func (t *TranslateSubtitle) translateGroupWithContext(texts []string, prevContex
 6. 分隔符：每句翻译用"###SENTENCE_BREAK###"分隔
 
 输入格式：句子用"###SENTENCE_BREAK###"分隔
-输出格式：只返回目标部分的中文翻译，用"###SENTENCE_BREAK###"分隔
+输出格式：只返回目标部分的%s翻译，用"###SENTENCE_BREAK###"分隔
 
-注意：只返回翻译的中文文本，不要添加序号、解释或其他内容。`, len(texts), contextInfo, len(texts))
+注意：只返回翻译的%s文本，不要添加序号、解释或其他内容。`, t.sourceLangName(), len(texts), contextInfo, t.targetLangName(), len(texts), t.targetLangName(), t.targetLangName())
 
 	translatedText, err := t.callDeepSeekAPI(systemPrompt, combinedText)
 	if err != nil {
@@ -520,6 +546,22 @@ func (t *TranslateSubtitle) translateGroupWithContext(texts []string, prevContex
 	return translatedSentences, nil
 }
 
+// sourceLangName 原文语言的中文名称，未检测时按英语处理
+func (t *TranslateSubtitle) sourceLangName() string {
+	if t.SourceLang == "" {
+		return lang.Name("en")
+	}
+	return lang.Name(t.SourceLang)
+}
+
+// targetLangName 目标语言的中文名称，未指定时为中文
+func (t *TranslateSubtitle) targetLangName() string {
+	if t.TargetLang == "" {
+		return lang.Name("zh")
+	}
+	return lang.Name(t.TargetLang)
+}
+
 // callDeepSeekAPI 调用DeepSeek API（实时获取最新的API Key）
 func (t *TranslateSubtitle) callDeepSeekAPI(systemPrompt, userPrompt string) (string, error) {
 	// 实时从配置中获取最新的API Key
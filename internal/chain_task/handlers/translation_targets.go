@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+)
+
+// 目标语言的翻译状态
+const (
+	TranslationRunning = "running" // 翻译中
+	TranslationDone    = "done"    // 已完成
+	TranslationFailed  = "failed"  // 失败（重试翻译步骤时重新翻译）
+)
+
+// TranslationStatus 单个目标语言的翻译进度
+type TranslationStatus struct {
+	Lang      string    `json:"lang"`            // 目标语言（BCP-47）
+	File      string    `json:"file"`            // 字幕文件名
+	Status    string    `json:"status"`          // running / done / failed
+	Cues      int       `json:"cues"`            // 字幕条数
+	Copied    bool      `json:"copied"`          // 原文即目标语言，直接使用原文字幕
+	Error     string    `json:"error,omitempty"` // 失败原因
+	Source    string    `json:"source"`          // 翻译时原文字幕的哈希，原文变化后需要重新翻译
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// translationTargets 视频的翻译目标语言：视频单独指定 > 按来源频道配置 > 默认配置，均未配置时翻译为中文
+// 语言代码统一规范化并去重，第一个为主语言
+func translationTargets(app *core.AppServer, videos *services.SavedVideoService, videoID string) []string {
+	var videoTargets string
+	if videos != nil {
+		videoTargets, _ = videos.GetTargetLangs(videoID)
+	}
+
+	var codes []string
+	if videoTargets != "" {
+		codes = strings.Split(videoTargets, ",")
+	} else if cfg := app.Config.TranslationConfig; cfg != nil {
+		var keys []string
+		if videos != nil {
+			if meta, err := videos.GetSourceMeta(videoID); err == nil {
+				keys = append(keys, meta.ChannelID, meta.Channel, meta.Uploader)
+			}
+		}
+		codes = cfg.TargetsFor(keys...)
+	}
+	return normalizeTargets(codes)
+}
+
+// normalizeTargets 规范化并去重目标语言，zh-Hans 与 zh 视为同一语言，为空时默认中文
+func normalizeTargets(codes []string) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		code = lang.Normalize(code)
+		if code == "zh-Hans" {
+			code = "zh" // 与 zh.srt 为同一文件
+		}
+		if code == "" || code == "auto" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		targets = append(targets, code)
+	}
+	if len(targets) == 0 {
+		targets = []string{"zh"}
+	}
+	return targets
+}
+
+// primaryTarget 主目标语言（第一个翻译目标）
+func primaryTarget(app *core.AppServer, videos *services.SavedVideoService, videoID string) string {
+	return translationTargets(app, videos, videoID)[0]
+}
+
+// primaryTranslatedSRT 主目标语言的译文字幕路径（双语字幕、烧录字幕和上传字幕使用）
+func primaryTranslatedSRT(app *core.AppServer, videos *services.SavedVideoService, sm *manager.StateManager) string {
+	return sm.TranslatedSRT(primaryTarget(app, videos, sm.VideoID))
+}
+
+// loadTranslationStatus 读取各目标语言的翻译状态，文件不存在时返回空
+func loadTranslationStatus(path string) []TranslationStatus {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var statuses []TranslationStatus
+	if err := json.Unmarshal(data, &statuses); err != nil {
+		return nil
+	}
+	return statuses
+}
+
+// saveTranslationStatus 保存各目标语言的翻译状态
+func saveTranslationStatus(path string, statuses []TranslationStatus) error {
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// translatedSubtitles 已完成翻译且文件存在的目标语言（供译文断句、上传字幕使用）
+func translatedSubtitles(sm *manager.StateManager) []TranslationStatus {
+	var result []TranslationStatus
+	for _, status := range loadTranslationStatus(sm.TargetsJSON) {
+		if status.Status != TranslationDone {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(sm.CurrentDir, status.File)); err == nil {
+			result = append(result, status)
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestVideoService 创建使用内存 SQLite 的视频服务，并写入给定的视频记录
+func newTestVideoService(t *testing.T, records ...interface{}) *services.SavedVideoService {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&model.SavedVideo{}, &model.VideoSourceMeta{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("create %T: %v", record, err)
+		}
+	}
+	return services.NewSavedVideoService(db)
+}
+
+func newTestApp(config *types.AppConfig) *core.AppServer {
+	return &core.AppServer{Config: config, Logger: zap.NewNop().Sugar()}
+}
+
+func TestNormalizeTargets(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []string
+		want  []string
+	}{
+		{"空列表默认中文", nil, []string{"zh"}},
+		{"zh-Hans 视为 zh", []string{"zh-Hans"}, []string{"zh"}},
+		{"zh-CN 视为 zh", []string{"zh-CN", "ja"}, []string{"zh", "ja"}},
+		{"繁体中文单独保留", []string{"zh", "zh-Hant"}, []string{"zh", "zh-Hant"}},
+		{"去重并保持顺序", []string{"ja", "zh-Hans", "zh", "ja"}, []string{"ja", "zh"}},
+		{"忽略空值与 auto", []string{" ", "auto", "ko"}, []string{"ko"}},
+		{"只有无效值时默认中文", []string{"", "auto"}, []string{"zh"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeTargets(tt.codes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeTargets(%q) = %q, want %q", tt.codes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslationTargets(t *testing.T) {
+	config := &types.TranslationConfig{
+		TargetLangs: []string{"zh-Hans", "en"},
+		Channels:    map[string][]string{"UC123": {"ja"}, "某频道": {"ko", "zh"}},
+	}
+
+	tests := []struct {
+		name    string
+		config  *types.TranslationConfig
+		records []interface{}
+		want    []string
+	}{
+		{"没有配置时默认中文", nil, nil, []string{"zh"}},
+		{"没有视频记录时使用默认配置", config, nil, []string{"zh", "en"}},
+		{"视频单独指定优先于频道配置", config, []interface{}{
+			&model.SavedVideo{VideoID: "v1", URL: "u", TargetLangs: "fr,zh-Hans,fr"},
+			&model.VideoSourceMeta{VideoID: "v1", ChannelID: "UC123"},
+		}, []string{"fr", "zh"}},
+		{"按频道ID匹配", config, []interface{}{
+			&model.SavedVideo{VideoID: "v1", URL: "u"},
+			&model.VideoSourceMeta{VideoID: "v1", ChannelID: "UC123", Channel: "某频道"},
+		}, []string{"ja"}},
+		{"按频道名称匹配", config, []interface{}{
+			&model.SavedVideo{VideoID: "v1", URL: "u"},
+			&model.VideoSourceMeta{VideoID: "v1", ChannelID: "UC999", Channel: "某频道"},
+		}, []string{"ko", "zh"}},
+		{"频道未配置时使用默认配置", config, []interface{}{
+			&model.SavedVideo{VideoID: "v1", URL: "u"},
+			&model.VideoSourceMeta{VideoID: "v1", ChannelID: "UC999"},
+		}, []string{"zh", "en"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(&types.AppConfig{TranslationConfig: tt.config})
+			videos := newTestVideoService(t, tt.records...)
+			if got := translationTargets(app, videos, "v1"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("translationTargets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
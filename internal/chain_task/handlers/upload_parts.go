@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/difyz9/bilibili-go-sdk/bilibili"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// prepareParts 按配置将长视频切分为多个分P，不需要分P或切分失败时返回 nil（按单P上传）
+func (t *UploadToBilibili) prepareParts(videoPath string) []media.Part {
+	cfg := t.App.Config.SplitConfig
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	// 1. 已有切分结果且源视频未变化时直接复用（上传失败重试不必重新切分）
+	if manifest, err := media.LoadParts(t.StateManager.PartsJSON); err == nil && partsReady(manifest.Parts) {
+		if manifest.Matches(videoPath) {
+			t.App.Logger.Infof("♻️  复用已切分的 %d 个分P", len(manifest.Parts))
+			return manifest.Parts
+		}
+		t.App.Logger.Info("♻️  视频已变化，重新切分分P")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	// 2. 获取时长与章节
+	var duration float64
+	var chapters []media.Chapter
+	if meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID); err == nil {
+		duration = meta.Duration
+		for _, ch := range meta.GetChapters() {
+			chapters = append(chapters, media.Chapter{Start: ch.StartTime, End: ch.EndTime, Title: ch.Title})
+		}
+	}
+	// 拼接了片头时章节整体后移
+	if branding, err := media.LoadBrandingResult(t.StateManager.BrandingJSON); err == nil && branding.IntroDuration > 0 {
+		for i := range chapters {
+			chapters[i].Start += branding.IntroDuration
+			chapters[i].End += branding.IntroDuration
+		}
+	}
+	if probed, err := media.ProbeDuration(ctx, videoPath); err == nil {
+		duration = probed
+	} else if duration <= 0 {
+		t.App.Logger.Warnf("⚠️  获取视频时长失败，不分P: %v", err)
+		return nil
+	}
+
+	if duration < float64(cfg.MinTotalMinutes*60) {
+		return nil
+	}
+
+	// 3. 规划分P并对齐关键帧
+	parts := media.PlanParts(duration, chapters, media.SplitOptions{
+		Mode:           cfg.Mode,
+		MaxPartSeconds: float64(cfg.MaxPartMinutes * 60),
+		MinPartSeconds: float64(cfg.MinPartSeconds),
+	})
+	if len(parts) == 0 {
+		return nil
+	}
+	if keyframes, err := media.KeyframeTimes(ctx, videoPath); err == nil {
+		parts = media.SnapToKeyframes(parts, keyframes)
+	} else {
+		t.App.Logger.Warnf("⚠️  读取关键帧失败，分P边界可能与字幕略有偏差: %v", err)
+	}
+
+	// 4. 流复制切分
+	if err := os.MkdirAll(t.StateManager.PartsDir, os.ModePerm); err != nil {
+		t.App.Logger.Warnf("⚠️  创建分P目录失败，按单P上传: %v", err)
+		return nil
+	}
+	for i := range parts {
+		parts[i].Path = filepath.Join(t.StateManager.PartsDir, fmt.Sprintf("p%02d.mp4", parts[i].Index))
+	}
+
+	t.App.Logger.Infof("✂️  切分为 %d 个分P（时长 %.0f 秒，章节 %d 个）", len(parts), duration, len(chapters))
+	if err := media.SplitVideo(ctx, videoPath, parts); err != nil {
+		t.App.Logger.Warnf("⚠️  切分视频失败，按单P上传: %v", err)
+		return nil
+	}
+
+	if err := media.SaveParts(t.StateManager.PartsJSON, videoPath, parts); err != nil {
+		t.App.Logger.Warnf("⚠️  保存分P清单失败: %v", err)
+	}
+	for _, p := range parts {
+		t.App.Logger.Infof("  P%d %s [%.1fs - %.1fs]", p.Index, p.Title, p.Start, p.End)
+	}
+	return parts
+}
+
+// uploadParts 逐个上传分P，分P标题使用章节标题
+func (t *UploadToBilibili) uploadParts(uploadClient *bilibili.UploadClient, parts []media.Part) ([]bilibili.Video, error) {
+	videos := make([]bilibili.Video, 0, len(parts))
+	for _, p := range parts {
+		t.App.Logger.Infof("⏫ 上传分P %d/%d: %s", p.Index, len(parts), p.Title)
+		video, err := uploadClient.UploadVideo(p.Path)
+		if err != nil {
+			return nil, fmt.Errorf("上传分P %d 失败: %w", p.Index, err)
+		}
+		video.Title = t.truncateTitle(p.Title, 80)
+		videos = append(videos, *video)
+	}
+	return videos, nil
+}
+
+// partsReady 分P清单中的文件是否都存在
+func partsReady(parts []media.Part) bool {
+	if len(parts) < 2 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := os.Stat(p.Path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// partSubtitlePath 分P字幕文件路径，如 parts/p01.en.srt
+func partSubtitlePath(sm *manager.StateManager, part media.Part, filename string) string {
+	return filepath.Join(sm.PartsDir, fmt.Sprintf("p%02d.%s", part.Index, filename))
+}
+
+// sliceSubtitleForPart 截取分P对应时间段的字幕，没有字幕内容时返回空字符串
+func sliceSubtitleForPart(sm *manager.StateManager, part media.Part, subtitlePath string) (string, error) {
+	output := partSubtitlePath(sm, part, filepath.Base(subtitlePath))
+	start := time.Duration(part.Start * float64(time.Second))
+	end := time.Duration(part.End * float64(time.Second))
+	cues, err := subtitle.ReadSRT(subtitlePath)
+	if err != nil {
+		return "", fmt.Errorf("读取字幕文件失败: %w", err)
+	}
+	if cues = subtitle.Slice(cues, start, end); len(cues) == 0 {
+		return "", nil
+	}
+	if err := subtitle.WriteSRT(output, cues); err != nil {
+		return "", fmt.Errorf("写入 SRT 文件失败: %w", err)
+	}
+	return output, nil
+}
+
+// fetchArchiveParts 获取稿件所有分P的 AID/CID（SDK 只返回第一个分P）
+func fetchArchiveParts(loginInfo *bilibili.LoginInfo, bvid string) ([]bilibili.SubtitleVideoInfo, error) {
+	req, err := http.NewRequest("GET", "https://member.bilibili.com/x/vupre/web/archive/view?bvid="+bvid, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Cookie", loginInfo.GetCookieString())
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求稿件信息失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response bilibili.VideoInfoResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("解析稿件信息失败: %w", err)
+	}
+	if response.Code != 0 {
+		return nil, fmt.Errorf("获取稿件信息失败: code=%d, message=%s", response.Code, response.Message)
+	}
+
+	infos := make([]bilibili.SubtitleVideoInfo, 0, len(response.Data.Videos))
+	for _, v := range response.Data.Videos {
+		infos = append(infos, bilibili.SubtitleVideoInfo{AID: v.AID, CID: v.CID})
+	}
+	return infos, nil
+}
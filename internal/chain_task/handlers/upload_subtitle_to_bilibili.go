@@ -8,6 +8,8 @@ import (
 	"github.com/difyz9/ytb2bili/internal/storage"
 	"github.com/difyz9/bilibili-go-sdk/bilibili"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/media"
 	"os"
 	"path/filepath"
 )
@@ -35,6 +37,12 @@ func (t *UploadSubtitleToBilibili) Execute(context map[string]interface{}) bool
 	t.App.Logger.Info("开始上传字幕到 Bilibili")
 	t.App.Logger.Info("========================================")
 
+	// 已烧录硬字幕时默认不再上传 CC 字幕
+	if _, ok := burnedVideo(t.App, t.StateManager); ok && !t.App.Config.BurnInConfig.KeepSoftSubtitles {
+		t.App.Logger.Info("⏭️  视频已烧录硬字幕，跳过字幕上传")
+		return true
+	}
+
 	// 1. 检查是否有BVID（视频已上传成功）
 	bvid, exists := context["bili_bvid"].(string)
 	if !exists || bvid == "" {
@@ -75,20 +83,24 @@ func (t *UploadSubtitleToBilibili) Execute(context map[string]interface{}) bool
 	client := bilibili.NewClient()
 	uploader := bilibili.NewSubtitleUploader(client, loginInfo)
 
-	// 5. 上传字幕文件
+	// 5. 上传字幕文件（多P稿件按分P切分字幕后分别上传）
 	uploadedCount := 0
-	for _, subtitleFile := range subtitleFiles {
-		t.App.Logger.Infof("📝 正在上传字幕: %s", filepath.Base(subtitleFile.Path))
-
-		err := uploader.UploadSubtitle(bvid, subtitleFile.Path, subtitleFile.Language)
-		if err != nil {
-			t.App.Logger.Errorf("❌ 上传字幕失败 %s: %v", subtitleFile.Path, err)
-			// 继续上传其他字幕文件，不因为一个失败就停止
-			continue
+	if manifest, err := media.LoadParts(t.StateManager.PartsJSON); err == nil && len(manifest.Parts) > 1 {
+		uploadedCount = t.uploadPartSubtitles(uploader, loginInfo, bvid, manifest.Parts, subtitleFiles)
+	} else {
+		for _, subtitleFile := range subtitleFiles {
+			t.App.Logger.Infof("📝 正在上传字幕: %s", filepath.Base(subtitleFile.Path))
+
+			err := uploader.UploadSubtitle(bvid, subtitleFile.Path, subtitleFile.Language)
+			if err != nil {
+				t.App.Logger.Errorf("❌ 上传字幕失败 %s: %v", subtitleFile.Path, err)
+				// 继续上传其他字幕文件，不因为一个失败就停止
+				continue
+			}
+
+			t.App.Logger.Infof("✅ 字幕上传成功: %s (%s)", filepath.Base(subtitleFile.Path), subtitleFile.Language)
+			uploadedCount++
 		}
-
-		t.App.Logger.Infof("✅ 字幕上传成功: %s (%s)", filepath.Base(subtitleFile.Path), subtitleFile.Language)
-		uploadedCount++
 	}
 
 	// 6. 记录结果
@@ -107,6 +119,52 @@ func (t *UploadSubtitleToBilibili) Execute(context map[string]interface{}) bool
 	}
 }
 
+// uploadPartSubtitles 将字幕按分P时间段切分并上传到对应分P，返回成功上传的字幕数
+func (t *UploadSubtitleToBilibili) uploadPartSubtitles(uploader *bilibili.SubtitleUploader, loginInfo *bilibili.LoginInfo, bvid string, parts []media.Part, subtitleFiles []SubtitleFileInfo) int {
+	archiveParts, err := fetchArchiveParts(loginInfo, bvid)
+	if err != nil {
+		t.App.Logger.Errorf("❌ 获取稿件分P信息失败: %v", err)
+		return 0
+	}
+	if len(archiveParts) != len(parts) {
+		t.App.Logger.Warnf("⚠️  稿件分P数(%d)与本地分P数(%d)不一致，按顺序匹配", len(archiveParts), len(parts))
+	}
+
+	uploadedCount := 0
+	for i, part := range parts {
+		if i >= len(archiveParts) {
+			break
+		}
+		info := archiveParts[i]
+
+		for _, subtitleFile := range subtitleFiles {
+			partPath, err := sliceSubtitleForPart(t.StateManager, part, subtitleFile.Path)
+			if err != nil {
+				t.App.Logger.Errorf("❌ 切分字幕失败 P%d %s: %v", part.Index, filepath.Base(subtitleFile.Path), err)
+				continue
+			}
+			if partPath == "" {
+				t.App.Logger.Infof("⏭️  P%d 没有 %s 字幕内容，跳过", part.Index, subtitleFile.Language)
+				continue
+			}
+
+			location, _, err := uploader.UploadSubtitleFile(partPath)
+			if err != nil {
+				t.App.Logger.Errorf("❌ 上传字幕失败 P%d %s: %v", part.Index, filepath.Base(partPath), err)
+				continue
+			}
+			if err := uploader.SaveSubtitleInfo(info.AID, info.CID, location, subtitleFile.Language); err != nil {
+				t.App.Logger.Errorf("❌ 保存字幕失败 P%d %s: %v", part.Index, filepath.Base(partPath), err)
+				continue
+			}
+
+			t.App.Logger.Infof("✅ 字幕上传成功: P%d %s (%s)", part.Index, filepath.Base(partPath), subtitleFile.Language)
+			uploadedCount++
+		}
+	}
+	return uploadedCount
+}
+
 // SubtitleFileInfo 字幕文件信息
 type SubtitleFileInfo struct {
 	Path     string
@@ -114,32 +172,76 @@ type SubtitleFileInfo struct {
 }
 
 // findSubtitleFiles 查找字幕文件
+// 主目标语言译文 + 原文字幕（按原文语言标记 B站语言代码，与译文语言相同时只上传一份）+ 其他目标语言的译文
 func (t *UploadSubtitleToBilibili) findSubtitleFiles() []SubtitleFileInfo {
 	var subtitleFiles []SubtitleFileInfo
 
-	// 检查常见的字幕文件
-	subtitleFilesToCheck := []struct {
-		filename string
-		language string
-	}{
-		{"zh_optimized.srt", "zh-Hans"}, // 中文简体
-		{"en.srt", "en"},                // 英文
-		//{"zh-cn.srt", "zh-Hans"}, // 中文简体
-		//{"zh-tw.srt", "zh-Hant"}, // 中文繁体
-		//{"ja.srt", "ja"},         // 日文
-		//{"ko.srt", "ko"},         // 韩文
-	}
-
-	for _, item := range subtitleFilesToCheck {
-		fullPath := filepath.Join(t.StateManager.CurrentDir, item.filename)
-		if _, err := os.Stat(fullPath); err == nil {
-			subtitleFiles = append(subtitleFiles, SubtitleFileInfo{
-				Path:     fullPath,
-				Language: item.language,
-			})
-			t.App.Logger.Infof("🎯 找到字幕文件: %s (%s)", item.filename, item.language)
+	sourceLang := subtitleLanguage(t.SavedVideoService, map[string]interface{}{}, t.StateManager.VideoID)
+
+	// 主目标语言译文: 校验优化结果与人工编辑都写回该文件；直接使用原文字幕（如原文即中文）时按原文语言标记
+	primary := primaryTarget(t.App, t.SavedVideoService, t.StateManager.VideoID)
+	primaryCode := lang.BilibiliCode(primary)
+	for _, status := range loadTranslationStatus(t.StateManager.TargetsJSON) {
+		if status.Lang == primary && status.Copied {
+			primaryCode = lang.BilibiliCode(sourceLang)
+		}
+	}
+	if file, ok := t.firstExisting(filepath.Base(t.StateManager.TranslatedSRT(primary))); ok {
+		subtitleFiles = append(subtitleFiles, SubtitleFileInfo{Path: file, Language: primaryCode})
+		t.App.Logger.Infof("🎯 找到字幕文件: %s (%s)", filepath.Base(file), primaryCode)
+	}
+
+	// 原文字幕: <lang>.srt（未记录语言的旧任务按英语查找 en.srt）
+	if code := lang.BilibiliCode(sourceLang); !containsSubtitleLanguage(subtitleFiles, code) {
+		if file, ok := t.firstExisting(sourceLang+".srt", code+".srt"); ok {
+			subtitleFiles = append(subtitleFiles, SubtitleFileInfo{Path: file, Language: code})
+			t.App.Logger.Infof("🎯 找到字幕文件: %s (%s)", filepath.Base(file), code)
+		}
+	}
+
+	// 其他目标语言的译文: <lang>.srt（B站同一语言代码只上传一份）
+	for _, status := range translatedSubtitles(t.StateManager) {
+		code := lang.BilibiliCode(status.Lang)
+		if containsSubtitleLanguage(subtitleFiles, code) {
+			continue
+		}
+		file := filepath.Join(t.StateManager.CurrentDir, status.File)
+		subtitleFiles = append(subtitleFiles, SubtitleFileInfo{Path: file, Language: code})
+		t.App.Logger.Infof("🎯 找到字幕文件: %s (%s)", status.File, code)
+	}
+
+	// 双语字幕: B站没有双语语言代码，按配置的语言代码单独上传（不能与已有字幕的语言代码相同，否则会覆盖）
+	if cfg := t.App.Config.BilingualConfig; cfg != nil && cfg.Enabled && cfg.Upload {
+		if cfg.UploadLang == "" {
+			t.App.Logger.Warn("⚠️  未配置双语字幕的上传语言代码 (BilingualConfig.upload_lang)，跳过双语字幕")
+		} else if containsSubtitleLanguage(subtitleFiles, cfg.UploadLang) {
+			t.App.Logger.Warnf("⚠️  双语字幕的上传语言代码 %s 与已有字幕冲突，跳过双语字幕，请修改 BilingualConfig.upload_lang", cfg.UploadLang)
+		} else if file, ok := t.firstExisting(filepath.Base(t.StateManager.BilingualSRT)); ok {
+			subtitleFiles = append(subtitleFiles, SubtitleFileInfo{Path: file, Language: cfg.UploadLang})
+			t.App.Logger.Infof("🎯 找到字幕文件: %s (%s)", filepath.Base(file), cfg.UploadLang)
 		}
 	}
 
 	return subtitleFiles
 }
+
+// containsSubtitleLanguage 是否已有该 B站语言代码的字幕
+func containsSubtitleLanguage(files []SubtitleFileInfo, code string) bool {
+	for _, file := range files {
+		if file.Language == code {
+			return true
+		}
+	}
+	return false
+}
+
+// firstExisting 返回任务目录下第一个存在的文件
+func (t *UploadSubtitleToBilibili) firstExisting(filenames ...string) (string, bool) {
+	for _, filename := range filenames {
+		fullPath := filepath.Join(t.StateManager.CurrentDir, filename)
+		if _, err := os.Stat(fullPath); err == nil {
+			return fullPath, true
+		}
+	}
+	return "", false
+}
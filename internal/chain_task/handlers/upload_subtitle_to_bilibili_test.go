@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+)
+
+func TestFindSubtitleFiles(t *testing.T) {
+	copied := func(code string) TranslationStatus {
+		return TranslationStatus{Lang: code, File: code + ".srt", Status: TranslationDone, Copied: true}
+	}
+	done := func(code string) TranslationStatus {
+		return TranslationStatus{Lang: code, File: code + ".srt", Status: TranslationDone}
+	}
+
+	tests := []struct {
+		name       string
+		sourceLang string              // 原文语言
+		targets    []string            // 翻译目标语言
+		statuses   []TranslationStatus // translations.json
+		files      []string            // 任务目录中存在的字幕文件
+		want       []SubtitleFileInfo  // 文件名 + B站语言代码
+	}{
+		{"英文翻译为中文", "en", []string{"zh"}, []TranslationStatus{done("zh")}, []string{"zh.srt", "en.srt"},
+			[]SubtitleFileInfo{{"zh.srt", "zh-Hans"}, {"en.srt", "en"}}},
+		{"原文即中文只上传一份", "zh", []string{"zh"}, []TranslationStatus{copied("zh")}, []string{"zh.srt"},
+			[]SubtitleFileInfo{{"zh.srt", "zh-Hans"}}},
+		{"原文为 zh-Hans 时与 zh.srt 为同一文件", "zh-Hans", []string{"zh-Hans"}, []TranslationStatus{copied("zh")}, []string{"zh.srt"},
+			[]SubtitleFileInfo{{"zh.srt", "zh-Hans"}}},
+		{"原文即日文只上传一份", "ja", []string{"ja", "zh"}, []TranslationStatus{copied("ja"), done("zh")}, []string{"ja.srt", "zh.srt"},
+			[]SubtitleFileInfo{{"ja.srt", "ja"}, {"zh.srt", "zh-Hans"}}},
+		{"原文为繁体中文时按繁体标记", "zh-Hant", []string{"zh-Hant", "en"}, []TranslationStatus{copied("zh-Hant"), done("en")}, []string{"zh-Hant.srt", "en.srt"},
+			[]SubtitleFileInfo{{"zh-Hant.srt", "zh-Hant"}, {"en.srt", "en"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := manager.NewStateManager(1, "v1", t.TempDir(), time.Now())
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(sm.CurrentDir, file), []byte("1\n00:00:00,000 --> 00:00:01,000\n字幕\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := saveTranslationStatus(sm.TargetsJSON, tt.statuses); err != nil {
+				t.Fatal(err)
+			}
+
+			videos := newTestVideoService(t, &model.SavedVideo{VideoID: "v1", URL: "u", SubtitleLang: tt.sourceLang})
+			app := newTestApp(&types.AppConfig{TranslationConfig: &types.TranslationConfig{TargetLangs: tt.targets}})
+			task := &UploadSubtitleToBilibili{
+				BaseTask:          base.BaseTask{Name: "上传字幕", StateManager: sm},
+				App:               app,
+				SavedVideoService: videos,
+			}
+
+			var got []SubtitleFileInfo
+			for _, file := range task.findSubtitleFiles() {
+				got = append(got, SubtitleFileInfo{Path: filepath.Base(file.Path), Language: file.Language})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findSubtitleFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
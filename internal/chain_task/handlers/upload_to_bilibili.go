@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -16,10 +15,11 @@ import (
 	"github.com/difyz9/ytb2bili/internal/core/services"
 	"github.com/difyz9/ytb2bili/internal/storage"
 	"github.com/difyz9/ytb2bili/pkg/cos"
+	"github.com/difyz9/ytb2bili/pkg/source"
 	"github.com/difyz9/ytb2bili/pkg/utils"
 )
 
-// fetchAndSaveMetadata 尝试从 YouTube 获取元数据并保存到数据库
+// fetchAndSaveMetadata 尝试从来源平台获取元数据并保存到数据库
 func (t *UploadToBilibili) fetchAndSaveMetadata(videoID string) error {
 	t.App.Logger.Infof("🔄 尝试补充获取视频元数据: %s", videoID)
 
@@ -35,7 +35,8 @@ func (t *UploadToBilibili) fetchAndSaveMetadata(videoID string) error {
 	ytdlpPath := manager.GetBinaryPath()
 
 	// 2. 构建命令
-	videoURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
+	provider := source.DefaultRegistry().ForID(videoID)
+	videoURL := sourceVideoURL(t.SavedVideoService, videoID)
 	command := []string{
 		ytdlpPath,
 		"--dump-json",
@@ -68,9 +69,9 @@ func (t *UploadToBilibili) fetchAndSaveMetadata(videoID string) error {
 	}
 
 	// 4. 解析 JSON
-	var metadata VideoMetadataInfo
-	if err := json.Unmarshal(output, &metadata); err != nil {
-		return fmt.Errorf("解析元数据失败: %v", err)
+	metadata, err := provider.MapMetadata(output)
+	if err != nil {
+		return err
 	}
 
 	// 5. 更新数据库
@@ -87,6 +88,16 @@ func (t *UploadToBilibili) fetchAndSaveMetadata(videoID string) error {
 		return fmt.Errorf("更新数据库失败: %v", err)
 	}
 
+	// 同时保存 info JSON 和源视频元数据
+	infoPath := t.StateManager.InfoJSON
+	if err := os.WriteFile(infoPath, output, 0644); err != nil {
+		t.App.Logger.Warnf("⚠️ 保存 info JSON 失败: %v", err)
+		infoPath = ""
+	}
+	if err := t.SavedVideoService.SaveSourceMeta(metadata.ToSourceMeta(videoID, savedVideo.ID, infoPath)); err != nil {
+		t.App.Logger.Warnf("⚠️ 保存源视频元数据失败: %v", err)
+	}
+
 	t.App.Logger.Infof("✅ 成功补充获取并保存元数据: %s", metadata.Title)
 	return nil
 }
@@ -114,6 +125,13 @@ func (t *UploadToBilibili) Execute(context map[string]interface{}) bool {
 	t.App.Logger.Info("开始上传视频到 Bilibili")
 	t.App.Logger.Info("========================================")
 
+	// 0. 疑似重复暂停上传或已确认重复的视频不投稿（重试步骤、手动上传都会直接执行本任务）
+	if savedVideo, err := t.SavedVideoService.GetVideoByVideoID(t.StateManager.VideoID); err == nil && uploadHeld(savedVideo.Status) {
+		t.App.Logger.Warnf("⏸️  视频疑似重复 (状态 %s)，不上传", savedVideo.Status)
+		context["error"] = "视频疑似重复，已暂停上传"
+		return false
+	}
+
 	// 1. 检查登录信息
 	loginStore := storage.GetDefaultStore()
 	if !loginStore.IsValid() {
@@ -131,37 +149,49 @@ func (t *UploadToBilibili) Execute(context map[string]interface{}) bool {
 
 	t.App.Logger.Infof("✓ 已加载登录信息，用户 MID: %d", loginInfo.TokenInfo.Mid)
 
-	// 2. 查找下载的视频文件
-	videoFiles := t.findVideoFiles()
-	if len(videoFiles) == 0 {
+	// 2. 查找投稿的视频文件（已烧录硬字幕时投稿烧录后的视频）
+	videoPath := submissionVideo(t.App, t.StateManager)
+	if videoPath == "" {
 		errMsg := "未找到视频文件"
 		t.App.Logger.Error("❌ " + errMsg)
 		context["error"] = errMsg
 		return false
 	}
-
-	videoPath := videoFiles[0] // 使用第一个视频文件
 	t.App.Logger.Infof("📹 找到视频文件: %s", filepath.Base(videoPath))
 
 	// 3. 创建上传客户端
 	uploadClient := bilibili.NewUploadClient(loginInfo)
 
-	// 4. 上传视频文件到 Bilibili
-	t.App.Logger.Info("⏫ 开始上传视频到 Bilibili...")
-	video, err := uploadClient.UploadVideo(videoPath)
-	if err != nil {
-		userFriendlyError := t.getUserFriendlyError(err, "上传视频")
-		t.App.Logger.Errorf("❌ 上传视频失败: %v", err)
-		context["error"] = userFriendlyError
-		return false
-	}
+	// 4. 上传视频文件到 Bilibili（长视频按章节/时长切分为多个分P）
+	var videos []bilibili.Video
+	if parts := t.prepareParts(videoPath); len(parts) > 0 {
+		t.App.Logger.Infof("⏫ 开始上传 %d 个分P到 Bilibili...", len(parts))
+		videos, err = t.uploadParts(uploadClient, parts)
+		if err != nil {
+			userFriendlyError := t.getUserFriendlyError(err, "上传视频")
+			t.App.Logger.Errorf("❌ 上传视频失败: %v", err)
+			context["error"] = userFriendlyError
+			return false
+		}
+		t.App.Logger.Infof("✓ %d 个分P上传成功！", len(videos))
+	} else {
+		t.App.Logger.Info("⏫ 开始上传视频到 Bilibili...")
+		video, err := uploadClient.UploadVideo(videoPath)
+		if err != nil {
+			userFriendlyError := t.getUserFriendlyError(err, "上传视频")
+			t.App.Logger.Errorf("❌ 上传视频失败: %v", err)
+			context["error"] = userFriendlyError
+			return false
+		}
 
-	t.App.Logger.Infof("✓ 视频上传成功！")
-	t.App.Logger.Infof("  Filename: %s", video.Filename)
-	t.App.Logger.Infof("  Title: %s", video.Title)
+		t.App.Logger.Infof("✓ 视频上传成功！")
+		t.App.Logger.Infof("  Filename: %s", video.Filename)
+		t.App.Logger.Infof("  Title: %s", video.Title)
+		videos = []bilibili.Video{*video}
+	}
 
 	// 5. 准备投稿信息
-	studio := t.buildStudioInfo(video, context)
+	studio := t.buildStudioInfo(videos, context)
 
 	// 6. 提交视频到 Bilibili
 	t.App.Logger.Info("📝 提交视频投稿信息...")
@@ -182,7 +212,8 @@ func (t *UploadToBilibili) Execute(context map[string]interface{}) bool {
 	}
 
 	// 9. 保存上传结果到数据库
-	context["bili_video"] = video
+	context["bili_video"] = studio.Videos[0]
+	context["bili_parts"] = len(studio.Videos)
 	context["bili_result"] = result
 
 	// 10. 保存结果信息到数据库和context
@@ -232,37 +263,8 @@ func (t *UploadToBilibili) Execute(context map[string]interface{}) bool {
 	return true
 }
 
-// findVideoFiles 查找下载目录中的视频文件
-func (t *UploadToBilibili) findVideoFiles() []string {
-	var videoFiles []string
-	videoExtensions := []string{".mp4", ".flv", ".mkv", ".webm", ".avi", ".mov"}
-
-	files, err := os.ReadDir(t.StateManager.CurrentDir)
-	if err != nil {
-		t.App.Logger.Errorf("读取目录失败: %v", err)
-		return videoFiles
-	}
-
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		ext := strings.ToLower(filepath.Ext(file.Name()))
-		for _, videoExt := range videoExtensions {
-			if ext == videoExt {
-				fullPath := filepath.Join(t.StateManager.CurrentDir, file.Name())
-				videoFiles = append(videoFiles, fullPath)
-				break
-			}
-		}
-	}
-
-	return videoFiles
-}
-
 // buildStudioInfo 构建投稿信息
-func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[string]interface{}) *bilibili.Studio {
+func (t *UploadToBilibili) buildStudioInfo(videos []bilibili.Video, context map[string]interface{}) *bilibili.Studio {
 	// 默认值
 	title := t.StateManager.VideoID
 	desc := "自动上传的视频"
@@ -306,6 +308,7 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 			cleanedOriginalTitle := cleanTitle(savedVideo.Title)
 			title = strings.ReplaceAll(title, "{original_title}", cleanedOriginalTitle)
 			title = strings.ReplaceAll(title, "{ai_title}", savedVideo.GeneratedTitle)
+			title = t.applySourceMetaTemplate(title)
 			t.App.Logger.Infof("✓ 使用自定义标题模板: %s", title)
 		} else if biliConfig != nil && !biliConfig.UseOriginalTitle {
 			// 配置为使用AI生成标题
@@ -362,6 +365,7 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 			desc = biliConfig.CustomDescTemplate
 			desc = strings.ReplaceAll(desc, "{original_desc}", savedVideo.Description)
 			desc = strings.ReplaceAll(desc, "{ai_desc}", savedVideo.GeneratedDesc)
+			desc = t.applySourceMetaTemplate(desc)
 			t.App.Logger.Infof("✓ 使用自定义描述模板")
 		} else if biliConfig != nil && biliConfig.UseOriginalDesc {
 			// 配置为使用原始描述
@@ -466,8 +470,8 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 		}
 	}
 
-	// 从 context 获取下载的封面图片并上传作为封面
-	if coverImagePath, ok := context["cover_image_path"].(string); ok && coverImagePath != "" {
+	// 获取封面图片并上传（任务上下文中的封面，定时上传时查找生成的封面或下载的平台封面）
+	if coverImagePath := findCoverImage(t.StateManager, context); coverImagePath != "" {
 		t.App.Logger.Infof("📸 找到封面图片: %s", filepath.Base(coverImagePath))
 
 		// 创建上传客户端并上传封面
@@ -492,10 +496,16 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 		hasZhSubtitle = true
 		t.App.Logger.Info("✓ 检测到中文字幕文件")
 	}
+	// 已烧录硬字幕且不保留 CC 字幕时不开启字幕
+	if _, ok := burnedVideo(t.App, t.StateManager); ok && !t.App.Config.BurnInConfig.KeepSoftSubtitles {
+		hasZhSubtitle = false
+	}
 
-	// 更新video对象的Title为翻译后的标题
-	video.Title = title
-	t.App.Logger.Infof("✓ 设置视频Title为: %s", title)
+	// 单P投稿时分P标题与稿件标题一致，多P投稿保留章节标题
+	if len(videos) == 1 {
+		videos[0].Title = title
+		t.App.Logger.Infof("✓ 设置视频Title为: %s", title)
+	}
 
 	// 读取配置
 	copyright := 1 // 默认自制
@@ -554,9 +564,7 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 		LosslessMusic: 0,
 		NoReprint:     noReprint,
 		OpenElec:      openElec,
-		Videos: []bilibili.Video{
-			*video,
-		},
+		Videos:        videos,
 		Source: source,
 	}
 
@@ -581,6 +589,7 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 	t.App.Logger.Infof("  分区: %d", studio.Tid)
 	t.App.Logger.Infof("  封面: %s", studio.Cover)
 	t.App.Logger.Infof("  字幕: %v", studio.OpenSubtitle)
+	t.App.Logger.Infof("  分P: %d", len(studio.Videos))
 	t.App.Logger.Infof("  类型: %d (1=自制, 2=转载)", studio.Copyright)
 	if studio.Copyright == 2 {
 		t.App.Logger.Infof("  来源: %s", studio.Source)
@@ -589,6 +598,53 @@ func (t *UploadToBilibili) buildStudioInfo(video *bilibili.Video, context map[st
 	return studio
 }
 
+// applySourceMetaTemplate 替换模板中的源视频元数据变量
+// 支持: {uploader} {channel} {upload_date} {duration} {view_count} {tags}
+func (t *UploadToBilibili) applySourceMetaTemplate(text string) string {
+	if !strings.Contains(text, "{") {
+		return text
+	}
+
+	var uploader, channel, uploadDate, duration, viewCount, tags string
+	meta, err := t.SavedVideoService.GetSourceMeta(t.StateManager.VideoID)
+	if err != nil {
+		t.App.Logger.Debugf("未找到源视频元数据: %v", err)
+	} else {
+		uploader = meta.Uploader
+		channel = meta.Channel
+		if channel == "" {
+			channel = meta.Uploader
+		}
+		uploadDate = meta.UploadDate
+		// YYYYMMDD -> YYYY-MM-DD
+		if len(uploadDate) == 8 {
+			uploadDate = uploadDate[:4] + "-" + uploadDate[4:6] + "-" + uploadDate[6:]
+		}
+		if meta.Duration > 0 {
+			total := int(meta.Duration)
+			if total >= 3600 {
+				duration = fmt.Sprintf("%d:%02d:%02d", total/3600, total%3600/60, total%60)
+			} else {
+				duration = fmt.Sprintf("%d:%02d", total/60, total%60)
+			}
+		}
+		if meta.ViewCount > 0 {
+			viewCount = fmt.Sprintf("%d", meta.ViewCount)
+		}
+		tags = strings.Join(meta.GetTags(), ",")
+	}
+
+	replacer := strings.NewReplacer(
+		"{uploader}", uploader,
+		"{channel}", channel,
+		"{upload_date}", uploadDate,
+		"{duration}", duration,
+		"{view_count}", viewCount,
+		"{tags}", tags,
+	)
+	return replacer.Replace(text)
+}
+
 // truncateString 截断字符串用于日志显示
 func (t *UploadToBilibili) truncateString(s string, maxLen int) string {
 	runes := []rune(s)
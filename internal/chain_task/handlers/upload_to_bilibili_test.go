@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/base"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+)
+
+func TestUploadToBilibiliSkipsHeldVideo(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+	}{
+		{"疑似重复暂停上传", "210"},
+		{"确认重复", "298"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := manager.NewStateManager(1, "v1", t.TempDir(), time.Now())
+			task := &UploadToBilibili{
+				BaseTask:          base.BaseTask{Name: "上传到Bilibili", StateManager: sm},
+				App:               newTestApp(&types.AppConfig{}),
+				SavedVideoService: newTestVideoService(t, &model.SavedVideo{VideoID: "v1", URL: "u", Status: tt.status}),
+			}
+
+			context := map[string]interface{}{}
+			if task.Execute(context) {
+				t.Fatal("Execute() = true, want held video not uploaded")
+			}
+			if context["error"] == nil {
+				t.Error("context error not set")
+			}
+		})
+	}
+}
+
+func TestSubmissionVideo(t *testing.T) {
+	temps := []string{"v1.branding.mp4", "v1.burning.mp4", "v1.loudnorm.mp4"}
+	tests := []struct {
+		name  string
+		burn  bool
+		files []string
+		want  string
+	}{
+		{"忽略中断留下的临时文件", false, append([]string{"v1.mp4"}, temps...), "v1.mp4"},
+		{"只有临时文件", false, temps, ""},
+		{"已烧录硬字幕", true, []string{"v1.mp4", "v1.burned.mp4"}, "v1.burned.mp4"},
+		{"未启用烧录时忽略烧录文件", false, []string{"v1.mp4", "v1.burned.mp4"}, "v1.mp4"},
+		{"未合并为 mp4", false, []string{"v1.mkv"}, "v1.mkv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := manager.NewStateManager(1, "v1", t.TempDir(), time.Now())
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(sm.CurrentDir, file), []byte(file), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			app := newTestApp(&types.AppConfig{BurnInConfig: &types.BurnInConfig{Enabled: tt.burn}})
+
+			got := submissionVideo(app, sm)
+			if got != "" {
+				got = filepath.Base(got)
+			}
+			if got != tt.want {
+				t.Errorf("submissionVideo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,20 +21,33 @@ type StateManager struct {
 	InputVideoPath  string
 	NoviceVideoPath string
 	OutVideoPath    string
+	BurnedVideo     string // 烧录硬字幕后的视频（存在时作为投稿文件）
 	ImageCover      string
 	OriginalMP3     string
 	OriginalWAV     string // WAV音频文件（用于Whisper）
 	TranslateMP3    string
 	OriginalJSON    string
+	InfoJSON        string // yt-dlp 完整 info JSON
 	TranslateJSON   string
 	OriginalSRT     string
-	M3u8FileName    string
-	M3u8FileDir     string
+	SourceSRT       string // 原文字幕（翻译输入，各字幕来源统一写入）
+	SourceWords     string // 语音识别的分段与词级时间戳（JSON）
+	M3u8FileName    string // HLS 预览主播放列表
+	M3u8FileDir     string // HLS 预览目录
 	TranslateSRT    string
 	TranslateVtt    string
 	TranslateTXT    string
+	TargetsJSON     string // 各目标语言的翻译状态
+	PartsJSON       string // 分P清单
+	BrandingJSON    string // 片头片尾/水印处理结果
+	AlignmentJSON   string // 字幕强制对齐结果（每条字幕的置信度）
+	BilingualSRT    string // 双语字幕（译文与原文各一行）
+	BilingualASS    string // 双语字幕 ASS 版本（第二行单独样式）
+	BurnInASS       string // 烧录硬字幕使用的 ASS 字幕
 	// 目录路径
 	AudioDir       string
+	PartsDir       string // 分P切片目录
+	StoryboardDir  string // 故事板目录
 	SaveUrlService *services.TbVideoService
 
 	// 内存缓存
@@ -61,22 +74,48 @@ func NewStateManager(Id uint, videoID, projectRoot string, createTim time.Time)
 		CurrentDir:     currentDir,
 		InputVideoPath: filepath.Join(currentDir, videoID+".mp4"),
 		OutVideoPath:   filepath.Join(currentDir, videoID+"out.mp4"),
+		BurnedVideo:    filepath.Join(currentDir, videoID+".burned.mp4"),
 		OriginalWAV:    filepath.Join(currentDir, videoID+".wav"),
 		OriginalMP3:    filepath.Join(currentDir, videoID+".mp3"),
 		ImageCover:     filepath.Join(currentDir, "cover.jpg"),
 		OriginalSRT:    filepath.Join(currentDir, "en.srt"),
+		SourceSRT:      filepath.Join(currentDir, videoID+".srt"),
+		SourceWords:    filepath.Join(currentDir, videoID+".words.json"),
 		OriginalJSON:   filepath.Join(currentDir, "en.json"),
+		InfoJSON:       filepath.Join(currentDir, videoID+".info.json"),
 		TranslateJSON:  filepath.Join(currentDir, "zh.json"),
 		TranslateSRT:   filepath.Join(currentDir, "zh.srt"),
 		TranslateVtt:   filepath.Join(currentDir, "zh.vtt"),
 		TranslateTXT:   filepath.Join(currentDir, videoID+"_trans.txt"),
+		TargetsJSON:    filepath.Join(currentDir, "translations.json"),
+		PartsJSON:      filepath.Join(currentDir, "parts.json"),
+		BrandingJSON:   filepath.Join(currentDir, "branding.json"),
+		AlignmentJSON:  filepath.Join(currentDir, "alignment.json"),
+		BilingualSRT:   filepath.Join(currentDir, "bilingual.srt"),
+		BilingualASS:   filepath.Join(currentDir, "bilingual.ass"),
+		BurnInASS:      filepath.Join(currentDir, "burnin.ass"),
+		PartsDir:       filepath.Join(currentDir, "parts"),
+		M3u8FileDir:    filepath.Join(currentDir, "preview"),
+		M3u8FileName:   filepath.Join(currentDir, "preview", "master.m3u8"),
+		StoryboardDir:  filepath.Join(currentDir, "storyboard"),
 		//AudioDir:       audioDir,
-		//M3u8FileDir:    m8u3Dir,
-		//M3u8FileName:   filepath.Join(m8u3Dir, "output.m3u8"),
 		cache: make(map[string]interface{}),
 	}
 }
 
+// SubtitlePath 按语言代码命名的字幕文件路径（如 ja.srt、zh-Hant.srt）
+func (s *StateManager) SubtitlePath(lang string) string {
+	return filepath.Join(s.CurrentDir, lang+".srt")
+}
+
+// TranslatedSRT 译文字幕路径：简体中文沿用 zh.srt，其他语言按 BCP-47 代码命名（如 ja.srt、zh-Hant.srt）
+func (s *StateManager) TranslatedSRT(lang string) string {
+	if lang == "zh" || lang == "zh-Hans" {
+		return s.TranslateSRT
+	}
+	return s.SubtitlePath(lang)
+}
+
 // GetCache 获取缓存
 func (s *StateManager) GetCache(key string) (interface{}, bool) {
 	s.mu.RLock()
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/difyz9/ytb2bili/pkg/store/model"
 	"gorm.io/gorm"
 )
@@ -17,10 +21,11 @@ func NewSavedVideoService(db *gorm.DB) *SavedVideoService {
 	}
 }
 
-// GetPendingVideos 获取待处理的视频列表（状态为 001 且 subtitles 不为空）
+// GetPendingVideos 获取待处理的视频列表（状态为 001）
+// 没有插件提交字幕的视频同样处理，由平台字幕轨或语音识别生成字幕
 func (s *SavedVideoService) GetPendingVideos(limit int) ([]model.SavedVideo, error) {
 	var videos []model.SavedVideo
-	err := s.DB.Where("status = ? AND subtitles IS NOT NULL AND subtitles != ''", "001").
+	err := s.DB.Where("status = ?", "001").
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&videos).Error
@@ -54,6 +59,40 @@ func (s *SavedVideoService) UpdateStatus(id uint, status string) error {
 		Update("status", status).Error
 }
 
+// UpdateSubtitleSource 记录原始字幕的来源和语言
+func (s *SavedVideoService) UpdateSubtitleSource(id uint, source, lang string) error {
+	return s.DB.Model(&model.SavedVideo{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"subtitle_source": source,
+			"subtitle_lang":   lang,
+		}).Error
+}
+
+// UpdateSourceLang 记录原视频语音语言和检测来源
+func (s *SavedVideoService) UpdateSourceLang(id uint, lang, from string) error {
+	return s.DB.Model(&model.SavedVideo{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"source_lang":      lang,
+			"source_lang_from": from,
+		}).Error
+}
+
+// GetLanguages 获取视频记录中的语音语言和原文字幕语言（只查询语言字段）
+func (s *SavedVideoService) GetLanguages(videoID string) (sourceLang, subtitleLang string, err error) {
+	var video model.SavedVideo
+	err = s.DB.Select("source_lang", "subtitle_lang").Where("video_id = ?", videoID).First(&video).Error
+	return video.SourceLang, video.SubtitleLang, err
+}
+
+// GetTargetLangs 获取视频单独指定的翻译目标语言（逗号分隔，未指定时为空）
+func (s *SavedVideoService) GetTargetLangs(videoID string) (string, error) {
+	var video model.SavedVideo
+	err := s.DB.Select("target_langs").Where("video_id = ?", videoID).First(&video).Error
+	return video.TargetLangs, err
+}
+
 // UpdateVideo 更新视频信息
 func (s *SavedVideoService) UpdateVideo(video *model.SavedVideo) error {
 	return s.DB.Save(video).Error
@@ -135,3 +174,218 @@ func (s *SavedVideoService) GetVideosPaginated(offset, limit int) ([]model.Saved
 func (s *SavedVideoService) GetByID(id uint) (*model.SavedVideo, error) {
 	return s.GetVideoByID(id)
 }
+
+// SaveSourceMeta 保存源视频元数据（按 VideoID 覆盖更新）
+func (s *SavedVideoService) SaveSourceMeta(meta *model.VideoSourceMeta) error {
+	var existing model.VideoSourceMeta
+	err := s.DB.Where("video_id = ?", meta.VideoID).First(&existing).Error
+	if err == nil {
+		meta.ID = existing.ID
+		meta.CreatedAt = existing.CreatedAt
+		return s.DB.Save(meta).Error
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return s.DB.Create(meta).Error
+}
+
+// GetSourceMeta 根据 VideoID 获取源视频元数据
+func (s *SavedVideoService) GetSourceMeta(videoID string) (*model.VideoSourceMeta, error) {
+	var meta model.VideoSourceMeta
+	err := s.DB.Where("video_id = ?", videoID).First(&meta).Error
+	if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// GetSourceMetaMap 批量获取源视频元数据（key 为 VideoID）
+func (s *SavedVideoService) GetSourceMetaMap(videoIDs []string) (map[string]*model.VideoSourceMeta, error) {
+	result := make(map[string]*model.VideoSourceMeta)
+	if len(videoIDs) == 0 {
+		return result, nil
+	}
+
+	var metas []model.VideoSourceMeta
+	if err := s.DB.Where("video_id IN ?", videoIDs).Find(&metas).Error; err != nil {
+		return nil, err
+	}
+	for i := range metas {
+		result[metas[i].VideoID] = &metas[i]
+	}
+	return result, nil
+}
+
+// SaveFingerprint 保存视频指纹（按 VideoID 覆盖更新，保留人工放行状态）
+func (s *SavedVideoService) SaveFingerprint(fp *model.VideoFingerprint) error {
+	var existing model.VideoFingerprint
+	err := s.DB.Where("video_id = ?", fp.VideoID).First(&existing).Error
+	if err == nil {
+		fp.ID = existing.ID
+		fp.CreatedAt = existing.CreatedAt
+		fp.Overridden = existing.Overridden
+		fp.ReviewedAt = existing.ReviewedAt
+		return s.DB.Save(fp).Error
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return s.DB.Create(fp).Error
+}
+
+// GetFingerprint 根据 VideoID 获取视频指纹
+func (s *SavedVideoService) GetFingerprint(videoID string) (*model.VideoFingerprint, error) {
+	var fp model.VideoFingerprint
+	err := s.DB.Where("video_id = ?", videoID).First(&fp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &fp, nil
+}
+
+// SaveMediaInfo 保存视频媒体信息（按 VideoID 覆盖更新）
+func (s *SavedVideoService) SaveMediaInfo(info *model.VideoMediaInfo) error {
+	var existing model.VideoMediaInfo
+	err := s.DB.Where("video_id = ?", info.VideoID).First(&existing).Error
+	if err == nil {
+		info.ID = existing.ID
+		info.CreatedAt = existing.CreatedAt
+		return s.DB.Save(info).Error
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return s.DB.Create(info).Error
+}
+
+// GetMediaInfo 根据 VideoID 获取视频媒体信息
+func (s *SavedVideoService) GetMediaInfo(videoID string) (*model.VideoMediaInfo, error) {
+	var info model.VideoMediaInfo
+	err := s.DB.Where("video_id = ?", videoID).First(&info).Error
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// FindFingerprintCandidates 查找时长相近的其他视频指纹（查重候选）
+func (s *SavedVideoService) FindFingerprintCandidates(videoID string, minDuration, maxDuration float64) ([]model.VideoFingerprint, error) {
+	var fps []model.VideoFingerprint
+	err := s.DB.Where("video_id <> ? AND duration BETWEEN ? AND ?", videoID, minDuration, maxDuration).
+		Find(&fps).Error
+	return fps, err
+}
+
+// ReviewDuplicate 记录人工查重处理结果
+func (s *SavedVideoService) ReviewDuplicate(videoID string, overridden bool) error {
+	now := time.Now()
+	return s.DB.Model(&model.VideoFingerprint{}).
+		Where("video_id = ?", videoID).
+		Updates(map[string]interface{}{
+			"overridden":  overridden,
+			"reviewed_at": &now,
+		}).Error
+}
+
+// ParkVideo 暂停处理视频（等待首播/直播结束），到达 wakeAt 后重新进入待处理队列
+func (s *SavedVideoService) ParkVideo(id uint, wakeAt time.Time, reason string) error {
+	return s.DB.Model(&model.SavedVideo{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":        "003",
+		"wake_at":       &wakeAt,
+		"status_reason": reason,
+	}).Error
+}
+
+// MarkUnavailable 将视频标记为不可用的终态（私享、删除、地区限制等）
+func (s *SavedVideoService) MarkUnavailable(id uint, status, reason string) error {
+	return s.DB.Model(&model.SavedVideo{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":        status,
+		"wake_at":       nil,
+		"status_reason": reason,
+	}).Error
+}
+
+// WakeParkedVideos 将到达检查时间的暂停视频重置为待处理，返回唤醒数量
+func (s *SavedVideoService) WakeParkedVideos(now time.Time) (int64, error) {
+	result := s.DB.Model(&model.SavedVideo{}).
+		Where("status = ? AND wake_at <= ?", "003", now).
+		Updates(map[string]interface{}{
+			"status":  "001",
+			"wake_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ErrSubtitleVersionConflict 字幕已被其他请求修改（版本号不一致）
+var ErrSubtitleVersionConflict = errors.New("字幕版本冲突")
+
+// GetSubtitleTrack 获取字幕轨的版本记录，未编辑过时返回 nil
+func (s *SavedVideoService) GetSubtitleTrack(videoID, lang string) (*model.SubtitleTrack, error) {
+	var track model.SubtitleTrack
+	err := s.DB.Where("video_id = ? AND lang = ?", videoID, lang).First(&track).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &track, nil
+}
+
+// SaveSubtitleVersion 保存字幕轨的新版本及修改记录
+// 以 previous（读取时记录的版本号）作为乐观锁条件，期间被其他请求更新时返回 ErrSubtitleVersionConflict
+func (s *SavedVideoService) SaveSubtitleVersion(track *model.SubtitleTrack, previous int, edits []model.SubtitleEdit) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if track.ID == 0 {
+			// 唯一索引保证并发首次保存时只有一个成功
+			if err := tx.Create(track).Error; err != nil {
+				if isDuplicateKey(err) {
+					return ErrSubtitleVersionConflict
+				}
+				return err
+			}
+		} else {
+			result := tx.Model(&model.SubtitleTrack{}).
+				Where("id = ? AND version = ?", track.ID, previous).
+				Updates(map[string]interface{}{
+					"version":   track.Version,
+					"checksum":  track.Checksum,
+					"cue_count": track.CueCount,
+					"edited_at": track.EditedAt,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrSubtitleVersionConflict
+			}
+		}
+		if len(edits) == 0 {
+			return nil
+		}
+		return tx.Create(&edits).Error
+	})
+}
+
+// isDuplicateKey 是否为唯一索引冲突（未开启 TranslateError 时按各数据库的错误信息判断）
+func isDuplicateKey(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || // SQLite
+		strings.Contains(msg, "Duplicate entry") || // MySQL
+		strings.Contains(msg, "duplicate key value") // PostgreSQL
+}
+
+// ListSubtitleEdits 获取字幕轨的修改记录（最新在前），cueIndex 大于 0 时只返回该条字幕的记录
+func (s *SavedVideoService) ListSubtitleEdits(videoID, lang string, cueIndex int) ([]model.SubtitleEdit, error) {
+	var edits []model.SubtitleEdit
+	query := s.DB.Where("video_id = ? AND lang = ?", videoID, lang)
+	if cueIndex > 0 {
+		query = query.Where("cue_index = ?", cueIndex)
+	}
+	err := query.Order("id DESC").Find(&edits).Error
+	return edits, err
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestSavedVideoService(t *testing.T) *SavedVideoService {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&model.SubtitleTrack{}, &model.SubtitleEdit{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return NewSavedVideoService(db)
+}
+
+func TestSaveSubtitleVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *model.SubtitleTrack // 已保存的版本记录，nil 表示首次保存
+		previous int                  // 请求读取时的版本号
+		wantErr  error
+		want     int // 保存后数据库中的版本号
+	}{
+		{"首次保存", nil, 0, nil, 1},
+		{"基于最新版本保存", &model.SubtitleTrack{Version: 3, Checksum: "a"}, 3, nil, 4},
+		{"基于旧版本保存", &model.SubtitleTrack{Version: 3, Checksum: "a"}, 2, ErrSubtitleVersionConflict, 3},
+		{"并发首次保存", &model.SubtitleTrack{Version: 1, Checksum: "a"}, 0, ErrSubtitleVersionConflict, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSavedVideoService(t)
+			var id uint
+			if tt.existing != nil {
+				tt.existing.VideoID, tt.existing.Lang = "v1", "zh"
+				if err := s.DB.Create(tt.existing).Error; err != nil {
+					t.Fatal(err)
+				}
+				id = tt.existing.ID
+			}
+			// "并发首次保存" 模拟另一个请求已先创建记录：本次请求读取时还没有记录
+			if tt.previous == 0 {
+				id = 0
+			}
+
+			track := &model.SubtitleTrack{VideoID: "v1", Lang: "zh", Version: tt.previous + 1, Checksum: "b"}
+			track.ID = id
+			edits := []model.SubtitleEdit{{VideoID: "v1", Lang: "zh", Version: tt.previous + 1, CueIndex: 1, Action: "edit"}}
+			err := s.SaveSubtitleVersion(track, tt.previous, edits)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SaveSubtitleVersion() error = %v, want %v", err, tt.wantErr)
+			}
+
+			saved, err := s.GetSubtitleTrack("v1", "zh")
+			if err != nil || saved == nil {
+				t.Fatalf("GetSubtitleTrack() = %v, %v", saved, err)
+			}
+			if saved.Version != tt.want {
+				t.Errorf("saved version = %d, want %d", saved.Version, tt.want)
+			}
+
+			// 冲突时整个事务回滚，不留下修改记录
+			history, err := s.ListSubtitleEdits("v1", "zh", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantEdits := 1
+			if tt.wantErr != nil {
+				wantEdits = 0
+			}
+			if len(history) != wantEdits {
+				t.Errorf("got %d edits, want %d", len(history), wantEdits)
+			}
+		})
+	}
+}
@@ -32,25 +32,49 @@ func (s *TaskStepService) InitTaskSteps(videoID string) error {
 		CanRetry bool
 	}{
 		{"下载视频", 1, true},
-		{"生成字幕", 2, true},
-		{"翻译字幕", 3, true},
-		{"生成元数据", 4, true},
-		{"上传到Bilibili", 5, true},
-		// {"上传字幕到Bilibili", 6, true},
+		{"视频查重", 2, true},
+		{"转码", 3, true},
+		{"检测语言", 4, true},
+		{"生成字幕", 5, true},
+		{"获取平台字幕", 6, true},
+		{"语音识别", 7, true},
+		{"字幕对齐", 8, true},
+		{"字幕断句", 9, true},
+		{"翻译字幕", 10, true},
+		{"译文断句", 11, true},
+		{"生成元数据", 12, true},
+		{"生成封面", 13, true},
+		{"片头片尾", 14, true},
+		{"响度标准化", 15, true},
+		{"烧录字幕", 16, true},
+		{"生成预览", 17, true},
+		{"生成故事板", 18, true},
+		{"媒体检查", 19, true},
+		{"上传到Bilibili", 20, true},
+		// {"上传字幕到Bilibili", 21, true},
 	}
 
-	// 检查是否已经初始化过
-	var count int64
-	if err := s.DB.Model(&model.TaskStep{}).Where("video_id = ?", videoID).Count(&count).Error; err != nil {
+	// 已有步骤按名称补齐: 旧视频缺少后来新增的步骤时补建，顺序变化时同步更新
+	var existing []model.TaskStep
+	if err := s.DB.Where("video_id = ?", videoID).Find(&existing).Error; err != nil {
 		return err
 	}
-
-	if count > 0 {
-		return nil // 已经初始化过，跳过
+	existingByName := make(map[string]model.TaskStep, len(existing))
+	for _, step := range existing {
+		existingByName[step.StepName] = step
 	}
 
-	// 创建任务步骤记录
 	for _, step := range steps {
+		if current, ok := existingByName[step.Name]; ok {
+			if current.StepOrder != step.Order {
+				if err := s.DB.Model(&model.TaskStep{}).Where("id = ?", current.ID).
+					Update("step_order", step.Order).Error; err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
 		taskStep := &model.TaskStep{
 			VideoID:   videoID,
 			StepName:  step.Name,
@@ -139,6 +163,20 @@ func (s *TaskStepService) ResetTaskStep(videoID, stepName string) error {
 		Updates(updates).Error
 }
 
+// HoldUploadStep 暂停投稿步骤：疑似重复时将待执行的上传步骤标记为跳过，避免被当作重试步骤执行
+func (s *TaskStepService) HoldUploadStep(videoID string) error {
+	return s.DB.Model(&model.TaskStep{}).
+		Where("video_id = ? AND step_name = ? AND status = ?", videoID, "上传到Bilibili", model.TaskStepStatusPending).
+		Update("status", model.TaskStepStatusSkipped).Error
+}
+
+// ReleaseUploadStep 恢复被暂停的投稿步骤为待执行
+func (s *TaskStepService) ReleaseUploadStep(videoID string) error {
+	return s.DB.Model(&model.TaskStep{}).
+		Where("video_id = ? AND step_name = ? AND status = ?", videoID, "上传到Bilibili", model.TaskStepStatusSkipped).
+		Update("status", model.TaskStepStatusPending).Error
+}
+
 // GetTaskStepByName 根据视频ID和步骤名称获取特定步骤
 func (s *TaskStepService) GetTaskStepByName(videoID, stepName string) (*model.TaskStep, error) {
 	var step model.TaskStep
@@ -226,17 +264,22 @@ func (s *TaskStepService) ResetAllRunningTasks() error {
 	return nil
 }
 
+// heldVideoStatuses 暂停处理的视频状态，这些视频的待执行步骤不作为重试步骤执行
+var heldVideoStatuses = []string{"003", "991", "992", "993", "994", "995", "210", "298"}
+
 // GetPendingSteps 获取所有状态为pending的任务步骤
 func (s *TaskStepService) GetPendingSteps() ([]*model.TaskStep, error) {
 	var steps []*model.TaskStep
 
 	// 使用 JOIN 查询，只获取未删除视频的待处理步骤
+	// 等待首播/直播（003）、源视频不可用（991-995）、疑似重复（210/298）的视频不执行，唤醒或放行后再执行
 	result := s.DB.Table("cw_task_steps").
 		Select("cw_task_steps.*").
 		Joins("INNER JOIN cw_saved_videos ON cw_task_steps.video_id = cw_saved_videos.video_id").
 		Where("cw_task_steps.status = ?", model.TaskStepStatusPending).
 		Where("cw_task_steps.deleted_at IS NULL").
 		Where("cw_saved_videos.deleted_at IS NULL").
+		Where("cw_saved_videos.status NOT IN ?", heldVideoStatuses).
 		Order("cw_task_steps.created_at ASC").
 		Find(&steps)
 
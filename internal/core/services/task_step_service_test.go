@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGetPendingSteps(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.SavedVideo{}, &model.TaskStep{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{"001", true},
+		{"200", true},
+		{"999", true},
+		{"003", false}, // 等待首播/直播
+		{"991", false}, // 已删除
+		{"995", false}, // 直播跳过
+		{"210", false}, // 疑似重复
+		{"298", false}, // 确认重复
+	}
+	for _, tt := range tests {
+		videoID := "v" + tt.status
+		if err := db.Create(&model.SavedVideo{VideoID: videoID, URL: "u", Status: tt.status}).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, status := range []string{model.TaskStepStatusPending, model.TaskStepStatusFailed} {
+			step := &model.TaskStep{VideoID: videoID, StepName: "获取平台字幕-" + status, Status: status}
+			if err := db.Create(step).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	steps, err := NewTaskStepService(db).GetPendingSteps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	for _, step := range steps {
+		if step.Status != model.TaskStepStatusPending {
+			t.Errorf("step %s/%s status = %s, want pending", step.VideoID, step.StepName, step.Status)
+		}
+		got[step.VideoID] = true
+	}
+	for _, tt := range tests {
+		if got["v"+tt.status] != tt.want {
+			t.Errorf("video status %s: pending step returned = %v, want %v", tt.status, got["v"+tt.status], tt.want)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package services
 
 import (
 	"github.com/difyz9/ytb2bili/internal/core/models"
-	"github.com/difyz9/ytb2bili/pkg/utils"
+	"github.com/difyz9/ytb2bili/pkg/source"
 	"fmt"
 	"time"
 
@@ -28,13 +28,16 @@ func (s *TbVideoService) SaveUrl(data *SaveUrlRequest) (*models.TbVideo, error)
 	defer s.lock.Unlock()
 
 	// 从URL提取videoId，如果请求中没有提供的话
-	videoId := utils.ExtractVideoID(data.Url)
+	videoId := source.ExtractVideoID(data.Url)
+	if videoId == "" {
+		return nil, fmt.Errorf("无法从URL中提取视频ID: %s", data.Url)
+	}
 
 	// 转换OperationType从string到int
 
-	// 检查是否已存在相同的videoId
+	// 检查是否已存在相同的videoId（包括不带平台前缀的历史ID）
 	var existingUrl models.TbVideo
-	dbErr := s.db.Where("video_id = ?", videoId).First(&existingUrl).Error
+	dbErr := s.db.Where("video_id IN ?", source.LookupIDs(videoId)).First(&existingUrl).Error
 
 	if dbErr == nil {
 		// 记录已存在，更新operation_type和其他字段
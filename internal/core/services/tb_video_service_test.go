@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/difyz9/ytb2bili/internal/core/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSaveUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string // 已有记录的 video_id
+		url      string
+		wantErr  bool
+		wantID   string // 保存后记录的 video_id
+		wantRows int64
+	}{
+		{"新视频", nil, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", false, "yt.dQw4w9WgXcQ", 1},
+		{"更新不带前缀的历史记录", []string{"dQw4w9WgXcQ"}, "https://youtu.be/dQw4w9WgXcQ", false, "dQw4w9WgXcQ", 1},
+		{"无法提取视频ID", nil, "not a url", true, "", 0},
+		{"无法提取视频ID时不覆盖空ID的记录", []string{""}, "https://b23.tv/abcdef", true, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.AutoMigrate(&models.TbVideo{}); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if sqlDB, err := db.DB(); err == nil {
+					sqlDB.Close()
+				}
+			})
+			for _, id := range tt.existing {
+				if err := db.Create(&models.TbVideo{VideoId: id, URL: "old"}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := NewVideoService(db).SaveUrl(&SaveUrlRequest{Url: tt.url})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SaveUrl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.VideoId != tt.wantID {
+				t.Errorf("VideoId = %q, want %q", got.VideoId, tt.wantID)
+			}
+			var rows int64
+			db.Model(&models.TbVideo{}).Count(&rows)
+			if rows != tt.wantRows {
+				t.Errorf("rows = %d, want %d", rows, tt.wantRows)
+			}
+			if tt.wantErr && tt.wantRows > 0 {
+				var old models.TbVideo
+				if db.First(&old).Error == nil && old.URL != "old" {
+					t.Errorf("existing record overwritten: URL = %q", old.URL)
+				}
+			}
+		})
+	}
+}
@@ -29,6 +29,26 @@ type AppConfig struct {
 	AnalyticsConfig     *AnalyticsConfig     `toml:"AnalyticsConfig"`     // 数据分析配置
 	BilibiliConfig      *BilibiliConfig      `toml:"BilibiliConfig"`      // Bilibili上传配置
 	WhisperConfig       *WhisperConfig       `toml:"WhisperConfig"`       // Whisper 语音识别配置
+	CaptionConfig       *CaptionConfig       `toml:"CaptionConfig"`       // 平台字幕获取配置
+	YtDlpConfig         *YtDlpConfig         `toml:"YtDlpConfig"`         // yt-dlp 版本管理配置
+	DuplicateConfig     *DuplicateConfig     `toml:"DuplicateConfig"`     // 重复视频检测配置
+	LiveConfig          *LiveConfig          `toml:"LiveConfig"`          // 直播/首播视频处理配置
+	SplitConfig         *SplitConfig         `toml:"SplitConfig"`         // 长视频分P配置
+	DownloadCheckConfig *DownloadCheckConfig `toml:"DownloadCheckConfig"` // 下载文件完整性校验配置
+	MediaCheckConfig    *MediaCheckConfig    `toml:"MediaCheckConfig"`    // 投稿前媒体检查配置
+	TranscodeConfig     *TranscodeConfig     `toml:"TranscodeConfig"`     // 转码步骤配置
+	BrandingConfig      *BrandingConfig      `toml:"BrandingConfig"`      // 片头片尾与水印后期处理配置
+	LoudnormConfig      *LoudnormConfig      `toml:"LoudnormConfig"`      // 音频响度标准化配置
+	CoverConfig         *CoverConfig         `toml:"CoverConfig"`         // 自动生成封面配置
+	PreviewConfig       *PreviewConfig       `toml:"PreviewConfig"`       // 上传前 HLS 预览配置
+	StoryboardConfig    *StoryboardConfig    `toml:"StoryboardConfig"`    // 故事板与缩略图总览配置
+	AsrConfig           *AsrConfig           `toml:"AsrConfig"`           // 语音识别提供商配置
+	LanguageConfig      *LanguageConfig      `toml:"LanguageConfig"`      // 原视频语言检测配置
+	SegmentConfig       *SegmentConfig       `toml:"SegmentConfig"`       // 字幕断句配置
+	AlignConfig         *AlignConfig         `toml:"AlignConfig"`         // 字幕强制对齐配置
+	BilingualConfig     *BilingualConfig     `toml:"BilingualConfig"`     // 双语字幕配置
+	BurnInConfig        *BurnInConfig        `toml:"BurnInConfig"`        // 硬字幕配置
+	TranslationConfig   *TranslationConfig   `toml:"TranslationConfig"`   // 翻译目标语言配置
 }
 
 // BilibiliConfig Bilibili上传配置
@@ -176,10 +196,393 @@ type AnalyticsConfig struct {
 type WhisperConfig struct {
 	Enabled   bool   `toml:"enabled"`    // 是否启用 Whisper
 	ModelPath string `toml:"model_path"` // Whisper 模型文件路径
-	Language  string `toml:"language"`   // 识别语言 (en, zh, auto等)
+	Language  string `toml:"language"`   // 识别语言 (en, zh, auto等)，启用语言检测时使用检测结果
 	Threads   int    `toml:"threads"`    // 使用的线程数
 }
 
+// CaptionConfig 平台字幕获取配置（通过 yt-dlp 获取视频平台自带的字幕轨）
+type CaptionConfig struct {
+	Enabled         bool     `toml:"enabled"`           // 是否启用平台字幕获取
+	Languages       []string `toml:"languages"`         // 优先语言列表（按顺序匹配）
+	UseAutoCaptions bool     `toml:"use_auto_captions"` // 没有人工字幕时是否使用自动生成字幕
+	MinCues         int      `toml:"min_cues"`          // 可用字幕的最少条数，低于该值视为不可用
+}
+
+// YtDlpConfig yt-dlp 版本管理配置
+type YtDlpConfig struct {
+	Channel        string `toml:"channel"`         // 发布渠道: stable / nightly
+	Version        string `toml:"version"`         // 固定版本（发布 tag，如 2024.12.13），为空时跟随渠道最新版本
+	VerifyChecksum bool   `toml:"verify_checksum"` // 是否校验 SHA2-256SUMS
+	AutoUpdate     bool   `toml:"auto_update"`     // 是否定期自动更新
+	UpdateCron     string `toml:"update_cron"`     // 自动更新周期（cron 表达式，含秒）
+	ReleaseAPI     string `toml:"release_api"`     // GitHub API 地址（可配置为镜像）
+}
+
+// DuplicateConfig 重复视频检测配置（时长 + 抽样帧感知哈希 + 音频指纹）
+type DuplicateConfig struct {
+	Enabled           bool    `toml:"enabled"`            // 是否启用重复检测
+	Threshold         float64 `toml:"threshold"`          // 综合相似度阈值（0-1），达到后暂停上传
+	FrameSamples      int     `toml:"frame_samples"`      // 抽样帧数
+	AudioSeconds      int     `toml:"audio_seconds"`      // 参与音频指纹计算的最大时长（秒）
+	DurationTolerance float64 `toml:"duration_tolerance"` // 候选视频的时长容差（秒）
+}
+
+// LiveConfig 直播/首播视频处理配置
+type LiveConfig struct {
+	LiveMode     string `toml:"live_mode"`     // 正在直播的视频: wait=直播结束后再下载, skip=直接跳过
+	PollInterval int    `toml:"poll_interval"` // 等待期间的检查间隔（分钟）
+	StartDelay   int    `toml:"start_delay"`   // 预定开始时间过后再等待多久检查（分钟）
+}
+
+// SplitConfig 长视频分P配置（按章节或最大时长切分为B站多P投稿）
+type SplitConfig struct {
+	Enabled         bool   `toml:"enabled"`           // 是否启用分P
+	Mode            string `toml:"mode"`              // 切分方式: auto=有章节按章节否则按时长, chapters=仅按章节, duration=仅按时长
+	MaxPartMinutes  int    `toml:"max_part_minutes"`  // 每P最大时长（分钟），0 表示不限制
+	MinPartSeconds  int    `toml:"min_part_seconds"`  // 短于该时长的章节与相邻章节合并（秒）
+	MinTotalMinutes int    `toml:"min_total_minutes"` // 视频总时长达到该值（分钟）才分P
+}
+
+// DownloadCheckConfig 下载文件完整性校验配置
+type DownloadCheckConfig struct {
+	Enabled           bool    `toml:"enabled"`            // 是否在下载后校验文件
+	DurationTolerance float64 `toml:"duration_tolerance"` // 与来源时长允许的误差（秒，至少为时长的 1%）
+	RequireAudio      bool    `toml:"require_audio"`      // 是否要求包含音频流
+	FullDecode        bool    `toml:"full_decode"`        // 是否完整解码一遍检查数据错误（较慢）
+}
+
+// MediaCheckConfig 投稿前媒体检查配置（B站投稿限制）
+type MediaCheckConfig struct {
+	Enabled            bool     `toml:"enabled"`              // 是否启用媒体检查
+	MaxSizeMB          int64    `toml:"max_size_mb"`          // 文件大小上限（MB）
+	MaxDurationMinutes int      `toml:"max_duration_minutes"` // 时长上限（分钟）
+	MaxWidth           int      `toml:"max_width"`            // 宽度上限
+	MaxHeight          int      `toml:"max_height"`           // 高度上限
+	MaxFPS             float64  `toml:"max_fps"`              // 帧率上限
+	Containers         []string `toml:"containers"`           // 允许的容器（文件扩展名）
+	VideoCodecs        []string `toml:"video_codecs"`         // 允许的视频编码（ffprobe codec_name）
+	AudioCodecs        []string `toml:"audio_codecs"`         // 允许的音频编码（ffprobe codec_name）
+	AutoTranscode      bool     `toml:"auto_transcode"`       // 检查不通过时是否自动转码
+}
+
+// TranscodeProfile 转码配置档
+type TranscodeProfile struct {
+	KeepOriginal bool    `toml:"keep_original"` // 保持原始文件，不转码
+	Preset       string  `toml:"preset"`        // x264 编码预设（ultrafast ~ veryslow）
+	CRF          int     `toml:"crf"`           // 质量参数（越小质量越高）
+	AudioBitrate string  `toml:"audio_bitrate"` // 音频码率，如 "192k"
+	FPS          float64 `toml:"fps"`           // 帧率上限，0 表示保持原帧率
+	MaxWidth     int     `toml:"max_width"`     // 最大宽度，0 表示不缩放
+	MaxHeight    int     `toml:"max_height"`    // 最大高度，0 表示不缩放
+}
+
+// TranscodeConfig 转码步骤配置
+type TranscodeConfig struct {
+	Enabled  bool                         `toml:"enabled"`  // 是否启用转码步骤
+	Profile  string                       `toml:"profile"`  // 使用的配置档名称
+	Profiles map[string]*TranscodeProfile `toml:"profiles"` // 自定义配置档（同名时覆盖内置配置档）
+}
+
+// DefaultTranscodeProfiles 内置转码配置档
+func DefaultTranscodeProfiles() map[string]*TranscodeProfile {
+	return map[string]*TranscodeProfile{
+		"keep-original": {KeepOriginal: true},
+		"bilibili-1080p-h264": {
+			Preset:       "medium",
+			CRF:          20,
+			AudioBitrate: "192k",
+			FPS:          60,
+			MaxWidth:     1920,
+			MaxHeight:    1080,
+		},
+		"720p-small": {
+			Preset:       "slow",
+			CRF:          26,
+			AudioBitrate: "128k",
+			FPS:          30,
+			MaxWidth:     1280,
+			MaxHeight:    720,
+		},
+	}
+}
+
+// GetProfile 按名称查找配置档，优先使用配置文件中的自定义配置档
+func (c *TranscodeConfig) GetProfile(name string) (*TranscodeProfile, bool) {
+	if profile, ok := c.Profiles[name]; ok && profile != nil {
+		return profile, true
+	}
+	profile, ok := DefaultTranscodeProfiles()[name]
+	return profile, ok
+}
+
+// BrandingProfile 片头片尾与水印配置
+type BrandingProfile struct {
+	Intro          string  `toml:"intro"`           // 片头视频路径，为空时不拼接
+	Outro          string  `toml:"outro"`           // 片尾视频路径，为空时不拼接
+	Watermark      string  `toml:"watermark"`       // 水印 PNG 路径，为空时不叠加
+	Position       string  `toml:"position"`        // 水印位置: top-left / top-right / bottom-left / bottom-right
+	Opacity        float64 `toml:"opacity"`         // 水印不透明度（0-1）
+	Margin         int     `toml:"margin"`          // 水印与边缘的距离（像素）
+	WatermarkScale float64 `toml:"watermark_scale"` // 水印宽度占画面宽度的比例
+	Width          int     `toml:"width"`           // 输出宽度，0 表示与正片一致
+	Height         int     `toml:"height"`          // 输出高度，0 表示与正片一致
+	FPS            float64 `toml:"fps"`             // 输出帧率，0 表示与正片一致
+}
+
+// BrandingConfig 片头片尾与水印后期处理配置
+type BrandingConfig struct {
+	Enabled  bool                        `toml:"enabled"`  // 是否启用
+	Default  *BrandingProfile            `toml:"default"`  // 默认配置
+	Channels map[string]*BrandingProfile `toml:"channels"` // 按来源频道覆盖（key 为频道ID、频道名或上传者）
+}
+
+// ProfileFor 按来源频道查找配置，没有匹配时返回默认配置
+func (c *BrandingConfig) ProfileFor(keys ...string) *BrandingProfile {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if profile, ok := c.Channels[key]; ok && profile != nil {
+			return profile
+		}
+	}
+	return c.Default
+}
+
+// LoudnormConfig EBU R128 响度标准化配置
+type LoudnormConfig struct {
+	Enabled      bool    `toml:"enabled"`       // 是否启用
+	TargetI      float64 `toml:"target_i"`      // 目标综合响度（LUFS）
+	TruePeak     float64 `toml:"true_peak"`     // 真峰值上限（dBTP）
+	LRA          float64 `toml:"lra"`           // 目标响度范围（LU）
+	Tolerance    float64 `toml:"tolerance"`     // 与目标相差不超过该值（LU）时不处理
+	AudioBitrate string  `toml:"audio_bitrate"` // 标准化后的音频码率
+}
+
+// CoverConfig 自动生成封面配置
+type CoverConfig struct {
+	Enabled        bool     `toml:"enabled"`         // 是否启用
+	AlwaysGenerate bool     `toml:"always_generate"` // 始终生成封面（否则仅在没有平台封面时生成）
+	PreferChannels []string `toml:"prefer_channels"` // 优先使用生成封面的来源频道（频道ID、频道名或上传者）
+	Samples        int      `toml:"samples"`         // 候选帧数量
+	Width          int      `toml:"width"`           // 封面宽度（B站封面比例 16:10）
+	Height         int      `toml:"height"`          // 封面高度
+	TitleTemplate  string   `toml:"title_template"`  // 封面文字模板，支持变量: {ai_title}, {original_title}, {channel}, {uploader}，为空时不绘制文字
+	FontFile       string   `toml:"font_file"`       // 字体文件路径（需支持中文），为空时自动查找系统字体
+	FontSize       int      `toml:"font_size"`       // 字号
+	FontColor      string   `toml:"font_color"`      // 文字颜色
+	BorderColor    string   `toml:"border_color"`    // 描边颜色
+	BorderWidth    int      `toml:"border_width"`    // 描边宽度
+	BandColor      string   `toml:"band_color"`      // 文字背景条颜色，为空时不绘制
+	Position       string   `toml:"position"`        // 文字位置: top / center / bottom
+	MaxLines       int      `toml:"max_lines"`       // 最多行数
+	MaxLineChars   int      `toml:"max_line_chars"`  // 每行最多中文字数（英文按半个字计算）
+}
+
+// PrefersGenerated 来源频道是否优先使用生成的封面
+func (c *CoverConfig) PrefersGenerated(keys ...string) bool {
+	if c.AlwaysGenerate {
+		return true
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		for _, channel := range c.PreferChannels {
+			if channel == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PreviewConfig HLS 预览配置
+type PreviewConfig struct {
+	Enabled        bool   `toml:"enabled"`         // 是否启用
+	MaxHeight      int    `toml:"max_height"`      // 预览最大高度
+	VideoBitrate   string `toml:"video_bitrate"`   // 视频码率
+	AudioBitrate   string `toml:"audio_bitrate"`   // 音频码率
+	SegmentSeconds int    `toml:"segment_seconds"` // 切片时长（秒）
+	AccessToken    string `toml:"access_token"`    // 预览访问令牌，为空时禁止访问预览
+}
+
+// StoryboardConfig 故事板与缩略图总览配置
+type StoryboardConfig struct {
+	Enabled        bool    `toml:"enabled"`          // 是否启用
+	Interval       float64 `toml:"interval"`         // 雪碧图缩略图间隔（秒）
+	TileWidth      int     `toml:"tile_width"`       // 雪碧图缩略图宽度
+	Columns        int     `toml:"columns"`          // 雪碧图每行缩略图数
+	MaxTiles       int     `toml:"max_tiles"`        // 雪碧图最多缩略图数，超过时加大间隔
+	SheetInterval  float64 `toml:"sheet_interval"`   // 缩略图总览间隔（秒）
+	SheetTileWidth int     `toml:"sheet_tile_width"` // 缩略图总览中每张图的宽度
+	SheetColumns   int     `toml:"sheet_columns"`    // 缩略图总览每行图片数
+	MaxSheetTiles  int     `toml:"max_sheet_tiles"`  // 缩略图总览最多图片数，超过时加大间隔
+	FontFile       string  `toml:"font_file"`        // 时间戳字体，为空时自动查找系统字体
+}
+
+// AsrServerConfig 语音识别 HTTP 服务配置
+type AsrServerConfig struct {
+	Endpoint string `toml:"endpoint"` // 服务地址（如 http://127.0.0.1:8080）
+	ApiKey   string `toml:"api_key"`  // API 密钥，为空时不发送 Authorization
+	Model    string `toml:"model"`    // 模型名称（OpenAI 兼容接口必填，whisper.cpp server 忽略）
+	Timeout  int    `toml:"timeout"`  // 请求超时（秒）
+}
+
+// AsrConfig 语音识别配置
+type AsrConfig struct {
+	Enabled           bool              `toml:"enabled"`            // 是否启用语音识别（WhisperConfig.enabled 为 true 时同样启用）
+	DefaultProvider   string            `toml:"default_provider"`   // 默认提供商: whisper_cpp / whisper_server / openai
+	FallbackProviders []string          `toml:"fallback_providers"` // 备选提供商（按顺序尝试）
+	Language          string            `toml:"language"`           // 识别语言（强制指定），为空时使用检测到的原视频语言或 WhisperConfig.language
+	Prompt            string            `toml:"prompt"`             // 提示词（专有名词、人名等）
+	WhisperServer     *AsrServerConfig  `toml:"whisper_server"`     // whisper.cpp server 配置
+	OpenAI            *AsrServerConfig  `toml:"openai"`             // OpenAI 兼容 /v1/audio/transcriptions 接口配置
+	Chunking          *AsrChunkConfig   `toml:"chunking"`           // 长音频分段并行识别配置
+	Channels          map[string]string `toml:"channels"`           // 按来源频道选择提供商（key 为频道ID、频道名或上传者）
+}
+
+// AsrChunkConfig 长音频分段识别配置（按静音切分，分段并行识别，每段完成后写入断点）
+type AsrChunkConfig struct {
+	Enabled         bool `toml:"enabled"`           // 是否启用
+	MinAudioSeconds int  `toml:"min_audio_seconds"` // 音频超过该时长（秒）才分段
+	MaxChunkSeconds int  `toml:"max_chunk_seconds"` // 单段最长时长（秒）
+	MinSilenceMs    int  `toml:"min_silence_ms"`    // 切分点的最短静音（毫秒）
+	PaddingMs       int  `toml:"padding_ms"`        // 语音段前后保留的静音（毫秒）
+	Parallel        int  `toml:"parallel"`          // 并行识别的段数（whisper_cpp 共享同一模型，各段串行识别）
+	ThreadBudget    int  `toml:"thread_budget"`     // whisper.cpp 识别线程数，0 时使用 WhisperConfig.threads
+}
+
+// ChunkConfig 分段识别配置，未配置的字段使用默认值
+func (c *AsrConfig) ChunkConfig() AsrChunkConfig {
+	cfg := AsrChunkConfig{Enabled: true}
+	if c.Chunking != nil {
+		cfg = *c.Chunking
+	}
+	if cfg.MinAudioSeconds <= 0 {
+		cfg.MinAudioSeconds = 900
+	}
+	if cfg.MaxChunkSeconds <= 0 {
+		cfg.MaxChunkSeconds = 300
+	}
+	if cfg.MinSilenceMs <= 0 {
+		cfg.MinSilenceMs = 500
+	}
+	if cfg.PaddingMs <= 0 {
+		cfg.PaddingMs = 200
+	}
+	if cfg.Parallel <= 0 {
+		cfg.Parallel = 2
+	}
+	return cfg
+}
+
+// ProviderFor 按来源频道选择提供商，没有匹配时返回默认提供商
+func (c *AsrConfig) ProviderFor(keys ...string) string {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if provider, ok := c.Channels[key]; ok && provider != "" {
+			return provider
+		}
+	}
+	return c.DefaultProvider
+}
+
+// LanguageConfig 原视频语言检测配置
+type LanguageConfig struct {
+	Enabled      bool     `toml:"enabled"`       // 是否启用
+	Sources      []string `toml:"sources"`       // 检测来源（按顺序）: subtitle=插件字幕语言, metadata=yt-dlp 元数据, whisper=语音试听检测
+	ProbeSeconds int      `toml:"probe_seconds"` // 语音试听时长（秒）
+	ProbeStart   float64  `toml:"probe_start"`   // 试听起点占视频时长的比例（跳过片头音乐）
+	Default      string   `toml:"default"`       // 检测失败时使用的语言
+	SkipChinese  bool     `toml:"skip_chinese"`  // 原文字幕为中文时跳过翻译
+}
+
+// TranscriptionEnabled 是否启用语音识别（兼容只配置了 WhisperConfig.enabled 的旧配置）
+func (c *AppConfig) TranscriptionEnabled() bool {
+	return (c.AsrConfig != nil && c.AsrConfig.Enabled) || (c.WhisperConfig != nil && c.WhisperConfig.Enabled)
+}
+
+// SegmentLimits 单一文字类型的字幕可读性限制（0 表示不限制）
+type SegmentLimits struct {
+	MaxLineChars int     `toml:"max_line_chars"` // 每行最多字符数
+	MaxLines     int     `toml:"max_lines"`      // 最多行数
+	MinDuration  float64 `toml:"min_duration"`   // 最短显示时长（秒）
+	MaxDuration  float64 `toml:"max_duration"`   // 最长显示时长（秒）
+	MaxCPS       float64 `toml:"max_cps"`        // 每秒最多字符数（不计空格）
+}
+
+// SegmentConfig 字幕断句配置（按词级时间戳或字数重新切分、合并字幕）
+type SegmentConfig struct {
+	Enabled     bool           `toml:"enabled"`       // 是否启用
+	Source      bool           `toml:"source"`        // 翻译前整理原文字幕
+	Target      bool           `toml:"target"`        // 翻译后整理中文字幕
+	MaxMergeGap float64        `toml:"max_merge_gap"` // 相邻字幕间隔不超过该值（秒）时允许合并，0 不合并
+	CJK         *SegmentLimits `toml:"cjk"`           // 中日韩文字限制
+	Latin       *SegmentLimits `toml:"latin"`         // 拉丁文字等以空格分词的文字限制
+}
+
+// AlignConfig 字幕强制对齐配置（按语音识别的词级时间戳重新计算字幕时间）
+type AlignConfig struct {
+	Enabled       bool     `toml:"enabled"`        // 是否启用
+	Sources       []string `toml:"sources"`        // 需要对齐的字幕来源: extension / platform_manual / platform_auto
+	Provider      string   `toml:"provider"`       // 语音识别提供商，为空时使用默认提供商
+	MinSimilarity float64  `toml:"min_similarity"` // 词相似度阈值（0-1）
+	MaxDrift      float64  `toml:"max_drift"`      // 字幕带有时间时，与识别时间相差超过该值（秒）的词不匹配，0 不限制
+	MinConfidence float64  `toml:"min_confidence"` // 整体置信度低于该值时视为文本与音频不符，保留原时间
+}
+
+// BilingualConfig 双语字幕配置（译文与原文合并为一条字幕轨）
+type BilingualConfig struct {
+	Enabled        bool    `toml:"enabled"`         // 是否启用
+	SourceFirst    bool    `toml:"source_first"`    // true=原文在上，false=译文在上
+	Upload         bool    `toml:"upload"`          // 作为单独的字幕语言上传到 B站
+	UploadLang     string  `toml:"upload_lang"`     // 上传使用的 B站字幕语言代码（需与中文、原文字幕的语言不同）
+	StyleSecondary bool    `toml:"style_secondary"` // 生成 ASS 字幕时第二行使用单独样式
+	SecondaryScale float64 `toml:"secondary_scale"` // 第二行字号相对第一行的比例
+	SecondaryColor string  `toml:"secondary_color"` // 第二行文字颜色（#RRGGBB）
+}
+
+// BurnInConfig 硬字幕配置（将字幕烧录到画面中，烧录后的视频作为投稿文件）
+type BurnInConfig struct {
+	Enabled           bool    `toml:"enabled"`             // 是否启用
+	Track             string  `toml:"track"`               // 烧录的字幕轨: translated=中文译文 / bilingual=双语 / source=原文
+	FontsDir          string  `toml:"fonts_dir"`           // 字体目录，为空时使用自动查找到的中文字体所在目录
+	FontName          string  `toml:"font_name"`           // 字体名称（字体内部名称，如 Noto Sans CJK SC）
+	FontSize          int     `toml:"font_size"`           // 字号（按 1080p 计算，随分辨率缩放）
+	Bold              bool    `toml:"bold"`                // 粗体
+	PrimaryColor      string  `toml:"primary_color"`       // 文字颜色（#RRGGBB）
+	OutlineColor      string  `toml:"outline_color"`       // 描边颜色（#RRGGBB）
+	Outline           float64 `toml:"outline"`             // 描边宽度
+	Shadow            float64 `toml:"shadow"`              // 阴影距离
+	Position          string  `toml:"position"`            // 位置: bottom / top
+	MarginV           int     `toml:"margin_v"`            // 与画面边缘的垂直距离（按 1080p 计算）
+	Preset            string  `toml:"preset"`              // x264 编码预设
+	CRF               int     `toml:"crf"`                 // 质量参数
+	KeepSoftSubtitles bool    `toml:"keep_soft_subtitles"` // 烧录后仍上传 CC 字幕
+}
+
+// TranslationConfig 翻译目标语言配置
+type TranslationConfig struct {
+	TargetLangs []string            `toml:"target_langs"` // 目标语言（BCP-47），第一个为主语言；zh / zh-Hans 写入 zh.srt，其余写入 <lang>.srt
+	Channels    map[string][]string `toml:"channels"`     // 按来源频道覆盖（key 为频道ID、频道名或上传者）
+}
+
+// TargetsFor 按来源频道查找目标语言，没有匹配时返回默认配置
+func (c *TranslationConfig) TargetsFor(keys ...string) []string {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if langs, ok := c.Channels[key]; ok && len(langs) > 0 {
+			return langs
+		}
+	}
+	return c.TargetLangs
+}
+
 // NewDefaultConfig 创建默认配置
 func NewDefaultConfig() *AppConfig {
 	return &AppConfig{
@@ -272,9 +675,220 @@ func NewDefaultConfig() *AppConfig {
 		WhisperConfig: &WhisperConfig{
 			Enabled:   false,
 			ModelPath: "",
-			Language:  "en",
+			Language:  "auto",
 			Threads:   4,
 		},
+		// 平台字幕配置（默认值，可被 config.toml 覆盖）
+		CaptionConfig: &CaptionConfig{
+			Enabled:         true,
+			Languages:       []string{"en", "en-US", "en-GB"},
+			UseAutoCaptions: true,
+			MinCues:         5,
+		},
+		// yt-dlp 版本管理配置（默认值，可被 config.toml 覆盖）
+		YtDlpConfig: &YtDlpConfig{
+			Channel:        "stable",
+			Version:        "",
+			VerifyChecksum: true,
+			AutoUpdate:     true,
+			UpdateCron:     "0 0 4 * * *",
+			ReleaseAPI:     "https://api.github.com",
+		},
+		// 重复视频检测配置（默认值，可被 config.toml 覆盖）
+		DuplicateConfig: &DuplicateConfig{
+			Enabled:           true,
+			Threshold:         0.85,
+			FrameSamples:      16,
+			AudioSeconds:      600,
+			DurationTolerance: 3,
+		},
+		// 直播/首播视频处理配置（默认值，可被 config.toml 覆盖）
+		LiveConfig: &LiveConfig{
+			LiveMode:     "wait",
+			PollInterval: 30,
+			StartDelay:   10,
+		},
+		// 长视频分P配置（默认值，可被 config.toml 覆盖）
+		SplitConfig: &SplitConfig{
+			Enabled:         false,
+			Mode:            "auto",
+			MaxPartMinutes:  30,
+			MinPartSeconds:  60,
+			MinTotalMinutes: 20,
+		},
+		// 下载文件完整性校验配置（默认值，可被 config.toml 覆盖）
+		DownloadCheckConfig: &DownloadCheckConfig{
+			Enabled:           true,
+			DurationTolerance: 5,
+			RequireAudio:      true,
+			FullDecode:        false,
+		},
+		// 投稿前媒体检查配置（默认值，可被 config.toml 覆盖）
+		MediaCheckConfig: &MediaCheckConfig{
+			Enabled:            true,
+			MaxSizeMB:          8192,
+			MaxDurationMinutes: 600,
+			MaxWidth:           7680,
+			MaxHeight:          4320,
+			MaxFPS:             120,
+			Containers:         []string{"mp4", "flv", "mkv", "mov", "webm", "avi", "wmv", "ts", "m4v"},
+			VideoCodecs:        []string{"h264", "hevc", "av1", "vp9"},
+			AudioCodecs:        []string{"aac", "mp3", "opus", "flac", "ac3", "eac3"},
+			AutoTranscode:      false,
+		},
+		// 转码步骤配置（默认值，可被 config.toml 覆盖）
+		TranscodeConfig: &TranscodeConfig{
+			Enabled:  false,
+			Profile:  "bilibili-1080p-h264",
+			Profiles: DefaultTranscodeProfiles(),
+		},
+		// 片头片尾与水印后期处理配置（默认值，可被 config.toml 覆盖）
+		BrandingConfig: &BrandingConfig{
+			Enabled: false,
+			Default: &BrandingProfile{
+				Position:       "top-right",
+				Opacity:        0.8,
+				Margin:         20,
+				WatermarkScale: 0.12,
+			},
+		},
+		// EBU R128 响度标准化配置（默认值，可被 config.toml 覆盖）
+		LoudnormConfig: &LoudnormConfig{
+			Enabled:      false,
+			TargetI:      -14,
+			TruePeak:     -1.5,
+			LRA:          11,
+			Tolerance:    1,
+			AudioBitrate: "192k",
+		},
+		// 自动生成封面配置（默认值，可被 config.toml 覆盖）
+		CoverConfig: &CoverConfig{
+			Enabled:       false,
+			Samples:       12,
+			Width:         1280,
+			Height:        800,
+			TitleTemplate: "{ai_title}",
+			FontSize:      80,
+			FontColor:     "white",
+			BorderColor:   "black",
+			BorderWidth:   4,
+			BandColor:     "black@0.45",
+			Position:      "bottom",
+			MaxLines:      2,
+			MaxLineChars:  14,
+		},
+		// HLS 预览配置（默认值，可被 config.toml 覆盖）
+		PreviewConfig: &PreviewConfig{
+			Enabled:        false,
+			MaxHeight:      480,
+			VideoBitrate:   "600k",
+			AudioBitrate:   "96k",
+			SegmentSeconds: 6,
+		},
+		// 故事板与缩略图总览配置（默认值，可被 config.toml 覆盖）
+		StoryboardConfig: &StoryboardConfig{
+			Enabled:        false,
+			Interval:       10,
+			TileWidth:      160,
+			Columns:        10,
+			MaxTiles:       600,
+			SheetInterval:  60,
+			SheetTileWidth: 320,
+			SheetColumns:   4,
+			MaxSheetTiles:  48,
+		},
+		// 语音识别配置（默认值，可被 config.toml 覆盖）
+		AsrConfig: &AsrConfig{
+			Enabled:         false,
+			DefaultProvider: "whisper_cpp",
+			WhisperServer: &AsrServerConfig{
+				Endpoint: "http://127.0.0.1:8080",
+				Timeout:  1800,
+			},
+			OpenAI: &AsrServerConfig{
+				Endpoint: "http://127.0.0.1:8000",
+				Model:    "whisper-1",
+				Timeout:  1800,
+			},
+			Chunking: &AsrChunkConfig{
+				Enabled:         true,
+				MinAudioSeconds: 900,
+				MaxChunkSeconds: 300,
+				MinSilenceMs:    500,
+				PaddingMs:       200,
+				Parallel:        2,
+			},
+		},
+		// 原视频语言检测配置（默认值，可被 config.toml 覆盖）
+		LanguageConfig: &LanguageConfig{
+			Enabled:      true,
+			Sources:      []string{"subtitle", "metadata", "whisper"},
+			ProbeSeconds: 30,
+			ProbeStart:   0.2,
+			Default:      "en",
+			SkipChinese:  true,
+		},
+		// 字幕断句配置（默认值，可被 config.toml 覆盖）
+		SegmentConfig: &SegmentConfig{
+			Enabled:     true,
+			Source:      true,
+			Target:      true,
+			MaxMergeGap: 0.5,
+			CJK: &SegmentLimits{
+				MaxLineChars: 16,
+				MaxLines:     2,
+				MinDuration:  1,
+				MaxDuration:  7,
+				MaxCPS:       9,
+			},
+			Latin: &SegmentLimits{
+				MaxLineChars: 42,
+				MaxLines:     2,
+				MinDuration:  1,
+				MaxDuration:  7,
+				MaxCPS:       17,
+			},
+		},
+		// 字幕强制对齐配置（默认值，可被 config.toml 覆盖）
+		AlignConfig: &AlignConfig{
+			Enabled:       false,
+			Sources:       []string{"extension"},
+			MinSimilarity: 0.6,
+			MaxDrift:      30,
+			MinConfidence: 0.5,
+		},
+		// 双语字幕配置（默认值，可被 config.toml 覆盖）
+		BilingualConfig: &BilingualConfig{
+			Enabled:        false,
+			SourceFirst:    false,
+			Upload:         false,
+			UploadLang:     "",
+			StyleSecondary: true,
+			SecondaryScale: 0.75,
+			SecondaryColor: "#FFE082",
+		},
+		// 硬字幕配置（默认值，可被 config.toml 覆盖）
+		BurnInConfig: &BurnInConfig{
+			Enabled:           false,
+			Track:             "translated",
+			FontsDir:          "",
+			FontName:          "Noto Sans CJK SC",
+			FontSize:          56,
+			Bold:              false,
+			PrimaryColor:      "#FFFFFF",
+			OutlineColor:      "#000000",
+			Outline:           2.5,
+			Shadow:            0,
+			Position:          "bottom",
+			MarginV:           48,
+			Preset:            "medium",
+			CRF:               20,
+			KeepSoftSubtitles: false,
+		},
+		// 翻译目标语言配置（默认值，可被 config.toml 覆盖）
+		TranslationConfig: &TranslationConfig{
+			TargetLangs: []string{"zh"},
+		},
 	}
 }
 
@@ -307,6 +921,26 @@ func LoadConfig(configFile string) (*AppConfig, error) {
 		AnalyticsConfig     *AnalyticsConfig     `toml:"AnalyticsConfig"`
 		BilibiliConfig      *BilibiliConfig      `toml:"BilibiliConfig"`
 		WhisperConfig       *WhisperConfig       `toml:"WhisperConfig"`
+		CaptionConfig       *CaptionConfig       `toml:"CaptionConfig"`
+		YtDlpConfig         *YtDlpConfig         `toml:"YtDlpConfig"`
+		DuplicateConfig     *DuplicateConfig     `toml:"DuplicateConfig"`
+		LiveConfig          *LiveConfig          `toml:"LiveConfig"`
+		SplitConfig         *SplitConfig         `toml:"SplitConfig"`
+		DownloadCheckConfig *DownloadCheckConfig `toml:"DownloadCheckConfig"`
+		MediaCheckConfig    *MediaCheckConfig    `toml:"MediaCheckConfig"`
+		TranscodeConfig     *TranscodeConfig     `toml:"TranscodeConfig"`
+		BrandingConfig      *BrandingConfig      `toml:"BrandingConfig"`
+		LoudnormConfig      *LoudnormConfig      `toml:"LoudnormConfig"`
+		CoverConfig         *CoverConfig         `toml:"CoverConfig"`
+		PreviewConfig       *PreviewConfig       `toml:"PreviewConfig"`
+		StoryboardConfig    *StoryboardConfig    `toml:"StoryboardConfig"`
+		AsrConfig           *AsrConfig           `toml:"AsrConfig"`
+		LanguageConfig      *LanguageConfig      `toml:"LanguageConfig"`
+		SegmentConfig       *SegmentConfig       `toml:"SegmentConfig"`
+		AlignConfig         *AlignConfig         `toml:"AlignConfig"`
+		BilingualConfig     *BilingualConfig     `toml:"BilingualConfig"`
+		BurnInConfig        *BurnInConfig        `toml:"BurnInConfig"`
+		TranslationConfig   *TranslationConfig   `toml:"TranslationConfig"`
 	}
 
 	// 解码TOML配置文件
@@ -344,6 +978,66 @@ func LoadConfig(configFile string) (*AppConfig, error) {
 	if fileConfig.WhisperConfig != nil {
 		config.WhisperConfig = fileConfig.WhisperConfig
 	}
+	if fileConfig.CaptionConfig != nil {
+		config.CaptionConfig = fileConfig.CaptionConfig
+	}
+	if fileConfig.YtDlpConfig != nil {
+		config.YtDlpConfig = fileConfig.YtDlpConfig
+	}
+	if fileConfig.DuplicateConfig != nil {
+		config.DuplicateConfig = fileConfig.DuplicateConfig
+	}
+	if fileConfig.LiveConfig != nil {
+		config.LiveConfig = fileConfig.LiveConfig
+	}
+	if fileConfig.SplitConfig != nil {
+		config.SplitConfig = fileConfig.SplitConfig
+	}
+	if fileConfig.DownloadCheckConfig != nil {
+		config.DownloadCheckConfig = fileConfig.DownloadCheckConfig
+	}
+	if fileConfig.MediaCheckConfig != nil {
+		config.MediaCheckConfig = fileConfig.MediaCheckConfig
+	}
+	if fileConfig.TranscodeConfig != nil {
+		config.TranscodeConfig = fileConfig.TranscodeConfig
+	}
+	if fileConfig.BrandingConfig != nil {
+		config.BrandingConfig = fileConfig.BrandingConfig
+	}
+	if fileConfig.LoudnormConfig != nil {
+		config.LoudnormConfig = fileConfig.LoudnormConfig
+	}
+	if fileConfig.CoverConfig != nil {
+		config.CoverConfig = fileConfig.CoverConfig
+	}
+	if fileConfig.PreviewConfig != nil {
+		config.PreviewConfig = fileConfig.PreviewConfig
+	}
+	if fileConfig.StoryboardConfig != nil {
+		config.StoryboardConfig = fileConfig.StoryboardConfig
+	}
+	if fileConfig.AsrConfig != nil {
+		config.AsrConfig = fileConfig.AsrConfig
+	}
+	if fileConfig.LanguageConfig != nil {
+		config.LanguageConfig = fileConfig.LanguageConfig
+	}
+	if fileConfig.SegmentConfig != nil {
+		config.SegmentConfig = fileConfig.SegmentConfig
+	}
+	if fileConfig.AlignConfig != nil {
+		config.AlignConfig = fileConfig.AlignConfig
+	}
+	if fileConfig.BilingualConfig != nil {
+		config.BilingualConfig = fileConfig.BilingualConfig
+	}
+	if fileConfig.BurnInConfig != nil {
+		config.BurnInConfig = fileConfig.BurnInConfig
+	}
+	if fileConfig.TranslationConfig != nil {
+		config.TranslationConfig = fileConfig.TranslationConfig
+	}
 
 
 	return config, nil
@@ -367,6 +1061,26 @@ func SaveConfig(config *AppConfig) error {
 		AnalyticsConfig     *AnalyticsConfig     `toml:"AnalyticsConfig"`
 		BilibiliConfig      *BilibiliConfig      `toml:"BilibiliConfig"`
 		WhisperConfig       *WhisperConfig       `toml:"WhisperConfig"`
+		CaptionConfig       *CaptionConfig       `toml:"CaptionConfig"`
+		YtDlpConfig         *YtDlpConfig         `toml:"YtDlpConfig"`
+		DuplicateConfig     *DuplicateConfig     `toml:"DuplicateConfig"`
+		LiveConfig          *LiveConfig          `toml:"LiveConfig"`
+		SplitConfig         *SplitConfig         `toml:"SplitConfig"`
+		DownloadCheckConfig *DownloadCheckConfig `toml:"DownloadCheckConfig"`
+		MediaCheckConfig    *MediaCheckConfig    `toml:"MediaCheckConfig"`
+		TranscodeConfig     *TranscodeConfig     `toml:"TranscodeConfig"`
+		BrandingConfig      *BrandingConfig      `toml:"BrandingConfig"`
+		LoudnormConfig      *LoudnormConfig      `toml:"LoudnormConfig"`
+		CoverConfig         *CoverConfig         `toml:"CoverConfig"`
+		PreviewConfig       *PreviewConfig       `toml:"PreviewConfig"`
+		StoryboardConfig    *StoryboardConfig    `toml:"StoryboardConfig"`
+		AsrConfig           *AsrConfig           `toml:"AsrConfig"`
+		LanguageConfig      *LanguageConfig      `toml:"LanguageConfig"`
+		SegmentConfig       *SegmentConfig       `toml:"SegmentConfig"`
+		AlignConfig         *AlignConfig         `toml:"AlignConfig"`
+		BilingualConfig     *BilingualConfig     `toml:"BilingualConfig"`
+		BurnInConfig        *BurnInConfig        `toml:"BurnInConfig"`
+		TranslationConfig   *TranslationConfig   `toml:"TranslationConfig"`
 	}{
 		Listen:              config.Listen,
 		Environment:         config.Environment,
@@ -382,6 +1096,26 @@ func SaveConfig(config *AppConfig) error {
 		AnalyticsConfig:     config.AnalyticsConfig,
 		BilibiliConfig:      config.BilibiliConfig,
 		WhisperConfig:       config.WhisperConfig,
+		CaptionConfig:       config.CaptionConfig,
+		YtDlpConfig:         config.YtDlpConfig,
+		DuplicateConfig:     config.DuplicateConfig,
+		LiveConfig:          config.LiveConfig,
+		SplitConfig:         config.SplitConfig,
+		DownloadCheckConfig: config.DownloadCheckConfig,
+		MediaCheckConfig:    config.MediaCheckConfig,
+		TranscodeConfig:     config.TranscodeConfig,
+		BrandingConfig:      config.BrandingConfig,
+		LoudnormConfig:      config.LoudnormConfig,
+		CoverConfig:         config.CoverConfig,
+		PreviewConfig:       config.PreviewConfig,
+		StoryboardConfig:    config.StoryboardConfig,
+		AsrConfig:           config.AsrConfig,
+		LanguageConfig:      config.LanguageConfig,
+		SegmentConfig:       config.SegmentConfig,
+		AlignConfig:         config.AlignConfig,
+		BilingualConfig:     config.BilingualConfig,
+		BurnInConfig:        config.BurnInConfig,
+		TranslationConfig:   config.TranslationConfig,
 	}
 
 	buf := new(bytes.Buffer)
@@ -3,10 +3,11 @@ package handler
 import (
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/pkg/store/model"
-	"github.com/difyz9/ytb2bili/pkg/utils"
+	"github.com/difyz9/ytb2bili/pkg/source"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -33,6 +34,7 @@ type SaveVideoRequest struct {
 	PlaylistID    string                     `json:"playlistId"`
 	Timestamp     string                     `json:"timestamp"`
 	SavedAt       string                     `json:"savedAt"`
+	TargetLangs   []string                   `json:"targetLangs"` // 翻译目标语言（BCP-47），为空时按配置
 }
 
 func (h *SubtitleHandler) saveVideoSubtitles(c *gin.Context) {
@@ -47,7 +49,7 @@ func (h *SubtitleHandler) saveVideoSubtitles(c *gin.Context) {
 
 	fmt.Println("Received saveVideoSubtitles request for URL:", req.URL)
 	// 从 URL 中提取 videoId
-	videoID := utils.ExtractVideoID(req.URL)
+	videoID := source.ExtractVideoID(req.URL)
 	if videoID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -79,9 +81,9 @@ func (h *SubtitleHandler) saveVideoSubtitles(c *gin.Context) {
 		fmt.Printf("字幕数据: %s\n", subtitlesJSONStr)
 	}
 
-	// 检查是否已存在相同的 videoId（包括已删除的记录）
+	// 检查是否已存在相同的 videoId（包括已删除的记录和不带平台前缀的历史ID）
 	var existingVideo model.SavedVideo
-	err = h.App.DB.Unscoped().Where("video_id = ?", videoID).First(&existingVideo).Error
+	err = h.App.DB.Unscoped().Where("video_id IN ?", source.LookupIDs(videoID)).First(&existingVideo).Error
 
 	var savedVideo *model.SavedVideo
 	isExisting := false
@@ -97,6 +99,7 @@ func (h *SubtitleHandler) saveVideoSubtitles(c *gin.Context) {
 		existingVideo.PlaylistID = req.PlaylistID
 		existingVideo.Timestamp = req.Timestamp
 		existingVideo.SavedAt = req.SavedAt
+		existingVideo.TargetLangs = strings.Join(req.TargetLangs, ",")
 		existingVideo.Status = "001" // 重置状态为待处理
 		existingVideo.DeletedAt = gorm.DeletedAt{} // 恢复记录（清除删除标记）
 
@@ -127,6 +130,7 @@ func (h *SubtitleHandler) saveVideoSubtitles(c *gin.Context) {
 			PlaylistID:    req.PlaylistID,
 			Timestamp:     req.Timestamp,
 			SavedAt:       req.SavedAt,
+			TargetLangs:   strings.Join(req.TargetLangs, ","),
 		}
 
 		// 保存到数据库
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/difyz9/ytb2bili/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+// SystemHandler 系统维护相关接口（yt-dlp 版本管理等）
+type SystemHandler struct {
+	BaseHandler
+	Task *cron.Cron
+
+	mu           sync.Mutex
+	updating     bool
+	lastUpdateAt time.Time
+	lastError    string
+}
+
+func NewSystemHandler(app *core.AppServer, task *cron.Cron) *SystemHandler {
+	return &SystemHandler{
+		BaseHandler: BaseHandler{App: app},
+		Task:        task,
+	}
+}
+
+// SetUp 注册 yt-dlp 定时更新任务
+func (h *SystemHandler) SetUp() {
+	cfg := h.App.Config.YtDlpConfig
+	if cfg == nil || !cfg.AutoUpdate || cfg.UpdateCron == "" {
+		return
+	}
+
+	_, err := h.Task.AddFunc(cfg.UpdateCron, func() {
+		if err := h.runUpdate(false); err != nil {
+			h.App.Logger.Warnf("⚠️  yt-dlp 定时更新失败: %v", err)
+		}
+	})
+	if err != nil {
+		h.App.Logger.Errorf("❌ 注册 yt-dlp 定时更新任务失败: %v", err)
+		return
+	}
+
+	h.App.Logger.Infof("⏰ yt-dlp 定时更新已启用: %s", cfg.UpdateCron)
+	h.Task.Start()
+}
+
+// RegisterRoutes 注册系统相关路由
+func (h *SystemHandler) RegisterRoutes(server *core.AppServer) {
+	api := server.Engine.Group("/api/v1")
+
+	system := api.Group("/system")
+	{
+		system.GET("/ytdlp", h.getYtDlpStatus)
+		system.POST("/ytdlp", h.updateYtDlp)
+	}
+}
+
+// YtDlpStatusResponse yt-dlp 状态响应
+type YtDlpStatusResponse struct {
+	Installed      bool   `json:"installed"`
+	BinaryPath     string `json:"binary_path"`
+	Version        string `json:"version"`
+	LatestVersion  string `json:"latest_version,omitempty"` // 仅在 check_latest=true 时返回
+	Channel        string `json:"channel"`
+	PinnedVersion  string `json:"pinned_version"`
+	VerifyChecksum bool   `json:"verify_checksum"`
+	AutoUpdate     bool   `json:"auto_update"`
+	UpdateCron     string `json:"update_cron"`
+	HasBackup      bool   `json:"has_backup"` // 是否有可回滚的上一版本
+	Updating       bool   `json:"updating"`
+	LastUpdateAt   string `json:"last_update_at,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+// YtDlpActionRequest yt-dlp 操作请求
+type YtDlpActionRequest struct {
+	Action string `json:"action"` // update（默认）/ rollback
+	Force  bool   `json:"force"`  // 版本相同时是否仍然重新安装
+}
+
+// getYtDlpStatus 获取 yt-dlp 版本状态
+func (h *SystemHandler) getYtDlpStatus(c *gin.Context) {
+	manager := utils.NewConfiguredYtDlpManager(h.App.Logger, h.App.Config)
+	cfg := h.App.Config.YtDlpConfig
+	if cfg == nil {
+		cfg = &types.YtDlpConfig{}
+	}
+
+	resp := YtDlpStatusResponse{
+		Installed:      manager.IsInstalled(),
+		Channel:        cfg.Channel,
+		PinnedVersion:  cfg.Version,
+		VerifyChecksum: cfg.VerifyChecksum,
+		AutoUpdate:     cfg.AutoUpdate,
+		UpdateCron:     cfg.UpdateCron,
+	}
+	if resp.Installed {
+		resp.BinaryPath = manager.GetBinaryPath()
+		resp.Version, _ = manager.GetVersion()
+	}
+	if _, err := os.Stat(manager.GetBackupPath()); err == nil {
+		resp.HasBackup = true
+	}
+
+	if c.Query("check_latest") == "true" {
+		latest, err := manager.GetLatestVersion()
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"code":    502,
+				"message": "Failed to fetch latest version: " + err.Error(),
+			})
+			return
+		}
+		resp.LatestVersion = latest
+	}
+
+	h.mu.Lock()
+	resp.Updating = h.updating
+	if !h.lastUpdateAt.IsZero() {
+		resp.LastUpdateAt = h.lastUpdateAt.Format(time.RFC3339)
+	}
+	resp.LastError = h.lastError
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+// updateYtDlp 触发 yt-dlp 更新或回滚
+func (h *SystemHandler) updateYtDlp(c *gin.Context) {
+	var req YtDlpActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	switch req.Action {
+	case "", "update":
+		h.mu.Lock()
+		if h.updating {
+			h.mu.Unlock()
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    409,
+				"message": "yt-dlp update is already in progress",
+			})
+			return
+		}
+		h.mu.Unlock()
+
+		// 下载可能耗时较长，异步执行，通过 GET 查询结果
+		go func() {
+			if err := h.runUpdate(req.Force); err != nil {
+				h.App.Logger.Errorf("❌ yt-dlp 更新失败: %v", err)
+			}
+		}()
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"code":    202,
+			"message": "yt-dlp update started",
+		})
+
+	case "rollback":
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.updating {
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    409,
+				"message": "yt-dlp update is in progress",
+			})
+			return
+		}
+
+		manager := utils.NewConfiguredYtDlpManager(h.App.Logger, h.App.Config)
+		if err := manager.Rollback(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "Rollback failed: " + err.Error(),
+			})
+			return
+		}
+
+		version, _ := manager.GetVersion()
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "success",
+			"data": gin.H{
+				"version": version,
+			},
+		})
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Unknown action: " + req.Action,
+		})
+	}
+}
+
+// runUpdate 执行更新，当前版本与目标版本一致且未强制时跳过
+func (h *SystemHandler) runUpdate(force bool) error {
+	h.mu.Lock()
+	if h.updating {
+		h.mu.Unlock()
+		return nil
+	}
+	h.updating = true
+	h.mu.Unlock()
+
+	var err error
+	defer func() {
+		h.mu.Lock()
+		h.updating = false
+		h.lastUpdateAt = time.Now()
+		h.lastError = ""
+		if err != nil {
+			h.lastError = err.Error()
+		}
+		h.mu.Unlock()
+	}()
+
+	manager := utils.NewConfiguredYtDlpManager(h.App.Logger, h.App.Config)
+
+	// 只比较本程序管理的安装（Update 替换的文件），而不是 PATH 中找到的 yt-dlp
+	if current, versionErr := manager.GetInstalledVersion(); !force && versionErr == nil {
+		var latest string
+		latest, err = manager.GetLatestVersion()
+		if err != nil {
+			return err
+		}
+		if current == latest {
+			h.App.Logger.Infof("✅ yt-dlp 已是目标版本: %s", current)
+			return nil
+		}
+	}
+
+	err = manager.Update()
+	return err
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/gin-gonic/gin"
+)
+
+// DuplicateInfo 重复检测信息
+type DuplicateInfo struct {
+	Fingerprint *model.VideoFingerprint `json:"fingerprint"`
+	Held        bool                    `json:"held"`              // 是否因疑似重复暂停上传
+	Matched     *VideoInfo              `json:"matched,omitempty"` // 疑似重复的视频（可能已上传到 Bilibili）
+}
+
+// DuplicateOverrideRequest 人工处理疑似重复请求
+type DuplicateOverrideRequest struct {
+	Action string `json:"action" binding:"required,oneof=release reject"` // release=放行上传, reject=确认重复不上传
+}
+
+// findSavedVideo 按数字ID或 video_id 查询视频
+func (h *VideoHandler) findSavedVideo(idStr string) (*model.SavedVideo, error) {
+	if id, parseErr := strconv.ParseUint(idStr, 10, 32); parseErr == nil {
+		return h.SavedVideoService.GetByID(uint(id))
+	}
+	return h.SavedVideoService.GetVideoByVideoID(idStr)
+}
+
+// getDuplicateInfo 获取视频的重复检测结果
+func (h *VideoHandler) getDuplicateInfo(c *gin.Context) {
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return
+	}
+
+	fp, err := h.SavedVideoService.GetFingerprint(savedVideo.VideoID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "该视频尚未完成重复检测",
+		})
+		return
+	}
+
+	info := DuplicateInfo{
+		Fingerprint: fp,
+		Held:        savedVideo.Status == "210",
+	}
+	if fp.DuplicateOf != "" {
+		if matched, err := h.SavedVideoService.GetVideoByVideoID(fp.DuplicateOf); err == nil {
+			info.Matched = &VideoInfo{
+				ID:       matched.ID,
+				VideoID:  matched.VideoID,
+				Title:    matched.Title,
+				URL:      matched.URL,
+				Status:   matched.Status,
+				BiliBVID: matched.BiliBVID,
+				BiliAID:  matched.BiliAID,
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data:    info,
+	})
+}
+
+// overrideDuplicate 人工处理疑似重复视频：放行上传或确认重复
+func (h *VideoHandler) overrideDuplicate(c *gin.Context) {
+	var req DuplicateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return
+	}
+
+	if _, err := h.SavedVideoService.GetFingerprint(savedVideo.VideoID); err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "该视频尚未完成重复检测",
+		})
+		return
+	}
+
+	// 状态流转: 210(疑似重复) → 200(准备就绪) 或 298(确认重复，不上传)
+	status := savedVideo.Status
+	release := req.Action == "release"
+	if release && savedVideo.Status == "210" {
+		status = "200"
+	} else if !release {
+		if savedVideo.Status != "210" && savedVideo.Status != "200" {
+			c.JSON(http.StatusBadRequest, VideoListResponse{
+				Code:    400,
+				Message: fmt.Sprintf("当前状态 %s 不允许标记为重复", savedVideo.Status),
+			})
+			return
+		}
+		status = "298"
+	}
+
+	if err := h.SavedVideoService.ReviewDuplicate(savedVideo.VideoID, release); err != nil {
+		h.App.Logger.Errorf("保存查重处理结果失败: %v", err)
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "保存处理结果失败",
+		})
+		return
+	}
+	if status != savedVideo.Status {
+		if err := h.SavedVideoService.UpdateStatus(savedVideo.ID, status); err != nil {
+			h.App.Logger.Errorf("更新视频状态失败: %v", err)
+			c.JSON(http.StatusInternalServerError, VideoListResponse{
+				Code:    500,
+				Message: "更新视频状态失败",
+			})
+			return
+		}
+	}
+
+	// 放行后恢复上传步骤，确认重复时暂停上传步骤（避免被当作重试步骤执行）
+	if release && status == "200" {
+		if err := h.TaskStepService.ReleaseUploadStep(savedVideo.VideoID); err != nil {
+			h.App.Logger.Errorf("恢复上传步骤失败: %v", err)
+		}
+	} else if !release {
+		if err := h.TaskStepService.HoldUploadStep(savedVideo.VideoID); err != nil {
+			h.App.Logger.Errorf("暂停上传步骤失败: %v", err)
+		}
+	}
+
+	h.App.Logger.Infof("👤 疑似重复视频已人工处理: %s (%s)", savedVideo.VideoID, req.Action)
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"video_id": savedVideo.VideoID,
+			"action":   req.Action,
+			"status":   status,
+		},
+	})
+}
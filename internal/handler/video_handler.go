@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/difyz9/ytb2bili/internal/core"
 	"github.com/difyz9/ytb2bili/internal/core/services"
@@ -24,6 +25,8 @@ type VideoHandler struct {
 		ExecuteManualUpload(videoID, taskType string) error
 	}
 	AnalyticsHandler *AnalyticsHandler
+
+	subtitleLocks subtitleTrackLocks // 字幕轨保存锁
 }
 
 func NewVideoHandler(app *core.AppServer, savedVideoService *services.SavedVideoService, taskStepService *services.TaskStepService) *VideoHandler {
@@ -51,8 +54,19 @@ func (h *VideoHandler) RegisterRoutes(api *gin.RouterGroup) {
 		video.DELETE("/:id", h.deleteVideo)
 		video.POST("/:id/steps/:stepName/retry", h.retryTaskStep)
 		video.GET("/:id/files", h.getVideoFiles)
+		video.GET("/:id/alignment", h.getAlignment)
+		video.GET("/:id/translations", h.getTranslations)
+		video.GET("/:id/subtitles/:lang", h.getSubtitles)
+		video.PUT("/:id/subtitles/:lang", h.updateSubtitles)
+		video.GET("/:id/subtitles/:lang/history", h.getSubtitleHistory)
+		video.POST("/:id/subtitles/:lang/cues/:index/regenerate", h.regenerateSubtitleCue)
 		video.POST("/:id/upload/video", h.manualUploadVideo)
 		video.POST("/:id/upload/subtitle", h.manualUploadSubtitle)
+		video.GET("/:id/duplicate", h.getDuplicateInfo)
+		video.POST("/:id/duplicate/override", h.overrideDuplicate)
+		video.POST("/:id/preview/session", h.createPreviewSession)
+		video.GET("/:id/preview/*filepath", h.requirePreviewAuth(), h.getPreviewFile)
+		video.GET("/:id/storyboard/*filepath", h.requirePreviewAuth(), h.getStoryboardFile)
 	}
 }
 
@@ -83,12 +97,27 @@ type VideoInfo struct {
 	GeneratedTags  string                 `json:"generated_tags"`
 	BiliBVID       string                 `json:"bili_bvid"`
 	BiliAID        int64                  `json:"bili_aid"`
+	SubtitleSource string                 `json:"subtitle_source,omitempty"`
+	StatusReason   string                 `json:"status_reason,omitempty"`
+	WakeAt         string                 `json:"wake_at,omitempty"`
 	CreatedAt      string                 `json:"created_at"`
 	UpdatedAt      string                 `json:"updated_at"`
 	TaskSteps      []TaskStepInfo         `json:"task_steps,omitempty"`
 	Progress       map[string]interface{} `json:"progress,omitempty"`
 	CoverImage     string                 `json:"cover_image,omitempty"`
 	MetaData       map[string]interface{} `json:"meta_data,omitempty"`
+	SourceMeta     *model.VideoSourceMeta `json:"source_meta,omitempty"`
+	MediaInfo      *model.VideoMediaInfo  `json:"media_info,omitempty"`
+	MediaProblems  []model.MediaProblem   `json:"media_problems,omitempty"`
+	PreviewURL     string                 `json:"preview_url,omitempty"` // HLS 预览地址（需认证）
+}
+
+// formatWakeAt 格式化下次检查时间
+func formatWakeAt(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
 }
 
 // TaskStepInfo 任务步骤信息
@@ -133,6 +162,16 @@ func (h *VideoHandler) getVideoList(c *gin.Context) {
 		return
 	}
 
+	// 批量获取源视频元数据
+	videoIDs := make([]string, 0, len(savedVideos))
+	for _, sv := range savedVideos {
+		videoIDs = append(videoIDs, sv.VideoID)
+	}
+	sourceMetas, err := h.SavedVideoService.GetSourceMetaMap(videoIDs)
+	if err != nil {
+		h.App.Logger.Errorf("获取源视频元数据失败: %v", err)
+	}
+
 	// 转换为响应格式
 	var videos []VideoInfo
 	for _, sv := range savedVideos {
@@ -147,8 +186,12 @@ func (h *VideoHandler) getVideoList(c *gin.Context) {
 			GeneratedTags:  sv.GeneratedTags,
 			BiliBVID:       sv.BiliBVID,
 			BiliAID:        sv.BiliAID,
+			SubtitleSource: sv.SubtitleSource,
+			StatusReason:   sv.StatusReason,
+			WakeAt:         formatWakeAt(sv.WakeAt),
 			CreatedAt:      sv.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:      sv.UpdatedAt.Format("2006-01-02 15:04:05"),
+			SourceMeta:     sourceMetas[sv.VideoID],
 		})
 	}
 
@@ -229,6 +272,19 @@ func (h *VideoHandler) getVideoDetail(c *gin.Context) {
 	// 获取封面图片
 	coverImage := h.getVideoCoverImage(savedVideo.VideoID)
 
+	// 获取源视频元数据（可能不存在）
+	sourceMeta, _ := h.SavedVideoService.GetSourceMeta(savedVideo.VideoID)
+
+	// 获取媒体检查结果（可能不存在）
+	mediaInfo, _ := h.SavedVideoService.GetMediaInfo(savedVideo.VideoID)
+	var mediaProblems []model.MediaProblem
+	if mediaInfo != nil {
+		mediaProblems = mediaInfo.GetProblems()
+	}
+
+	// 获取预览地址（已生成预览时）
+	previewURL, _ := h.previewAssets(savedVideo)["preview"].(string)
+
 	videoInfo := VideoInfo{
 		ID:             savedVideo.ID,
 		VideoID:        savedVideo.VideoID,
@@ -240,12 +296,19 @@ func (h *VideoHandler) getVideoDetail(c *gin.Context) {
 		GeneratedTags:  savedVideo.GeneratedTags,
 		BiliBVID:       savedVideo.BiliBVID,
 		BiliAID:        savedVideo.BiliAID,
+		SubtitleSource: savedVideo.SubtitleSource,
+		StatusReason:   savedVideo.StatusReason,
+		WakeAt:         formatWakeAt(savedVideo.WakeAt),
 		CreatedAt:      savedVideo.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:      savedVideo.UpdatedAt.Format("2006-01-02 15:04:05"),
 		TaskSteps:      taskStepInfos,
 		Progress:       progress,
 		CoverImage:     coverImage,
 		MetaData:       metaData,
+		SourceMeta:     sourceMeta,
+		MediaInfo:      mediaInfo,
+		MediaProblems:  mediaProblems,
+		PreviewURL:     previewURL,
 	}
 
 	c.JSON(http.StatusOK, VideoListResponse{
@@ -427,6 +490,7 @@ func (h *VideoHandler) getVideoFiles(c *gin.Context) {
 			"video_id":  savedVideo.VideoID,
 			"directory": videoDir,
 			"files":     files,
+			"preview":   h.previewAssets(savedVideo), // HLS 预览与故事板地址（需认证）
 		},
 	})
 }
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/pkg/media"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/gin-gonic/gin"
+)
+
+// previewSessionCookie 预览会话 Cookie（播放器请求切片、字幕和故事板时自动携带）
+const previewSessionCookie = "ytb2bili_preview_session"
+
+// previewSessionTTL 预览会话有效期
+const previewSessionTTL = 12 * time.Hour
+
+// previewContentTypes 允许访问的预览文件类型
+var previewContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".vtt":  "text/vtt; charset=utf-8",
+	".jpg":  "image/jpeg",
+}
+
+// previewAccessToken 配置的预览访问令牌
+func (h *VideoHandler) previewAccessToken() string {
+	if cfg := h.App.Config.PreviewConfig; cfg != nil {
+		return cfg.AccessToken
+	}
+	return ""
+}
+
+// requirePreviewAuth 预览路由的认证中间件
+// 校验访问令牌（Authorization: Bearer、X-Access-Token 请求头或 token 查询参数）或预览会话 Cookie；
+// 未配置访问令牌时拒绝访问
+func (h *VideoHandler) requirePreviewAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := h.previewAccessToken()
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, VideoListResponse{
+				Code:    403,
+				Message: "未配置预览访问令牌（PreviewConfig.access_token），预览已禁用",
+			})
+			return
+		}
+
+		videoID := c.Param("id")
+		if previewTokenMatches(c.Query("token"), token) {
+			// 通过查询参数访问时签发会话，播放器后续按相对路径请求切片时无需再携带令牌
+			setPreviewSession(c, token, videoID)
+			c.Next()
+			return
+		}
+		cookie, _ := c.Cookie(previewSessionCookie)
+		if previewRequestToken(c, token) || validPreviewSession(cookie, token, videoID, time.Now()) {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, VideoListResponse{
+			Code:    401,
+			Message: "无效的访问令牌",
+		})
+	}
+}
+
+// createPreviewSession 使用请求头中的访问令牌签发预览会话 Cookie，供网页播放器使用
+func (h *VideoHandler) createPreviewSession(c *gin.Context) {
+	token := h.previewAccessToken()
+	if token == "" {
+		c.JSON(http.StatusForbidden, VideoListResponse{
+			Code:    403,
+			Message: "未配置预览访问令牌（PreviewConfig.access_token），预览已禁用",
+		})
+		return
+	}
+	if !previewRequestToken(c, token) {
+		c.JSON(http.StatusUnauthorized, VideoListResponse{
+			Code:    401,
+			Message: "无效的访问令牌",
+		})
+		return
+	}
+
+	expires := setPreviewSession(c, token, c.Param("id"))
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"expires_at": expires.Format(time.RFC3339),
+		},
+	})
+}
+
+// previewTokenMatches 常量时间比较访问令牌
+func previewTokenMatches(value, token string) bool {
+	return value != "" && subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1
+}
+
+// previewRequestToken 请求头中是否携带了正确的访问令牌
+func previewRequestToken(c *gin.Context, token string) bool {
+	return previewTokenMatches(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "), token) ||
+		previewTokenMatches(c.GetHeader("X-Access-Token"), token)
+}
+
+// setPreviewSession 签发只对该视频有效的预览会话 Cookie，返回过期时间
+func setPreviewSession(c *gin.Context, token, videoID string) time.Time {
+	expires := time.Now().Add(previewSessionTTL)
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(previewSessionCookie, signPreviewSession(token, videoID, expires), int(previewSessionTTL.Seconds()),
+		"/api/v1/videos/"+videoID+"/", "", c.Request.TLS != nil, true)
+	return expires
+}
+
+// signPreviewSession 预览会话值: <过期时间戳>.<HMAC-SHA256(令牌, 视频ID:过期时间戳)>
+// Cookie 中不保存访问令牌本身，修改令牌后已签发的会话全部失效
+func signPreviewSession(token, videoID string, expires time.Time) string {
+	ts := strconv.FormatInt(expires.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(videoID + ":" + ts))
+	return ts + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validPreviewSession 校验预览会话值的签名和有效期
+func validPreviewSession(value, token, videoID string, now time.Time) bool {
+	ts, _, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || now.Unix() >= unix {
+		return false
+	}
+	expected := signPreviewSession(token, videoID, time.Unix(unix, 0))
+	return hmac.Equal([]byte(value), []byte(expected))
+}
+
+// getPreviewFile 获取视频的 HLS 预览文件（master.m3u8、切片和 WebVTT 字幕）
+func (h *VideoHandler) getPreviewFile(c *gin.Context) {
+	h.serveTaskFile(c, "preview")
+}
+
+// getStoryboardFile 获取视频的故事板文件（sprite.jpg、thumbnails.vtt、contact_sheet.jpg）
+func (h *VideoHandler) getStoryboardFile(c *gin.Context) {
+	h.serveTaskFile(c, "storyboard")
+}
+
+// serveTaskFile 返回任务目录下 subdir 子目录中的文件
+func (h *VideoHandler) serveTaskFile(c *gin.Context, subdir string) {
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return
+	}
+
+	// 只允许访问子目录下的播放列表、切片、字幕和图片文件
+	name := filepath.Clean("/" + c.Param("filepath"))[1:]
+	contentType, ok := previewContentTypes[strings.ToLower(filepath.Ext(name))]
+	if name == "" || strings.Contains(name, "..") || !ok {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "无效的文件路径",
+		})
+		return
+	}
+
+	sm, err := h.taskState(savedVideo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "获取视频目录失败: " + err.Error(),
+		})
+		return
+	}
+
+	path := filepath.Join(sm.CurrentDir, subdir, name)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "文件尚未生成",
+		})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "no-cache")
+	c.File(path)
+}
+
+// previewAssets 已生成的预览与故事板访问地址
+func (h *VideoHandler) previewAssets(savedVideo *model.SavedVideo) gin.H {
+	assets := gin.H{}
+	sm, err := h.taskState(savedVideo)
+	if err != nil {
+		return assets
+	}
+
+	files := map[string]string{
+		"preview":       filepath.Join("preview", "master.m3u8"),
+		"thumbnails":    filepath.Join("storyboard", media.StoryboardThumbnails),
+		"sprite":        filepath.Join("storyboard", media.StoryboardSprite),
+		"contact_sheet": filepath.Join("storyboard", media.StoryboardContactSheet),
+	}
+	for key, file := range files {
+		if _, err := os.Stat(filepath.Join(sm.CurrentDir, file)); err == nil {
+			assets[key] = fmt.Sprintf("/api/v1/videos/%s/%s", savedVideo.VideoID, filepath.ToSlash(file))
+		}
+	}
+	return assets
+}
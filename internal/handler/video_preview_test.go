@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/core"
+	"github.com/difyz9/ytb2bili/internal/core/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newPreviewTestRouter 只挂载预览认证中间件的路由，认证通过时返回 200
+func newPreviewTestRouter(token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &VideoHandler{BaseHandler: BaseHandler{App: &core.AppServer{
+		Config: &types.AppConfig{PreviewConfig: &types.PreviewConfig{AccessToken: token}},
+		Logger: zap.NewNop().Sugar(),
+	}}}
+	r := gin.New()
+	r.GET("/api/v1/videos/:id/preview/*filepath", h.requirePreviewAuth(), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	r.POST("/api/v1/videos/:id/preview/session", h.createPreviewSession)
+	return r
+}
+
+func TestRequirePreviewAuth(t *testing.T) {
+	const token = "secret"
+	now := time.Now()
+	valid := signPreviewSession(token, "v1", now.Add(time.Hour))
+	tampered := valid[:len(valid)-1] + "0"
+	if strings.HasSuffix(valid, "0") {
+		tampered = valid[:len(valid)-1] + "1"
+	}
+
+	tests := []struct {
+		name       string
+		configured string // 配置的访问令牌
+		query      string
+		header     map[string]string
+		cookie     string
+		want       int
+		wantCookie bool // 是否签发预览会话
+	}{
+		{"未配置访问令牌", "", "token=secret", nil, "", http.StatusForbidden, false},
+		{"没有凭证", token, "", nil, "", http.StatusUnauthorized, false},
+		{"Authorization 请求头", token, "", map[string]string{"Authorization": "Bearer secret"}, "", http.StatusOK, false},
+		{"X-Access-Token 请求头", token, "", map[string]string{"X-Access-Token": "secret"}, "", http.StatusOK, false},
+		{"错误的请求头令牌", token, "", map[string]string{"Authorization": "Bearer wrong"}, "", http.StatusUnauthorized, false},
+		{"查询参数签发会话", token, "token=secret", nil, "", http.StatusOK, true},
+		{"错误的查询参数", token, "token=wrong", nil, "", http.StatusUnauthorized, false},
+		{"有效会话", token, "", nil, valid, http.StatusOK, false},
+		{"会话已过期", token, "", nil, signPreviewSession(token, "v1", now.Add(-time.Minute)), http.StatusUnauthorized, false},
+		{"其他视频的会话", token, "", nil, signPreviewSession(token, "v2", now.Add(time.Hour)), http.StatusUnauthorized, false},
+		{"签名被篡改", token, "", nil, tampered, http.StatusUnauthorized, false},
+		{"修改令牌后旧会话失效", "rotated", "", nil, valid, http.StatusUnauthorized, false},
+		{"会话格式错误", token, "", nil, "not-a-session", http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/api/v1/videos/v1/preview/master.m3u8"
+			if tt.query != "" {
+				target += "?" + tt.query
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: previewSessionCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			newPreviewTestRouter(tt.configured).ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			gotCookie := strings.Contains(w.Header().Get("Set-Cookie"), previewSessionCookie+"=")
+			if gotCookie != tt.wantCookie {
+				t.Errorf("Set-Cookie = %q, want session issued = %v", w.Header().Get("Set-Cookie"), tt.wantCookie)
+			}
+		})
+	}
+}
+
+func TestCreatePreviewSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		want       int
+	}{
+		{"未配置访问令牌", "", "Bearer secret", http.StatusForbidden},
+		{"没有请求头", "secret", "", http.StatusUnauthorized},
+		{"错误的令牌", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"签发会话", "secret", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/videos/v1/preview/session", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			newPreviewTestRouter(tt.configured).ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			// 签发的会话只对该视频有效，Cookie 中不包含访问令牌
+			var session *http.Cookie
+			for _, c := range w.Result().Cookies() {
+				if c.Name == previewSessionCookie {
+					session = c
+				}
+			}
+			if session == nil {
+				t.Fatal("preview session cookie not set")
+			}
+			if !session.HttpOnly || session.Path != "/api/v1/videos/v1/" || strings.Contains(session.Value, tt.configured) {
+				t.Errorf("cookie = %+v", session)
+			}
+			if !validPreviewSession(session.Value, tt.configured, "v1", time.Now()) {
+				t.Error("issued session is not valid")
+			}
+		})
+	}
+}
+
+func TestValidPreviewSession(t *testing.T) {
+	const token = "secret"
+	now := time.Unix(1700000000, 0)
+	expires := now.Add(previewSessionTTL)
+	valid := signPreviewSession(token, "v1", expires)
+
+	tests := []struct {
+		name  string
+		value string
+		token string
+		id    string
+		now   time.Time
+		want  bool
+	}{
+		{"有效", valid, token, "v1", now, true},
+		{"到期时刻失效", valid, token, "v1", expires, false},
+		{"其他视频", valid, token, "v2", now, false},
+		{"其他令牌", valid, "other", "v1", now, false},
+		{"篡改过期时间", "1900000000" + valid[strings.Index(valid, "."):], token, "v1", now, false},
+		{"空值", "", token, "v1", now, false},
+		{"过期时间不是数字", "abc.def", token, "v1", now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validPreviewSession(tt.value, tt.token, tt.id, tt.now); got != tt.want {
+				t.Errorf("validPreviewSession() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,705 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/chain_task/handlers"
+	"github.com/difyz9/ytb2bili/internal/chain_task/manager"
+	"github.com/difyz9/ytb2bili/internal/core/services"
+	"github.com/difyz9/ytb2bili/pkg/lang"
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getAlignment 获取字幕强制对齐结果（整体与每条字幕的置信度）
+func (h *VideoHandler) getAlignment(c *gin.Context) {
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return
+	}
+
+	sm, err := h.taskState(savedVideo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "获取视频目录失败: " + err.Error(),
+		})
+		return
+	}
+
+	data, err := os.ReadFile(sm.AlignmentJSON)
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "尚未进行字幕对齐",
+		})
+		return
+	}
+	var report map[string]interface{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "解析对齐结果失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data:    report,
+	})
+}
+
+// getTranslations 获取各目标语言的翻译进度与失败原因
+func (h *VideoHandler) getTranslations(c *gin.Context) {
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return
+	}
+
+	sm, err := h.taskState(savedVideo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "获取视频目录失败: " + err.Error(),
+		})
+		return
+	}
+
+	data, err := os.ReadFile(sm.TargetsJSON)
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "尚未进行翻译",
+		})
+		return
+	}
+	var statuses []handlers.TranslationStatus
+	if err := json.Unmarshal(data, &statuses); err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "解析翻译进度失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data:    statuses,
+	})
+}
+
+// subtitleTrackLocks 同一字幕轨的保存串行执行，保证写文件与记录版本的顺序一致（数据库版本号另做乐观锁校验）
+// 按引用计数管理，没有请求持有时删除，不会随视频数量无限增长
+type subtitleTrackLocks struct {
+	mu    sync.Mutex
+	locks map[string]*subtitleTrackLock
+}
+
+type subtitleTrackLock struct {
+	sync.Mutex
+	refs int
+}
+
+// SubtitleCueJSON 字幕编辑接口中的一条字幕（时间为毫秒）
+type SubtitleCueJSON struct {
+	Index int    `json:"index"`
+	Start int64  `json:"start_ms"`
+	End   int64  `json:"end_ms"`
+	Text  string `json:"text"`
+}
+
+// UpdateSubtitlesRequest 保存字幕请求
+type UpdateSubtitlesRequest struct {
+	Version  int               `json:"version"`  // 读取时的版本号
+	Checksum string            `json:"checksum"` // 读取时的文件哈希（可选，用于发现读取后任务链重新生成的字幕）
+	Cues     []SubtitleCueJSON `json:"cues" binding:"required"`
+}
+
+// RegenerateCueRequest 重新翻译单条字幕请求
+type RegenerateCueRequest struct {
+	Version  int    `json:"version"`  // 读取时的版本号
+	Checksum string `json:"checksum"` // 读取时的文件哈希（可选）
+}
+
+// subtitleTrack 待审核的字幕轨
+type subtitleTrack struct {
+	Video    *model.SavedVideo
+	State    *manager.StateManager
+	Lang     string
+	Path     string
+	Record   *model.SubtitleTrack // 版本记录（未编辑过时为新记录）
+	Version  int                  // 当前版本号（文件在编辑器之外被修改时为记录的版本号加一）
+	Checksum string               // 当前文件哈希
+	Content  []byte               // 当前文件内容（保存失败时用于恢复）
+	Cues     []subtitle.Cue
+}
+
+// getSubtitles 获取字幕轨（字幕列表与版本号）
+func (h *VideoHandler) getSubtitles(c *gin.Context) {
+	savedVideo, ok := h.findSubtitleVideo(c)
+	if !ok {
+		return
+	}
+	track, ok := h.loadSubtitleTrack(c, savedVideo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"video_id":  track.Video.VideoID,
+			"lang":      track.Lang,
+			"file":      filepath.Base(track.Path),
+			"version":   track.Version,
+			"checksum":  track.Checksum,
+			"edited_at": track.Record.EditedAt,
+			"cues":      subtitleCuesJSON(track.Cues),
+		},
+	})
+}
+
+// updateSubtitles 保存人工修改后的字幕，版本号与当前不一致时返回 409
+func (h *VideoHandler) updateSubtitles(c *gin.Context) {
+	var req UpdateSubtitlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	cues, err := parseSubtitleCues(req.Cues)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	savedVideo, ok := h.findSubtitleVideo(c)
+	if !ok {
+		return
+	}
+	unlock := h.subtitleLocks.lock(savedVideo.VideoID + "/" + subtitleTrackLang(c.Param("lang")))
+	defer unlock()
+
+	track, ok := h.loadSubtitleTrack(c, savedVideo)
+	if !ok || !h.checkSubtitleVersion(c, track, req.Version, req.Checksum) {
+		return
+	}
+
+	edits := diffSubtitleCues(track.Cues, cues)
+	if len(edits) == 0 {
+		c.JSON(http.StatusOK, VideoListResponse{
+			Code:    200,
+			Message: "字幕没有变化",
+			Data:    gin.H{"version": track.Version, "checksum": track.Checksum, "edits": 0},
+		})
+		return
+	}
+	if !h.saveSubtitleTrack(c, track, cues, edits) {
+		return
+	}
+
+	h.App.Logger.Infof("✏️  字幕已人工修改: %s %s v%d，%d 处修改", track.Video.VideoID, track.Lang, track.Version, len(edits))
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "字幕已保存",
+		Data:    gin.H{"version": track.Version, "checksum": track.Checksum, "edits": len(edits)},
+	})
+}
+
+// regenerateSubtitleCue 重新翻译单条译文字幕并保存为新版本
+func (h *VideoHandler) regenerateSubtitleCue(c *gin.Context) {
+	var req RegenerateCueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 1 {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "无效的字幕序号",
+		})
+		return
+	}
+
+	savedVideo, ok := h.findSubtitleVideo(c)
+	if !ok {
+		return
+	}
+	unlock := h.subtitleLocks.lock(savedVideo.VideoID + "/" + subtitleTrackLang(c.Param("lang")))
+	defer unlock()
+
+	track, ok := h.loadSubtitleTrack(c, savedVideo)
+	if !ok || !h.checkSubtitleVersion(c, track, req.Version, req.Checksum) {
+		return
+	}
+	if index > len(track.Cues) {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: fmt.Sprintf("字幕序号超出范围 (共 %d 条)", len(track.Cues)),
+		})
+		return
+	}
+
+	// 只有翻译得到的字幕可以重新生成
+	sourceLang := handlers.SourceLanguage(h.SavedVideoService, track.Video.VideoID)
+	if track.Path == track.State.TranslatedSRT(sourceLang) || (lang.IsChinese(track.Lang) && lang.IsChinese(sourceLang)) {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "只有翻译生成的字幕可以重新翻译",
+		})
+		return
+	}
+	source, err := subtitle.ReadSRT(track.State.SourceSRT)
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "读取原文字幕失败: " + err.Error(),
+		})
+		return
+	}
+
+	text, err := handlers.TranslateCue(h.App, h.SavedVideoService, track.State, source, track.Cues[index-1], track.Lang)
+	if err != nil {
+		h.App.Logger.Errorf("重新翻译字幕失败: %v", err)
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "重新翻译失败: " + err.Error(),
+		})
+		return
+	}
+
+	cues := append([]subtitle.Cue(nil), track.Cues...)
+	cues[index-1].Text = text
+	edits := diffSubtitleCues(track.Cues, cues)
+	for i := range edits {
+		edits[i].Action = "regenerate"
+	}
+	if len(edits) > 0 && !h.saveSubtitleTrack(c, track, cues, edits) {
+		return
+	}
+
+	h.App.Logger.Infof("🔁 已重新翻译字幕: %s %s #%d", track.Video.VideoID, track.Lang, index)
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"version":  track.Version,
+			"checksum": track.Checksum,
+			"cue":      subtitleCuesJSON(cues)[index-1],
+		},
+	})
+}
+
+// getSubtitleHistory 获取字幕修改记录，可用 ?cue=<序号> 只查询某一条
+func (h *VideoHandler) getSubtitleHistory(c *gin.Context) {
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return
+	}
+	code := subtitleTrackLang(c.Param("lang"))
+	cueIndex, _ := strconv.Atoi(c.Query("cue"))
+
+	edits, err := h.SavedVideoService.ListSubtitleEdits(savedVideo.VideoID, code, cueIndex)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "获取修改记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, VideoListResponse{
+		Code:    200,
+		Message: "success",
+		Data:    edits,
+	})
+}
+
+// findSubtitleVideo 按路径参数 id（数字 ID 或 video_id）查找视频，不存在时直接写入 404
+func (h *VideoHandler) findSubtitleVideo(c *gin.Context) (*model.SavedVideo, bool) {
+	savedVideo, err := h.findSavedVideo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "视频不存在",
+		})
+		return nil, false
+	}
+	return savedVideo, true
+}
+
+// loadSubtitleTrack 读取字幕文件与版本记录，失败时直接写入错误响应
+// 只读取不修改：文件内容与最近一次保存的哈希不一致（任务链重新生成过字幕）时版本号加一，保存时才写入新版本
+func (h *VideoHandler) loadSubtitleTrack(c *gin.Context, savedVideo *model.SavedVideo) (*subtitleTrack, bool) {
+	code := subtitleTrackLang(c.Param("lang"))
+	if code == "" || code == "auto" {
+		c.JSON(http.StatusBadRequest, VideoListResponse{
+			Code:    400,
+			Message: "无效的字幕语言: " + c.Param("lang"),
+		})
+		return nil, false
+	}
+
+	sm, err := h.taskState(savedVideo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "获取视频目录失败: " + err.Error(),
+		})
+		return nil, false
+	}
+	path := sm.TranslatedSRT(code)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.JSON(http.StatusNotFound, VideoListResponse{
+			Code:    404,
+			Message: "字幕文件不存在: " + filepath.Base(path),
+		})
+		return nil, false
+	}
+	cues, err := subtitle.ParseSRT(string(data))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "解析字幕失败: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	record, err := h.SavedVideoService.GetSubtitleTrack(savedVideo.VideoID, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "获取字幕版本失败: " + err.Error(),
+		})
+		return nil, false
+	}
+	if record == nil {
+		record = &model.SubtitleTrack{VideoID: savedVideo.VideoID, Lang: code}
+	}
+
+	checksum := subtitleChecksum(data)
+	version := record.Version
+	if record.Checksum != checksum {
+		// 文件在编辑器之外被修改，之后的保存必须基于当前内容
+		version++
+	}
+
+	return &subtitleTrack{
+		Video:    savedVideo,
+		State:    sm,
+		Lang:     code,
+		Path:     path,
+		Record:   record,
+		Version:  version,
+		Checksum: checksum,
+		Content:  data,
+		Cues:     cues,
+	}, true
+}
+
+// taskState 视频任务目录的状态管理器（文件路径与任务链一致）
+func (h *VideoHandler) taskState(savedVideo *model.SavedVideo) (*manager.StateManager, error) {
+	baseDir, err := filepath.Abs(h.App.Config.FileUpDir)
+	if err != nil {
+		return nil, err
+	}
+	return manager.NewStateManager(savedVideo.ID, savedVideo.VideoID, baseDir, savedVideo.CreatedAt), nil
+}
+
+// checkSubtitleVersion 校验请求的版本号与文件哈希（提供时），不一致时返回 409 及当前版本号
+func (h *VideoHandler) checkSubtitleVersion(c *gin.Context, track *subtitleTrack, version int, checksum string) bool {
+	if version == track.Version && (checksum == "" || checksum == track.Checksum) {
+		return true
+	}
+	c.JSON(http.StatusConflict, VideoListResponse{
+		Code:    409,
+		Message: fmt.Sprintf("字幕已被修改（当前版本 %d），请刷新后重试", track.Version),
+		Data:    gin.H{"version": track.Version, "checksum": track.Checksum},
+	})
+	return false
+}
+
+// saveSubtitleTrack 写入字幕文件并记录新版本，成功后 track.Version 为新版本号
+// 先替换文件再提交版本记录，提交失败时恢复原文件，保证记录的哈希与磁盘上的文件一致
+// 原文字幕同步到翻译输入文件，并重新生成双语字幕；重置预览步骤，已烧录硬字幕时同时重置烧录与媒体检查步骤
+func (h *VideoHandler) saveSubtitleTrack(c *gin.Context, track *subtitleTrack, cues []subtitle.Cue, edits []model.SubtitleEdit) bool {
+	content := []byte(subtitle.FormatSRT(cues))
+	if err := replaceSubtitleFile(track.Path, content); err != nil {
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "写入字幕失败: " + err.Error(),
+		})
+		return false
+	}
+
+	previous := track.Record.Version
+	version := track.Version + 1
+	track.Record.Version = version
+	track.Record.Checksum = subtitleChecksum(content)
+	track.Record.CueCount = len(cues)
+	track.Record.EditedAt = time.Now()
+	for i := range edits {
+		edits[i].VideoID = track.Video.VideoID
+		edits[i].Lang = track.Lang
+		edits[i].Version = version
+	}
+
+	if err := h.SavedVideoService.SaveSubtitleVersion(track.Record, previous, edits); err != nil {
+		if restoreErr := replaceSubtitleFile(track.Path, track.Content); restoreErr != nil {
+			h.App.Logger.Errorf("恢复字幕文件失败: %v", restoreErr)
+		}
+		if errors.Is(err, services.ErrSubtitleVersionConflict) {
+			c.JSON(http.StatusConflict, VideoListResponse{
+				Code:    409,
+				Message: "字幕已被修改，请刷新后重试",
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, VideoListResponse{
+			Code:    500,
+			Message: "保存字幕版本失败: " + err.Error(),
+		})
+		return false
+	}
+	track.Version = version
+	track.Checksum = track.Record.Checksum
+	track.Content = content
+	track.Cues = cues
+
+	sm := track.State
+	if track.Path == sm.TranslatedSRT(handlers.SourceLanguage(h.SavedVideoService, track.Video.VideoID)) {
+		if err := os.WriteFile(sm.SourceSRT, content, 0644); err != nil {
+			h.App.Logger.Warnf("⚠️  同步原文字幕失败: %v", err)
+		}
+	}
+	handlers.RefreshBilingualSubtitles(h.App, h.SavedVideoService, sm)
+	// 预览内嵌字幕轨，需要重新生成；已烧录硬字幕时投稿文件会被重新渲染，媒体检查也需重新执行
+	steps := []string{"生成预览"}
+	if _, err := os.Stat(sm.BurnedVideo); err == nil {
+		steps = []string{"烧录字幕", "媒体检查", "生成预览"}
+	}
+	for _, step := range steps {
+		if err := h.TaskStepService.UpdateTaskStepStatus(track.Video.VideoID, step, "pending"); err != nil {
+			h.App.Logger.Warnf("⚠️  重置%s步骤失败: %v", step, err)
+		}
+	}
+	return true
+}
+
+// subtitleTrackLang 规范化字幕语言代码，简体中文统一为 zh（与 zh.srt 对应）
+func subtitleTrackLang(code string) string {
+	code = lang.Normalize(code)
+	if code == "zh-Hans" {
+		return "zh"
+	}
+	return code
+}
+
+// lock 获取字幕轨的互斥锁，返回解锁函数
+func (l *subtitleTrackLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*subtitleTrackLock)
+	}
+	entry := l.locks[key]
+	if entry == nil {
+		entry = &subtitleTrackLock{}
+		l.locks[key] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+		l.mu.Lock()
+		if entry.refs--; entry.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// replaceSubtitleFile 先写临时文件再替换，避免中断时留下不完整的字幕
+func replaceSubtitleFile(path string, content []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// subtitleChecksum 字幕文件内容哈希
+func subtitleChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// subtitleCuesJSON 转换为接口格式（序号从 1 开始）
+func subtitleCuesJSON(cues []subtitle.Cue) []SubtitleCueJSON {
+	result := make([]SubtitleCueJSON, 0, len(cues))
+	for i, cue := range cues {
+		result = append(result, SubtitleCueJSON{
+			Index: i + 1,
+			Start: cue.Start.Milliseconds(),
+			End:   cue.End.Milliseconds(),
+			Text:  cue.Text,
+		})
+	}
+	return result
+}
+
+// parseSubtitleCues 校验并转换提交的字幕：时间有效、文本非空、按开始时间排序
+func parseSubtitleCues(items []SubtitleCueJSON) ([]subtitle.Cue, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("字幕不能为空")
+	}
+	cues := make([]subtitle.Cue, 0, len(items))
+	for i, item := range items {
+		text := strings.TrimSpace(strings.ReplaceAll(item.Text, "\r\n", "\n"))
+		switch {
+		case item.Start < 0 || item.End <= item.Start:
+			return nil, fmt.Errorf("第 %d 条字幕时间无效", i+1)
+		case text == "":
+			return nil, fmt.Errorf("第 %d 条字幕文本为空", i+1)
+		case i > 0 && item.Start < items[i-1].Start:
+			return nil, fmt.Errorf("第 %d 条字幕开始时间早于上一条", i+1)
+		}
+		cues = append(cues, subtitle.Cue{
+			Start: time.Duration(item.Start) * time.Millisecond,
+			End:   time.Duration(item.End) * time.Millisecond,
+			Text:  text,
+		})
+	}
+	return cues, nil
+}
+
+// diffSubtitleCues 比较修改前后的字幕，生成每条字幕的修改记录
+// 先按 (开始, 结束, 文本) 求最长公共子序列对齐两组字幕，未对齐的部分中相同位置的前后字幕记为 edit，
+// 多出的记为 delete / insert，插入或删除一条字幕不会把之后的字幕都记为修改
+// edit / insert 的序号为修改后的序号，delete 的序号为修改前的序号
+func diffSubtitleCues(before, after []subtitle.Cue) []model.SubtitleEdit {
+	// 去掉相同的开头与结尾，只对中间部分求公共子序列
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && sameSubtitleCue(before[prefix], after[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		sameSubtitleCue(before[len(before)-1-suffix], after[len(after)-1-suffix]) {
+		suffix++
+	}
+	oldCues, newCues := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	// lcs[i][j]: oldCues[i:] 与 newCues[j:] 的最长公共子序列长度
+	lcs := make([][]int32, len(oldCues)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(newCues)+1)
+	}
+	for i := len(oldCues) - 1; i >= 0; i-- {
+		for j := len(newCues) - 1; j >= 0; j-- {
+			if sameSubtitleCue(oldCues[i], newCues[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []model.SubtitleEdit
+	var removed, added []int // 当前未对齐区间中修改前、修改后字幕的下标（相对 before / after）
+	flush := func() {
+		paired := min(len(removed), len(added))
+		for k := 0; k < paired; k++ {
+			edit := model.SubtitleEdit{CueIndex: added[k] + 1, Action: "edit"}
+			setSubtitleEditOld(&edit, before[removed[k]])
+			setSubtitleEditNew(&edit, after[added[k]])
+			edits = append(edits, edit)
+		}
+		for _, i := range removed[paired:] {
+			edit := model.SubtitleEdit{CueIndex: i + 1, Action: "delete"}
+			setSubtitleEditOld(&edit, before[i])
+			edits = append(edits, edit)
+		}
+		for _, j := range added[paired:] {
+			edit := model.SubtitleEdit{CueIndex: j + 1, Action: "insert"}
+			setSubtitleEditNew(&edit, after[j])
+			edits = append(edits, edit)
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(oldCues) || j < len(newCues) {
+		switch {
+		case i < len(oldCues) && j < len(newCues) && sameSubtitleCue(oldCues[i], newCues[j]):
+			flush()
+			i++
+			j++
+		case j < len(newCues) && (i == len(oldCues) || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, prefix+j)
+			j++
+		default:
+			removed = append(removed, prefix+i)
+			i++
+		}
+	}
+	flush()
+	return edits
+}
+
+// sameSubtitleCue 两条字幕的时间与文本是否完全相同
+func sameSubtitleCue(a, b subtitle.Cue) bool {
+	return a.Start == b.Start && a.End == b.End && a.Text == b.Text
+}
+
+func setSubtitleEditOld(edit *model.SubtitleEdit, cue subtitle.Cue) {
+	edit.OldStart, edit.OldEnd, edit.OldText = cue.Start.Milliseconds(), cue.End.Milliseconds(), cue.Text
+}
+
+func setSubtitleEditNew(edit *model.SubtitleEdit, cue subtitle.Cue) {
+	edit.NewStart, edit.NewEnd, edit.NewText = cue.Start.Milliseconds(), cue.End.Milliseconds(), cue.Text
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+func TestDiffSubtitleCues(t *testing.T) {
+	cue := func(start, end int, text string) subtitle.Cue {
+		return subtitle.Cue{Start: time.Duration(start) * time.Second, End: time.Duration(end) * time.Second, Text: text}
+	}
+	base := []subtitle.Cue{cue(0, 2, "一"), cue(2, 4, "二"), cue(4, 6, "三"), cue(6, 8, "四")}
+
+	type change struct {
+		action  string
+		index   int
+		oldText string
+		newText string
+	}
+	tests := []struct {
+		name  string
+		after []subtitle.Cue
+		want  []change
+	}{
+		{"没有变化", base, nil},
+		{
+			"修改文本",
+			[]subtitle.Cue{cue(0, 2, "一"), cue(2, 4, "二！"), cue(4, 6, "三"), cue(6, 8, "四")},
+			[]change{{"edit", 2, "二", "二！"}},
+		},
+		{
+			"修改时间",
+			[]subtitle.Cue{cue(0, 2, "一"), cue(2, 4, "二"), cue(4, 6, "三"), cue(6, 9, "四")},
+			[]change{{"edit", 4, "四", "四"}},
+		},
+		{
+			"中间插入一条只记录插入",
+			[]subtitle.Cue{cue(0, 2, "一"), cue(2, 3, "新"), cue(2, 4, "二"), cue(4, 6, "三"), cue(6, 8, "四")},
+			[]change{{"insert", 2, "", "新"}},
+		},
+		{
+			"删除一条只记录删除",
+			[]subtitle.Cue{cue(0, 2, "一"), cue(4, 6, "三"), cue(6, 8, "四")},
+			[]change{{"delete", 2, "二", ""}},
+		},
+		{
+			"删除开头",
+			[]subtitle.Cue{cue(2, 4, "二"), cue(4, 6, "三"), cue(6, 8, "四")},
+			[]change{{"delete", 1, "一", ""}},
+		},
+		{
+			"末尾追加",
+			append(append([]subtitle.Cue(nil), base...), cue(8, 10, "五")),
+			[]change{{"insert", 5, "", "五"}},
+		},
+		{
+			"插入与修改同时发生",
+			[]subtitle.Cue{cue(0, 1, "零"), cue(1, 2, "一"), cue(2, 4, "二"), cue(4, 6, "叁"), cue(6, 8, "四")},
+			[]change{{"edit", 1, "一", "零"}, {"insert", 2, "", "一"}, {"edit", 4, "三", "叁"}},
+		},
+		{
+			"一条拆成两条",
+			[]subtitle.Cue{cue(0, 2, "一"), cue(2, 3, "二上"), cue(3, 4, "二下"), cue(4, 6, "三"), cue(6, 8, "四")},
+			[]change{{"edit", 2, "二", "二上"}, {"insert", 3, "", "二下"}},
+		},
+		{
+			"全部删除",
+			nil,
+			[]change{{"delete", 1, "一", ""}, {"delete", 2, "二", ""}, {"delete", 3, "三", ""}, {"delete", 4, "四", ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := diffSubtitleCues(base, tt.after)
+			if len(edits) != len(tt.want) {
+				t.Fatalf("got %d edits, want %d: %+v", len(edits), len(tt.want), edits)
+			}
+			for i, want := range tt.want {
+				got := edits[i]
+				if got.Action != want.action || got.CueIndex != want.index || got.OldText != want.oldText || got.NewText != want.newText {
+					t.Errorf("edit[%d] = {%s #%d %q -> %q}, want {%s #%d %q -> %q}",
+						i, got.Action, got.CueIndex, got.OldText, got.NewText, want.action, want.index, want.oldText, want.newText)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffSubtitleCuesRecordsTimes(t *testing.T) {
+	before := []subtitle.Cue{{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "旧"}}
+	after := []subtitle.Cue{{Start: 1600 * time.Millisecond, End: 3200 * time.Millisecond, Text: "新"}}
+	edits := diffSubtitleCues(before, after)
+	if len(edits) != 1 {
+		t.Fatalf("got %d edits, want 1", len(edits))
+	}
+	edit := edits[0]
+	if edit.OldStart != 1500 || edit.OldEnd != 3000 || edit.NewStart != 1600 || edit.NewEnd != 3200 {
+		t.Errorf("edit times = %d-%d -> %d-%d", edit.OldStart, edit.OldEnd, edit.NewStart, edit.NewEnd)
+	}
+}
+
+func TestSubtitleTrackLocks(t *testing.T) {
+	var locks subtitleTrackLocks
+
+	unlock := locks.lock("v1/zh")
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		locks.lock("v1/zh")()
+	}()
+
+	// 其他字幕轨不受影响
+	locks.lock("v1/en")()
+
+	select {
+	case <-acquired:
+		t.Fatal("same track lock acquired while held")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+
+	// 全部释放后不保留锁
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("locks not released: %d left", len(locks.locks))
+	}
+}
@@ -112,6 +112,12 @@ func main() {
 			h.SetUp()
 		}),
 
+		// 系统维护（yt-dlp 定时更新）
+		fx.Provide(handler.NewSystemHandler),
+		fx.Invoke(func(h *handler.SystemHandler) {
+			h.SetUp()
+		}),
+
 		// 生命周期管理
 		fx.Provide(func() *AppLifecycle {
 			return &AppLifecycle{}
@@ -152,6 +158,7 @@ func main() {
 			uploadScheduler *chain_task.UploadScheduler,
 			analyticsMiddleware *analytics.Middleware,
 			analyticsClient *analytics.Client,
+			systemHandler *handler.SystemHandler,
 		) {
 			// 初始化服务器
 			server.Init(db)
@@ -163,7 +170,7 @@ func main() {
 			}
 
 			// 注册所有 Handler 路由（包括连接 VideoHandler 和 UploadScheduler）
-			registerHandlers(server, logger, savedVideoService, taskStepService, uploadScheduler, analyticsClient)
+			registerHandlers(server, logger, savedVideoService, taskStepService, uploadScheduler, analyticsClient, systemHandler)
 
 			// 健康检查
 			server.Engine.GET("/health", func(c *gin.Context) {
@@ -252,6 +259,7 @@ func registerHandlers(
 	taskStepService *services.TaskStepService,
 	uploadScheduler *chain_task.UploadScheduler,
 	analyticsClient *analytics.Client,
+	systemHandler *handler.SystemHandler,
 ) {
 	logger.Info("Registering handlers...")
 
@@ -292,19 +300,17 @@ func registerHandlers(
 	configHandler.RegisterRoutes(server)
 	logger.Info("✓ Config routes registered")
 
+	// 系统 Handler
+	systemHandler.RegisterRoutes(server)
+	logger.Info("✓ System routes registered")
+
 	logger.Info("All handlers registered successfully")
 }
 
 // checkYtDlpInstallation 检查并自动安装 yt-dlp
 func checkYtDlpInstallation(logger *zap.SugaredLogger, config *types.AppConfig) error {
-	// 从配置中获取安装目录，如果未配置则使用默认值
-	var installDir string
-	if config != nil && config.YtDlpPath != "" {
-		installDir = config.YtDlpPath
-	}
-
-	// 创建 yt-dlp 管理器
-	manager := utils.NewYtDlpManager(logger, installDir)
+	// 创建 yt-dlp 管理器（安装目录、固定版本、渠道从配置中读取）
+	manager := utils.NewConfiguredYtDlpManager(logger, config)
 
 	// 检查并自动安装
 	if err := manager.CheckAndInstall(); err != nil {
@@ -0,0 +1,639 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+)
+
+// fakeASRServer 本地模拟的 whisper.cpp server / OpenAI 兼容语音识别服务
+type fakeASRServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int                 // 非 0 时直接返回该状态码
+	response map[string]any      // verbose_json 响应
+	apiKey   string              // 非空时校验 Authorization
+	failAt   int                 // 第 N 个识别请求返回 500（从 1 开始，0 不失败）
+	forms    []map[string]string // 收到的表单字段
+	files    [][]byte            // 收到的音频内容
+	paths    []string            // 请求路径
+}
+
+func newFakeASRServer(t *testing.T) *fakeASRServer {
+	s := &fakeASRServer{
+		response: map[string]any{
+			"language": "english",
+			"duration": 4.5,
+			"text":     " Hello world. Second line.",
+			"segments": []map[string]any{
+				{"id": 0, "start": 0.0, "end": 2.0, "text": " Hello world."},
+				{"id": 1, "start": 2.0, "end": 4.5, "text": " Second line."},
+				{"id": 2, "start": 4.5, "end": 4.5, "text": "  "},
+			},
+		},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeASRServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths = append(s.paths, r.Method+" "+r.URL.Path)
+
+	if s.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+		http.Error(w, `{"error":{"message":"invalid api key"}}`, http.StatusUnauthorized)
+		return
+	}
+	if s.status != 0 {
+		http.Error(w, "model not loaded", s.status)
+		return
+	}
+	if r.Method == http.MethodGet {
+		w.Write([]byte(`{"data":[]}`))
+		return
+	}
+
+	if s.failAt != 0 && len(s.forms)+1 == s.failAt {
+		s.forms = append(s.forms, nil)
+		http.Error(w, "inference failed", http.StatusInternalServerError)
+		return
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	form := make(map[string]string)
+	for key, values := range r.MultipartForm.Value {
+		form[key] = values[0]
+	}
+	s.forms = append(s.forms, form)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+	s.files = append(s.files, data)
+
+	json.NewEncoder(w).Encode(s.response)
+}
+
+func (s *fakeASRServer) lastForm() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.forms) == 0 {
+		return nil
+	}
+	return s.forms[len(s.forms)-1]
+}
+
+// writeTestWAV 写入 16kHz 单声道 16位 WAV（带 LIST 块，与 ffmpeg 输出一致）
+func writeTestWAV(t *testing.T, samples []int16) string {
+	t.Helper()
+	var pcm bytes.Buffer
+	binary.Write(&pcm, binary.LittleEndian, samples)
+	list := []byte("INFOISFT\x0e\x00\x00\x00Lavf61.7.100\x00\x00")
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+16+8+len(list)+8+pcm.Len()))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(1), uint32(16000), uint32(32000), uint16(2), uint16(16)} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(list)))
+	buf.Write(list)
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(pcm.Len()))
+	buf.Write(pcm.Bytes())
+
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testConfig(defaultProvider string, fallback ...string) *types.AppConfig {
+	return &types.AppConfig{
+		AsrConfig: &types.AsrConfig{
+			Enabled:           true,
+			DefaultProvider:   defaultProvider,
+			FallbackProviders: fallback,
+			WhisperServer:     &types.AsrServerConfig{Timeout: 10},
+			OpenAI:            &types.AsrServerConfig{Model: "Systran/faster-whisper-small", Timeout: 10},
+		},
+	}
+}
+
+func TestWhisperServerTranscribe(t *testing.T) {
+	server := newFakeASRServer(t)
+	audio := writeTestWAV(t, []int16{0, 100, -100, 32767})
+
+	transcriber, err := NewWhisperServerTranscriber(&types.AsrServerConfig{Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := transcriber.Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio, Prompt: "ytb2bili"})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+
+	if got := server.paths[0]; got != "POST /inference" {
+		t.Errorf("request = %q, want POST /inference", got)
+	}
+	form := server.lastForm()
+	if form["response_format"] != "verbose_json" || form["language"] != "auto" || form["prompt"] != "ytb2bili" {
+		t.Errorf("form = %v", form)
+	}
+	want, _ := os.ReadFile(audio)
+	if !bytes.Equal(server.files[0], want) {
+		t.Errorf("uploaded %d bytes, want %d", len(server.files[0]), len(want))
+	}
+
+	if result.Provider != ProviderWhisperServer || result.Language != "en" || result.Duration != 4500*time.Millisecond {
+		t.Errorf("result = %+v", result)
+	}
+	wantSegments := []Segment{
+		{Start: 0, End: 2 * time.Second, Text: "Hello world."},
+		{Start: 2 * time.Second, End: 4500 * time.Millisecond, Text: "Second line."},
+	}
+	if len(result.Segments) != len(wantSegments) {
+		t.Fatalf("segments = %+v", result.Segments)
+	}
+	for i, s := range wantSegments {
+		if !reflect.DeepEqual(result.Segments[i], s) {
+			t.Errorf("segment %d = %+v, want %+v", i, result.Segments[i], s)
+		}
+	}
+}
+
+func TestWhisperServerErrorBody(t *testing.T) {
+	server := newFakeASRServer(t)
+	server.response = map[string]any{"error": "failed to read WAV file"}
+
+	transcriber, _ := NewWhisperServerTranscriber(&types.AsrServerConfig{Endpoint: server.URL})
+	_, err := transcriber.Transcribe(context.Background(), &TranscriptionRequest{AudioPath: writeTestWAV(t, []int16{1})})
+	if err == nil || !strings.Contains(err.Error(), "failed to read WAV file") {
+		t.Fatalf("err = %v, want server error", err)
+	}
+}
+
+func TestOpenAITranscribe(t *testing.T) {
+	for _, endpoint := range []string{"", "/v1", "/v1/"} {
+		server := newFakeASRServer(t)
+		server.apiKey = "sk-test"
+		audio := writeTestWAV(t, []int16{1, 2, 3})
+
+		transcriber, err := NewOpenAITranscriber(&types.AsrServerConfig{
+			Endpoint: server.URL + endpoint,
+			ApiKey:   "sk-test",
+			Model:    "Systran/faster-whisper-small",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := transcriber.Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio, Language: "auto"})
+		if err != nil {
+			t.Fatalf("endpoint %q: Transcribe: %v", endpoint, err)
+		}
+
+		if got := server.paths[0]; got != "POST /v1/audio/transcriptions" {
+			t.Errorf("endpoint %q: request = %q", endpoint, got)
+		}
+		form := server.lastForm()
+		if form["model"] != "Systran/faster-whisper-small" || form["response_format"] != "verbose_json" {
+			t.Errorf("endpoint %q: form = %v", endpoint, form)
+		}
+		if _, ok := form["language"]; ok {
+			t.Errorf("endpoint %q: language should be omitted for auto detection, got %q", endpoint, form["language"])
+		}
+		if result.Provider != ProviderOpenAI || result.Model != "Systran/faster-whisper-small" || len(result.Segments) != 2 {
+			t.Errorf("endpoint %q: result = %+v", endpoint, result)
+		}
+
+		if err := transcriber.IsHealthy(context.Background()); err != nil {
+			t.Errorf("endpoint %q: IsHealthy: %v", endpoint, err)
+		}
+	}
+}
+
+func TestWordTimestamps(t *testing.T) {
+	audio := writeTestWAV(t, []int16{1})
+
+	// whisper.cpp server: 分段内的 words
+	server := newFakeASRServer(t)
+	server.response = map[string]any{
+		"language": "en",
+		"segments": []map[string]any{
+			{"start": 0.0, "end": 1.0, "text": " Hi there.", "words": []map[string]any{
+				{"word": " Hi", "start": 0.0, "end": 0.4},
+				{"word": " there.", "start": 0.4, "end": 1.0},
+			}},
+		},
+	}
+	config := testConfig(ProviderWhisperServer)
+	config.AsrConfig.WhisperServer.Endpoint = server.URL
+	result, err := NewTranscriberManager(config).Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	want := []Word{{Start: 0, End: 400 * time.Millisecond, Text: "Hi"}, {Start: 400 * time.Millisecond, End: time.Second, Text: "there."}}
+	if !reflect.DeepEqual(result.Segments[0].Words, want) {
+		t.Errorf("words = %+v, want %+v", result.Segments[0].Words, want)
+	}
+
+	// OpenAI: 顶层 words 按时间分配到分段
+	openai := newFakeASRServer(t)
+	openai.response = map[string]any{
+		"language": "english",
+		"segments": []map[string]any{
+			{"start": 0.0, "end": 1.0, "text": "Hi there."},
+			{"start": 1.0, "end": 2.0, "text": "Bye."},
+		},
+		"words": []map[string]any{
+			{"word": "Hi", "start": 0.0, "end": 0.4},
+			{"word": "there.", "start": 0.4, "end": 1.0},
+			{"word": "Bye.", "start": 1.2, "end": 1.8},
+		},
+	}
+	config = testConfig(ProviderOpenAI)
+	config.AsrConfig.OpenAI.Endpoint = openai.URL
+	result, err = NewTranscriberManager(config).Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if len(result.Segments) != 2 || len(result.Segments[0].Words) != 2 || len(result.Segments[1].Words) != 1 {
+		t.Errorf("segments = %+v", result.Segments)
+	}
+}
+
+func TestOpenAIUnauthorized(t *testing.T) {
+	server := newFakeASRServer(t)
+	server.apiKey = "sk-test"
+
+	transcriber, _ := NewOpenAITranscriber(&types.AsrServerConfig{Endpoint: server.URL, ApiKey: "wrong"})
+	_, err := transcriber.Transcribe(context.Background(), &TranscriptionRequest{AudioPath: writeTestWAV(t, []int16{1}), Language: "en"})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Fatalf("err = %v, want HTTP 401", err)
+	}
+	if err := transcriber.IsHealthy(context.Background()); err == nil {
+		t.Error("IsHealthy should fail with wrong api key")
+	}
+}
+
+func TestOpenAITextOnlyResponse(t *testing.T) {
+	server := newFakeASRServer(t)
+	server.response = map[string]any{"text": "Just text.", "duration": 1.25}
+
+	transcriber, _ := NewOpenAITranscriber(&types.AsrServerConfig{Endpoint: server.URL})
+	result, err := transcriber.Transcribe(context.Background(), &TranscriptionRequest{AudioPath: writeTestWAV(t, []int16{1}), Language: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Segment{End: 1250 * time.Millisecond, Text: "Just text."}
+	if len(result.Segments) != 1 || !reflect.DeepEqual(result.Segments[0], want) || result.Language != "en" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestManagerFallback(t *testing.T) {
+	broken := newFakeASRServer(t)
+	broken.status = http.StatusServiceUnavailable
+	working := newFakeASRServer(t)
+	audio := writeTestWAV(t, []int16{1, 2})
+
+	config := testConfig(ProviderWhisperServer, ProviderWhisperServer, ProviderOpenAI)
+	config.AsrConfig.WhisperServer.Endpoint = broken.URL
+	config.AsrConfig.OpenAI.Endpoint = working.URL
+
+	manager := NewTranscriberManager(config)
+	result, err := manager.Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if result.Provider != ProviderOpenAI {
+		t.Errorf("provider = %q, want fallback %q", result.Provider, ProviderOpenAI)
+	}
+	// 默认提供商出现在备选列表中时不重复请求
+	if len(broken.paths) != 1 || len(working.paths) != 1 {
+		t.Errorf("requests: broken=%v working=%v", broken.paths, working.paths)
+	}
+}
+
+func TestManagerProviderSelection(t *testing.T) {
+	whisperServer := newFakeASRServer(t)
+	openai := newFakeASRServer(t)
+	audio := writeTestWAV(t, []int16{1})
+
+	config := testConfig(ProviderWhisperServer)
+	config.AsrConfig.WhisperServer.Endpoint = whisperServer.URL
+	config.AsrConfig.OpenAI.Endpoint = openai.URL
+	config.AsrConfig.Channels = map[string]string{"UCchannel": ProviderOpenAI}
+
+	manager := NewTranscriberManager(config)
+	provider := config.AsrConfig.ProviderFor("UCchannel", "Channel Name")
+	result, err := manager.TranscribeWithProvider(context.Background(), provider, &TranscriptionRequest{AudioPath: audio})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Provider != ProviderOpenAI || len(openai.paths) != 1 || len(whisperServer.paths) != 0 {
+		t.Errorf("provider = %q, openai=%v whisper=%v", result.Provider, openai.paths, whisperServer.paths)
+	}
+
+	if got := config.AsrConfig.ProviderFor("", "other"); got != ProviderWhisperServer {
+		t.Errorf("ProviderFor(other) = %q, want default", got)
+	}
+}
+
+func TestManagerAllFailed(t *testing.T) {
+	broken := newFakeASRServer(t)
+	broken.status = http.StatusInternalServerError
+
+	config := testConfig(ProviderWhisperServer, "unknown")
+	config.AsrConfig.WhisperServer.Endpoint = broken.URL
+
+	_, err := NewTranscriberManager(config).Transcribe(context.Background(), &TranscriptionRequest{AudioPath: writeTestWAV(t, []int16{1})})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"whisper_server: ", "HTTP 500", "unknown: unsupported asr provider"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestReadWAV(t *testing.T) {
+	samples, err := ReadWAV(writeTestWAV(t, []int16{0, 16384, -32768, 32767}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{0, 0.5, -1, 32767.0 / 32768.0}
+	if len(samples) != len(want) {
+		t.Fatalf("samples = %v", samples)
+	}
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, samples[i], want[i])
+		}
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.wav")
+	os.WriteFile(bad, []byte("ID3\x03not a wav file"), 0644)
+	if _, err := ReadWAV(bad); err == nil {
+		t.Error("ReadWAV should reject non-WAV file")
+	}
+}
+
+func TestWAVFileReadSamples(t *testing.T) {
+	wav, err := OpenWAV(writeTestWAV(t, []int16{0, 16384, -32768, 32767, 8192}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wav.Close()
+
+	if wav.Samples() != 5 {
+		t.Fatalf("Samples() = %d, want 5", wav.Samples())
+	}
+	samples, err := wav.ReadSamples(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 || samples[0] != 0.5 || samples[1] != -1 {
+		t.Errorf("ReadSamples(1, 3) = %v, want [0.5 -1]", samples)
+	}
+	// 超出范围时截断
+	if samples, _ := wav.ReadSamples(3, 100); len(samples) != 2 || samples[1] != 0.25 {
+		t.Errorf("ReadSamples(3, 100) = %v, want last 2 samples", samples)
+	}
+	if samples, _ := wav.ReadSamples(4, 2); samples != nil {
+		t.Errorf("ReadSamples(4, 2) = %v, want nil", samples)
+	}
+}
+
+func TestFormatSRT(t *testing.T) {
+	got := FormatSRT([]Segment{
+		{Start: 0, End: 1500 * time.Millisecond, Text: " Hello "},
+		{Start: 2 * time.Second, End: 3 * time.Second, Text: ""},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "World"},
+	})
+	want := "1\n00:00:00,000 --> 00:00:01,500\nHello\n\n2\n01:02:03,045 --> 01:02:05,000\nWorld\n\n"
+	if got != want {
+		t.Errorf("FormatSRT =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// speechAudio 生成测试音频: 每个参数为一段时长，奇数位置为语音（正弦波），偶数位置为静音（微弱噪声）
+func speechAudio(parts ...time.Duration) []float32 {
+	var samples []float32
+	for i, d := range parts {
+		n := int(d * SampleRate / time.Second)
+		for j := 0; j < n; j++ {
+			if i%2 == 0 {
+				samples = append(samples, 0.3*float32(math.Sin(float64(j)*2*math.Pi*220/SampleRate)))
+			} else {
+				samples = append(samples, 0.001*float32(j%3-1))
+			}
+		}
+	}
+	return samples
+}
+
+func TestPlanChunksSplitsAtSilence(t *testing.T) {
+	samples := speechAudio(8*time.Second, time.Second, 8*time.Second, time.Second, 8*time.Second)
+	chunks := PlanChunks(samples, VADOptions{MaxChunk: 10 * time.Second, MinSilence: 500 * time.Millisecond, Padding: 200 * time.Millisecond})
+
+	if len(chunks) != 3 {
+		t.Fatalf("chunks = %v, want 3", chunks)
+	}
+	for i, chunk := range chunks {
+		if chunk.Duration() > 10*time.Second {
+			t.Errorf("chunk %d = %v, longer than max", i, chunk)
+		}
+		if i > 0 && chunk.Start < chunks[i-1].End {
+			t.Errorf("chunk %d = %v overlaps %v", i, chunk, chunks[i-1])
+		}
+	}
+	// 第二段从静音之后开始（保留 200ms 前置静音）
+	if got := chunks[1].Start; got < 8*time.Second || got > 9*time.Second {
+		t.Errorf("chunk 1 start = %v, want inside the first silence", got)
+	}
+}
+
+func TestPlanChunksSplitsLongSpeech(t *testing.T) {
+	samples := speechAudio(25 * time.Second)
+	chunks := PlanChunks(samples, VADOptions{MaxChunk: 10 * time.Second, MinSilence: 500 * time.Millisecond})
+
+	if len(chunks) != 3 {
+		t.Fatalf("chunks = %v, want 3", chunks)
+	}
+	for i, chunk := range chunks {
+		if chunk.Duration() > 10*time.Second || chunk.Duration() <= 0 {
+			t.Errorf("chunk %d = %v", i, chunk)
+		}
+		if i > 0 && chunk.Start != chunks[i-1].End {
+			t.Errorf("chunk %d = %v not contiguous with %v", i, chunk, chunks[i-1])
+		}
+	}
+	if chunks[2].End != 25*time.Second {
+		t.Errorf("last chunk ends at %v", chunks[2].End)
+	}
+}
+
+func TestPlanWAVChunks(t *testing.T) {
+	samples := speechAudio(8*time.Second, time.Second, 8*time.Second, time.Second, 80*time.Second)
+	audio := filepath.Join(t.TempDir(), "audio.wav")
+	if err := WriteWAV(audio, samples); err != nil {
+		t.Fatal(err)
+	}
+	wav, err := OpenWAV(audio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wav.Close()
+
+	// 分块读取计算的能量与整段计算一致（WriteWAV 量化后样本略有不同，比较规划结果）
+	opts := VADOptions{MaxChunk: 10 * time.Second, MinSilence: 500 * time.Millisecond, Padding: 200 * time.Millisecond}
+	got, err := PlanWAVChunks(wav, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, _ := ReadWAV(audio)
+	if want := PlanChunks(full, opts); !slices.Equal(got, want) {
+		t.Errorf("PlanWAVChunks = %v, want %v", got, want)
+	}
+}
+
+func TestChunkedTranscriberResumes(t *testing.T) {
+	server := newFakeASRServer(t)
+	server.failAt = 2
+
+	samples := speechAudio(8*time.Second, time.Second, 8*time.Second, time.Second, 8*time.Second)
+	audio := filepath.Join(t.TempDir(), "audio.wav")
+	if err := WriteWAV(audio, samples); err != nil {
+		t.Fatal(err)
+	}
+
+	config := testConfig(ProviderWhisperServer)
+	config.AsrConfig.WhisperServer.Endpoint = server.URL
+	checkpoints := filepath.Join(t.TempDir(), "chunks")
+	opts := ChunkOptions{
+		VADOptions:    VADOptions{MaxChunk: 10 * time.Second, MinSilence: 500 * time.Millisecond, Padding: 200 * time.Millisecond},
+		Parallel:      1,
+		CheckpointDir: checkpoints,
+	}
+
+	// 第一次: 第 2 段失败，其余段写入断点
+	_, err := NewChunkedTranscriber(NewTranscriberManager(config), opts).Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio})
+	if err == nil || !strings.Contains(err.Error(), "1/3") {
+		t.Fatalf("err = %v, want 1/3 chunks failed", err)
+	}
+	saved, _ := filepath.Glob(filepath.Join(checkpoints, "*.json"))
+	if len(saved) != 2 {
+		t.Fatalf("checkpoints = %v, want 2", saved)
+	}
+
+	// 第二次: 只识别失败的段
+	server.failAt = 0
+	var cached int
+	opts.OnChunk = func(done, total int, chunk Span, fromCheckpoint bool) {
+		if fromCheckpoint {
+			cached++
+		}
+	}
+	result, err := NewChunkedTranscriber(NewTranscriberManager(config), opts).Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if len(server.forms) != 4 || cached != 2 {
+		t.Errorf("requests = %d, cached = %d; want 4 requests in total and 2 chunks from checkpoints", len(server.forms), cached)
+	}
+	if len(result.Segments) != 6 {
+		t.Fatalf("segments = %d, want 6", len(result.Segments))
+	}
+	for i := 1; i < len(result.Segments); i++ {
+		if result.Segments[i].Start < result.Segments[i-1].End {
+			t.Errorf("segment %d %v overlaps previous %v", i, result.Segments[i], result.Segments[i-1])
+		}
+	}
+	// 第三段的时间按分段起点偏移
+	if got := result.Segments[4].Start; got < 17*time.Second {
+		t.Errorf("segment 4 start = %v, want offset into the third chunk", got)
+	}
+	if result.Language != "en" {
+		t.Errorf("language = %q", result.Language)
+	}
+	if _, err := os.Stat(checkpoints); !os.IsNotExist(err) {
+		t.Errorf("checkpoint dir not removed: %v", err)
+	}
+}
+
+func TestChunkedTranscriberKeepsRequestedLanguage(t *testing.T) {
+	server := newFakeASRServer(t)
+
+	samples := speechAudio(8*time.Second, time.Second, 8*time.Second)
+	audio := filepath.Join(t.TempDir(), "audio.wav")
+	if err := WriteWAV(audio, samples); err != nil {
+		t.Fatal(err)
+	}
+
+	config := testConfig(ProviderWhisperServer)
+	config.AsrConfig.WhisperServer.Endpoint = server.URL
+	opts := ChunkOptions{
+		VADOptions:    VADOptions{MaxChunk: 10 * time.Second, MinSilence: 500 * time.Millisecond},
+		CheckpointDir: filepath.Join(t.TempDir(), "chunks"),
+	}
+
+	// 各段检测结果为 en，但请求指定了 ja
+	result, err := NewChunkedTranscriber(NewTranscriberManager(config), opts).Transcribe(context.Background(), &TranscriptionRequest{AudioPath: audio, Language: "ja"})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if result.Language != "ja" {
+		t.Errorf("language = %q, want ja", result.Language)
+	}
+}
+
+func TestStitchSegments(t *testing.T) {
+	got := StitchSegments([][]Segment{
+		{{Start: 0, End: 2 * time.Second, Text: "Hello"}, {Start: time.Second, End: 3 * time.Second, Text: "world"}},
+		{{Start: 3 * time.Second, End: 3 * time.Second, Text: "again"}, {Start: 4 * time.Second, End: 5 * time.Second, Text: "你好"}, {Start: 4 * time.Second, End: 5 * time.Second, Text: "世界"}},
+	})
+	want := []Segment{
+		{Start: 0, End: 2 * time.Second, Text: "Hello"},
+		{Start: 2 * time.Second, End: 3 * time.Second, Text: "world again"},
+		{Start: 4 * time.Second, End: 5 * time.Second, Text: "你好世界"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i].Start != want[i].Start || got[i].End != want[i].End || got[i].Text != want[i].Text {
+			t.Errorf("segment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// ChunkOptions 分段识别参数
+type ChunkOptions struct {
+	VADOptions
+	Provider      string // 提供商，为空时使用默认提供商
+	Parallel      int    // 并行识别的段数（whisper.cpp 共享同一模型，各段仍串行识别）
+	ThreadBudget  int    // whisper.cpp 识别线程数（0 时使用配置值）
+	CheckpointDir string // 断点目录，已完成的段保存为 chunk_NNNN.json
+
+	// OnChunk 每段完成（或从断点恢复）后回调，用于输出进度
+	OnChunk func(done, total int, chunk Span, cached bool)
+}
+
+// chunkCheckpoint 单段识别结果断点（请求参数不同时断点作废）
+type chunkCheckpoint struct {
+	Chunk    Span   `json:"chunk"`
+	Provider string `json:"provider"` // 请求的提供商
+	Language string `json:"language"` // 请求的语言
+
+	UsedProvider string    `json:"used_provider"` // 实际使用的提供商（可能是备选提供商）
+	Detected     string    `json:"detected"`      // 识别出的语言
+	Model        string    `json:"model,omitempty"`
+	Segments     []Segment `json:"segments"` // 时间相对于整段音频
+}
+
+// ChunkedTranscriber 长音频分段识别: 按静音切分为语音段，并行识别后拼接为完整结果
+// 每段完成后写入断点，失败重试时只识别未完成的段；全部完成后删除断点
+type ChunkedTranscriber struct {
+	manager *TranscriberManager
+	opts    ChunkOptions
+}
+
+// NewChunkedTranscriber 创建分段识别器
+func NewChunkedTranscriber(manager *TranscriberManager, opts ChunkOptions) *ChunkedTranscriber {
+	if opts.Parallel <= 0 {
+		opts.Parallel = 1
+	}
+	return &ChunkedTranscriber{manager: manager, opts: opts}
+}
+
+// Transcribe 分段识别音频文件（16kHz 单声道 16位 WAV）
+func (c *ChunkedTranscriber) Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	wav, err := OpenWAV(req.AudioPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取WAV文件失败: %v", err)
+	}
+	defer wav.Close()
+	chunks, err := PlanWAVChunks(wav, c.opts.VADOptions)
+	if err != nil {
+		return nil, fmt.Errorf("读取WAV文件失败: %v", err)
+	}
+
+	if err := os.MkdirAll(c.opts.CheckpointDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建断点目录失败: %v", err)
+	}
+
+	provider := c.opts.Provider
+	if provider == "" {
+		provider = c.manager.DefaultProvider()
+	}
+	results := make([]*chunkCheckpoint, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var mu sync.Mutex
+	done := 0
+	report := func(i int, cached bool) {
+		mu.Lock()
+		done++
+		n := done
+		mu.Unlock()
+		if c.opts.OnChunk != nil {
+			c.opts.OnChunk(n, len(chunks), chunks[i], cached)
+		}
+	}
+
+	// 1. 已完成的段从断点恢复，其余段放入队列
+	queue := make(chan int, len(chunks))
+	for i, chunk := range chunks {
+		if cp := c.loadCheckpoint(i, chunk, provider, req.Language); cp != nil {
+			results[i] = cp
+			report(i, true)
+			continue
+		}
+		queue <- i
+	}
+	close(queue)
+
+	// 2. 并行识别
+	var wg sync.WaitGroup
+	for w := 0; w < min(c.opts.Parallel, len(queue)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					errs[i] = ctx.Err()
+					continue
+				}
+				cp, err := c.transcribeChunk(ctx, wav, i, chunks[i], provider, req)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i] = cp
+				report(i, false)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("第 %d 段 (%s-%s): %v", i+1, chunks[i].Start, chunks[i].End, err))
+		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%d/%d 段识别失败: %s", len(failed), len(chunks), strings.Join(failed, "; "))
+	}
+
+	// 3. 拼接
+	result := &TranscriptionResult{
+		Duration: sampleDuration(wav.Samples()),
+		Provider: provider,
+	}
+	languages := make(map[string]int)
+	var segments [][]Segment
+	for _, cp := range results {
+		segments = append(segments, cp.Segments)
+		if cp.Detected != "" {
+			languages[cp.Detected]++
+		}
+		if cp.Model != "" {
+			result.Model = cp.Model
+		}
+		if cp.UsedProvider != "" {
+			result.Provider = cp.UsedProvider
+		}
+	}
+	result.Segments = StitchSegments(segments)
+
+	// 指定语言时直接使用；自动检测时各段的语言可能不同，取出现最多的
+	result.Language = req.Language
+	if result.Language == "" || result.Language == "auto" {
+		for language, n := range languages {
+			if result.Language == "" || result.Language == "auto" || n > languages[result.Language] {
+				result.Language = language
+			}
+		}
+	}
+
+	os.RemoveAll(c.opts.CheckpointDir)
+	return result, nil
+}
+
+// transcribeChunk 读取一段音频识别并写入断点
+func (c *ChunkedTranscriber) transcribeChunk(ctx context.Context, wav *WAVFile, index int, chunk Span, provider string, req *TranscriptionRequest) (*chunkCheckpoint, error) {
+	wavPath := filepath.Join(c.opts.CheckpointDir, fmt.Sprintf("chunk_%04d.wav", index))
+	defer os.Remove(wavPath)
+
+	samples, err := wav.ReadSamples(sampleIndex(chunk.Start, wav.Samples()), sampleIndex(chunk.End, wav.Samples()))
+	if err != nil {
+		return nil, fmt.Errorf("读取分段音频失败: %v", err)
+	}
+	if err := WriteWAV(wavPath, samples); err != nil {
+		return nil, fmt.Errorf("写入分段音频失败: %v", err)
+	}
+
+	result, err := c.manager.TranscribeWithProvider(ctx, provider, &TranscriptionRequest{
+		AudioPath: wavPath,
+		Language:  req.Language,
+		Prompt:    req.Prompt,
+		Threads:   c.opts.ThreadBudget,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 时间换算为整段音频时间，并限制在分段范围内
+	cp := &chunkCheckpoint{
+		Chunk:        chunk,
+		Provider:     provider,
+		Language:     req.Language,
+		UsedProvider: result.Provider,
+		Detected:     result.Language,
+		Model:        result.Model,
+	}
+	for _, seg := range result.Segments {
+		seg.Start = min(seg.Start+chunk.Start, chunk.End)
+		seg.End = min(seg.End+chunk.Start, chunk.End)
+		words := seg.Words
+		seg.Words = nil
+		for _, w := range words {
+			w.Start = min(w.Start+chunk.Start, chunk.End)
+			w.End = min(w.End+chunk.Start, chunk.End)
+			seg.Words = append(seg.Words, w)
+		}
+		cp.Segments = append(cp.Segments, seg)
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.checkpointPath(index), data, 0644); err != nil {
+		return nil, fmt.Errorf("写入断点失败: %v", err)
+	}
+	return cp, nil
+}
+
+// checkpointPath 断点文件路径
+func (c *ChunkedTranscriber) checkpointPath(index int) string {
+	return filepath.Join(c.opts.CheckpointDir, fmt.Sprintf("chunk_%04d.json", index))
+}
+
+// loadCheckpoint 读取断点，分段区间或请求参数不一致时返回 nil
+func (c *ChunkedTranscriber) loadCheckpoint(index int, chunk Span, provider, language string) *chunkCheckpoint {
+	data, err := os.ReadFile(c.checkpointPath(index))
+	if err != nil {
+		return nil
+	}
+	var cp chunkCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil
+	}
+	if cp.Chunk != chunk || cp.Provider != provider || cp.Language != language {
+		return nil
+	}
+	return &cp
+}
+
+// StitchSegments 按顺序拼接各段识别结果，消除时间重叠
+// 开始时间早于上一条结束时间时后移；后移后没有时长的文本并入上一条
+func StitchSegments(chunks [][]Segment) []Segment {
+	var out []Segment
+	for _, segments := range chunks {
+		for _, seg := range segments {
+			seg.Text = strings.TrimSpace(seg.Text)
+			if seg.Text == "" {
+				continue
+			}
+			if n := len(out); n > 0 {
+				prev := &out[n-1]
+				seg.Start = max(seg.Start, prev.End)
+				if seg.End <= seg.Start {
+					prev.Text = subtitle.JoinText(prev.Text, seg.Text)
+					prev.Words = append(prev.Words, seg.Words...)
+					continue
+				}
+			} else if seg.End <= seg.Start {
+				continue
+			}
+			out = append(out, seg)
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package asr
+
+import (
+	"fmt"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+)
+
+// Factory 语音识别器工厂实现
+type Factory struct {
+	config *types.AppConfig
+}
+
+// NewTranscriberFactory 创建语音识别器工厂
+func NewTranscriberFactory(config *types.AppConfig) *Factory {
+	return &Factory{
+		config: config,
+	}
+}
+
+// CreateTranscriber 创建语音识别器实例
+func (f *Factory) CreateTranscriber(provider string, config map[string]interface{}) (Transcriber, error) {
+	switch provider {
+	case ProviderWhisperCpp:
+		return f.createWhisperCpp(config)
+	case ProviderWhisperServer:
+		return f.createWhisperServer(config)
+	case ProviderOpenAI:
+		return f.createOpenAI(config)
+	default:
+		return nil, fmt.Errorf("unsupported asr provider: %s", provider)
+	}
+}
+
+// GetSupportedProviders 获取支持的提供商列表
+func (f *Factory) GetSupportedProviders() []string {
+	return []string{
+		ProviderWhisperCpp,
+		ProviderWhisperServer,
+		ProviderOpenAI,
+	}
+}
+
+// createWhisperCpp 创建进程内 whisper.cpp 识别器（使用 WhisperConfig 的模型和线程数）
+func (f *Factory) createWhisperCpp(config map[string]interface{}) (Transcriber, error) {
+	var modelPath string
+	var threads int
+	if f.config.WhisperConfig != nil {
+		modelPath = f.config.WhisperConfig.ModelPath
+		threads = f.config.WhisperConfig.Threads
+	}
+
+	// 覆盖配置
+	if v, ok := config["model_path"].(string); ok && v != "" {
+		modelPath = v
+	}
+	if v, ok := config["threads"].(int); ok && v > 0 {
+		threads = v
+	}
+
+	return NewWhisperCppTranscriber(modelPath, threads)
+}
+
+// createWhisperServer 创建 whisper.cpp server 识别器
+func (f *Factory) createWhisperServer(config map[string]interface{}) (Transcriber, error) {
+	var serverConfig types.AsrServerConfig
+	if f.config.AsrConfig != nil && f.config.AsrConfig.WhisperServer != nil {
+		serverConfig = *f.config.AsrConfig.WhisperServer
+	}
+	overrideServerConfig(&serverConfig, config)
+
+	return NewWhisperServerTranscriber(&serverConfig)
+}
+
+// createOpenAI 创建 OpenAI 兼容接口识别器
+func (f *Factory) createOpenAI(config map[string]interface{}) (Transcriber, error) {
+	var serverConfig types.AsrServerConfig
+	if f.config.AsrConfig != nil && f.config.AsrConfig.OpenAI != nil {
+		serverConfig = *f.config.AsrConfig.OpenAI
+	}
+	overrideServerConfig(&serverConfig, config)
+
+	return NewOpenAITranscriber(&serverConfig)
+}
+
+// overrideServerConfig 使用传入的配置覆盖 HTTP 服务配置
+func overrideServerConfig(serverConfig *types.AsrServerConfig, config map[string]interface{}) {
+	if endpoint, ok := config["endpoint"].(string); ok && endpoint != "" {
+		serverConfig.Endpoint = endpoint
+	}
+	if apiKey, ok := config["api_key"].(string); ok && apiKey != "" {
+		serverConfig.ApiKey = apiKey
+	}
+	if model, ok := config["model"].(string); ok && model != "" {
+		serverConfig.Model = model
+	}
+	if timeout, ok := config["timeout"].(int); ok && timeout > 0 {
+		serverConfig.Timeout = timeout
+	}
+}
@@ -0,0 +1,198 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/pkg/lang"
+)
+
+// verboseJSON whisper.cpp server 与 OpenAI 兼容接口的 verbose_json 响应
+type verboseJSON struct {
+	Language         string  `json:"language"`
+	DetectedLanguage string  `json:"detected_language"` // whisper.cpp server 自动检测的语言
+	Duration         float64 `json:"duration"`
+	Text             string  `json:"text"`
+	Segments         []struct {
+		Start float64       `json:"start"`
+		End   float64       `json:"end"`
+		Text  string        `json:"text"`
+		Words []verboseWord `json:"words"` // whisper.cpp server、faster-whisper-server 在分段内返回
+	} `json:"segments"`
+	Words []verboseWord `json:"words"` // OpenAI timestamp_granularities[]=word 在顶层返回
+}
+
+// verboseWord verbose_json 中的词级时间戳
+type verboseWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// toResult 转换为识别结果，没有分段时整段文本作为一个分段
+func (v *verboseJSON) toResult(provider, model, requestLanguage string) *TranscriptionResult {
+	result := &TranscriptionResult{
+		Language: lang.Normalize(v.DetectedLanguage),
+		Duration: seconds(v.Duration),
+		Provider: provider,
+		Model:    model,
+	}
+	if result.Language == "" {
+		result.Language = lang.Normalize(v.Language)
+	}
+	if result.Language == "" && requestLanguage != "auto" {
+		result.Language = requestLanguage
+	}
+
+	for _, s := range v.Segments {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		result.Segments = append(result.Segments, Segment{Start: seconds(s.Start), End: seconds(s.End), Text: text, Words: toWords(s.Words)})
+	}
+	if len(v.Segments) == 0 && strings.TrimSpace(v.Text) != "" {
+		result.Segments = []Segment{{End: result.Duration, Text: strings.TrimSpace(v.Text)}}
+	}
+
+	// 顶层词列表按时间分配到分段
+	if words := toWords(v.Words); len(words) > 0 {
+		for i := range result.Segments {
+			seg := &result.Segments[i]
+			if len(seg.Words) > 0 {
+				continue
+			}
+			for _, w := range words {
+				mid := (w.Start + w.End) / 2
+				if mid >= seg.Start && (mid < seg.End || i == len(result.Segments)-1) {
+					seg.Words = append(seg.Words, w)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// toWords 转换词级时间戳，跳过空白词
+func toWords(words []verboseWord) []Word {
+	var out []Word
+	for _, w := range words {
+		if text := strings.TrimSpace(w.Word); text != "" {
+			out = append(out, Word{Start: seconds(w.Start), End: seconds(w.End), Text: text})
+		}
+	}
+	return out
+}
+
+// postAudio 以 multipart/form-data 上传音频文件并解析 verbose_json 响应
+// 音频文件按流方式发送，不整体读入内存
+func postAudio(ctx context.Context, client *http.Client, url, apiKey string, fields [][2]string, audioPath string) (*verboseJSON, error) {
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开音频文件失败: %v", err)
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// 表单字段和文件头写入 head，文件内容之后写入结束边界
+	var head bytes.Buffer
+	writer := multipart.NewWriter(&head)
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := writer.CreateFormFile("file", filepath.Base(audioPath)); err != nil {
+		return nil, err
+	}
+	tail := fmt.Sprintf("\r\n--%s--\r\n", writer.Boundary())
+
+	body := io.MultiReader(&head, file, strings.NewReader(tail))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(head.Len()) + stat.Size() + int64(len(tail))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(data), 200))
+	}
+
+	var result verboseJSON
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	// whisper.cpp server 出错时也可能返回 200 和 {"error": "..."}
+	var errResp struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &errResp) == nil && len(errResp.Error) > 0 && string(errResp.Error) != "null" {
+		return nil, fmt.Errorf("服务返回错误: %s", truncate(string(errResp.Error), 200))
+	}
+	return &result, nil
+}
+
+// checkHealth 发送 GET 请求检查服务是否可用
+func checkHealth(ctx context.Context, client *http.Client, url, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// seconds 秒数转换为 time.Duration（精确到毫秒）
+func seconds(v float64) time.Duration {
+	return time.Duration(math.Round(v*1000)) * time.Millisecond
+}
+
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
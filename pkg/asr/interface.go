@@ -0,0 +1,75 @@
+package asr
+
+import (
+	"context"
+	"time"
+)
+
+// 支持的语音识别提供商
+const (
+	ProviderWhisperCpp    = "whisper_cpp"    // 进程内 whisper.cpp（Go 绑定）
+	ProviderWhisperServer = "whisper_server" // whisper.cpp server HTTP 接口
+	ProviderOpenAI        = "openai"         // OpenAI 兼容 /v1/audio/transcriptions 接口
+)
+
+// TranscriptionRequest 语音识别请求
+type TranscriptionRequest struct {
+	AudioPath string `json:"audioPath"`          // 音频文件路径（16kHz 单声道 16位 WAV）
+	Language  string `json:"language,omitempty"` // 识别语言，为空或 auto 时自动检测
+	Prompt    string `json:"prompt,omitempty"`   // 提示词（专有名词、人名等）
+	Threads   int    `json:"threads,omitempty"`  // 进程内识别线程数，0 时使用配置值（HTTP 提供商忽略）
+}
+
+// Segment 识别出的一段文本
+type Segment struct {
+	Start time.Duration `json:"start"`           // 开始时间
+	End   time.Duration `json:"end"`             // 结束时间
+	Text  string        `json:"text"`            // 文本
+	Words []Word        `json:"words,omitempty"` // 词级时间戳（提供商支持时）
+}
+
+// Word 带时间戳的词
+type Word struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// TranscriptionResult 语音识别结果
+type TranscriptionResult struct {
+	Language string        `json:"language"`        // 识别语言（ISO 639-1 代码）
+	Duration time.Duration `json:"duration"`        // 音频时长
+	Segments []Segment     `json:"segments"`        // 分段文本
+	Provider string        `json:"provider"`        // 语音识别提供商
+	Model    string        `json:"model,omitempty"` // 使用的模型
+}
+
+// TranscriberInfo 语音识别器信息
+type TranscriberInfo struct {
+	Name     string   `json:"name"`     // 识别器名称
+	Provider string   `json:"provider"` // 提供商
+	Model    string   `json:"model"`    // 模型
+	Features []string `json:"features"` // 支持的功能
+	IsOnline bool     `json:"isOnline"` // 是否为远程服务
+}
+
+// Transcriber 语音识别器接口
+type Transcriber interface {
+	// Transcribe 转录音频文件
+	Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResult, error)
+
+	// GetInfo 获取识别器信息
+	GetInfo() *TranscriberInfo
+
+	// IsHealthy 健康检查
+	IsHealthy(ctx context.Context) error
+}
+
+// TranscriberFactory 语音识别器工厂接口
+type TranscriberFactory interface {
+	// CreateTranscriber 创建识别器实例
+	CreateTranscriber(provider string, config map[string]interface{}) (Transcriber, error)
+
+	// GetSupportedProviders 获取支持的提供商列表
+	GetSupportedProviders() []string
+}
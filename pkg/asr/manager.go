@@ -0,0 +1,163 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+)
+
+// TranscriberManager 语音识别器管理器
+type TranscriberManager struct {
+	config            *types.AppConfig
+	factory           TranscriberFactory
+	transcribers      map[string]Transcriber
+	mutex             sync.Mutex
+	defaultProvider   string
+	fallbackProviders []string
+}
+
+// NewTranscriberManager 创建语音识别器管理器
+func NewTranscriberManager(config *types.AppConfig) *TranscriberManager {
+	defaultProvider := ProviderWhisperCpp
+	var fallbackProviders []string
+
+	// 从配置中读取默认提供商和备选提供商
+	if config.AsrConfig != nil {
+		if config.AsrConfig.DefaultProvider != "" {
+			defaultProvider = config.AsrConfig.DefaultProvider
+		}
+		fallbackProviders = config.AsrConfig.FallbackProviders
+	}
+
+	return &TranscriberManager{
+		config:            config,
+		factory:           NewTranscriberFactory(config),
+		transcribers:      make(map[string]Transcriber),
+		defaultProvider:   defaultProvider,
+		fallbackProviders: fallbackProviders,
+	}
+}
+
+// GetTranscriber 获取识别器实例
+func (m *TranscriberManager) GetTranscriber(provider string) (Transcriber, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// 检查是否已存在实例
+	if transcriber, exists := m.transcribers[provider]; exists {
+		return transcriber, nil
+	}
+
+	// 创建新的识别器实例
+	transcriber, err := m.factory.CreateTranscriber(provider, make(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	// 缓存实例
+	m.transcribers[provider] = transcriber
+	return transcriber, nil
+}
+
+// Close 释放已创建的识别器（进程内 whisper.cpp 的模型）
+func (m *TranscriberManager) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var errs []error
+	for provider, transcriber := range m.transcribers {
+		if closer, ok := transcriber.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", provider, err))
+			}
+		}
+		delete(m.transcribers, provider)
+	}
+	return errors.Join(errs...)
+}
+
+// DefaultProvider 默认提供商
+func (m *TranscriberManager) DefaultProvider() string {
+	return m.defaultProvider
+}
+
+// Transcribe 使用默认提供商转录
+func (m *TranscriberManager) Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	return m.TranscribeWithProvider(ctx, m.defaultProvider, req)
+}
+
+// TranscribeWithProvider 使用指定提供商转录，失败时按顺序尝试备选提供商
+func (m *TranscriberManager) TranscribeWithProvider(ctx context.Context, provider string, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	if provider == "" {
+		provider = m.defaultProvider
+	}
+
+	var errs []string
+	tried := make(map[string]bool)
+	for _, p := range append([]string{provider}, m.fallbackProviders...) {
+		if tried[p] {
+			continue
+		}
+		tried[p] = true
+
+		result, err := m.transcribeOnce(ctx, p, req)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", p, err))
+
+		// 任务被取消或超时时不再尝试备选提供商
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("all asr providers failed: %s", strings.Join(errs, "; "))
+}
+
+// transcribeOnce 使用单个提供商转录
+func (m *TranscriberManager) transcribeOnce(ctx context.Context, provider string, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	transcriber, err := m.GetTranscriber(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := transcriber.Transcribe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if result.Provider == "" {
+		result.Provider = provider
+	}
+	return result, nil
+}
+
+// GetProviderInfo 获取提供商信息
+func (m *TranscriberManager) GetProviderInfo(provider string) (*TranscriberInfo, error) {
+	transcriber, err := m.GetTranscriber(provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcriber %s: %v", provider, err)
+	}
+
+	return transcriber.GetInfo(), nil
+}
+
+// GetAllProviders 获取所有支持的提供商
+func (m *TranscriberManager) GetAllProviders() []string {
+	return m.factory.GetSupportedProviders()
+}
+
+// HealthCheck 健康检查
+func (m *TranscriberManager) HealthCheck(ctx context.Context, provider string) error {
+	transcriber, err := m.GetTranscriber(provider)
+	if err != nil {
+		return fmt.Errorf("failed to get transcriber %s: %v", provider, err)
+	}
+
+	return transcriber.IsHealthy(ctx)
+}
@@ -0,0 +1,94 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+)
+
+// OpenAITranscriber OpenAI 兼容语音识别接口（OpenAI、faster-whisper-server、speaches 等）
+// 接口: POST {endpoint}/v1/audio/transcriptions，multipart 表单上传音频
+type OpenAITranscriber struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAITranscriber 创建 OpenAI 兼容接口识别器
+func NewOpenAITranscriber(config *types.AsrServerConfig) (*OpenAITranscriber, error) {
+	if config == nil || config.Endpoint == "" {
+		return nil, fmt.Errorf("openai asr endpoint is required")
+	}
+
+	// 地址可以带或不带 /v1 前缀
+	baseURL := strings.TrimRight(config.Endpoint, "/")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+
+	model := config.Model
+	if model == "" {
+		model = "whisper-1"
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 1800 // 长音频转录耗时较长，默认30分钟
+	}
+
+	return &OpenAITranscriber{
+		baseURL: baseURL,
+		apiKey:  config.ApiKey,
+		model:   model,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Transcribe 转录音频文件
+func (o *OpenAITranscriber) Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	// 接口要求 ISO 639-1 代码，自动检测时不传
+	language := req.Language
+	if language == "auto" {
+		language = ""
+	}
+
+	resp, err := postAudio(ctx, o.client, o.baseURL+"/audio/transcriptions", o.apiKey, [][2]string{
+		{"model", o.model},
+		{"response_format", "verbose_json"},
+		{"timestamp_granularities[]", "segment"},
+		{"timestamp_granularities[]", "word"},
+		{"language", language},
+		{"prompt", req.Prompt},
+	}, req.AudioPath)
+	if err != nil {
+		return nil, fmt.Errorf("openai asr 转录失败: %v", err)
+	}
+
+	return resp.toResult(ProviderOpenAI, o.model, req.Language), nil
+}
+
+// GetInfo 获取识别器信息
+func (o *OpenAITranscriber) GetInfo() *TranscriberInfo {
+	return &TranscriberInfo{
+		Name:     "OpenAI compatible",
+		Provider: ProviderOpenAI,
+		Model:    o.model,
+		Features: []string{"language_detection", "prompt", "word_timestamps"},
+		IsOnline: true,
+	}
+}
+
+// IsHealthy 健康检查
+func (o *OpenAITranscriber) IsHealthy(ctx context.Context) error {
+	if err := checkHealth(ctx, o.client, o.baseURL+"/models", o.apiKey); err != nil {
+		return fmt.Errorf("openai asr 不可用: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package asr
+
+import (
+	"strings"
+
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+)
+
+// Cues 将识别结果分段转换为字幕（跳过空文本，保留词级时间戳）
+func Cues(segments []Segment) []subtitle.Cue {
+	cues := make([]subtitle.Cue, 0, len(segments))
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		cue := subtitle.Cue{Start: segment.Start, End: segment.End, Text: text}
+		for _, w := range segment.Words {
+			cue.Words = append(cue.Words, subtitle.Word{Start: w.Start, End: w.End, Text: w.Text})
+		}
+		cues = append(cues, cue)
+	}
+	return cues
+}
+
+// FormatSRT 将识别结果分段格式化为 SRT 字幕
+func FormatSRT(segments []Segment) string {
+	return subtitle.FormatSRT(Cues(segments))
+}
+
+// WriteSRT 将识别结果写入 SRT 字幕文件
+func WriteSRT(path string, segments []Segment) error {
+	return subtitle.WriteSRT(path, Cues(segments))
+}
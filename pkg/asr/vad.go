@@ -0,0 +1,183 @@
+package asr
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// SampleRate 识别输入的采样率（16kHz 单声道）
+const SampleRate = 16000
+
+const (
+	vadFrame       = 30 * time.Millisecond // 能量分析帧长
+	vadFloorOffset = 12.0                  // 语音阈值高于噪声底的分贝数
+	vadMinDB       = -60.0                 // 低于该能量的帧始终视为静音
+	vadSplitWindow = 30 * time.Second      // 超长语音段在末尾该窗口内找最安静的位置切分
+)
+
+// Span 音频中的一段时间区间
+type Span struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// Duration 区间时长
+func (s Span) Duration() time.Duration {
+	return s.End - s.Start
+}
+
+// VADOptions 语音活动检测与分段参数
+type VADOptions struct {
+	MaxChunk   time.Duration // 单段最长时长
+	MinSilence time.Duration // 切分点的最短静音
+	Padding    time.Duration // 语音段前后保留的静音
+}
+
+// frameLevels 计算每帧的 RMS 能量（dB）
+func frameLevels(samples []float32) []float64 {
+	frameSize := int(vadFrame * SampleRate / time.Second)
+	levels := make([]float64, 0, len(samples)/frameSize+1)
+	for i := 0; i < len(samples); i += frameSize {
+		end := min(i+frameSize, len(samples))
+		var sum float64
+		for _, s := range samples[i:end] {
+			sum += float64(s) * float64(s)
+		}
+		rms := math.Sqrt(sum / float64(end-i))
+		levels = append(levels, 20*math.Log10(rms+1e-10))
+	}
+	return levels
+}
+
+// wavLevels 分块读取 WAV 文件计算每帧能量，不整段载入样本
+func wavLevels(wav *WAVFile) ([]float64, error) {
+	frameSize := int(vadFrame * SampleRate / time.Second)
+	block := frameSize * 2000 // 每次读取约 1 分钟（帧长的整数倍，保证帧不跨块）
+	levels := make([]float64, 0, wav.Samples()/frameSize+1)
+	for start := 0; start < wav.Samples(); start += block {
+		samples, err := wav.ReadSamples(start, start+block)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, frameLevels(samples)...)
+	}
+	return levels, nil
+}
+
+// DetectSpeech 基于能量的语音活动检测，返回语音区间
+func DetectSpeech(samples []float32, opts VADOptions) []Span {
+	return detectSpeech(frameLevels(samples), sampleDuration(len(samples)), opts)
+}
+
+// detectSpeech 按帧能量检测语音区间
+// 阈值随音频自适应: 取能量第 5 百分位作为噪声底、第 95 百分位作为语音电平，
+// 高出噪声底 vadFloorOffset 分贝（最多两者差值的一半）的帧视为语音；两者接近时（几乎没有停顿）所有非静音帧都视为语音
+func detectSpeech(levels []float64, total time.Duration, opts VADOptions) []Span {
+	if len(levels) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(levels)
+	slices.Sort(sorted)
+	floor, peak := sorted[len(sorted)*5/100], sorted[len(sorted)*95/100]
+	threshold := vadMinDB
+	if peak-floor >= vadFloorOffset {
+		threshold = max(floor+min(vadFloorOffset, (peak-floor)/2), vadMinDB)
+	}
+
+	var spans []Span
+	for i := 0; i < len(levels); {
+		if levels[i] < threshold {
+			i++
+			continue
+		}
+		j := i
+		for j < len(levels) && levels[j] >= threshold {
+			j++
+		}
+		span := Span{
+			Start: max(time.Duration(i)*vadFrame-opts.Padding, 0),
+			End:   min(time.Duration(j)*vadFrame+opts.Padding, total),
+		}
+		// 间隔短于最短静音的语音段合并
+		if n := len(spans); n > 0 && span.Start-spans[n-1].End < opts.MinSilence {
+			spans[n-1].End = span.End
+		} else {
+			spans = append(spans, span)
+		}
+		i = j
+	}
+	return spans
+}
+
+// PlanChunks 将语音区间合并为不超过 MaxChunk 的识别段
+// 段边界落在静音处，超长的连续语音在末尾窗口内最安静的帧处切分
+func PlanChunks(samples []float32, opts VADOptions) []Span {
+	total := sampleDuration(len(samples))
+	if opts.MaxChunk <= 0 || total <= opts.MaxChunk {
+		return []Span{{Start: 0, End: total}}
+	}
+	return planChunks(frameLevels(samples), total, opts)
+}
+
+// PlanWAVChunks 与 PlanChunks 相同，分块读取 WAV 文件计算能量
+func PlanWAVChunks(wav *WAVFile, opts VADOptions) ([]Span, error) {
+	total := sampleDuration(wav.Samples())
+	if opts.MaxChunk <= 0 || total <= opts.MaxChunk {
+		return []Span{{Start: 0, End: total}}, nil
+	}
+	levels, err := wavLevels(wav)
+	if err != nil {
+		return nil, err
+	}
+	return planChunks(levels, total, opts), nil
+}
+
+// planChunks 按帧能量规划识别段
+func planChunks(levels []float64, total time.Duration, opts VADOptions) []Span {
+	window := min(vadSplitWindow, opts.MaxChunk/2)
+	var chunks []Span
+	for _, speech := range detectSpeech(levels, total, opts) {
+		// 超长语音段先切开
+		for speech.Duration() > opts.MaxChunk {
+			cut := quietestFrame(levels, speech.Start+opts.MaxChunk-window, speech.Start+opts.MaxChunk)
+			chunks = appendChunk(chunks, Span{Start: speech.Start, End: cut}, opts.MaxChunk)
+			speech.Start = cut
+		}
+		chunks = appendChunk(chunks, speech, opts.MaxChunk)
+	}
+	return chunks
+}
+
+// appendChunk 语音区间能并入上一段时合并（保留中间的静音），否则新开一段
+func appendChunk(chunks []Span, speech Span, maxChunk time.Duration) []Span {
+	if n := len(chunks); n > 0 && speech.End-chunks[n-1].Start <= maxChunk {
+		chunks[n-1].End = speech.End
+		return chunks
+	}
+	return append(chunks, speech)
+}
+
+// quietestFrame 区间内能量最低的帧的起始时间（能量相同时取靠后的帧，使分段尽量长）
+func quietestFrame(levels []float64, from, to time.Duration) time.Duration {
+	first := int((from + vadFrame - 1) / vadFrame)
+	last := min(int(to/vadFrame), len(levels)-1)
+	best := last
+	for i := last; i >= first; i-- {
+		if levels[i] < levels[best] {
+			best = i
+		}
+	}
+	return time.Duration(best) * vadFrame
+}
+
+// sampleDuration 样本数对应的时长
+func sampleDuration(n int) time.Duration {
+	return time.Duration(n) * time.Second / SampleRate
+}
+
+// sampleIndex 时间对应的样本下标
+func sampleIndex(d time.Duration, n int) int {
+	return min(max(int(d*SampleRate/time.Second), 0), n)
+}
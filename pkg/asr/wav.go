@@ -0,0 +1,136 @@
+package asr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WAVFile 按需读取样本的 16位 PCM WAV 文件（长音频分段识别时不必整段载入内存）
+type WAVFile struct {
+	file       *os.File
+	dataOffset int64
+	samples    int
+}
+
+// OpenWAV 打开 16位 PCM WAV 文件并解析文件头
+// 按块解析文件头（ffmpeg 输出的 WAV 在 data 块前通常带有 LIST 块，不能固定跳过44字节）
+func OpenWAV(path string) (*WAVFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(file, header); err != nil || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		file.Close()
+		return nil, fmt.Errorf("不是有效的WAV文件")
+	}
+
+	for pos := int64(12); pos+8 <= stat.Size(); {
+		chunk := make([]byte, 8)
+		if _, err := file.ReadAt(chunk, pos); err != nil {
+			break
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		if size > stat.Size()-pos-8 {
+			size = stat.Size() - pos - 8 // 流式写入的 WAV data 块长度可能不准确
+		}
+
+		switch id {
+		case "fmt ":
+			body := make([]byte, 16)
+			if size < 16 {
+				file.Close()
+				return nil, fmt.Errorf("WAV fmt 块无效")
+			} else if _, err := file.ReadAt(body, pos+8); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("WAV fmt 块无效")
+			}
+			format := binary.LittleEndian.Uint16(body[0:2])
+			bits := binary.LittleEndian.Uint16(body[14:16])
+			if format != 1 || bits != 16 {
+				file.Close()
+				return nil, fmt.Errorf("仅支持16位PCM WAV (format=%d, bits=%d)", format, bits)
+			}
+		case "data":
+			return &WAVFile{file: file, dataOffset: pos + 8, samples: int(size / 2)}, nil
+		}
+		pos += 8 + size + size%2 // 块按偶数字节对齐
+	}
+	file.Close()
+	return nil, fmt.Errorf("WAV文件缺少 data 块")
+}
+
+// Samples 样本总数
+func (w *WAVFile) Samples() int {
+	return w.samples
+}
+
+// ReadSamples 读取 [start, end) 范围内的样本（-1.0~1.0），可并发调用
+func (w *WAVFile) ReadSamples(start, end int) ([]float32, error) {
+	start, end = min(max(start, 0), w.samples), min(max(end, 0), w.samples)
+	if start >= end {
+		return nil, nil
+	}
+	pcm := make([]byte, (end-start)*2)
+	if _, err := w.file.ReadAt(pcm, w.dataOffset+int64(start)*2); err != nil {
+		return nil, err
+	}
+
+	// 将PCM数据转换为float32样本（小端序）
+	samples := make([]float32, end-start)
+	for i := range samples {
+		samples[i] = float32(int16(binary.LittleEndian.Uint16(pcm[i*2:]))) / 32768.0
+	}
+	return samples, nil
+}
+
+// Close 关闭文件
+func (w *WAVFile) Close() error {
+	return w.file.Close()
+}
+
+// ReadWAV 读取 16位 PCM WAV 文件并返回 -1.0~1.0 的浮点样本
+func ReadWAV(path string) ([]float32, error) {
+	wav, err := OpenWAV(path)
+	if err != nil {
+		return nil, err
+	}
+	defer wav.Close()
+	return wav.ReadSamples(0, wav.Samples())
+}
+
+// WriteWAV 将 -1.0~1.0 的浮点样本写入 16kHz 单声道 16位 PCM WAV 文件
+func WriteWAV(path string, samples []float32) error {
+	const sampleRate, channels, bits = 16000, 1, 16
+	dataSize := len(samples) * 2
+
+	buf := make([]byte, 44+dataSize)
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(36+dataSize))
+	copy(buf[8:12], "WAVE")
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], 16)
+	binary.LittleEndian.PutUint16(buf[20:22], 1)
+	binary.LittleEndian.PutUint16(buf[22:24], channels)
+	binary.LittleEndian.PutUint32(buf[24:28], sampleRate)
+	binary.LittleEndian.PutUint32(buf[28:32], sampleRate*channels*bits/8)
+	binary.LittleEndian.PutUint16(buf[32:34], channels*bits/8)
+	binary.LittleEndian.PutUint16(buf[34:36], bits)
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(dataSize))
+
+	for i, s := range samples {
+		v := max(-1, min(1, s)) * 32767
+		binary.LittleEndian.PutUint16(buf[44+i*2:], uint16(int16(v)))
+	}
+	return os.WriteFile(path, buf, 0644)
+}
@@ -0,0 +1,198 @@
+//go:build whisper
+
+package asr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
+	whisper "github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
+)
+
+// WhisperCppTranscriber 进程内 whisper.cpp 识别器（cgo 绑定）
+// 模型在创建时加载一次，各次识别共享；识别结果保存在模型状态中，因此识别过程串行执行
+type WhisperCppTranscriber struct {
+	modelPath string
+	threads   int
+	model     whisper.Model
+	mu        sync.Mutex
+}
+
+// NewWhisperCppTranscriber 创建进程内 whisper.cpp 识别器并加载模型，使用完毕后需调用 Close 释放内存
+func NewWhisperCppTranscriber(modelPath string, threads int) (*WhisperCppTranscriber, error) {
+	if modelPath == "" {
+		return nil, fmt.Errorf("whisper model path is required")
+	}
+	if threads <= 0 {
+		threads = 4 // 默认使用4个线程
+	}
+	if _, err := os.Stat(modelPath); err != nil {
+		return nil, fmt.Errorf("Whisper 模型文件不存在: %s", modelPath)
+	}
+
+	model, err := whisper.New(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载模型失败: %v", err)
+	}
+
+	return &WhisperCppTranscriber{
+		modelPath: modelPath,
+		threads:   threads,
+		model:     model,
+	}, nil
+}
+
+// Close 释放模型
+func (w *WhisperCppTranscriber) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.model == nil {
+		return nil
+	}
+	err := w.model.Close()
+	w.model = nil
+	return err
+}
+
+// Transcribe 转录音频文件（每次识别创建新的处理上下文）
+func (w *WhisperCppTranscriber) Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	samples, err := ReadWAV(req.AudioPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取WAV文件失败: %v", err)
+	}
+
+	// 等待其他识别完成（同一模型不能同时处理多段音频）
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.model == nil {
+		return nil, fmt.Errorf("whisper 模型已释放")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 创建处理上下文
+	wctx, err := w.model.NewContext()
+	if err != nil {
+		return nil, fmt.Errorf("创建上下文失败: %v", err)
+	}
+
+	language := req.Language
+	if language == "" {
+		language = "auto"
+	}
+	if err := wctx.SetLanguage(language); err != nil {
+		return nil, fmt.Errorf("设置语言失败: %v", err)
+	}
+	threads := w.threads
+	if req.Threads > 0 {
+		threads = req.Threads
+	}
+	wctx.SetThreads(uint(threads))
+	wctx.SetTranslate(false)
+	wctx.SetTokenTimestamps(true)
+	if req.Prompt != "" {
+		wctx.SetInitialPrompt(req.Prompt)
+	}
+
+	// 编码器开始前检查任务是否已取消
+	if err := wctx.Process(samples, func() bool { return ctx.Err() == nil }, nil, nil); err != nil {
+		return nil, fmt.Errorf("处理音频失败: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 收集所有片段
+	result := &TranscriptionResult{
+		Language: wctx.DetectedLanguage(),
+		Duration: time.Duration(len(samples)) * time.Second / whisper.SampleRate,
+		Provider: ProviderWhisperCpp,
+		Model:    strings.TrimSuffix(filepath.Base(w.modelPath), filepath.Ext(w.modelPath)),
+	}
+	if result.Language == "" {
+		result.Language = language
+	}
+	for {
+		segment, err := wctx.NextSegment()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取识别结果失败: %v", err)
+		}
+		result.Segments = append(result.Segments, Segment{
+			Start: segment.Start,
+			End:   segment.End,
+			Text:  strings.TrimSpace(segment.Text),
+			Words: tokenWords(wctx, segment.Tokens),
+		})
+	}
+
+	return result, nil
+}
+
+// tokenWords 将文本 token 合并为词: 以空格开头的 token 开始新词，中日韩文字每个 token 单独成词
+// 多字节文字可能被拆分到多个 token 中，未组成完整 UTF-8 字符前继续合并
+func tokenWords(wctx whisper.Context, tokens []whisper.Token) []Word {
+	var words []Word
+	for _, token := range tokens {
+		if !wctx.IsText(token) || token.Text == "" {
+			continue
+		}
+		n := len(words)
+		first, _ := utf8.DecodeRuneInString(strings.TrimSpace(token.Text))
+		if n > 0 && !utf8.ValidString(words[n-1].Text) {
+			words[n-1].Text += token.Text
+			words[n-1].End = token.End
+			continue
+		}
+		if n == 0 || strings.HasPrefix(token.Text, " ") || subtitle.IsCJK(first) || isCJKWord(words[n-1].Text) {
+			words = append(words, Word{Start: token.Start, End: token.End, Text: token.Text})
+			continue
+		}
+		words[n-1].Text += token.Text
+		words[n-1].End = token.End
+	}
+
+	out := words[:0]
+	for _, w := range words {
+		if w.Text = strings.TrimSpace(w.Text); w.Text != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// isCJKWord 词是否以中日韩文字结尾
+func isCJKWord(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(text))
+	return subtitle.IsCJK(r)
+}
+
+// GetInfo 获取识别器信息
+func (w *WhisperCppTranscriber) GetInfo() *TranscriberInfo {
+	return &TranscriberInfo{
+		Name:     "whisper.cpp",
+		Provider: ProviderWhisperCpp,
+		Model:    filepath.Base(w.modelPath),
+		Features: []string{"language_detection", "prompt", "word_timestamps"},
+		IsOnline: false,
+	}
+}
+
+// IsHealthy 检查模型是否已加载
+func (w *WhisperCppTranscriber) IsHealthy(ctx context.Context) error {
+	if w.model == nil {
+		return fmt.Errorf("whisper 模型未加载: %s", w.modelPath)
+	}
+	return nil
+}
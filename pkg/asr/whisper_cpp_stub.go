@@ -0,0 +1,11 @@
+//go:build !whisper
+
+package asr
+
+import "fmt"
+
+// NewWhisperCppTranscriber 未启用 whisper 构建标签时不包含 whisper.cpp（cgo）绑定，
+// 需要进程内识别时使用 `go build -tags whisper` 构建，否则请配置 whisper.cpp server 或 OpenAI 兼容服务
+func NewWhisperCppTranscriber(modelPath string, threads int) (Transcriber, error) {
+	return nil, fmt.Errorf("当前构建未包含 whisper.cpp，请使用 -tags whisper 重新构建或改用 %s / %s", ProviderWhisperServer, ProviderOpenAI)
+}
@@ -0,0 +1,77 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/internal/core/types"
+)
+
+// WhisperServerTranscriber whisper.cpp server（examples/server）识别器
+// 接口: POST {endpoint}/inference，multipart 表单上传音频
+type WhisperServerTranscriber struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewWhisperServerTranscriber 创建 whisper.cpp server 识别器
+func NewWhisperServerTranscriber(config *types.AsrServerConfig) (*WhisperServerTranscriber, error) {
+	if config == nil || config.Endpoint == "" {
+		return nil, fmt.Errorf("whisper server endpoint is required")
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 1800 // 长音频转录耗时较长，默认30分钟
+	}
+
+	return &WhisperServerTranscriber{
+		endpoint: strings.TrimRight(config.Endpoint, "/"),
+		apiKey:   config.ApiKey,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Transcribe 转录音频文件
+func (w *WhisperServerTranscriber) Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResult, error) {
+	language := req.Language
+	if language == "" {
+		language = "auto"
+	}
+
+	resp, err := postAudio(ctx, w.client, w.endpoint+"/inference", w.apiKey, [][2]string{
+		{"response_format", "verbose_json"},
+		{"temperature", "0.0"},
+		{"language", language},
+		{"prompt", req.Prompt},
+	}, req.AudioPath)
+	if err != nil {
+		return nil, fmt.Errorf("whisper server 转录失败: %v", err)
+	}
+
+	return resp.toResult(ProviderWhisperServer, "", language), nil
+}
+
+// GetInfo 获取识别器信息
+func (w *WhisperServerTranscriber) GetInfo() *TranscriberInfo {
+	return &TranscriberInfo{
+		Name:     "whisper.cpp server",
+		Provider: ProviderWhisperServer,
+		Features: []string{"language_detection", "prompt", "word_timestamps"},
+		IsOnline: true,
+	}
+}
+
+// IsHealthy 健康检查
+func (w *WhisperServerTranscriber) IsHealthy(ctx context.Context) error {
+	if err := checkHealth(ctx, w.client, w.endpoint+"/", w.apiKey); err != nil {
+		return fmt.Errorf("whisper server 不可用: %v", err)
+	}
+	return nil
+}
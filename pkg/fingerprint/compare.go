@@ -0,0 +1,98 @@
+package fingerprint
+
+import "math/bits"
+
+const (
+	// 音频对齐时允许的最大偏移（窗口数），覆盖片头被裁剪/增加约 10 秒的情况
+	maxAudioShift = 40
+
+	// 计算音频相似度时要求的最少重叠位数
+	minAudioOverlap = 20
+
+	// 帧与音频相似度的权重
+	frameWeight = 0.6
+	audioWeight = 0.4
+)
+
+// Match 两个指纹的比较结果
+type Match struct {
+	FrameScore float64 // 抽样帧相似度（0-1）
+	AudioScore float64 // 音频相似度（0-1），-1 表示无法比较
+	Score      float64 // 综合相似度（0-1）
+}
+
+// Compare 比较两个指纹
+func Compare(a, b *Fingerprint) Match {
+	m := Match{
+		FrameScore: FrameSimilarity(a.FrameHashes, b.FrameHashes),
+		AudioScore: AudioSimilarity(a.AudioBits, a.AudioLength, b.AudioBits, b.AudioLength),
+	}
+	if m.AudioScore < 0 {
+		m.Score = m.FrameScore
+	} else {
+		m.Score = m.FrameScore*frameWeight + m.AudioScore*audioWeight
+	}
+	return m
+}
+
+// IsDuplicate 综合相似度达到阈值（含）时视为重复
+func (m Match) IsDuplicate(threshold float64) bool {
+	return m.Score >= threshold
+}
+
+// FrameSimilarity 按时间顺序逐帧比较 dHash，返回平均相似度
+func FrameSimilarity(a, b []uint64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 0
+	}
+
+	var total float64
+	for i := 0; i < n; i++ {
+		total += 1 - float64(bits.OnesCount64(a[i]^b[i]))/64
+	}
+	return total / float64(n)
+}
+
+// AudioSimilarity 在允许的偏移范围内对齐两个音频指纹，返回最高的位一致率
+// 任一指纹为空时返回 -1
+func AudioSimilarity(a []byte, aLen int, b []byte, bLen int) float64 {
+	if aLen < minAudioOverlap || bLen < minAudioOverlap {
+		return -1
+	}
+
+	best := 0.0
+	for shift := -maxAudioShift; shift <= maxAudioShift; shift++ {
+		// a 的第 i 位与 b 的第 i+shift 位比较
+		start := 0
+		if shift < 0 {
+			start = -shift
+		}
+		end := aLen
+		if bLen-shift < end {
+			end = bLen - shift
+		}
+		overlap := end - start
+		if overlap < minAudioOverlap {
+			continue
+		}
+
+		same := 0
+		for i := start; i < end; i++ {
+			if bitAt(a, i) == bitAt(b, i+shift) {
+				same++
+			}
+		}
+		if score := float64(same) / float64(overlap); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func bitAt(data []byte, i int) byte {
+	return (data[i/8] >> uint(7-i%8)) & 1
+}
@@ -0,0 +1,217 @@
+package fingerprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/difyz9/ytb2bili/pkg/media"
+)
+
+const (
+	// dHash 使用 9x8 灰度图，每行相邻像素比较得到 8 位，共 64 位
+	hashWidth  = 9
+	hashHeight = 8
+
+	// 音频指纹采样参数：8kHz 单声道，每 250ms 一个能量窗口
+	audioSampleRate = 8000
+	audioWindow     = audioSampleRate / 4
+)
+
+// Options 指纹计算参数
+type Options struct {
+	FrameSamples int // 抽样帧数
+	AudioSeconds int // 参与音频指纹计算的最大时长（秒），0 表示整段音频
+}
+
+// Fingerprint 视频指纹：时长 + 抽样帧感知哈希 + 音频能量指纹
+type Fingerprint struct {
+	Duration    float64  // 时长（秒）
+	FrameHashes []uint64 // 按时间顺序的抽样帧 dHash
+	AudioBits   []byte   // 相邻能量窗口的升降位序列（按位打包）
+	AudioLength int      // 有效位数
+}
+
+// Compute 使用 ffmpeg/ffprobe 计算视频文件的指纹
+func Compute(ctx context.Context, videoPath string, opts Options) (*Fingerprint, error) {
+	if opts.FrameSamples <= 0 {
+		opts.FrameSamples = 16
+	}
+
+	duration, err := media.ProbeDuration(ctx, videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频时长失败: %w", err)
+	}
+
+	fp := &Fingerprint{Duration: duration}
+
+	// 1. 均匀抽样帧（避开首尾，减少片头片尾黑屏的影响）
+	for i := 1; i <= opts.FrameSamples; i++ {
+		at := duration * float64(i) / float64(opts.FrameSamples+1)
+		hash, err := frameHash(ctx, videoPath, at)
+		if err != nil {
+			return nil, fmt.Errorf("计算第 %d 帧哈希失败: %w", i, err)
+		}
+		fp.FrameHashes = append(fp.FrameHashes, hash)
+	}
+
+	// 2. 音频指纹（没有音轨时留空）
+	bits, length, err := audioFingerprint(ctx, videoPath, opts.AudioSeconds)
+	if err == nil {
+		fp.AudioBits = bits
+		fp.AudioLength = length
+	}
+
+	return fp, nil
+}
+
+// frameHash 截取指定时间点的帧并计算 dHash
+func frameHash(ctx context.Context, videoPath string, at float64) (uint64, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d:flags=area,format=gray", hashWidth, hashHeight),
+		"-f", "rawvideo",
+		"-",
+	)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return 0, err
+	}
+	return DHash(stdout.Bytes())
+}
+
+// DHash 根据 9x8 灰度像素计算差值哈希
+func DHash(pixels []byte) (uint64, error) {
+	if len(pixels) < hashWidth*hashHeight {
+		return 0, fmt.Errorf("像素数据不足: %d", len(pixels))
+	}
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		row := pixels[y*hashWidth : (y+1)*hashWidth]
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if row[x] > row[x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash, nil
+}
+
+// audioFingerprint 解码音频并计算能量升降位序列
+// 对音量、码率、编码格式的变化不敏感，适合识别重新上传/搬运的同一音轨
+func audioFingerprint(ctx context.Context, videoPath string, maxSeconds int) ([]byte, int, error) {
+	args := []string{"-v", "error", "-i", videoPath}
+	if maxSeconds > 0 {
+		args = append(args, "-t", strconv.Itoa(maxSeconds))
+	}
+	args = append(args, "-vn", "-ac", "1", "-ar", strconv.Itoa(audioSampleRate), "-f", "s16le", "-")
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, 0, err
+	}
+
+	bits, length := EnergyBits(stdout.Bytes())
+	if length == 0 {
+		return nil, 0, fmt.Errorf("没有可用的音频数据")
+	}
+	return bits, length, nil
+}
+
+// EnergyBits 将 16 位 PCM 数据按窗口计算能量，相邻窗口能量上升记为 1
+func EnergyBits(pcm []byte) ([]byte, int) {
+	samples := len(pcm) / 2
+	windows := samples / audioWindow
+	if windows < 2 {
+		return nil, 0
+	}
+
+	energies := make([]float64, windows)
+	for w := 0; w < windows; w++ {
+		var sum float64
+		for i := 0; i < audioWindow; i++ {
+			offset := (w*audioWindow + i) * 2
+			sample := float64(int16(uint16(pcm[offset]) | uint16(pcm[offset+1])<<8))
+			sum += sample * sample
+		}
+		energies[w] = math.Sqrt(sum / audioWindow)
+	}
+
+	length := windows - 1
+	bits := make([]byte, (length+7)/8)
+	for i := 0; i < length; i++ {
+		if energies[i+1] > energies[i] {
+			bits[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return bits, length
+}
+
+// EncodeFrameHashes 将帧哈希编码为逗号分隔的十六进制字符串（用于数据库存储）
+func EncodeFrameHashes(hashes []uint64) string {
+	parts := make([]string, len(hashes))
+	for i, h := range hashes {
+		parts[i] = fmt.Sprintf("%016x", h)
+	}
+	return strings.Join(parts, ",")
+}
+
+// DecodeFrameHashes 解析 EncodeFrameHashes 的结果
+func DecodeFrameHashes(s string) ([]uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	hashes := make([]uint64, 0, len(parts))
+	for _, p := range parts {
+		h, err := strconv.ParseUint(p, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的帧哈希: %s", p)
+		}
+		hashes = append(hashes, h)
+	}
+	return hashes, nil
+}
+
+// EncodeAudio 将音频指纹编码为 "位数:十六进制" 字符串
+func EncodeAudio(bits []byte, length int) string {
+	if length == 0 {
+		return ""
+	}
+	return strconv.Itoa(length) + ":" + hex.EncodeToString(bits)
+}
+
+// DecodeAudio 解析 EncodeAudio 的结果
+func DecodeAudio(s string) ([]byte, int, error) {
+	if s == "" {
+		return nil, 0, nil
+	}
+	lengthStr, hexStr, found := strings.Cut(s, ":")
+	if !found {
+		return nil, 0, fmt.Errorf("无效的音频指纹")
+	}
+	length, err := strconv.Atoi(lengthStr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("无效的音频指纹长度: %s", lengthStr)
+	}
+	bits, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(bits)*8 < length {
+		return nil, 0, fmt.Errorf("音频指纹数据不完整")
+	}
+	return bits, length, nil
+}
@@ -0,0 +1,200 @@
+package fingerprint
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// gradient 生成 9x8 灰度像素，每行按 step 递增（step < 0 时递减）
+func gradient(step int) []byte {
+	pixels := make([]byte, hashWidth*hashHeight)
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth; x++ {
+			pixels[y*hashWidth+x] = byte(128 + step*x)
+		}
+	}
+	return pixels
+}
+
+func TestDHash(t *testing.T) {
+	tests := []struct {
+		name   string
+		pixels []byte
+		want   uint64
+	}{
+		{"递增", gradient(10), 0},
+		{"递减", gradient(-10), math.MaxUint64},
+		{"平坦", make([]byte, hashWidth*hashHeight), 0},
+		{"只有首行递减", append(gradient(-10)[:hashWidth], gradient(10)[hashWidth:]...), 0xff << 56},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DHash(tt.pixels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DHash = %016x, want %016x", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := DHash(make([]byte, hashWidth*hashHeight-1)); err == nil {
+		t.Error("DHash with short input should fail")
+	}
+}
+
+func TestFrameSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []uint64
+		want float64
+	}{
+		{"相同", []uint64{0x1234, 0xffff}, []uint64{0x1234, 0xffff}, 1},
+		{"全部不同", []uint64{0}, []uint64{math.MaxUint64}, 0},
+		{"汉明距离 16", []uint64{0}, []uint64{0xffff}, 0.75},
+		{"逐帧平均", []uint64{0, 0}, []uint64{0, 0xffffffff}, 0.75},
+		{"按较短的一方比较", []uint64{0, 0, 0}, []uint64{0}, 1},
+		{"空", nil, []uint64{0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FrameSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("FrameSimilarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// pcm 生成每个能量窗口内为恒定幅度的 16 位 PCM
+func pcm(levels ...int16) []byte {
+	data := make([]byte, 0, len(levels)*audioWindow*2)
+	for _, level := range levels {
+		for i := 0; i < audioWindow; i++ {
+			data = binary.LittleEndian.AppendUint16(data, uint16(level))
+		}
+	}
+	return data
+}
+
+func TestEnergyBits(t *testing.T) {
+	bits, length := EnergyBits(pcm(100, 200, 150, -300, 300, 400))
+	// 能量: 100 200 150 300 300 400 -> 升 降 升 平 升
+	if length != 5 || len(bits) != 1 || bits[0] != 0b10101000 {
+		t.Errorf("EnergyBits = %08b (%d), want 10101000 (5)", bits, length)
+	}
+
+	if _, length := EnergyBits(pcm(100)); length != 0 {
+		t.Errorf("EnergyBits with one window = %d bits, want 0", length)
+	}
+}
+
+// pattern 生成伪随机位序列（按位打包）
+func pattern(n int, seed uint32) []byte {
+	bits := make([]byte, (n+7)/8)
+	for i := 0; i < n; i++ {
+		seed = seed*1103515245 + 12345
+		if seed>>16&1 == 1 {
+			bits[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return bits
+}
+
+// shiftBits 去掉前 n 位（模拟片头被裁剪）
+func shiftBits(bits []byte, length, n int) ([]byte, int) {
+	out := make([]byte, (length-n+7)/8)
+	for i := 0; i < length-n; i++ {
+		out[i/8] |= bitAt(bits, i+n) << uint(7-i%8)
+	}
+	return out, length - n
+}
+
+func TestAudioSimilarity(t *testing.T) {
+	a := pattern(200, 1)
+	trimmed, trimmedLen := shiftBits(a, 200, 25)
+	tooFar, tooFarLen := shiftBits(a, 200, maxAudioShift+10)
+
+	tests := []struct {
+		name string
+		b    []byte
+		bLen int
+		min  float64
+		max  float64
+	}{
+		{"相同", a, 200, 1, 1},
+		{"允许范围内的偏移", trimmed, trimmedLen, 1, 1},
+		{"超出偏移范围", tooFar, tooFarLen, 0, 0.8},
+		{"不同音频", pattern(200, 2), 200, 0, 0.8},
+		{"重叠不足", a, minAudioOverlap - 1, -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AudioSimilarity(a, 200, tt.b, tt.bLen)
+			if got < tt.min || got > tt.max {
+				t.Errorf("AudioSimilarity = %v, want [%v, %v]", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	audio := pattern(100, 1)
+	a := &Fingerprint{FrameHashes: []uint64{0, 0}, AudioBits: audio, AudioLength: 100}
+
+	// 帧相似度 0.75，音频完全一致: 0.75*0.6 + 1*0.4
+	m := Compare(a, &Fingerprint{FrameHashes: []uint64{0, 0xffffffff}, AudioBits: audio, AudioLength: 100})
+	if math.Abs(m.Score-0.85) > 1e-9 {
+		t.Errorf("Score = %v, want 0.85", m.Score)
+	}
+
+	// 没有音频时只看帧相似度
+	m = Compare(a, &Fingerprint{FrameHashes: []uint64{0, 0xffffffff}})
+	if m.AudioScore != -1 || math.Abs(m.Score-0.75) > 1e-9 {
+		t.Errorf("Match = %+v, want frame score only", m)
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	tests := []struct {
+		score     float64
+		threshold float64
+		want      bool
+	}{
+		{0.85, 0.85, true},
+		{0.8499, 0.85, false},
+		{1, 0.85, true},
+		{0, 0, true},
+		{0.99, 1, false},
+	}
+	for _, tt := range tests {
+		if got := (Match{Score: tt.score}).IsDuplicate(tt.threshold); got != tt.want {
+			t.Errorf("IsDuplicate(%v, %v) = %v, want %v", tt.score, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	hashes := []uint64{0, 0x0123456789abcdef, math.MaxUint64}
+	decoded, err := DecodeFrameHashes(EncodeFrameHashes(hashes))
+	if err != nil || len(decoded) != len(hashes) {
+		t.Fatalf("DecodeFrameHashes = %v, %v", decoded, err)
+	}
+	for i := range hashes {
+		if decoded[i] != hashes[i] {
+			t.Errorf("hash %d = %x, want %x", i, decoded[i], hashes[i])
+		}
+	}
+	if _, err := DecodeFrameHashes("xyz"); err == nil {
+		t.Error("DecodeFrameHashes should reject invalid hex")
+	}
+
+	bits, length, err := DecodeAudio(EncodeAudio([]byte{0xa5, 0x80}, 9))
+	if err != nil || length != 9 || bits[0] != 0xa5 || bits[1] != 0x80 {
+		t.Errorf("DecodeAudio = %x, %d, %v", bits, length, err)
+	}
+	if _, _, err := DecodeAudio("nocolon"); err == nil {
+		t.Error("DecodeAudio should reject input without length")
+	}
+}
@@ -0,0 +1,149 @@
+package lang
+
+import "strings"
+
+// names Whisper/yt-dlp 使用的语言名称与语言代码
+var names = map[string]string{
+	"english":    "en",
+	"chinese":    "zh",
+	"mandarin":   "zh",
+	"japanese":   "ja",
+	"korean":     "ko",
+	"german":     "de",
+	"french":     "fr",
+	"spanish":    "es",
+	"portuguese": "pt",
+	"russian":    "ru",
+	"italian":    "it",
+	"dutch":      "nl",
+	"arabic":     "ar",
+	"hindi":      "hi",
+	"turkish":    "tr",
+	"vietnamese": "vi",
+	"thai":       "th",
+	"indonesian": "id",
+	"polish":     "pl",
+	"ukrainian":  "uk",
+	"cantonese":  "yue",
+}
+
+// displayNames 语言的中文名称（用于翻译提示词和字幕轨名称）
+var displayNames = map[string]string{
+	"en":      "英语",
+	"zh":      "中文",
+	"zh-Hans": "简体中文",
+	"zh-Hant": "繁体中文",
+	"ja":      "日语",
+	"ko":      "韩语",
+	"de":      "德语",
+	"fr":      "法语",
+	"es":      "西班牙语",
+	"pt":      "葡萄牙语",
+	"ru":      "俄语",
+	"it":      "意大利语",
+	"nl":      "荷兰语",
+	"ar":      "阿拉伯语",
+	"hi":      "印地语",
+	"tr":      "土耳其语",
+	"vi":      "越南语",
+	"th":      "泰语",
+	"id":      "印尼语",
+	"pl":      "波兰语",
+	"uk":      "乌克兰语",
+	"yue":     "粤语",
+}
+
+// Normalize 规范化语言代码：en_US -> en-US，english -> en，zh-CN -> zh-Hans，zh-TW -> zh-Hant
+// 无法识别时返回空字符串，auto 原样返回
+func Normalize(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	lower := strings.ToLower(code)
+	if lower == "auto" {
+		return "auto"
+	}
+	if c, ok := names[lower]; ok {
+		return c
+	}
+
+	parts := strings.Split(strings.ReplaceAll(code, "_", "-"), "-")
+	base := strings.ToLower(parts[0])
+	if len(base) < 2 || len(base) > 3 {
+		return ""
+	}
+	for _, r := range base {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+
+	// 中文按书写系统区分简繁
+	if base == "zh" && len(parts) > 1 {
+		for _, p := range parts[1:] {
+			switch strings.ToLower(p) {
+			case "hans", "cn", "sg", "my":
+				return "zh-Hans"
+			case "hant", "tw", "hk", "mo":
+				return "zh-Hant"
+			}
+		}
+		return "zh"
+	}
+
+	// 其余子标签: 4 位为书写系统（首字母大写），2 位或 3 位数字为地区（大写）
+	result := []string{base}
+	for _, p := range parts[1:] {
+		switch {
+		case len(p) == 4:
+			result = append(result, strings.ToUpper(p[:1])+strings.ToLower(p[1:]))
+		case len(p) == 2 || len(p) == 3:
+			result = append(result, strings.ToUpper(p))
+		case p != "":
+			result = append(result, p)
+		}
+	}
+	return strings.Join(result, "-")
+}
+
+// Base 主语言子标签（en-US -> en）
+func Base(code string) string {
+	code = Normalize(code)
+	if i := strings.Index(code, "-"); i > 0 {
+		return code[:i]
+	}
+	return code
+}
+
+// IsChinese 是否为中文（简体、繁体或未区分）
+func IsChinese(code string) bool {
+	return Base(code) == "zh"
+}
+
+// Name 语言的中文名称，未知语言返回语言代码
+func Name(code string) string {
+	code = Normalize(code)
+	if name, ok := displayNames[code]; ok {
+		return name
+	}
+	if name, ok := displayNames[Base(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// BilibiliCode B站字幕语言代码（lan 参数）：中文区分简繁，其他语言使用主语言代码，无法识别或 auto 时返回空字符串
+func BilibiliCode(code string) string {
+	code = Normalize(code)
+	switch {
+	case code == "auto":
+		return ""
+	case code == "zh-Hant":
+		return "zh-Hant"
+	case IsChinese(code):
+		return "zh-Hans"
+	default:
+		return Base(code)
+	}
+}
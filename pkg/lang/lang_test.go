@@ -0,0 +1,112 @@
+package lang
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en", "en"},
+		{" EN ", "en"},
+		{"en_US", "en-US"},
+		{"en-us", "en-US"},
+		{"english", "en"},
+		{"English", "en"},
+		{"es-419", "es-419"},
+		{"zh", "zh"},
+		{"zh-CN", "zh-Hans"},
+		{"zh_cn", "zh-Hans"},
+		{"zh-Hans", "zh-Hans"},
+		{"zh-Hans-CN", "zh-Hans"},
+		{"zh-SG", "zh-Hans"},
+		{"zh-TW", "zh-Hant"},
+		{"zh-HK", "zh-Hant"},
+		{"zh-Hant-TW", "zh-Hant"},
+		{"chinese", "zh"},
+		{"yue", "yue"},
+		{"cantonese", "yue"},
+		{"sr-latn-rs", "sr-Latn-RS"},
+		{"auto", "auto"},
+		{"AUTO", "auto"},
+		{"", ""},
+		{"e", ""},
+		{"klingon", ""},
+		{"12", ""},
+		{"中文", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.code); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestBilibiliCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"zh", "zh-Hans"},
+		{"zh-CN", "zh-Hans"},
+		{"zh-Hans", "zh-Hans"},
+		{"zh-TW", "zh-Hant"},
+		{"zh-HK", "zh-Hant"},
+		{"en_US", "en"},
+		{"english", "en"},
+		{"es-419", "es"},
+		{"ja-JP", "ja"},
+		{"yue", "yue"},
+		{"auto", ""},
+		{"", ""},
+		{"klingon", ""},
+	}
+	for _, tt := range tests {
+		if got := BilibiliCode(tt.code); got != tt.want {
+			t.Errorf("BilibiliCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestIsChinese(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"zh", true},
+		{"zh-CN", true},
+		{"zh-TW", true},
+		{"zh-Hant", true},
+		{"chinese", true},
+		{"yue", false}, // 粤语单独处理，不按中文字幕
+		{"en", false},
+		{"auto", false},
+		{"", false},
+		{"klingon", false},
+	}
+	for _, tt := range tests {
+		if got := IsChinese(tt.code); got != tt.want {
+			t.Errorf("IsChinese(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"zh-CN", "简体中文"},
+		{"zh-HK", "繁体中文"},
+		{"zh", "中文"},
+		{"en_US", "英语"},
+		{"es-419", "西班牙语"},
+		{"yue", "粤语"},
+		{"sw", "sw"}, // 未知语言返回语言代码
+	}
+	for _, tt := range tests {
+		if got := Name(tt.code); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
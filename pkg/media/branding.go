@@ -0,0 +1,257 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 水印位置
+const (
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+)
+
+// BrandingOptions 片头片尾拼接与水印参数
+type BrandingOptions struct {
+	Intro          string  // 片头视频路径，为空时不拼接
+	Outro          string  // 片尾视频路径，为空时不拼接
+	Watermark      string  // 水印 PNG 路径，为空时不叠加
+	Position       string  // 水印位置，默认 top-right
+	Opacity        float64 // 水印不透明度（0-1），默认 0.8
+	Margin         int     // 水印与边缘的距离（像素），默认 20
+	WatermarkScale float64 // 水印宽度占画面宽度的比例，默认 0.12
+	Width          int     // 输出宽度，0 表示与正片一致
+	Height         int     // 输出高度，0 表示与正片一致
+	FPS            float64 // 输出帧率，0 表示与正片一致
+	Preset         string  // x264 编码预设，默认 medium
+	CRF            int     // 质量参数，默认 20
+}
+
+// BrandingResult 处理结果，字幕需要按 IntroDuration 平移
+// 视频替换后立即保存（Pending 为 true），每平移一个字幕文件记录一次，全部完成后清除 Pending，
+// 中断后重试时不会重复拼接视频，也不会重复平移字幕
+type BrandingResult struct {
+	IntroDuration float64  `json:"intro_duration"`
+	OutroDuration float64  `json:"outro_duration"`
+	Watermark     bool     `json:"watermark"`
+	Pending       bool     `json:"pending,omitempty"`           // 视频已替换，字幕尚未全部平移
+	Shifted       []string `json:"shifted_subtitles,omitempty"` // 已平移的字幕文件名
+}
+
+// SubtitleOffset 字幕需要后移的时长（片头时长，精确到毫秒）
+func (r *BrandingResult) SubtitleOffset() time.Duration {
+	if r.IntroDuration <= 0 {
+		return 0
+	}
+	return time.Duration(math.Round(r.IntroDuration*1000)) * time.Millisecond
+}
+
+// NeedsShift 字幕文件是否还需要平移（每个文件只平移一次）
+func (r *BrandingResult) NeedsShift(name string) bool {
+	return r.Pending && r.SubtitleOffset() > 0 && !slices.Contains(r.Shifted, name)
+}
+
+// MarkShifted 记录字幕文件已平移
+func (r *BrandingResult) MarkShifted(name string) {
+	if !slices.Contains(r.Shifted, name) {
+		r.Shifted = append(r.Shifted, name)
+	}
+}
+
+// ApplyBranding 拼接片头片尾并在正片上叠加水印
+// 所有片段统一缩放（等比缩放后补黑边）到相同分辨率和帧率，没有音轨的片段补静音
+func ApplyBranding(ctx context.Context, input, output string, opts BrandingOptions) (*BrandingResult, error) {
+	mainInfo, err := Probe(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("读取正片信息失败: %w", err)
+	}
+	mainVideo := mainInfo.VideoStream()
+	if mainVideo == nil {
+		return nil, fmt.Errorf("正片没有视频流")
+	}
+
+	// 目标分辨率与帧率默认跟随正片
+	width, height, fps := opts.Width, opts.Height, opts.FPS
+	if width <= 0 || height <= 0 {
+		width, height = mainVideo.Width, mainVideo.Height
+	}
+	width, height = width/2*2, height/2*2
+	if fps <= 0 {
+		fps = mainVideo.FrameRate
+	}
+	if fps <= 0 {
+		fps = 30
+	}
+	applyBrandingDefaults(&opts)
+
+	type clip struct {
+		path     string
+		duration float64
+		hasAudio bool
+		main     bool
+	}
+	var clips []clip
+	result := &BrandingResult{}
+
+	addClip := func(path string, main bool) error {
+		info := mainInfo
+		if !main {
+			if info, err = Probe(ctx, path); err != nil {
+				return fmt.Errorf("读取 %s 失败: %w", path, err)
+			}
+			if info.VideoStream() == nil {
+				return fmt.Errorf("%s 没有视频流", path)
+			}
+		}
+		clips = append(clips, clip{path: path, duration: info.Duration, hasAudio: info.AudioStream() != nil, main: main})
+		return nil
+	}
+	if opts.Intro != "" {
+		if err := addClip(opts.Intro, false); err != nil {
+			return nil, err
+		}
+		result.IntroDuration = clips[0].duration
+	}
+	if err := addClip(input, true); err != nil {
+		return nil, err
+	}
+	if opts.Outro != "" {
+		if err := addClip(opts.Outro, false); err != nil {
+			return nil, err
+		}
+		result.OutroDuration = clips[len(clips)-1].duration
+	}
+
+	// 构建 filter_complex
+	var args []string
+	var filters []string
+	var concatInputs string
+	fpsStr := strconv.FormatFloat(fps, 'f', 3, 64)
+	for i, c := range clips {
+		args = append(args, "-i", c.path)
+
+		video := fmt.Sprintf("[%d:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%s,format=yuv420p",
+			i, width, height, width, height, fpsStr)
+		if c.main && opts.Watermark != "" {
+			filters = append(filters, video+"[main]")
+		} else {
+			filters = append(filters, fmt.Sprintf("%s[v%d]", video, i))
+		}
+
+		if c.hasAudio {
+			filters = append(filters, fmt.Sprintf("[%d:a]aresample=48000,aformat=sample_fmts=fltp:channel_layouts=stereo[a%d]", i, i))
+		} else {
+			filters = append(filters, fmt.Sprintf("anullsrc=r=48000:cl=stereo,atrim=duration=%.3f[a%d]", c.duration, i))
+		}
+		concatInputs += fmt.Sprintf("[v%d][a%d]", i, i)
+	}
+
+	if opts.Watermark != "" {
+		wmIndex := len(clips)
+		mainIndex := 0
+		for i, c := range clips {
+			if c.main {
+				mainIndex = i
+			}
+		}
+		args = append(args, "-i", opts.Watermark)
+		wmWidth := int(float64(width)*opts.WatermarkScale) / 2 * 2
+		filters = append(filters,
+			fmt.Sprintf("[%d:v]format=rgba,colorchannelmixer=aa=%.2f,scale=%d:-1[wm]", wmIndex, opts.Opacity, wmWidth),
+			fmt.Sprintf("[main][wm]overlay=%s:format=auto,format=yuv420p[v%d]", overlayPosition(opts.Position, opts.Margin), mainIndex),
+		)
+		result.Watermark = true
+	}
+
+	if len(clips) > 1 {
+		filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=1:a=1[outv][outa]", concatInputs, len(clips)))
+	} else {
+		filters = append(filters, "[v0]null[outv]", "[a0]anull[outa]")
+	}
+
+	args = append([]string{"-y", "-v", "error"}, args...)
+	args = append(args,
+		"-filter_complex", strings.Join(filters, ";"),
+		"-map", "[outv]", "-map", "[outa]",
+		"-c:v", "libx264", "-preset", opts.Preset, "-crf", strconv.Itoa(opts.CRF),
+		"-c:a", "aac", "-b:a", "192k",
+		"-movflags", "+faststart",
+		output,
+	)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg 处理失败: %v: %s", err, firstLines(stderr.String(), 3))
+	}
+	return result, nil
+}
+
+func applyBrandingDefaults(opts *BrandingOptions) {
+	if opts.Position == "" {
+		opts.Position = PositionTopRight
+	}
+	if opts.Opacity <= 0 || opts.Opacity > 1 {
+		opts.Opacity = 0.8
+	}
+	if opts.Margin <= 0 {
+		opts.Margin = 20
+	}
+	if opts.WatermarkScale <= 0 || opts.WatermarkScale > 1 {
+		opts.WatermarkScale = 0.12
+	}
+	if opts.Preset == "" {
+		opts.Preset = "medium"
+	}
+	if opts.CRF <= 0 {
+		opts.CRF = 20
+	}
+}
+
+// overlayPosition 水印位置对应的 overlay 坐标表达式
+func overlayPosition(position string, margin int) string {
+	switch position {
+	case PositionTopLeft:
+		return fmt.Sprintf("%d:%d", margin, margin)
+	case PositionBottomLeft:
+		return fmt.Sprintf("%d:H-h-%d", margin, margin)
+	case PositionBottomRight:
+		return fmt.Sprintf("W-w-%d:H-h-%d", margin, margin)
+	default:
+		return fmt.Sprintf("W-w-%d:%d", margin, margin)
+	}
+}
+
+// SaveBrandingResult 保存处理结果（用于避免重复处理和平移章节）
+func SaveBrandingResult(path string, result *BrandingResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadBrandingResult 读取处理结果
+func LoadBrandingResult(path string) (*BrandingResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result BrandingResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
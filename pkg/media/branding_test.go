@@ -0,0 +1,98 @@
+package media
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSubtitleOffset(t *testing.T) {
+	tests := []struct {
+		intro float64
+		want  time.Duration
+	}{
+		{0, 0},
+		{-1, 0},
+		{5, 5 * time.Second},
+		{5.0054, 5005 * time.Millisecond},
+		{3.2396, 3240 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		r := &BrandingResult{IntroDuration: tt.intro}
+		if got := r.SubtitleOffset(); got != tt.want {
+			t.Errorf("SubtitleOffset(%v) = %v, want %v", tt.intro, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsShift(t *testing.T) {
+	tests := []struct {
+		name   string
+		result BrandingResult
+		file   string
+		want   bool
+	}{
+		{"视频已替换，字幕未平移", BrandingResult{IntroDuration: 5, Pending: true}, "zh.srt", true},
+		{"已平移的文件", BrandingResult{IntroDuration: 5, Pending: true, Shifted: []string{"zh.srt"}}, "zh.srt", false},
+		{"其他文件仍需平移", BrandingResult{IntroDuration: 5, Pending: true, Shifted: []string{"zh.srt"}}, "en.srt", true},
+		{"全部完成", BrandingResult{IntroDuration: 5}, "zh.srt", false},
+		{"没有片头", BrandingResult{OutroDuration: 5, Pending: true}, "zh.srt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.NeedsShift(tt.file); got != tt.want {
+				t.Errorf("NeedsShift(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrandingResultResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "branding.json")
+	if _, err := LoadBrandingResult(path); err == nil {
+		t.Fatal("LoadBrandingResult without file should fail")
+	}
+
+	// 视频替换后立即保存，平移一个文件后中断
+	result := &BrandingResult{IntroDuration: 4.5, Pending: true}
+	result.MarkShifted("zh.srt")
+	result.MarkShifted("zh.srt")
+	if err := SaveBrandingResult(path, result); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重试时只平移剩余的文件
+	loaded, err := LoadBrandingResult(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Pending || len(loaded.Shifted) != 1 {
+		t.Fatalf("loaded = %+v", loaded)
+	}
+	if loaded.NeedsShift("zh.srt") || !loaded.NeedsShift("en.srt") {
+		t.Errorf("resume shifts wrong files: %+v", loaded)
+	}
+
+	// 之前版本保存的结果没有 pending 字段，视为已完成
+	if err := SaveBrandingResult(path, &BrandingResult{IntroDuration: 4.5}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := LoadBrandingResult(path); loaded.Pending || loaded.NeedsShift("zh.srt") {
+		t.Errorf("completed result should not shift again: %+v", loaded)
+	}
+}
+
+func TestOverlayPosition(t *testing.T) {
+	tests := map[string]string{
+		PositionTopLeft:     "20:20",
+		PositionTopRight:    "W-w-20:20",
+		PositionBottomLeft:  "20:H-h-20",
+		PositionBottomRight: "W-w-20:H-h-20",
+		"":                  "W-w-20:20",
+	}
+	for position, want := range tests {
+		if got := overlayPosition(position, 20); got != want {
+			t.Errorf("overlayPosition(%q) = %q, want %q", position, got, want)
+		}
+	}
+}
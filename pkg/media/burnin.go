@@ -0,0 +1,54 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// BurnInOptions 硬字幕烧录参数
+type BurnInOptions struct {
+	Subtitle string // ASS 字幕文件路径
+	FontsDir string // 字体目录（libass 优先从该目录查找字体），为空时只使用系统字体
+	Preset   string // x264 编码预设，默认 medium
+	CRF      int    // 质量参数，默认 20
+}
+
+// BurnSubtitles 使用 ass 滤镜将字幕烧录到画面中，音频流直接复制
+func BurnSubtitles(ctx context.Context, input, output string, opts BurnInOptions) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", burnInArgs(input, output, opts)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg 烧录字幕失败: %v: %s", err, firstLines(stderr.String(), 3))
+	}
+	return nil
+}
+
+// burnInArgs 构建烧录字幕的 ffmpeg 参数（零值参数使用默认值）
+func burnInArgs(input, output string, opts BurnInOptions) []string {
+	if opts.Preset == "" {
+		opts.Preset = "medium"
+	}
+	if opts.CRF <= 0 {
+		opts.CRF = 20
+	}
+
+	filter := "ass=filename=" + escapeFilterValue(opts.Subtitle)
+	if opts.FontsDir != "" {
+		filter += ":fontsdir=" + escapeFilterValue(opts.FontsDir)
+	}
+
+	return []string{
+		"-y", "-v", "error",
+		"-i", input,
+		"-map", "0:v:0", "-map", "0:a?",
+		"-vf", filter + ",format=yuv420p",
+		"-c:v", "libx264", "-preset", opts.Preset, "-crf", strconv.Itoa(opts.CRF),
+		"-c:a", "copy",
+		"-movflags", "+faststart",
+		output,
+	}
+}
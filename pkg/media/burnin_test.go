@@ -0,0 +1,69 @@
+package media
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBurnInArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       BurnInOptions
+		wantFilter string
+		wantPreset string
+		wantCRF    string
+	}{
+		{
+			name:       "默认参数",
+			opts:       BurnInOptions{Subtitle: "/data/v1/burn.ass"},
+			wantFilter: "ass=filename=/data/v1/burn.ass,format=yuv420p",
+			wantPreset: "medium",
+			wantCRF:    "20",
+		},
+		{
+			name:       "字体目录与编码参数",
+			opts:       BurnInOptions{Subtitle: "/data/v1/burn.ass", FontsDir: "/usr/share/fonts/noto", Preset: "fast", CRF: 18},
+			wantFilter: "ass=filename=/data/v1/burn.ass:fontsdir=/usr/share/fonts/noto,format=yuv420p",
+			wantPreset: "fast",
+			wantCRF:    "18",
+		},
+		{
+			name:       "路径中的特殊字符需要转义",
+			opts:       BurnInOptions{Subtitle: `C:\data\it's.ass`},
+			wantFilter: `ass=filename=C\:\\data\\it\'s.ass,format=yuv420p`,
+			wantPreset: "medium",
+			wantCRF:    "20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := burnInArgs("in.mp4", "out.mp4", tt.opts)
+			if got := argValue(args, "-vf"); got != tt.wantFilter {
+				t.Errorf("-vf = %q, want %q", got, tt.wantFilter)
+			}
+			if got := argValue(args, "-preset"); got != tt.wantPreset {
+				t.Errorf("-preset = %q, want %q", got, tt.wantPreset)
+			}
+			if got := argValue(args, "-crf"); got != tt.wantCRF {
+				t.Errorf("-crf = %q, want %q", got, tt.wantCRF)
+			}
+			// 音频直接复制，输出为最后一个参数
+			if got := argValue(args, "-c:a"); got != "copy" {
+				t.Errorf("-c:a = %q, want copy", got)
+			}
+			if args[len(args)-1] != "out.mp4" {
+				t.Errorf("output = %q, want out.mp4 (args %s)", args[len(args)-1], strings.Join(args, " "))
+			}
+		})
+	}
+}
+
+// argValue 返回 ffmpeg 参数中 flag 之后的值，不存在时返回空字符串
+func argValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package media
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Constraints 投稿平台对媒体文件的限制
+type Constraints struct {
+	MaxSizeBytes       int64    // 文件大小上限，0 表示不限制
+	MaxDurationSeconds float64  // 时长上限，0 表示不限制
+	MaxWidth           int      // 宽度上限，0 表示不限制
+	MaxHeight          int      // 高度上限，0 表示不限制
+	MaxFPS             float64  // 帧率上限，0 表示不限制
+	Containers         []string // 允许的容器（文件扩展名），为空时不检查
+	VideoCodecs        []string // 允许的视频编码（ffprobe codec_name），为空时不检查
+	AudioCodecs        []string // 允许的音频编码，为空时不检查
+}
+
+// 检查项
+const (
+	ProblemSize       = "size"
+	ProblemDuration   = "duration"
+	ProblemResolution = "resolution"
+	ProblemFPS        = "fps"
+	ProblemContainer  = "container"
+	ProblemVideoCodec = "video_codec"
+	ProblemAudioCodec = "audio_codec"
+	ProblemNoVideo    = "no_video"
+)
+
+// Problem 不符合限制的检查项
+type Problem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Check 检查媒体文件是否符合限制，返回所有不符合的项
+func (c Constraints) Check(path string, info *Info) []Problem {
+	var problems []Problem
+	add := func(code, format string, args ...interface{}) {
+		problems = append(problems, Problem{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.MaxSizeBytes > 0 && info.Size > c.MaxSizeBytes {
+		add(ProblemSize, "文件大小 %.1f MB 超过上限 %.1f MB", float64(info.Size)/1024/1024, float64(c.MaxSizeBytes)/1024/1024)
+	}
+	if c.MaxDurationSeconds > 0 && info.Duration > c.MaxDurationSeconds {
+		add(ProblemDuration, "时长 %.0f 秒超过上限 %.0f 秒", info.Duration, c.MaxDurationSeconds)
+	}
+
+	container := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if len(c.Containers) > 0 && !containsFold(c.Containers, container) {
+		add(ProblemContainer, "容器格式 %s 不在允许列表 %v 中", container, c.Containers)
+	}
+
+	video := info.VideoStream()
+	if video == nil {
+		add(ProblemNoVideo, "没有视频流")
+	} else {
+		if (c.MaxWidth > 0 && video.Width > c.MaxWidth) || (c.MaxHeight > 0 && video.Height > c.MaxHeight) {
+			add(ProblemResolution, "分辨率 %dx%d 超过上限 %dx%d", video.Width, video.Height, c.MaxWidth, c.MaxHeight)
+		}
+		if c.MaxFPS > 0 && video.FrameRate > c.MaxFPS+0.01 {
+			add(ProblemFPS, "帧率 %.2f 超过上限 %.0f", video.FrameRate, c.MaxFPS)
+		}
+		if len(c.VideoCodecs) > 0 && !containsFold(c.VideoCodecs, video.CodecName) {
+			add(ProblemVideoCodec, "视频编码 %s 不在允许列表 %v 中", video.CodecName, c.VideoCodecs)
+		}
+	}
+
+	if audio := info.AudioStream(); audio != nil && len(c.AudioCodecs) > 0 && !containsFold(c.AudioCodecs, audio.CodecName) {
+		add(ProblemAudioCodec, "音频编码 %s 不在允许列表 %v 中", audio.CodecName, c.AudioCodecs)
+	}
+
+	return problems
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
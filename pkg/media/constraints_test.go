@@ -0,0 +1,87 @@
+package media
+
+import (
+	"reflect"
+	"testing"
+)
+
+// problemCodes 检查结果中的检查项，用于比较
+func problemCodes(problems []Problem) []string {
+	var codes []string
+	for _, p := range problems {
+		codes = append(codes, p.Code)
+	}
+	return codes
+}
+
+func TestConstraintsCheck(t *testing.T) {
+	constraints := Constraints{
+		MaxSizeBytes:       100 * 1024 * 1024,
+		MaxDurationSeconds: 3600,
+		MaxWidth:           1920,
+		MaxHeight:          1080,
+		MaxFPS:             60,
+		Containers:         []string{"mp4", "flv"},
+		VideoCodecs:        []string{"h264", "hevc"},
+		AudioCodecs:        []string{"aac"},
+	}
+	// mediaInfo 符合限制的媒体信息，modify 修改其中的字段
+	mediaInfo := func(modify func(info *Info)) *Info {
+		info := &Info{
+			Duration: 600,
+			Size:     50 * 1024 * 1024,
+			Streams: []Stream{
+				{CodecType: "video", CodecName: "h264", Width: 1920, Height: 1080, FrameRate: 30},
+				{CodecType: "audio", CodecName: "aac"},
+			},
+		}
+		if modify != nil {
+			modify(info)
+		}
+		return info
+	}
+
+	tests := []struct {
+		name        string
+		constraints Constraints
+		path        string
+		info        *Info
+		want        []string
+	}{
+		{"符合全部限制", constraints, "a.mp4", mediaInfo(nil), nil},
+		{"文件过大", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Size = 101 * 1024 * 1024 }), []string{ProblemSize}},
+		{"恰好等于大小上限", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Size = 100 * 1024 * 1024 }), nil},
+		{"时长过长", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Duration = 3601 }), []string{ProblemDuration}},
+		{"宽度超过上限", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams[0].Width = 3840 }), []string{ProblemResolution}},
+		{"高度超过上限", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams[0].Height = 1440 }), []string{ProblemResolution}},
+		{"帧率超过上限", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams[0].FrameRate = 120 }), []string{ProblemFPS}},
+		{"帧率误差内不算超过", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams[0].FrameRate = 60.005 }), nil},
+		{"容器不在允许列表", constraints, "a.webm", mediaInfo(nil), []string{ProblemContainer}},
+		{"容器扩展名不区分大小写", constraints, "a.MP4", mediaInfo(nil), nil},
+		{"视频编码不在允许列表", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams[0].CodecName = "vp9" }), []string{ProblemVideoCodec}},
+		{"音频编码不在允许列表", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams[1].CodecName = "opus" }), []string{ProblemAudioCodec}},
+		{"没有音频流不检查音频编码", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams = i.Streams[:1] }), nil},
+		{"没有视频流", constraints, "a.mp4", mediaInfo(func(i *Info) { i.Streams = i.Streams[1:] }), []string{ProblemNoVideo}},
+		{"封面图片流不算视频流", constraints, "a.mp4", mediaInfo(func(i *Info) {
+			i.Streams = []Stream{{CodecType: "video", CodecName: "mjpeg", Width: 640, Height: 360}, i.Streams[1]}
+		}), []string{ProblemNoVideo}},
+		{"多项不符合", constraints, "a.mkv", mediaInfo(func(i *Info) {
+			i.Size = 200 * 1024 * 1024
+			i.Streams[0].CodecName = "av1"
+			i.Streams[0].FrameRate = 144
+		}), []string{ProblemSize, ProblemContainer, ProblemFPS, ProblemVideoCodec}},
+		{"零值限制不检查", Constraints{}, "a.webm", mediaInfo(func(i *Info) {
+			i.Size = 10 * 1024 * 1024 * 1024
+			i.Duration = 36000
+			i.Streams[0] = Stream{CodecType: "video", CodecName: "vp9", Width: 7680, Height: 4320, FrameRate: 240}
+		}), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := problemCodes(tt.constraints.Check(tt.path, tt.info))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
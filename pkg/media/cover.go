@@ -0,0 +1,371 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 标题位置
+const (
+	TitleTop    = "top"
+	TitleCenter = "center"
+	TitleBottom = "bottom"
+)
+
+// FrameScore 候选帧评分
+type FrameScore struct {
+	Time       float64 `json:"time"`       // 在视频中的时间（秒）
+	Path       string  `json:"path"`       // 帧图片路径
+	Sharpness  float64 `json:"sharpness"`  // 清晰度（拉普拉斯方差归一化，0-1）
+	Brightness float64 `json:"brightness"` // 亮度适中程度（0-1）
+	Contrast   float64 `json:"contrast"`   // 对比度（0-1）
+	Skin       float64 `json:"skin"`       // 肤色区域得分，近似判断是否有人脸（0-1）
+	Score      float64 `json:"score"`      // 综合得分
+}
+
+// CoverStyle 封面标题样式
+type CoverStyle struct {
+	FontFile     string // 字体文件路径，需支持中文
+	FontSize     int    // 字号，默认 72
+	FontColor    string // 文字颜色
+	BorderColor  string // 描边颜色
+	BorderWidth  int    // 描边宽度
+	BandColor    string // 文字背景条颜色（如 black@0.45），为空时不绘制
+	Position     string // 标题位置: top / center / bottom
+	MaxLines     int    // 最多行数
+	MaxLineChars int    // 每行最多中文字数（英文按半个字计算）
+}
+
+// SampleFrames 在视频中均匀抽取候选帧（避开首尾 5%），裁剪到封面尺寸后评分，按得分从高到低返回
+func SampleFrames(ctx context.Context, videoPath, dir string, count, width, height int) ([]FrameScore, error) {
+	if count <= 0 {
+		count = 12
+	}
+	duration, err := ProbeDuration(ctx, videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频时长失败: %w", err)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	start, span := duration*0.05, duration*0.9
+	var scores []FrameScore
+	for i := 0; i < count; i++ {
+		at := start + span*(float64(i)+0.5)/float64(count)
+		path := filepath.Join(dir, fmt.Sprintf("candidate_%02d.jpg", i+1))
+		if err := extractCoverFrame(ctx, videoPath, path, at, width, height); err != nil {
+			continue
+		}
+		score, err := ScoreImageFile(path)
+		if err != nil {
+			continue
+		}
+		score.Time = at
+		scores = append(scores, *score)
+	}
+	if len(scores) == 0 {
+		return nil, fmt.Errorf("未能抽取任何候选帧")
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores, nil
+}
+
+// extractCoverFrame 截取指定时间点的帧，等比放大后居中裁剪到封面尺寸
+func extractCoverFrame(ctx context.Context, videoPath, output string, at float64, width, height int) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-y", "-v", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1", width, height, width, height),
+		"-q:v", "2",
+		output,
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("截取帧失败: %v: %s", err, firstLines(stderr.String(), 3))
+	}
+	return nil
+}
+
+// ScoreImageFile 读取图片并评分
+func ScoreImageFile(path string) (*FrameScore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
+	score := ScoreImage(img)
+	score.Path = path
+	return &score, nil
+}
+
+// ScoreImage 按清晰度、亮度、对比度和肤色比例为图片评分
+// 过暗或过亮（黑场、白场、转场）的画面直接判为 0 分
+func ScoreImage(img image.Image) FrameScore {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w < 3 || h < 3 {
+		return FrameScore{}
+	}
+
+	luma := make([]float64, w*h)
+	var sum, sumSq float64
+	skin := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			rf, gf, bf := float64(r>>8), float64(g>>8), float64(b>>8)
+			l := 0.299*rf + 0.587*gf + 0.114*bf
+			luma[y*w+x] = l
+			sum += l
+			sumSq += l * l
+
+			cb := 128 - 0.168736*rf - 0.331264*gf + 0.5*bf
+			cr := 128 + 0.5*rf - 0.418688*gf - 0.081312*bf
+			if cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173 {
+				skin++
+			}
+		}
+	}
+
+	n := float64(w * h)
+	mean := sum / n
+	stddev := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+
+	// 拉普拉斯方差衡量清晰度
+	var lapSum, lapSq float64
+	lapCount := 0
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			v := luma[i-w] + luma[i+w] + luma[i-1] + luma[i+1] - 4*luma[i]
+			lapSum += v
+			lapSq += v * v
+			lapCount++
+		}
+	}
+	lapMean := lapSum / float64(lapCount)
+	lapVar := lapSq/float64(lapCount) - lapMean*lapMean
+
+	score := FrameScore{
+		Sharpness:  lapVar / (lapVar + 300),
+		Brightness: 1 - math.Abs(mean-128)/128,
+		Contrast:   math.Min(stddev/64, 1),
+	}
+	// 肤色占比 3%-40% 时认为画面中有人物，占比过大多为肤色背景
+	skinRatio := float64(skin) / n
+	switch {
+	case skinRatio <= 0.4:
+		score.Skin = math.Min(skinRatio/0.03, 1)
+	default:
+		score.Skin = math.Max(1-(skinRatio-0.4)/0.4, 0)
+	}
+
+	if mean < 20 || mean > 235 {
+		return score
+	}
+	score.Score = 0.4*score.Sharpness + 0.2*score.Brightness + 0.2*score.Contrast + 0.2*score.Skin
+	return score
+}
+
+// RenderCover 在帧图片上绘制标题并输出封面，title 为空时直接输出原图
+func RenderCover(ctx context.Context, framePath, output, title string, style CoverStyle) error {
+	applyCoverDefaults(&style)
+
+	filters := []string{}
+	lines := WrapTitle(title, style.MaxLineChars, style.MaxLines)
+	if len(lines) > 0 {
+		if style.FontFile == "" {
+			return fmt.Errorf("未找到中文字体，请配置字体文件")
+		}
+
+		tmpDir, err := os.MkdirTemp(filepath.Dir(output), "cover_text_")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		// 行高与文字块的起始位置（ih 为画面高度）
+		fontSize := strconv.Itoa(style.FontSize)
+		lineHeight := style.FontSize * 5 / 4
+		blockHeight := lineHeight * len(lines)
+		margin := style.FontSize / 2
+		var top string
+		switch style.Position {
+		case TitleTop:
+			top = strconv.Itoa(margin)
+		case TitleCenter:
+			top = fmt.Sprintf("(ih-%d)/2", blockHeight)
+		default:
+			top = fmt.Sprintf("ih-%d", blockHeight+margin)
+		}
+
+		if style.BandColor != "" {
+			filters = append(filters, fmt.Sprintf("drawbox=x=0:y=%s-%d:w=iw:h=%d:color=%s:t=fill",
+				top, margin/2, blockHeight+margin, style.BandColor))
+		}
+
+		// 每行单独绘制以便水平居中（文本写入文件，避免转义问题）
+		for i, line := range lines {
+			textFile := filepath.Join(tmpDir, fmt.Sprintf("line%d.txt", i+1))
+			if err := os.WriteFile(textFile, []byte(line), 0644); err != nil {
+				return err
+			}
+			filters = append(filters, fmt.Sprintf(
+				"drawtext=fontfile=%s:textfile=%s:fontsize=%s:fontcolor=%s:borderw=%d:bordercolor=%s:x=(main_w-text_w)/2:y=%s+%d",
+				escapeFilterValue(style.FontFile), escapeFilterValue(textFile), fontSize,
+				style.FontColor, style.BorderWidth, style.BorderColor, strings.ReplaceAll(top, "ih", "main_h"), i*lineHeight))
+		}
+	}
+
+	args := []string{"-y", "-v", "error", "-i", framePath}
+	if len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+	args = append(args, "-frames:v", "1", "-q:v", "2", output)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("渲染封面失败: %v: %s", err, firstLines(stderr.String(), 3))
+	}
+	return nil
+}
+
+func applyCoverDefaults(style *CoverStyle) {
+	if style.FontFile == "" {
+		style.FontFile = FindCJKFont()
+	}
+	if style.FontSize <= 0 {
+		style.FontSize = 72
+	}
+	if style.FontColor == "" {
+		style.FontColor = "white"
+	}
+	if style.BorderColor == "" {
+		style.BorderColor = "black"
+	}
+	if style.BorderWidth < 0 {
+		style.BorderWidth = 0
+	}
+	if style.MaxLines <= 0 {
+		style.MaxLines = 2
+	}
+	if style.MaxLineChars <= 0 {
+		style.MaxLineChars = 14
+	}
+}
+
+// WrapTitle 按宽度将标题折行（中文字占 1 个宽度，英文和数字占半个），英文单词尽量不拆开
+// 超出最大行数时截断并在末尾加省略号
+func WrapTitle(title string, maxChars, maxLines int) []string {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" || maxChars <= 0 || maxLines <= 0 {
+		return nil
+	}
+
+	limit := maxChars * 2
+	width := func(r rune) int {
+		if r < 0x80 {
+			return 1
+		}
+		return 2
+	}
+
+	var lines []string
+	runes := []rune(title)
+	for len(runes) > 0 {
+		used, end, lastSpace := 0, 0, -1
+		for end < len(runes) && used+width(runes[end]) <= limit {
+			if runes[end] == ' ' {
+				lastSpace = end
+			}
+			used += width(runes[end])
+			end++
+		}
+		// 在英文单词中间断行时回退到上一个空格
+		if end < len(runes) && lastSpace > 0 && isWordRune(runes[end]) && isWordRune(runes[end-1]) {
+			end = lastSpace
+		}
+
+		lines = append(lines, strings.TrimSpace(string(runes[:end])))
+		runes = []rune(strings.TrimSpace(string(runes[end:])))
+
+		if len(lines) == maxLines && len(runes) > 0 {
+			last := []rune(lines[len(lines)-1])
+			for len(last) > 0 && stringWidth(last)+2 > limit {
+				last = last[:len(last)-1]
+			}
+			lines[len(lines)-1] = strings.TrimSpace(string(last)) + "…"
+			break
+		}
+	}
+	return lines
+}
+
+func isWordRune(r rune) bool {
+	return r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func stringWidth(runes []rune) int {
+	w := 0
+	for _, r := range runes {
+		if r < 0x80 {
+			w++
+		} else {
+			w += 2
+		}
+	}
+	return w
+}
+
+// FindCJKFont 查找系统中常见的中文字体，找不到时返回空字符串
+func FindCJKFont() string {
+	candidates := []string{
+		"/usr/share/fonts/opentype/noto/NotoSansCJK-Bold.ttc",
+		"/usr/share/fonts/noto/NotoSansCJK-Bold.ttc", // Alpine font-noto-cjk（Docker 镜像内置）
+		"/usr/share/fonts/noto-cjk/NotoSansCJK-Bold.ttc",
+		"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Bold.ttc",
+		"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+		"/usr/share/fonts/truetype/wqy/wqy-microhei.ttc",
+		"/usr/share/fonts/wenquanyi/wqy-microhei/wqy-microhei.ttc",
+		"/System/Library/Fonts/PingFang.ttc",
+		"/System/Library/Fonts/STHeiti Medium.ttc",
+		"C:/Windows/Fonts/msyhbd.ttc",
+		"C:/Windows/Fonts/msyh.ttc",
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// escapeFilterValue 转义滤镜参数值中的特殊字符（如 Windows 路径中的冒号）
+func escapeFilterValue(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `:`, `\:`, `'`, `\'`)
+	return replacer.Replace(s)
+}
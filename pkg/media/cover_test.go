@@ -0,0 +1,111 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestWrapTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		maxChars int
+		maxLines int
+		want     []string
+	}{
+		{"不需要换行", "Hello World", 20, 2, []string{"Hello World"}},
+		{"英文按空格换行", "The quick brown fox jumps", 5, 3, []string{"The quick", "brown fox", "jumps"}},
+		{"不在单词中间断行", "Hello wonderful", 5, 2, []string{"Hello", "wonderful"}},
+		{"超长单词强制断行", "Supercalifragilistic", 5, 2, []string{"Supercalif", "ragilistic"}},
+		{"中文按宽度换行", "这是一个很长的中文标题需要换行", 6, 3, []string{"这是一个很长", "的中文标题需", "要换行"}},
+		{"中英混排", "iPhone 15 评测", 5, 2, []string{"iPhone 15", "评测"}},
+		{"超出行数时截断并加省略号", "这是一个很长的中文标题需要换行", 6, 2, []string{"这是一个很长", "的中文标题…"}},
+		{"英文截断", "The quick brown fox jumps", 5, 1, []string{"The quic…"}},
+		{"合并多余空白", "  a   b  ", 10, 1, []string{"a b"}},
+		{"空标题", "   ", 10, 2, nil},
+		{"无效参数", "title", 0, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapTitle(tt.title, tt.maxChars, tt.maxLines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WrapTitle(%q, %d, %d) = %q, want %q", tt.title, tt.maxChars, tt.maxLines, got, tt.want)
+			}
+		})
+	}
+}
+
+// testImage 生成 64x64 灰度图，像素值由 f(x, y) 决定
+func testImage(f func(x, y int) uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetGray(x, y, color.Gray{Y: f(x, y)})
+		}
+	}
+	return img
+}
+
+func TestScoreImage(t *testing.T) {
+	checker := testImage(func(x, y int) uint8 {
+		if (x/4+y/4)%2 == 0 {
+			return 40
+		}
+		return 216
+	})
+	gradient := testImage(func(x, y int) uint8 { return uint8(x * 4) })
+	flat := testImage(func(x, y int) uint8 { return 128 })
+	black := testImage(func(x, y int) uint8 { return 5 })
+	white := testImage(func(x, y int) uint8 { return 250 })
+
+	// 清晰、对比度高的画面 > 模糊的渐变 > 平坦的灰场 > 黑场/白场
+	ordered := []struct {
+		name string
+		img  image.Image
+	}{
+		{"checker", checker},
+		{"gradient", gradient},
+		{"flat", flat},
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, b := ScoreImage(ordered[i-1].img), ScoreImage(ordered[i].img)
+		if a.Score <= b.Score {
+			t.Errorf("%s score %.3f should be higher than %s score %.3f", ordered[i-1].name, a.Score, ordered[i].name, b.Score)
+		}
+	}
+
+	for name, img := range map[string]image.Image{"black": black, "white": white} {
+		if s := ScoreImage(img); s.Score != 0 {
+			t.Errorf("%s score = %.3f, want 0", name, s.Score)
+		}
+	}
+
+	if s := ScoreImage(image.NewGray(image.Rect(0, 0, 2, 2))); s != (FrameScore{}) {
+		t.Errorf("tiny image score = %+v, want zero", s)
+	}
+}
+
+func TestScoreImageSkin(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.RGBA{R: 60, G: 90, B: 120, A: 255}
+			if x >= 20 && x < 40 && y >= 20 && y < 40 {
+				c = color.RGBA{R: 224, G: 172, B: 140, A: 255} // 肤色
+			}
+			img.Set(x, y, c)
+		}
+	}
+	if s := ScoreImage(img); s.Skin != 1 {
+		t.Errorf("Skin = %.3f, want 1 for ~10%% skin area", s.Skin)
+	}
+
+	full := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := 0; i < len(full.Pix); i += 4 {
+		copy(full.Pix[i:], []uint8{224, 172, 140, 255})
+	}
+	if s := ScoreImage(full); s.Skin != 0 {
+		t.Errorf("Skin = %.3f, want 0 when the whole frame is skin-coloured", s.Skin)
+	}
+}
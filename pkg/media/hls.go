@@ -0,0 +1,92 @@
+package media
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HLSTimestampMap WebVTT 与 MPEG-TS 时间轴的对应关系（ffmpeg 输出的 TS 从 1.4 秒开始）
+const HLSTimestampMap = "X-TIMESTAMP-MAP=MPEGTS:126000,LOCAL:00:00:00.000"
+
+// SubtitleTrack HLS 字幕轨
+type SubtitleTrack struct {
+	Name     string // 显示名称，如 "中文"
+	Language string // 语言代码，如 "zh"
+	URI      string // VTT 文件名（相对于输出目录）
+	Default  bool   // 是否默认显示
+}
+
+// HLSMaster 主播放列表参数
+type HLSMaster struct {
+	Playlist  string  // 媒体播放列表文件名（相对于输出目录）
+	Width     int     // 视频宽度
+	Height    int     // 视频高度
+	Duration  float64 // 视频时长（秒）
+	Subtitles []SubtitleTrack
+}
+
+// WriteHLSMaster 在 dir 中写入 master.m3u8 及每个字幕轨的播放列表（subs_<语言>.m3u8）
+// 码率按切片总大小估算
+func WriteHLSMaster(dir string, master HLSMaster) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+
+	for _, track := range master.Subtitles {
+		playlist := fmt.Sprintf("subs_%s.m3u8", track.Language)
+		if err := writeSubtitlePlaylist(filepath.Join(dir, playlist), track.URI, master.Duration); err != nil {
+			return "", err
+		}
+		isDefault := "NO"
+		if track.Default {
+			isDefault = "YES"
+		}
+		sb.WriteString(fmt.Sprintf("#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID=\"subs\",NAME=\"%s\",LANGUAGE=\"%s\",DEFAULT=%s,AUTOSELECT=YES,URI=\"%s\"\n",
+			track.Name, track.Language, isDefault, playlist))
+	}
+
+	streamInf := fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d", segmentBandwidth(dir, master.Duration))
+	if master.Width > 0 && master.Height > 0 {
+		streamInf += fmt.Sprintf(",RESOLUTION=%dx%d", master.Width, master.Height)
+	}
+	if len(master.Subtitles) > 0 {
+		streamInf += ",SUBTITLES=\"subs\""
+	}
+	sb.WriteString(streamInf + "\n" + master.Playlist + "\n")
+
+	path := filepath.Join(dir, "master.m3u8")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return "", fmt.Errorf("写入主播放列表失败: %w", err)
+	}
+	return path, nil
+}
+
+// writeSubtitlePlaylist 写入只包含一个 VTT 文件的字幕播放列表
+func writeSubtitlePlaylist(path, vtt string, duration float64) error {
+	content := fmt.Sprintf("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:%d\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXTINF:%.3f,\n%s\n#EXT-X-ENDLIST\n",
+		int(math.Ceil(duration)), duration, vtt)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入字幕播放列表失败: %w", err)
+	}
+	return nil
+}
+
+// segmentBandwidth 按 TS 切片总大小估算平均码率（bit/s），留 10% 余量
+func segmentBandwidth(dir string, duration float64) int64 {
+	if duration <= 0 {
+		return 1000000
+	}
+	var total int64
+	files, _ := filepath.Glob(filepath.Join(dir, "*.ts"))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			total += info.Size()
+		}
+	}
+	if total == 0 {
+		return 1000000
+	}
+	return int64(float64(total*8) / duration * 1.1)
+}
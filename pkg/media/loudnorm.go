@@ -0,0 +1,161 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrNoAudio 没有音轨或音频为静音，无法测量响度
+var ErrNoAudio = errors.New("没有可测量的音频（静音或无音轨）")
+
+// LoudnessTarget EBU R128 响度目标
+type LoudnessTarget struct {
+	Integrated float64 // 综合响度（LUFS），如 -14
+	TruePeak   float64 // 真峰值上限（dBTP），如 -1.5
+	LRA        float64 // 响度范围（LU），如 11
+}
+
+// Loudness loudnorm 测量结果
+type Loudness struct {
+	InputI       float64 `json:"input_i"`       // 综合响度（LUFS）
+	InputTP      float64 `json:"input_tp"`      // 真峰值（dBTP）
+	InputLRA     float64 `json:"input_lra"`     // 响度范围（LU）
+	InputThresh  float64 `json:"input_thresh"`  // 门限（LUFS）
+	TargetOffset float64 `json:"target_offset"` // 目标偏移（LU）
+}
+
+// loudnormOutput loudnorm print_format=json 的输出（数值为字符串）
+type loudnormOutput struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	OutputI      string `json:"output_i"`
+	OutputTP     string `json:"output_tp"`
+	OutputLRA    string `json:"output_lra"`
+	OutputThresh string `json:"output_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// MeasureLoudness 第一遍：测量音频的综合响度、真峰值和响度范围
+// 没有音轨或音频为静音时返回 ErrNoAudio
+func MeasureLoudness(ctx context.Context, path string, target LoudnessTarget) (*Loudness, error) {
+	info, err := Probe(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if info.AudioStream() == nil {
+		return nil, ErrNoAudio
+	}
+
+	stderr, err := runLoudnorm(ctx,
+		"-hide_banner", "-nostats",
+		"-i", path,
+		"-map", "0:a:0",
+		"-af", loudnormFilter(target, nil),
+		"-f", "null", "-",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := parseLoudnormOutput(stderr)
+	if err != nil {
+		return nil, err
+	}
+	return &Loudness{
+		InputI:       parseFloat(out.InputI),
+		InputTP:      parseFloat(out.InputTP),
+		InputLRA:     parseFloat(out.InputLRA),
+		InputThresh:  parseFloat(out.InputThresh),
+		TargetOffset: parseFloat(out.TargetOffset),
+	}, nil
+}
+
+// NormalizeLoudness 第二遍：使用测量值线性标准化音频，视频流直接复制，返回标准化后的响度
+func NormalizeLoudness(ctx context.Context, input, output string, target LoudnessTarget, measured *Loudness, audioBitrate string) (*Loudness, error) {
+	if audioBitrate == "" {
+		audioBitrate = "192k"
+	}
+	stderr, err := runLoudnorm(ctx,
+		"-hide_banner", "-nostats", "-y",
+		"-i", input,
+		"-map", "0:v?", "-map", "0:a:0",
+		"-c:v", "copy",
+		"-af", loudnormFilter(target, measured),
+		"-ar", "48000",
+		"-c:a", "aac", "-b:a", audioBitrate,
+		"-movflags", "+faststart",
+		output,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := parseLoudnormOutput(stderr)
+	if err != nil {
+		return nil, err
+	}
+	return &Loudness{
+		InputI:       parseFloat(out.OutputI),
+		InputTP:      parseFloat(out.OutputTP),
+		InputLRA:     parseFloat(out.OutputLRA),
+		InputThresh:  parseFloat(out.OutputThresh),
+		TargetOffset: parseFloat(out.TargetOffset),
+	}, nil
+}
+
+// loudnormFilter 构建 loudnorm 滤镜参数，measured 不为空时使用第二遍的线性模式
+func loudnormFilter(target LoudnessTarget, measured *Loudness) string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	filter := fmt.Sprintf("loudnorm=I=%s:TP=%s:LRA=%s", f(target.Integrated), f(target.TruePeak), f(target.LRA))
+	if measured != nil {
+		filter += fmt.Sprintf(":measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+			f(measured.InputI), f(measured.InputTP), f(measured.InputLRA), f(measured.InputThresh), f(measured.TargetOffset))
+	}
+	return filter + ":print_format=json"
+}
+
+// runLoudnorm 执行 ffmpeg 并返回标准错误输出（loudnorm 的 JSON 结果输出在 stderr）
+func runLoudnorm(ctx context.Context, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg loudnorm 失败: %v: %s", err, lastLines(stderr.String(), 3))
+	}
+	return stderr.String(), nil
+}
+
+// parseLoudnormOutput 从 ffmpeg 输出中提取最后一个 JSON 块
+func parseLoudnormOutput(stderr string) (*loudnormOutput, error) {
+	end := strings.LastIndex(stderr, "}")
+	start := strings.LastIndex(stderr[:max(end, 0)], "{")
+	if start < 0 || end < 0 {
+		return nil, fmt.Errorf("未找到 loudnorm 输出")
+	}
+
+	var out loudnormOutput
+	if err := json.Unmarshal([]byte(stderr[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("解析 loudnorm 输出失败: %w", err)
+	}
+	if out.InputI == "" || out.InputI == "-inf" {
+		return nil, ErrNoAudio
+	}
+	return &out, nil
+}
+
+// lastLines 返回最后 n 行
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "; ")
+}
@@ -0,0 +1,44 @@
+package media
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLoudnormOutput(t *testing.T) {
+	stderr := `[Parsed_loudnorm_0 @ 0x1] 
+{
+	"input_i" : "-23.51",
+	"input_tp" : "-4.20",
+	"input_lra" : "7.80",
+	"input_thresh" : "-34.02",
+	"output_i" : "-14.02",
+	"output_tp" : "-1.50",
+	"output_lra" : "6.90",
+	"output_thresh" : "-24.50",
+	"normalization_type" : "linear",
+	"target_offset" : "0.02"
+}
+`
+	out, err := parseLoudnormOutput(stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parseFloat(out.InputI) != -23.51 || parseFloat(out.OutputTP) != -1.5 || parseFloat(out.TargetOffset) != 0.02 {
+		t.Errorf("parseLoudnormOutput = %+v", out)
+	}
+
+	tests := map[string]string{
+		"静音":    `{"input_i" : "-inf", "input_tp" : "-inf"}`,
+		"没有测量值": `{"input_tp" : "-1.00"}`,
+	}
+	for name, stderr := range tests {
+		if _, err := parseLoudnormOutput(stderr); !errors.Is(err, ErrNoAudio) {
+			t.Errorf("%s: err = %v, want ErrNoAudio", name, err)
+		}
+	}
+
+	if _, err := parseLoudnormOutput("ffmpeg version 6.0"); err == nil || errors.Is(err, ErrNoAudio) {
+		t.Errorf("output without JSON: err = %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Stream 媒体流信息
+type Stream struct {
+	Index      int     `json:"index"`
+	CodecType  string  `json:"codec_type"` // video / audio / subtitle / data
+	CodecName  string  `json:"codec_name"`
+	Profile    string  `json:"profile,omitempty"`
+	PixFmt     string  `json:"pix_fmt,omitempty"`
+	Width      int     `json:"width,omitempty"`
+	Height     int     `json:"height,omitempty"`
+	FrameRate  float64 `json:"frame_rate,omitempty"`
+	SampleRate int     `json:"sample_rate,omitempty"`
+	Channels   int     `json:"channels,omitempty"`
+	BitRate    int64   `json:"bit_rate,omitempty"`
+	Duration   float64 `json:"duration,omitempty"`
+}
+
+// Info 媒体文件信息
+type Info struct {
+	FormatName string   `json:"format_name"` // 容器格式（ffprobe 的 format_name，可能为逗号分隔的多个名称）
+	Duration   float64  `json:"duration"`
+	Size       int64    `json:"size"`
+	BitRate    int64    `json:"bit_rate"`
+	Streams    []Stream `json:"streams"`
+}
+
+// VideoStream 第一个视频流，没有时返回 nil（封面图片流不计入）
+func (i *Info) VideoStream() *Stream {
+	for idx := range i.Streams {
+		s := &i.Streams[idx]
+		if s.CodecType == "video" && s.CodecName != "mjpeg" && s.CodecName != "png" {
+			return s
+		}
+	}
+	return nil
+}
+
+// AudioStream 第一个音频流，没有时返回 nil
+func (i *Info) AudioStream() *Stream {
+	for idx := range i.Streams {
+		if i.Streams[idx].CodecType == "audio" {
+			return &i.Streams[idx]
+		}
+	}
+	return nil
+}
+
+// ffprobeOutput ffprobe -show_format -show_streams 的 JSON 输出（数值字段为字符串）
+type ffprobeOutput struct {
+	Streams []struct {
+		Index        int    `json:"index"`
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Profile      string `json:"profile"`
+		PixFmt       string `json:"pix_fmt"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		SampleRate   string `json:"sample_rate"`
+		Channels     int    `json:"channels"`
+		BitRate      string `json:"bit_rate"`
+		Duration     string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// Probe 使用 ffprobe 读取容器和所有流的信息
+func Probe(ctx context.Context, path string) (*Info, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_format",
+		"-show_streams",
+		"-of", "json",
+		path,
+	)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe 失败: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var raw ffprobeOutput
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("解析 ffprobe 输出失败: %w", err)
+	}
+
+	info := &Info{
+		FormatName: raw.Format.FormatName,
+		Duration:   parseFloat(raw.Format.Duration),
+		Size:       parseInt(raw.Format.Size),
+		BitRate:    parseInt(raw.Format.BitRate),
+	}
+	for _, s := range raw.Streams {
+		info.Streams = append(info.Streams, Stream{
+			Index:      s.Index,
+			CodecType:  s.CodecType,
+			CodecName:  s.CodecName,
+			Profile:    s.Profile,
+			PixFmt:     s.PixFmt,
+			Width:      s.Width,
+			Height:     s.Height,
+			FrameRate:  parseRational(s.AvgFrameRate),
+			SampleRate: int(parseInt(s.SampleRate)),
+			Channels:   s.Channels,
+			BitRate:    parseInt(s.BitRate),
+			Duration:   parseFloat(s.Duration),
+		})
+	}
+	return info, nil
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func parseInt(s string) int64 {
+	v, _ := strconv.ParseInt(s, 10, 64)
+	return v
+}
+
+// parseRational 解析 "30000/1001" 形式的帧率
+func parseRational(s string) float64 {
+	num, den, found := strings.Cut(s, "/")
+	if !found {
+		return parseFloat(s)
+	}
+	d := parseFloat(den)
+	if d == 0 {
+		return 0
+	}
+	return parseFloat(num) / d
+}
+
+// ProbeDuration 使用 ffprobe 获取媒体文件时长（秒）
+func ProbeDuration(ctx context.Context, path string) (float64, error) {
+	output, err := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	).Output()
+	if err != nil {
+		return 0, err
+	}
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析时长: %q", strings.TrimSpace(string(output)))
+	}
+	return duration, nil
+}
+
+// KeyframeTimes 获取视频流所有关键帧的时间点（秒，升序）
+func KeyframeTimes(ctx context.Context, path string) ([]float64, error) {
+	output, err := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-skip_frame", "nokey",
+		"-show_entries", "frame=pts_time",
+		"-of", "csv=p=0",
+		path,
+	).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var times []float64
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimSuffix(scanner.Text(), ","))
+		if line == "" {
+			continue
+		}
+		t, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			continue
+		}
+		times = append(times, t)
+	}
+	if len(times) == 0 {
+		return nil, fmt.Errorf("未找到关键帧")
+	}
+	return times, nil
+}
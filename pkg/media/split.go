@@ -0,0 +1,255 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chapter 视频章节
+type Chapter struct {
+	Start float64
+	End   float64
+	Title string
+}
+
+// Part 分P片段
+type Part struct {
+	Index int     `json:"index"` // 从 1 开始
+	Title string  `json:"title"`
+	Start float64 `json:"start"` // 在原视频中的开始时间（秒）
+	End   float64 `json:"end"`   // 在原视频中的结束时间（秒）
+	Path  string  `json:"path"`  // 切分后的文件路径
+}
+
+// Duration 片段时长（秒）
+func (p Part) Duration() float64 {
+	return p.End - p.Start
+}
+
+// 分P方式
+const (
+	SplitModeChapters = "chapters" // 按章节
+	SplitModeDuration = "duration" // 按最大时长
+	SplitModeAuto     = "auto"     // 有章节按章节，否则按最大时长
+)
+
+// SplitOptions 分P规划参数
+type SplitOptions struct {
+	Mode           string
+	MaxPartSeconds float64 // 每P最大时长，0 表示不限制
+	MinPartSeconds float64 // 短于该时长的章节与相邻章节合并
+}
+
+// PlanParts 根据章节或最大时长规划分P，少于 2 个片段时返回 nil
+func PlanParts(duration float64, chapters []Chapter, opts SplitOptions) []Part {
+	if duration <= 0 {
+		return nil
+	}
+
+	var parts []Part
+	useChapters := opts.Mode == SplitModeChapters || (opts.Mode == SplitModeAuto && len(chapters) >= 2)
+	if useChapters {
+		parts = chapterParts(duration, chapters, opts.MinPartSeconds)
+	} else if opts.Mode == SplitModeDuration || opts.Mode == SplitModeAuto {
+		parts = []Part{{Start: 0, End: duration}}
+	}
+
+	// 超过最大时长的片段继续等分
+	if opts.MaxPartSeconds > 0 {
+		var limited []Part
+		for _, p := range parts {
+			limited = append(limited, splitByDuration(p, opts.MaxPartSeconds)...)
+		}
+		parts = limited
+	}
+
+	if len(parts) < 2 {
+		return nil
+	}
+	for i := range parts {
+		parts[i].Index = i + 1
+		if parts[i].Title == "" {
+			parts[i].Title = fmt.Sprintf("P%d", i+1)
+		}
+	}
+	return parts
+}
+
+// chapterParts 将章节转换为片段，过短的章节合并到前一个片段（第一个章节合并到后一个）
+func chapterParts(duration float64, chapters []Chapter, minSeconds float64) []Part {
+	var parts []Part
+	for i, ch := range chapters {
+		end := ch.End
+		if end <= 0 || end > duration {
+			end = duration
+		}
+		if i+1 < len(chapters) && chapters[i+1].Start > ch.Start {
+			end = math.Min(end, chapters[i+1].Start)
+		}
+		if end <= ch.Start {
+			continue
+		}
+		parts = append(parts, Part{Title: strings.TrimSpace(ch.Title), Start: ch.Start, End: end})
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	// 章节没有从 0 开始时，把开头补到第一个片段
+	parts[0].Start = 0
+
+	if minSeconds <= 0 {
+		return parts
+	}
+	var merged []Part
+	for _, p := range parts {
+		if len(merged) > 0 && p.Duration() < minSeconds {
+			merged[len(merged)-1].End = p.End
+			continue
+		}
+		if len(merged) == 1 && merged[0].Duration() < minSeconds {
+			merged[0].End = p.End
+			merged[0].Title = p.Title
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// splitByDuration 将超过最大时长的片段等分，标题追加序号
+func splitByDuration(p Part, maxSeconds float64) []Part {
+	n := int(math.Ceil(p.Duration() / maxSeconds))
+	if n <= 1 {
+		return []Part{p}
+	}
+
+	step := p.Duration() / float64(n)
+	parts := make([]Part, 0, n)
+	for i := 0; i < n; i++ {
+		sub := Part{
+			Start: p.Start + step*float64(i),
+			End:   p.Start + step*float64(i+1),
+		}
+		if i == n-1 {
+			sub.End = p.End
+		}
+		if p.Title != "" {
+			sub.Title = fmt.Sprintf("%s (%d/%d)", p.Title, i+1, n)
+		}
+		parts = append(parts, sub)
+	}
+	return parts
+}
+
+// SnapToKeyframes 将片段边界对齐到不晚于边界的最近关键帧
+// 流复制只能从关键帧开始，对齐后字幕的时间偏移才是准确的
+func SnapToKeyframes(parts []Part, keyframes []float64) []Part {
+	if len(keyframes) == 0 {
+		return parts
+	}
+
+	snapped := make([]Part, len(parts))
+	copy(snapped, parts)
+	for i := 1; i < len(snapped); i++ {
+		boundary := snapped[i].Start
+		best := boundary
+		for _, k := range keyframes {
+			if k > boundary {
+				break
+			}
+			best = k
+		}
+		// 对齐后不能早于上一个片段的开始时间
+		if best > snapped[i-1].Start {
+			snapped[i].Start = best
+			snapped[i-1].End = best
+		}
+	}
+	return snapped
+}
+
+// SplitVideo 使用 ffmpeg 流复制按片段切分视频，片段的 Path 为输出路径
+func SplitVideo(ctx context.Context, input string, parts []Part) error {
+	for i, p := range parts {
+		args := []string{
+			"-y", "-v", "error",
+			"-ss", strconv.FormatFloat(p.Start, 'f', 3, 64),
+			"-i", input,
+		}
+		// 最后一个片段直接到结尾，避免时长误差导致丢失末尾
+		if i < len(parts)-1 {
+			args = append(args, "-t", strconv.FormatFloat(p.Duration(), 'f', 3, 64))
+		}
+		args = append(args,
+			"-map", "0:v?", "-map", "0:a?",
+			"-c", "copy",
+			"-avoid_negative_ts", "make_zero",
+			"-movflags", "+faststart",
+			p.Path,
+		)
+
+		output, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("切分第 %d 段失败: %v: %s", p.Index, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// PartsManifest 分P清单，记录切分时源视频的路径、大小和修改时间
+// 源视频被替换（重新烧录、重新下载、重新添加片头片尾）后清单失效，需要重新切分
+type PartsManifest struct {
+	Source        string    `json:"source"`
+	SourceSize    int64     `json:"source_size"`
+	SourceModTime time.Time `json:"source_mod_time"`
+	Parts         []Part    `json:"parts"`
+}
+
+// Matches 源视频是否与切分时相同
+func (m *PartsManifest) Matches(source string) bool {
+	info, err := os.Stat(source)
+	if err != nil {
+		return false
+	}
+	return m.Source == source && m.SourceSize == info.Size() && m.SourceModTime.Equal(info.ModTime())
+}
+
+// SaveParts 保存分P清单，同时记录源视频的大小和修改时间
+func SaveParts(path, source string, parts []Part) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	manifest := PartsManifest{Source: source, SourceSize: info.Size(), SourceModTime: info.ModTime(), Parts: parts}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadParts 读取分P清单，兼容只有分P列表的旧格式（没有源视频信息，Matches 始终为 false）
+func LoadParts(path string) (*PartsManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest PartsManifest
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &manifest.Parts)
+	} else {
+		err = json.Unmarshal(data, &manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析分P清单失败: %w", err)
+	}
+	return &manifest, nil
+}
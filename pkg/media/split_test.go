@@ -0,0 +1,211 @@
+package media
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// span 片段的时间范围，用于比较
+type span struct{ start, end float64 }
+
+func spans(parts []Part) []span {
+	var s []span
+	for _, p := range parts {
+		s = append(s, span{p.Start, p.End})
+	}
+	return s
+}
+
+func equalSpans(a, b []span) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i].start-b[i].start) > 1e-6 || math.Abs(a[i].end-b[i].end) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPlanParts(t *testing.T) {
+	chapters := []Chapter{
+		{Start: 5, End: 300, Title: " Intro "},
+		{Start: 300, End: 320, Title: "Short"},
+		{Start: 320, End: 900, Title: "Main"},
+	}
+	tests := []struct {
+		name     string
+		duration float64
+		chapters []Chapter
+		opts     SplitOptions
+		want     []span
+	}{
+		{"时长为 0", 0, chapters, SplitOptions{Mode: SplitModeAuto, MaxPartSeconds: 600}, nil},
+		{"短于最大时长", 500, nil, SplitOptions{Mode: SplitModeDuration, MaxPartSeconds: 600}, nil},
+		{"恰好等于最大时长", 600, nil, SplitOptions{Mode: SplitModeDuration, MaxPartSeconds: 600}, nil},
+		{"按最大时长等分", 1300, nil, SplitOptions{Mode: SplitModeDuration, MaxPartSeconds: 600},
+			[]span{{0, 1300.0 / 3}, {1300.0 / 3, 2600.0 / 3}, {2600.0 / 3, 1300}}},
+		{"不限制时长", 5000, nil, SplitOptions{Mode: SplitModeDuration}, nil},
+		{"按章节，开头补齐，短章节合并", 900, chapters, SplitOptions{Mode: SplitModeChapters, MinPartSeconds: 60},
+			[]span{{0, 320}, {320, 900}}},
+		{"章节超过最大时长继续等分", 900, chapters, SplitOptions{Mode: SplitModeAuto, MaxPartSeconds: 400, MinPartSeconds: 60},
+			[]span{{0, 320}, {320, 610}, {610, 900}}},
+		{"auto 没有章节时按时长", 1000, chapters[:1], SplitOptions{Mode: SplitModeAuto, MaxPartSeconds: 600},
+			[]span{{0, 500}, {500, 1000}}},
+		{"章节结束时间超出视频时长", 600, []Chapter{{Start: 0, End: 200}, {Start: 200, End: 9999}}, SplitOptions{Mode: SplitModeChapters},
+			[]span{{0, 200}, {200, 600}}},
+		{"第一个章节过短合并到后一个", 600, []Chapter{{Start: 0, End: 10, Title: "a"}, {Start: 10, End: 300, Title: "b"}, {Start: 300, End: 600, Title: "c"}},
+			SplitOptions{Mode: SplitModeChapters, MinPartSeconds: 60}, []span{{0, 300}, {300, 600}}},
+		{"未知分P方式", 5000, nil, SplitOptions{Mode: "none", MaxPartSeconds: 600}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := PlanParts(tt.duration, tt.chapters, tt.opts)
+			if got := spans(parts); !equalSpans(got, tt.want) {
+				t.Fatalf("PlanParts = %v, want %v", got, tt.want)
+			}
+			for i, p := range parts {
+				if p.Index != i+1 || p.Title == "" {
+					t.Errorf("part %d: index %d, title %q", i, p.Index, p.Title)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanPartsTitles(t *testing.T) {
+	parts := PlanParts(900, []Chapter{{Start: 0, End: 300, Title: " Intro "}, {Start: 300, End: 900, Title: "Main"}},
+		SplitOptions{Mode: SplitModeChapters, MaxPartSeconds: 400})
+	want := []string{"Intro", "Main (1/2)", "Main (2/2)"}
+	if len(parts) != len(want) {
+		t.Fatalf("PlanParts = %+v", parts)
+	}
+	for i, p := range parts {
+		if p.Title != want[i] {
+			t.Errorf("title %d = %q, want %q", i, p.Title, want[i])
+		}
+	}
+
+	parts = PlanParts(1000, nil, SplitOptions{Mode: SplitModeDuration, MaxPartSeconds: 600})
+	if parts[0].Title != "P1" || parts[1].Title != "P2" {
+		t.Errorf("default titles = %q, %q", parts[0].Title, parts[1].Title)
+	}
+}
+
+func TestSnapToKeyframes(t *testing.T) {
+	parts := []Part{{Start: 0, End: 100}, {Start: 100, End: 200}, {Start: 200, End: 300}}
+	tests := []struct {
+		name      string
+		keyframes []float64
+		want      []span
+	}{
+		{"没有关键帧", nil, []span{{0, 100}, {100, 200}, {200, 300}}},
+		{"对齐到之前最近的关键帧", []float64{0, 48, 98, 150, 199.5, 250}, []span{{0, 98}, {98, 199.5}, {199.5, 300}}},
+		{"恰好在关键帧上", []float64{0, 100, 200}, []span{{0, 100}, {100, 200}, {200, 300}}},
+		// 超出结尾的关键帧被忽略；对齐后不能早于上一个片段的开始，保留原边界
+		{"关键帧超出结尾", []float64{0, 90, 400, 500}, []span{{0, 90}, {90, 200}, {200, 300}}},
+		{"边界之前没有关键帧", []float64{150, 250}, []span{{0, 100}, {100, 150}, {150, 300}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spans(SnapToKeyframes(parts, tt.keyframes)); !equalSpans(got, tt.want) {
+				t.Errorf("SnapToKeyframes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if parts[1].Start != 100 {
+		t.Errorf("SnapToKeyframes modified its input: %+v", parts)
+	}
+}
+
+func TestPartsManifest(t *testing.T) {
+	parts := []Part{{Index: 1, Start: 0, End: 600, Path: "p01.mp4"}, {Index: 2, Start: 600, End: 900, Path: "p02.mp4"}}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, source string) string // 切分后对源视频的改动，返回比较的路径
+		want   bool
+	}{
+		{"源视频未变化", func(t *testing.T, source string) string { return source }, true},
+		{"源视频被替换", func(t *testing.T, source string) string {
+			if err := os.WriteFile(source, []byte("burned again"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			return source
+		}, false},
+		{"大小相同但修改时间变化", func(t *testing.T, source string) string {
+			later := modTime.Add(time.Minute)
+			if err := os.Chtimes(source, later, later); err != nil {
+				t.Fatal(err)
+			}
+			return source
+		}, false},
+		{"投稿文件换为另一个文件", func(t *testing.T, source string) string {
+			other := filepath.Join(filepath.Dir(source), "v1.burned.mp4")
+			if err := os.WriteFile(other, []byte("source"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			os.Chtimes(other, modTime, modTime)
+			return other
+		}, false},
+		{"源视频已删除", func(t *testing.T, source string) string {
+			os.Remove(source)
+			return source
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := filepath.Join(dir, "v1.mp4")
+			manifestPath := filepath.Join(dir, "parts.json")
+			if err := os.WriteFile(source, []byte("source"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(source, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+			if err := SaveParts(manifestPath, source, parts); err != nil {
+				t.Fatal(err)
+			}
+
+			compared := tt.change(t, source)
+			manifest, err := LoadParts(manifestPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(manifest.Parts) != len(parts) {
+				t.Errorf("LoadParts() = %d parts, want %d", len(manifest.Parts), len(parts))
+			}
+			if got := manifest.Matches(compared); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPartsLegacy(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "v1.mp4")
+	manifestPath := filepath.Join(dir, "parts.json")
+	os.WriteFile(source, []byte("source"), 0644)
+	if err := os.WriteFile(manifestPath, []byte(`[{"index":1,"path":"p01.mp4"},{"index":2,"path":"p02.mp4"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := LoadParts(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Parts) != 2 {
+		t.Errorf("LoadParts() = %d parts, want 2", len(manifest.Parts))
+	}
+	// 旧格式没有源视频信息，不能确定分P是否过期
+	if manifest.Matches(source) {
+		t.Error("legacy manifest Matches() = true, want false")
+	}
+}
@@ -0,0 +1,207 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 故事板输出文件名
+const (
+	StoryboardSprite       = "sprite.jpg"
+	StoryboardThumbnails   = "thumbnails.vtt"
+	StoryboardContactSheet = "contact_sheet.jpg"
+)
+
+// StoryboardOptions 故事板生成参数，零值表示使用默认值
+type StoryboardOptions struct {
+	Interval       float64 // 雪碧图缩略图间隔（秒），默认 10
+	TileWidth      int     // 雪碧图缩略图宽度，默认 160
+	Columns        int     // 雪碧图每行缩略图数，默认 10
+	MaxTiles       int     // 雪碧图最多缩略图数，超过时加大间隔，默认 600
+	SheetInterval  float64 // 缩略图总览间隔（秒），默认 60
+	SheetTileWidth int     // 缩略图总览中每张图的宽度，默认 320
+	SheetColumns   int     // 缩略图总览每行图片数，默认 4
+	MaxSheetTiles  int     // 缩略图总览最多图片数，超过时加大间隔，默认 48
+	FontFile       string  // 时间戳字体，为空时自动查找，找不到时不绘制时间戳
+}
+
+// StoryboardResult 故事板生成结果
+type StoryboardResult struct {
+	Interval      float64 `json:"interval"`
+	TileWidth     int     `json:"tile_width"`
+	TileHeight    int     `json:"tile_height"`
+	Columns       int     `json:"columns"`
+	Rows          int     `json:"rows"`
+	Count         int     `json:"count"`
+	SheetInterval float64 `json:"sheet_interval"`
+	SheetCount    int     `json:"sheet_count"`
+}
+
+// GenerateStoryboard 生成 WebVTT 故事板（雪碧图 + thumbnails.vtt）和缩略图总览
+// 只解码关键帧并在一次 ffmpeg 调用中同时输出两张图
+func GenerateStoryboard(ctx context.Context, input, dir string, opts StoryboardOptions) (*StoryboardResult, error) {
+	applyStoryboardDefaults(&opts)
+
+	info, err := Probe(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("读取媒体信息失败: %w", err)
+	}
+	video := info.VideoStream()
+	if video == nil || video.Width <= 0 || video.Height <= 0 {
+		return nil, fmt.Errorf("没有视频流")
+	}
+	if info.Duration <= 0 {
+		return nil, fmt.Errorf("无法获取视频时长")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	// 1. 计算布局（缩略图数量超过上限时加大间隔）
+	result, sheet := storyboardLayout(info.Duration, video.Width, video.Height, opts)
+
+	// 2. 雪碧图与总览图滤镜（总览图上绘制时间戳需要字体文件）
+	filter := storyboardFilter(result, sheet, opts.FontFile)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-y", "-v", "error",
+		"-skip_frame", "nokey",
+		"-i", input,
+		"-filter_complex", filter,
+		"-map", "[sprite]", "-frames:v", "1", "-q:v", "4", filepath.Join(dir, StoryboardSprite),
+		"-map", "[sheet]", "-frames:v", "1", "-q:v", "3", filepath.Join(dir, StoryboardContactSheet),
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("生成故事板失败: %v: %s", err, firstLines(stderr.String(), 3))
+	}
+
+	// 3. thumbnails.vtt：每条 cue 指向雪碧图中的一块区域
+	if err := os.WriteFile(filepath.Join(dir, StoryboardThumbnails), []byte(StoryboardVTT(result, info.Duration, StoryboardSprite)), 0644); err != nil {
+		return nil, fmt.Errorf("写入 thumbnails.vtt 失败: %w", err)
+	}
+	return result, nil
+}
+
+// StoryboardVTT 生成故事板 WebVTT，cue 文本为 sprite#xywh=x,y,w,h
+func StoryboardVTT(result *StoryboardResult, duration float64, sprite string) string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for i := 0; i < result.Count; i++ {
+		start := float64(i) * result.Interval
+		end := math.Min(start+result.Interval, duration)
+		x := (i % result.Columns) * result.TileWidth
+		y := (i / result.Columns) * result.TileHeight
+		sb.WriteString(fmt.Sprintf("%s --> %s\n%s#xywh=%d,%d,%d,%d\n\n",
+			vttTimestamp(start), vttTimestamp(end), sprite, x, y, result.TileWidth, result.TileHeight))
+	}
+	return sb.String()
+}
+
+// storyboardSheet 缩略图总览布局
+type storyboardSheet struct {
+	TileWidth  int
+	TileHeight int
+	Columns    int
+	Rows       int
+}
+
+// storyboardLayout 计算雪碧图与总览图的布局（opts 需已填充默认值）
+func storyboardLayout(duration float64, width, height int, opts StoryboardOptions) (*StoryboardResult, storyboardSheet) {
+	result := &StoryboardResult{
+		Interval:      storyboardInterval(duration, opts.Interval, opts.MaxTiles),
+		TileWidth:     opts.TileWidth,
+		TileHeight:    evenHeight(opts.TileWidth, width, height),
+		SheetInterval: storyboardInterval(duration, opts.SheetInterval, opts.MaxSheetTiles),
+	}
+	result.Count = int(math.Ceil(duration / result.Interval))
+	result.Columns = min(opts.Columns, result.Count)
+	result.Rows = (result.Count + result.Columns - 1) / result.Columns
+	result.SheetCount = int(math.Ceil(duration / result.SheetInterval))
+
+	sheet := storyboardSheet{
+		TileWidth:  opts.SheetTileWidth,
+		TileHeight: evenHeight(opts.SheetTileWidth, width, height),
+		Columns:    min(opts.SheetColumns, result.SheetCount),
+	}
+	sheet.Rows = (result.SheetCount + sheet.Columns - 1) / sheet.Columns
+	return result, sheet
+}
+
+// storyboardFilter 构建同时输出雪碧图与总览图的 filter_complex，fontFile 为空时总览图不绘制时间戳
+func storyboardFilter(result *StoryboardResult, sheet storyboardSheet, fontFile string) string {
+	sheetFilter := fmt.Sprintf("fps=1/%s,scale=%d:%d", formatSeconds(result.SheetInterval), sheet.TileWidth, sheet.TileHeight)
+	if fontFile != "" {
+		sheetFilter += fmt.Sprintf(",drawtext=fontfile=%s:text='%%{pts\\:hms}':fontsize=%d:fontcolor=white:box=1:boxcolor=black@0.6:boxborderw=4:x=6:y=h-th-6",
+			escapeFilterValue(fontFile), max(sheet.TileHeight/10, 12))
+	}
+
+	return strings.Join([]string{
+		"[0:v]split=2[a][b]",
+		fmt.Sprintf("[a]fps=1/%s,scale=%d:%d,tile=%dx%d[sprite]",
+			formatSeconds(result.Interval), result.TileWidth, result.TileHeight, result.Columns, result.Rows),
+		fmt.Sprintf("[b]%s,tile=%dx%d:padding=6:margin=6:color=white[sheet]", sheetFilter, sheet.Columns, sheet.Rows),
+	}, ";")
+}
+
+func applyStoryboardDefaults(opts *StoryboardOptions) {
+	if opts.Interval <= 0 {
+		opts.Interval = 10
+	}
+	if opts.TileWidth <= 0 {
+		opts.TileWidth = 160
+	}
+	if opts.Columns <= 0 {
+		opts.Columns = 10
+	}
+	if opts.MaxTiles <= 0 {
+		opts.MaxTiles = 600
+	}
+	if opts.SheetInterval <= 0 {
+		opts.SheetInterval = 60
+	}
+	if opts.SheetTileWidth <= 0 {
+		opts.SheetTileWidth = 320
+	}
+	if opts.SheetColumns <= 0 {
+		opts.SheetColumns = 4
+	}
+	if opts.MaxSheetTiles <= 0 {
+		opts.MaxSheetTiles = 48
+	}
+	if opts.FontFile == "" {
+		opts.FontFile = FindCJKFont()
+	}
+}
+
+// storyboardInterval 数量超过上限时按上限均分时长
+func storyboardInterval(duration, interval float64, maxTiles int) float64 {
+	if duration/interval > float64(maxTiles) {
+		return math.Ceil(duration / float64(maxTiles))
+	}
+	return interval
+}
+
+// evenHeight 按宽高比计算偶数高度
+func evenHeight(width, srcWidth, srcHeight int) int {
+	return max(int(math.Round(float64(width)*float64(srcHeight)/float64(srcWidth)/2))*2, 2)
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// vttTimestamp 格式化为 WebVTT 时间格式 (HH:MM:SS.mmm)
+func vttTimestamp(seconds float64) string {
+	ms := time.Duration(seconds * float64(time.Second)).Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,86 @@
+package media
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStoryboardLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		duration      float64
+		width, height int
+		want          StoryboardResult
+		wantSheet     storyboardSheet
+	}{
+		{
+			name: "短视频", duration: 95, width: 1920, height: 1080,
+			want:      StoryboardResult{Interval: 10, TileWidth: 160, TileHeight: 90, Columns: 10, Rows: 1, Count: 10, SheetInterval: 60, SheetCount: 2},
+			wantSheet: storyboardSheet{TileWidth: 320, TileHeight: 180, Columns: 2, Rows: 1},
+		},
+		{
+			name: "缩略图不足一行", duration: 25, width: 1280, height: 720,
+			want:      StoryboardResult{Interval: 10, TileWidth: 160, TileHeight: 90, Columns: 3, Rows: 1, Count: 3, SheetInterval: 60, SheetCount: 1},
+			wantSheet: storyboardSheet{TileWidth: 320, TileHeight: 180, Columns: 1, Rows: 1},
+		},
+		{
+			name: "超过上限时加大间隔", duration: 3 * 3600, width: 1920, height: 1080,
+			want:      StoryboardResult{Interval: 18, TileWidth: 160, TileHeight: 90, Columns: 10, Rows: 60, Count: 600, SheetInterval: 225, SheetCount: 48},
+			wantSheet: storyboardSheet{TileWidth: 320, TileHeight: 180, Columns: 4, Rows: 12},
+		},
+		{
+			name: "竖屏视频", duration: 60, width: 1080, height: 1920,
+			want:      StoryboardResult{Interval: 10, TileWidth: 160, TileHeight: 284, Columns: 6, Rows: 1, Count: 6, SheetInterval: 60, SheetCount: 1},
+			wantSheet: storyboardSheet{TileWidth: 320, TileHeight: 568, Columns: 1, Rows: 1},
+		},
+		{
+			name: "奇数高度取偶", duration: 10, width: 720, height: 405,
+			want:      StoryboardResult{Interval: 10, TileWidth: 160, TileHeight: 90, Columns: 1, Rows: 1, Count: 1, SheetInterval: 60, SheetCount: 1},
+			wantSheet: storyboardSheet{TileWidth: 320, TileHeight: 180, Columns: 1, Rows: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := StoryboardOptions{FontFile: "none"}
+			applyStoryboardDefaults(&opts)
+			got, sheet := storyboardLayout(tt.duration, tt.width, tt.height, opts)
+			if *got != tt.want {
+				t.Errorf("result = %+v, want %+v", *got, tt.want)
+			}
+			if sheet != tt.wantSheet {
+				t.Errorf("sheet = %+v, want %+v", sheet, tt.wantSheet)
+			}
+		})
+	}
+}
+
+func TestStoryboardFilter(t *testing.T) {
+	result := &StoryboardResult{Interval: 10, TileWidth: 160, TileHeight: 90, Columns: 10, Rows: 2, Count: 15, SheetInterval: 60, SheetCount: 3}
+	sheet := storyboardSheet{TileWidth: 320, TileHeight: 180, Columns: 3, Rows: 1}
+
+	filter := storyboardFilter(result, sheet, "")
+	want := "[0:v]split=2[a][b];" +
+		"[a]fps=1/10,scale=160:90,tile=10x2[sprite];" +
+		"[b]fps=1/60,scale=320:180,tile=3x1:padding=6:margin=6:color=white[sheet]"
+	if filter != want {
+		t.Errorf("filter = %q, want %q", filter, want)
+	}
+
+	// 有字体时绘制时间戳，路径中的冒号需要转义
+	filter = storyboardFilter(result, sheet, `C:\fonts\cjk.otf`)
+	if !strings.Contains(filter, `drawtext=fontfile=C\:\\fonts\\cjk.otf:text='%{pts\:hms}':fontsize=18`) {
+		t.Errorf("filter without escaped drawtext: %q", filter)
+	}
+}
+
+func TestStoryboardVTT(t *testing.T) {
+	result := &StoryboardResult{Interval: 10, TileWidth: 160, TileHeight: 90, Columns: 2, Rows: 2, Count: 3}
+	got := StoryboardVTT(result, 25.5, "sprite.jpg")
+	want := "WEBVTT\n\n" +
+		"00:00:00.000 --> 00:00:10.000\nsprite.jpg#xywh=0,0,160,90\n\n" +
+		"00:00:10.000 --> 00:00:20.000\nsprite.jpg#xywh=160,0,160,90\n\n" +
+		"00:00:20.000 --> 00:00:25.500\nsprite.jpg#xywh=0,90,160,90\n\n"
+	if got != want {
+		t.Errorf("StoryboardVTT() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+)
+
+// VerifyOptions 下载文件完整性校验参数
+type VerifyOptions struct {
+	ExpectedDuration  float64 // 来源平台给出的时长（秒），0 表示不比较
+	DurationTolerance float64 // 允许的时长误差（秒）
+	RequireVideo      bool    // 必须包含视频流
+	RequireAudio      bool    // 必须包含音频流
+	FullDecode        bool    // 是否完整解码一遍检查数据错误
+}
+
+// Verify 校验媒体文件：容器可解析、时长与来源一致、音视频流齐全，可选完整解码
+// 校验通过时返回文件信息
+func Verify(ctx context.Context, path string, opts VerifyOptions) (*Info, error) {
+	info, err := Probe(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("容器无法解析: %w", err)
+	}
+
+	if opts.RequireVideo && info.VideoStream() == nil {
+		return info, fmt.Errorf("缺少视频流")
+	}
+	if opts.RequireAudio && info.AudioStream() == nil {
+		return info, fmt.Errorf("缺少音频流")
+	}
+
+	if info.Duration <= 0 {
+		return info, fmt.Errorf("无法读取时长")
+	}
+	if opts.ExpectedDuration > 0 {
+		diff := math.Abs(info.Duration - opts.ExpectedDuration)
+		if diff > opts.DurationTolerance {
+			return info, fmt.Errorf("时长不一致: 文件 %.1f 秒，来源 %.1f 秒", info.Duration, opts.ExpectedDuration)
+		}
+	}
+
+	if opts.FullDecode {
+		if err := DecodeCheck(ctx, path); err != nil {
+			return info, err
+		}
+	}
+	return info, nil
+}
+
+// DecodeCheck 完整解码所有流（不输出），遇到数据错误时返回错误
+func DecodeCheck(ctx context.Context, path string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-xerror",
+		"-threads", "0",
+		"-i", path,
+		"-map", "0:v?", "-map", "0:a?",
+		"-f", "null",
+		"-",
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("解码失败: %v: %s", err, firstLines(stderr.String(), 3))
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("解码出错: %s", firstLines(msg, 3))
+	}
+	return nil
+}
+
+// firstLines 返回前 n 行，避免错误信息过长
+func firstLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "; ")
+}
@@ -0,0 +1,132 @@
+package source
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Outcome 源视频可用性判定结果
+type Outcome string
+
+const (
+	OutcomeAvailable     Outcome = "available"      // 可以下载
+	OutcomeUpcoming      Outcome = "upcoming"       // 预定首播/直播尚未开始
+	OutcomeLive          Outcome = "live"           // 正在直播（或首播进行中）
+	OutcomePostLive      Outcome = "post_live"      // 直播已结束，回放仍在处理中
+	OutcomePrivate       Outcome = "private"        // 私享视频
+	OutcomeRemoved       Outcome = "removed"        // 已删除/不可用
+	OutcomeGeoBlocked    Outcome = "geo_blocked"    // 地区限制
+	OutcomeLoginRequired Outcome = "login_required" // 需要登录、会员或年龄验证
+)
+
+// Availability 源视频可用性
+type Availability struct {
+	Outcome     Outcome
+	ReleaseTime time.Time // 预定开始时间（仅 upcoming，未知时为零值）
+	Reason      string    // 判定依据（yt-dlp 的字段值或错误信息）
+}
+
+// Terminal 是否为无法恢复的终态（私享、删除、地区限制、需要登录）
+func (a Availability) Terminal() bool {
+	switch a.Outcome {
+	case OutcomePrivate, OutcomeRemoved, OutcomeGeoBlocked, OutcomeLoginRequired:
+		return true
+	}
+	return false
+}
+
+// ClassifyMetadata 根据 yt-dlp info JSON 中的 live_status、availability 判断可用性
+func ClassifyMetadata(meta *Metadata) Availability {
+	switch meta.LiveStatus {
+	case "is_upcoming":
+		a := Availability{Outcome: OutcomeUpcoming, Reason: "live_status=is_upcoming"}
+		if meta.ReleaseTimestamp > 0 {
+			a.ReleaseTime = time.Unix(meta.ReleaseTimestamp, 0)
+		}
+		return a
+	case "is_live":
+		return Availability{Outcome: OutcomeLive, Reason: "live_status=is_live"}
+	case "post_live":
+		return Availability{Outcome: OutcomePostLive, Reason: "live_status=post_live"}
+	}
+
+	switch meta.Availability {
+	case "private":
+		return Availability{Outcome: OutcomePrivate, Reason: "availability=private"}
+	case "premium_only", "subscriber_only", "needs_auth":
+		return Availability{Outcome: OutcomeLoginRequired, Reason: "availability=" + meta.Availability}
+	}
+
+	return Availability{Outcome: OutcomeAvailable}
+}
+
+// errorPattern yt-dlp 错误输出匹配规则（按顺序匹配，先匹配到的优先）
+type errorPattern struct {
+	outcome Outcome
+	regex   *regexp.Regexp
+}
+
+var errorPatterns = []errorPattern{
+	{OutcomeUpcoming, regexp.MustCompile(`(?i)(premieres? in|premiere will begin|live event will begin|this live event will|scheduled to start|is_upcoming|waiting for scheduled stream)`)},
+	{OutcomePostLive, regexp.MustCompile(`(?i)(post_live|this live stream recording is not available|live stream recording is not yet available)`)},
+	{OutcomeLive, regexp.MustCompile(`(?i)(is currently live|this live stream is ongoing|live stream is still in progress)`)},
+	{OutcomePrivate, regexp.MustCompile(`(?i)(private video|this video is private|video is private)`)},
+	{OutcomeGeoBlocked, regexp.MustCompile(`(?i)(not (?:made this video )?available in your country|geo[- ]?restrict|geo[- ]?block|not available in your (?:region|location))`)},
+	{OutcomeLoginRequired, regexp.MustCompile(`(?i)(members[- ]only|join this channel|sign in to confirm your age|age[- ]restricted|requires payment|premium members)`)},
+	{OutcomeRemoved, regexp.MustCompile(`(?i)(video unavailable|has been removed|no longer available|account .* terminated|(?:this|the requested|the) (?:video|content|post) does not exist|video (?:has been )?deleted|copyright claim|violating .* terms of service)`)},
+}
+
+// relativeTimeRegex 匹配 "in 3 hours"、"in 15 minutes"、"in 2 days" 等相对时间
+var relativeTimeRegex = regexp.MustCompile(`(?i)in (\d+) (second|minute|hour|day)s?`)
+
+// ClassifyError 根据 yt-dlp 的错误输出判断可用性，无法识别时返回 false
+func ClassifyError(output string) (Availability, bool) {
+	for _, p := range errorPatterns {
+		match := p.regex.FindString(output)
+		if match == "" {
+			continue
+		}
+
+		a := Availability{Outcome: p.outcome, Reason: errorLine(output, match)}
+		if p.outcome == OutcomeUpcoming {
+			a.ReleaseTime = parseRelativeTime(output, time.Now())
+		}
+		return a, true
+	}
+	return Availability{}, false
+}
+
+// errorLine 返回包含匹配内容的那一行，作为判定依据
+func errorLine(output, match string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, match) {
+			return strings.TrimSpace(line)
+		}
+	}
+	return match
+}
+
+// parseRelativeTime 解析错误信息中的相对开始时间，无法解析时返回零值
+func parseRelativeTime(output string, now time.Time) time.Time {
+	m := relativeTimeRegex.FindStringSubmatch(output)
+	if len(m) < 3 {
+		return time.Time{}
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	unit := time.Second
+	switch strings.ToLower(m[2]) {
+	case "minute":
+		unit = time.Minute
+	case "hour":
+		unit = time.Hour
+	case "day":
+		unit = 24 * time.Hour
+	}
+	return now.Add(time.Duration(n) * unit)
+}
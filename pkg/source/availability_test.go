@@ -0,0 +1,115 @@
+package source
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    Metadata
+		want    Outcome
+		release bool
+	}{
+		{"普通视频", Metadata{LiveStatus: "not_live", Availability: "public"}, OutcomeAvailable, false},
+		{"字段缺失", Metadata{}, OutcomeAvailable, false},
+		{"直播回放", Metadata{LiveStatus: "was_live"}, OutcomeAvailable, false},
+		{"预定首播", Metadata{LiveStatus: "is_upcoming", ReleaseTimestamp: 1700000000}, OutcomeUpcoming, true},
+		{"预定首播无时间", Metadata{LiveStatus: "is_upcoming"}, OutcomeUpcoming, false},
+		{"直播中", Metadata{LiveStatus: "is_live"}, OutcomeLive, false},
+		{"回放处理中", Metadata{LiveStatus: "post_live"}, OutcomePostLive, false},
+		{"私享", Metadata{Availability: "private"}, OutcomePrivate, false},
+		{"会员", Metadata{Availability: "subscriber_only"}, OutcomeLoginRequired, false},
+		{"需要登录", Metadata{Availability: "needs_auth"}, OutcomeLoginRequired, false},
+		{"不公开链接可下载", Metadata{Availability: "unlisted"}, OutcomeAvailable, false},
+		{"直播状态优先", Metadata{LiveStatus: "is_live", Availability: "subscriber_only"}, OutcomeLive, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyMetadata(&tt.meta)
+			if got.Outcome != tt.want {
+				t.Errorf("Outcome = %s, want %s", got.Outcome, tt.want)
+			}
+			if tt.release != !got.ReleaseTime.IsZero() {
+				t.Errorf("ReleaseTime = %v, want set=%v", got.ReleaseTime, tt.release)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Outcome // 为空表示无法识别
+	}{
+		{"ERROR: [youtube] abc: Premieres in 3 hours", OutcomeUpcoming},
+		{"ERROR: [youtube] abc: This live event will begin in 15 minutes.", OutcomeUpcoming},
+		{"ERROR: [youtube] abc: This live stream recording is not available.", OutcomePostLive},
+		{"ERROR: [youtube] abc: Private video. Sign in if you've been granted access", OutcomePrivate},
+		{"ERROR: [youtube] abc: The uploader has not made this video available in your country", OutcomeGeoBlocked},
+		{"ERROR: [youtube] abc: Join this channel to get access to members-only content", OutcomeLoginRequired},
+		{"ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate", OutcomeLoginRequired},
+		{"ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader", OutcomeRemoved},
+		{"ERROR: [youtube] abc: Video unavailable. This video does not exist.", OutcomeRemoved},
+		{"ERROR: [vimeo] 123: The requested video does not exist", OutcomeRemoved},
+		{"ERROR: [youtube] abc: This video is no longer available because the YouTube account associated with this video has been terminated.", OutcomeRemoved},
+		// 本地错误不能判定为视频已删除
+		{"ERROR: unable to open for writing: [Errno 2] No such file or directory", ""},
+		{"open /data/videos/abc/cookies.txt: file does not exist", ""},
+		{"ERROR: output directory does not exist", ""},
+		{"ERROR: unable to download video data: HTTP Error 403: Forbidden", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			got, ok := ClassifyError(tt.output)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("ClassifyError = %s, want unrecognized", got.Outcome)
+				}
+				return
+			}
+			if !ok || got.Outcome != tt.want {
+				t.Errorf("ClassifyError = %s (%v), want %s", got.Outcome, ok, tt.want)
+			}
+			if got.Reason == "" {
+				t.Error("Reason should not be empty")
+			}
+		})
+	}
+}
+
+func TestClassifyErrorReleaseTime(t *testing.T) {
+	got, ok := ClassifyError("WARNING: something\nERROR: [youtube] abc: Premieres in 2 hours")
+	if !ok || got.Outcome != OutcomeUpcoming {
+		t.Fatalf("ClassifyError = %+v, %v", got, ok)
+	}
+	if got.Reason != "ERROR: [youtube] abc: Premieres in 2 hours" {
+		t.Errorf("Reason = %q", got.Reason)
+	}
+	if d := time.Until(got.ReleaseTime); d < 119*time.Minute || d > 2*time.Hour {
+		t.Errorf("ReleaseTime in %v, want about 2h", d)
+	}
+}
+
+func TestParseRelativeTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		output string
+		want   time.Duration
+	}{
+		{"Premieres in 30 seconds", 30 * time.Second},
+		{"begin in 1 minute", time.Minute},
+		{"Premieres in 5 Hours", 5 * time.Hour},
+		{"live event will begin in 2 days", 48 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := parseRelativeTime(tt.output, now); !got.Equal(now.Add(tt.want)) {
+			t.Errorf("parseRelativeTime(%q) = %v, want %v", tt.output, got, now.Add(tt.want))
+		}
+	}
+	if got := parseRelativeTime("Premieres soon", now); !got.IsZero() {
+		t.Errorf("parseRelativeTime without time = %v, want zero", got)
+	}
+}
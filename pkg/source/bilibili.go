@@ -0,0 +1,93 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const bilibiliIDPrefix = "bili."
+
+var (
+	bilibiliBVRegex = regexp.MustCompile(`(BV[0-9A-Za-z]{10})`)
+	bilibiliAVRegex = regexp.MustCompile(`(?i)/video/av(\d+)`)
+	bilibiliIDRegex = regexp.MustCompile(`^(BV[0-9A-Za-z]{10}|av\d+)(_p\d+)?$`)
+)
+
+// BilibiliProvider 哔哩哔哩平台
+// 规范化 ID 为 bili.{BV/av 号}，分P视频追加 _p{页码}；历史数据中没有前缀的 ID 由 Registry.ForID 兼容
+type BilibiliProvider struct{}
+
+func NewBilibiliProvider() *BilibiliProvider {
+	return &BilibiliProvider{}
+}
+
+func (p *BilibiliProvider) Name() string {
+	return ProviderBilibili
+}
+
+func (p *BilibiliProvider) Match(u *url.URL) bool {
+	return hostMatches(u.Host, "bilibili.com", "b23.tv")
+}
+
+func (p *BilibiliProvider) ParseURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	id := bilibiliIDPrefix
+	if match := bilibiliBVRegex.FindStringSubmatch(u.Path); len(match) > 1 {
+		id += match[1]
+	} else if match := bilibiliAVRegex.FindStringSubmatch(u.Path); len(match) > 1 {
+		id += "av" + match[1]
+	} else {
+		return "", fmt.Errorf("无效的 Bilibili 地址（b23.tv 短链需要先展开）: %s", rawURL)
+	}
+
+	// 分P参数
+	if page := u.Query().Get("p"); page != "" && page != "1" {
+		id += "_p" + page
+	}
+	return id, nil
+}
+
+func (p *BilibiliProvider) OwnsID(id string) bool {
+	rest, ok := strings.CutPrefix(id, bilibiliIDPrefix)
+	return ok && bilibiliIDRegex.MatchString(rest)
+}
+
+func (p *BilibiliProvider) VideoURL(id string) string {
+	id = strings.TrimPrefix(id, bilibiliIDPrefix)
+	base, page, found := strings.Cut(id, "_p")
+	if found {
+		return fmt.Sprintf("https://www.bilibili.com/video/%s?p=%s", base, page)
+	}
+	return fmt.Sprintf("https://www.bilibili.com/video/%s", id)
+}
+
+func (p *BilibiliProvider) MapMetadata(raw []byte) (*Metadata, error) {
+	meta, err := decodeMetadata(raw, p.Name())
+	if err != nil {
+		return nil, err
+	}
+	// B站没有频道概念，使用 UP 主作为频道
+	if meta.Channel == "" {
+		meta.Channel = meta.Uploader
+	}
+	if meta.ChannelID == "" {
+		meta.ChannelID = meta.UploaderID
+	}
+	return meta, nil
+}
+
+func (p *BilibiliProvider) DownloadThumbnail(ctx context.Context, id string, meta *Metadata, saveDir string) (string, error) {
+	if meta == nil {
+		return "", fmt.Errorf("缺少视频元数据，无法获取封面地址")
+	}
+	// B站封面地址可能是 http，统一使用 https
+	thumbnail := strings.Replace(meta.Thumbnail, "http://", "https://", 1)
+	return downloadThumbnailURL(ctx, thumbnail, saveDir, "thumbnail")
+}
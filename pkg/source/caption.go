@@ -0,0 +1,24 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CaptionExts yt-dlp 下载字幕轨时使用的格式（按优先级）
+var CaptionExts = []string{"vtt", "srv3"}
+
+// SelectCaption 在 yt-dlp 下载的字幕文件（{name}.{lang}.{ext}）中按语言优先级选择一个非空文件
+// 返回文件路径和语言，没有匹配的字幕轨时返回空
+func SelectCaption(dir, name string, languages []string) (string, string) {
+	for _, lang := range languages {
+		for _, ext := range CaptionExts {
+			path := filepath.Join(dir, fmt.Sprintf("%s.%s.%s", name, lang, ext))
+			if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+				return path, lang
+			}
+		}
+	}
+	return "", ""
+}
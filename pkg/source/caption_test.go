@@ -0,0 +1,51 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectCaption(t *testing.T) {
+	files := map[string]string{
+		"platform_manual.en.srv3": "<timedtext/>",
+		"platform_manual.ja.vtt":  "WEBVTT",
+		"platform_manual.ja.srv3": "<timedtext/>",
+		"platform_manual.de.vtt":  "", // 空文件不可用
+		"platform_auto.fr.vtt":    "WEBVTT",
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		languages []string
+		wantFile  string
+		wantLang  string
+	}{
+		{"按语言优先级", []string{"ja", "en"}, "platform_manual.ja.vtt", "ja"},
+		{"vtt 优先于 srv3", []string{"ja"}, "platform_manual.ja.vtt", "ja"},
+		{"退回 srv3", []string{"en", "ja"}, "platform_manual.en.srv3", "en"},
+		{"跳过空文件", []string{"de", "en"}, "platform_manual.en.srv3", "en"},
+		{"只匹配同一类字幕轨", []string{"fr"}, "", ""},
+		{"没有语言", nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, lang := SelectCaption(dir, "platform_manual", tt.languages)
+			if tt.wantFile == "" {
+				if path != "" || lang != "" {
+					t.Errorf("SelectCaption = %q, %q, want none", path, lang)
+				}
+				return
+			}
+			if filepath.Base(path) != tt.wantFile || lang != tt.wantLang {
+				t.Errorf("SelectCaption = %q, %q, want %q, %q", filepath.Base(path), lang, tt.wantFile, tt.wantLang)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package source
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const genericIDPrefix = "web."
+
+// GenericProvider 通用 yt-dlp 平台，兜底处理其他 yt-dlp 支持的站点
+// 规范化 ID 格式: web.{URL 哈希}，ID 无法还原为地址，下载时使用提交时保存的原始 URL
+type GenericProvider struct{}
+
+func NewGenericProvider() *GenericProvider {
+	return &GenericProvider{}
+}
+
+func (p *GenericProvider) Name() string {
+	return ProviderGeneric
+}
+
+func (p *GenericProvider) Match(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (p *GenericProvider) ParseURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if !p.Match(u) {
+		return "", fmt.Errorf("无效的视频地址: %s", rawURL)
+	}
+
+	// 去掉 fragment 并统一 host 大小写，避免同一视频生成不同 ID
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	sum := sha1.Sum([]byte(u.String()))
+	return genericIDPrefix + hex.EncodeToString(sum[:])[:16], nil
+}
+
+func (p *GenericProvider) OwnsID(id string) bool {
+	return strings.HasPrefix(id, genericIDPrefix)
+}
+
+func (p *GenericProvider) VideoURL(id string) string {
+	return ""
+}
+
+func (p *GenericProvider) MapMetadata(raw []byte) (*Metadata, error) {
+	meta, err := decodeMetadata(raw, p.Name())
+	if err != nil {
+		return nil, err
+	}
+	if meta.Channel == "" {
+		meta.Channel = meta.Uploader
+	}
+	if meta.Title == "" && meta.WebpageURL != "" {
+		if u, err := url.Parse(meta.WebpageURL); err == nil {
+			meta.Title = u.Host
+		}
+	}
+	return meta, nil
+}
+
+func (p *GenericProvider) DownloadThumbnail(ctx context.Context, id string, meta *Metadata, saveDir string) (string, error) {
+	if meta == nil {
+		return "", fmt.Errorf("缺少视频元数据，无法获取封面地址")
+	}
+	return downloadThumbnailURL(ctx, meta.Thumbnail, saveDir, "thumbnail")
+}
@@ -0,0 +1,246 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/difyz9/ytb2bili/pkg/store/model"
+)
+
+// Metadata 统一的视频元数据（由各平台 yt-dlp info JSON 映射而来）
+type Metadata struct {
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Extractor   string               `json:"extractor_key"`
+	Uploader    string               `json:"uploader"`
+	UploaderID  string               `json:"uploader_id"`
+	Channel     string               `json:"channel"`
+	ChannelID   string               `json:"channel_id"`
+	UploadDate  string               `json:"upload_date"`
+	Duration    float64              `json:"duration"`
+	Tags        []string             `json:"tags"`
+	Categories  []string             `json:"categories"`
+	Chapters    []model.VideoChapter `json:"chapters"`
+	ViewCount   int64                `json:"view_count"`
+	LikeCount   int64                `json:"like_count"`
+	Language    string               `json:"language"`
+	WebpageURL  string               `json:"webpage_url"`
+	Thumbnail   string               `json:"thumbnail"`
+	Platform    string               `json:"-"` // 来源平台（Provider 名称）
+
+	// 直播/首播与可用性（yt-dlp: live_status, availability, release_timestamp）
+	LiveStatus       string `json:"live_status"`
+	Availability     string `json:"availability"`
+	ReleaseTimestamp int64  `json:"release_timestamp"`
+}
+
+// Provider 视频来源平台接口
+type Provider interface {
+	// Name 平台名称（youtube/bilibili/vimeo/x/generic）
+	Name() string
+
+	// Match 判断 URL 是否属于该平台
+	Match(u *url.URL) bool
+
+	// ParseURL 从 URL 中解析出规范化的视频 ID
+	ParseURL(rawURL string) (string, error)
+
+	// OwnsID 判断规范化 ID 是否属于该平台
+	OwnsID(id string) bool
+
+	// VideoURL 根据规范化 ID 构建视频地址，无法还原时返回空字符串
+	VideoURL(id string) string
+
+	// MapMetadata 将 yt-dlp info JSON 映射为统一元数据
+	MapMetadata(raw []byte) (*Metadata, error)
+
+	// DownloadThumbnail 下载封面图片到 saveDir，返回文件路径
+	DownloadThumbnail(ctx context.Context, id string, meta *Metadata, saveDir string) (string, error)
+}
+
+// Registry 来源平台注册表
+type Registry struct {
+	providers []Provider
+	fallback  Provider
+}
+
+// NewRegistry 创建注册表，按顺序匹配，最后一个 Provider 作为兜底
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: providers}
+	if len(providers) > 0 {
+		r.fallback = providers[len(providers)-1]
+	}
+	return r
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// DefaultRegistry 默认注册表：YouTube、Bilibili、Vimeo、X/Twitter、通用 yt-dlp
+func DefaultRegistry() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry(
+			NewYouTubeProvider(),
+			NewBilibiliProvider(),
+			NewVimeoProvider(),
+			NewTwitterProvider(),
+			NewGenericProvider(),
+		)
+	})
+	return defaultRegistry
+}
+
+// Providers 获取所有已注册的平台
+func (r *Registry) Providers() []Provider {
+	return r.providers
+}
+
+// Resolve 解析 URL，返回所属平台和规范化 ID
+func (r *Registry) Resolve(rawURL string) (Provider, string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("URL 解析失败: %w", err)
+	}
+
+	for _, p := range r.providers {
+		if p.Match(u) {
+			id, err := p.ParseURL(rawURL)
+			if err != nil {
+				return nil, "", err
+			}
+			return p, id, nil
+		}
+	}
+	return nil, "", fmt.Errorf("不支持的视频地址: %s", rawURL)
+}
+
+// ForID 根据规范化 ID 查找所属平台
+// 没有平台前缀的历史 ID 按 BV 号或 YouTube ID 格式识别；无法确定平台时（如随机生成的 ID，
+// 或同时符合两种格式的 av 号）返回兜底平台，由调用方使用提交时保存的原始 URL
+func (r *Registry) ForID(id string) Provider {
+	for _, p := range r.providers {
+		if p.OwnsID(id) {
+			return p
+		}
+	}
+	if prefixed, ok := legacyID(id); ok {
+		for _, p := range r.providers {
+			if p.OwnsID(prefixed) {
+				return p
+			}
+		}
+	}
+	return r.fallback
+}
+
+// legacyID 将没有平台前缀的历史 YouTube/Bilibili ID 转换为规范化 ID，无法确定平台时返回 false
+func legacyID(id string) (string, bool) {
+	bilibili, youtube := bilibiliIDRegex.MatchString(id), youtubeIDRegex.MatchString(id)
+	switch {
+	case bilibili && !youtube:
+		return bilibiliIDPrefix + id, true
+	case youtube && !bilibili:
+		return youtubeIDPrefix + id, true
+	}
+	return "", false
+}
+
+// LookupIDs 按规范化 ID 查询已有记录时使用的 ID：规范化 ID 及其历史写法（YouTube/Bilibili 早期不加平台前缀）
+func LookupIDs(id string) []string {
+	for _, prefix := range []string{youtubeIDPrefix, bilibiliIDPrefix} {
+		if rest, ok := strings.CutPrefix(id, prefix); ok && rest != "" {
+			return []string{id, rest}
+		}
+	}
+	return []string{id}
+}
+
+// Get 按名称获取平台
+func (r *Registry) Get(name string) (Provider, bool) {
+	for _, p := range r.providers {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// ExtractVideoID 使用默认注册表从 URL 中提取规范化视频 ID，失败时返回空字符串
+func ExtractVideoID(rawURL string) string {
+	_, id, err := DefaultRegistry().Resolve(rawURL)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// decodeMetadata 解析 yt-dlp info JSON 的通用字段
+func decodeMetadata(raw []byte, platform string) (*Metadata, error) {
+	var meta Metadata
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("解析元数据失败: %w", err)
+	}
+	meta.Platform = platform
+	return &meta, nil
+}
+
+// ToSourceMeta 转换为数据库中的源视频元数据记录
+func (m *Metadata) ToSourceMeta(videoID string, savedVideoID uint, infoJSONPath string) *model.VideoSourceMeta {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+
+	meta := &model.VideoSourceMeta{
+		VideoID:      videoID,
+		SavedVideoID: savedVideoID,
+		Platform:     m.Platform,
+		Extractor:    strings.ToLower(m.Extractor),
+		Uploader:     m.Uploader,
+		UploaderID:   m.UploaderID,
+		Channel:      m.Channel,
+		ChannelID:    m.ChannelID,
+		UploadDate:   m.UploadDate,
+		Duration:     m.Duration,
+		ViewCount:    m.ViewCount,
+		LikeCount:    m.LikeCount,
+		Language:     m.Language,
+		WebpageURL:   m.WebpageURL,
+		InfoJSONPath: infoJSONPath,
+	}
+	if len(m.Tags) > 0 {
+		meta.Tags = encode(m.Tags)
+	}
+	if len(m.Categories) > 0 {
+		meta.Categories = encode(m.Categories)
+	}
+	if len(m.Chapters) > 0 {
+		meta.Chapters = encode(m.Chapters)
+	}
+	return meta
+}
+
+// hostMatches 判断 host 是否为指定域名或其子域名
+func hostMatches(host string, domains ...string) bool {
+	host = strings.ToLower(strings.TrimPrefix(host, "www."))
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package source
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		url      string
+		provider string
+		id       string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", ProviderYouTube, "yt.dQw4w9WgXcQ"},
+		{"https://youtube.com/watch?feature=share&v=dQw4w9WgXcQ&t=10", ProviderYouTube, "yt.dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ?si=abc", ProviderYouTube, "yt.dQw4w9WgXcQ"},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", ProviderYouTube, "yt.dQw4w9WgXcQ"},
+		{"https://m.youtube.com/live/dQw4w9WgXcQ", ProviderYouTube, "yt.dQw4w9WgXcQ"},
+		{"  https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ  ", ProviderYouTube, "yt.dQw4w9WgXcQ"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD", ProviderBilibili, "bili.BV1xx411c7mD"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD/?p=1", ProviderBilibili, "bili.BV1xx411c7mD"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD?p=3", ProviderBilibili, "bili.BV1xx411c7mD_p3"},
+		{"https://www.bilibili.com/video/av170001", ProviderBilibili, "bili.av170001"},
+		{"https://www.youtube.com/watch?v=av123456789", ProviderYouTube, "yt.av123456789"}, // 符合 av 号格式的 YouTube ID
+		{"https://vimeo.com/76979871", ProviderVimeo, "vimeo.76979871"},
+		{"https://vimeo.com/channels/staffpicks/76979871", ProviderVimeo, "vimeo.76979871"},
+		{"https://x.com/user/status/1234567890", ProviderTwitter, "x.1234567890"},
+		{"https://twitter.com/user/statuses/1234567890?s=20", ProviderTwitter, "x.1234567890"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			p, id, err := DefaultRegistry().Resolve(tt.url)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if p.Name() != tt.provider || id != tt.id {
+				t.Errorf("Resolve = %s %q, want %s %q", p.Name(), id, tt.provider, tt.id)
+			}
+		})
+	}
+}
+
+func TestResolveInvalid(t *testing.T) {
+	for _, rawURL := range []string{
+		"https://www.youtube.com/watch?v=short",
+		"https://b23.tv/abcdef",
+		"https://vimeo.com/about",
+		"https://x.com/user",
+		"ftp://example.com/video.mp4",
+		"not a url",
+	} {
+		if p, id, err := DefaultRegistry().Resolve(rawURL); err == nil {
+			t.Errorf("Resolve(%q) = %s %q, want error", rawURL, p.Name(), id)
+		}
+	}
+}
+
+func TestGenericID(t *testing.T) {
+	p, id, err := DefaultRegistry().Resolve("https://Example.com/videos/42#t=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != ProviderGeneric || !strings.HasPrefix(id, genericIDPrefix) {
+		t.Fatalf("Resolve = %s %q", p.Name(), id)
+	}
+	// host 大小写和 fragment 不影响 ID
+	if _, same, _ := DefaultRegistry().Resolve("https://example.com/videos/42"); same != id {
+		t.Errorf("generic ID not stable: %q vs %q", same, id)
+	}
+	if _, other, _ := DefaultRegistry().Resolve("https://example.com/videos/43"); other == id {
+		t.Errorf("different URLs share ID %q", id)
+	}
+}
+
+func TestForID(t *testing.T) {
+	tests := []struct {
+		id       string
+		provider string
+		url      string
+	}{
+		{"yt.dQw4w9WgXcQ", ProviderYouTube, "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"yt.av123456789", ProviderYouTube, "https://www.youtube.com/watch?v=av123456789"},
+		{"bili.BV1xx411c7mD", ProviderBilibili, "https://www.bilibili.com/video/BV1xx411c7mD"},
+		{"bili.BV1xx411c7mD_p3", ProviderBilibili, "https://www.bilibili.com/video/BV1xx411c7mD?p=3"},
+		{"bili.av170001", ProviderBilibili, "https://www.bilibili.com/video/av170001"},
+		{"vimeo.76979871", ProviderVimeo, "https://vimeo.com/76979871"},
+		{"x.1234567890", ProviderTwitter, "https://x.com/i/status/1234567890"},
+		{"web.0123456789abcdef", ProviderGeneric, ""},
+		// 没有前缀的历史 ID
+		{"dQw4w9WgXcQ", ProviderYouTube, "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"BV1xx411c7mD_p1", ProviderBilibili, "https://www.bilibili.com/video/BV1xx411c7mD?p=1"},
+		{"av170001", ProviderBilibili, "https://www.bilibili.com/video/av170001"},
+		{"av123456789", ProviderGeneric, ""}, // 同时符合 av 号与 YouTube ID 格式，使用保存的 URL
+		{"k3Jd9aQpZx0L", ProviderGeneric, ""}, // 旧版随机生成的 12 位 ID
+		{"yt.short", ProviderGeneric, ""},
+	}
+	for _, tt := range tests {
+		p := DefaultRegistry().ForID(tt.id)
+		if p.Name() != tt.provider || p.VideoURL(tt.id) != tt.url {
+			t.Errorf("ForID(%q) = %s %q, want %s %q", tt.id, p.Name(), p.VideoURL(tt.id), tt.provider, tt.url)
+		}
+	}
+}
+
+func TestLookupIDs(t *testing.T) {
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"yt.dQw4w9WgXcQ", []string{"yt.dQw4w9WgXcQ", "dQw4w9WgXcQ"}},
+		{"bili.BV1xx411c7mD_p3", []string{"bili.BV1xx411c7mD_p3", "BV1xx411c7mD_p3"}},
+		{"vimeo.76979871", []string{"vimeo.76979871"}},
+		{"dQw4w9WgXcQ", []string{"dQw4w9WgXcQ"}},
+	}
+	for _, tt := range tests {
+		if got := LookupIDs(tt.id); !slices.Equal(got, tt.want) {
+			t.Errorf("LookupIDs(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
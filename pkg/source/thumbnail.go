@@ -0,0 +1,76 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// thumbnailClient 下载封面使用的 HTTP 客户端
+var thumbnailClient = &http.Client{Timeout: 30 * time.Second}
+
+// downloadThumbnailURL 下载封面图片，文件名为 name + 原始扩展名
+func downloadThumbnailURL(ctx context.Context, thumbnailURL, saveDir, name string) (string, error) {
+	if thumbnailURL == "" {
+		return "", fmt.Errorf("没有可用的封面地址")
+	}
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, thumbnailURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := thumbnailClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("下载封面失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载封面失败: HTTP %d", resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image") {
+		return "", fmt.Errorf("响应内容不是图片: %s", contentType)
+	}
+
+	filePath := filepath.Join(saveDir, name+thumbnailExt(thumbnailURL, contentType))
+	f, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		os.Remove(filePath)
+		return "", fmt.Errorf("保存封面失败: %w", err)
+	}
+	return filePath, nil
+}
+
+// thumbnailExt 根据 Content-Type 或 URL 推断图片扩展名
+func thumbnailExt(thumbnailURL, contentType string) string {
+	switch {
+	case strings.Contains(contentType, "png"):
+		return ".png"
+	case strings.Contains(contentType, "webp"):
+		return ".webp"
+	case strings.Contains(contentType, "jpeg"), strings.Contains(contentType, "jpg"):
+		return ".jpg"
+	}
+	if u, err := url.Parse(thumbnailURL); err == nil {
+		if ext := strings.ToLower(path.Ext(u.Path)); ext == ".png" || ext == ".webp" || ext == ".jpg" || ext == ".jpeg" {
+			return ext
+		}
+	}
+	return ".jpg"
+}
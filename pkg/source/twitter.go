@@ -0,0 +1,83 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var twitterStatusRegex = regexp.MustCompile(`/status(?:es)?/(\d+)`)
+
+const twitterIDPrefix = "x."
+
+// TwitterProvider X/Twitter 平台，规范化 ID 格式: x.{推文ID}
+type TwitterProvider struct{}
+
+func NewTwitterProvider() *TwitterProvider {
+	return &TwitterProvider{}
+}
+
+func (p *TwitterProvider) Name() string {
+	return ProviderTwitter
+}
+
+func (p *TwitterProvider) Match(u *url.URL) bool {
+	return hostMatches(u.Host, "x.com", "twitter.com")
+}
+
+func (p *TwitterProvider) ParseURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if match := twitterStatusRegex.FindStringSubmatch(u.Path); len(match) > 1 {
+		return twitterIDPrefix + match[1], nil
+	}
+	return "", fmt.Errorf("无效的 X/Twitter 地址: %s", rawURL)
+}
+
+func (p *TwitterProvider) OwnsID(id string) bool {
+	return strings.HasPrefix(id, twitterIDPrefix)
+}
+
+func (p *TwitterProvider) VideoURL(id string) string {
+	return "https://x.com/i/status/" + strings.TrimPrefix(id, twitterIDPrefix)
+}
+
+// MapMetadata yt-dlp 的推文标题格式为 "作者 - 推文内容"，这里改用推文正文的第一行作为标题
+func (p *TwitterProvider) MapMetadata(raw []byte) (*Metadata, error) {
+	meta, err := decodeMetadata(raw, p.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.Description != "" {
+		firstLine := strings.TrimSpace(strings.SplitN(meta.Description, "\n", 2)[0])
+		// 去掉结尾的 t.co 短链
+		if i := strings.Index(firstLine, "https://t.co/"); i >= 0 {
+			firstLine = strings.TrimSpace(firstLine[:i])
+		}
+		if firstLine != "" {
+			if runes := []rune(firstLine); len(runes) > 80 {
+				firstLine = string(runes[:80])
+			}
+			meta.Title = firstLine
+		}
+	}
+	if meta.Channel == "" {
+		meta.Channel = meta.Uploader
+	}
+	if meta.ChannelID == "" {
+		meta.ChannelID = meta.UploaderID
+	}
+	return meta, nil
+}
+
+func (p *TwitterProvider) DownloadThumbnail(ctx context.Context, id string, meta *Metadata, saveDir string) (string, error) {
+	if meta == nil {
+		return "", fmt.Errorf("缺少视频元数据，无法获取封面地址")
+	}
+	return downloadThumbnailURL(ctx, meta.Thumbnail, saveDir, "thumbnail")
+}
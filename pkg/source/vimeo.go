@@ -0,0 +1,69 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var vimeoPathRegex = regexp.MustCompile(`(?:^|/)(\d{5,})(?:/|$)`)
+
+const vimeoIDPrefix = "vimeo."
+
+// VimeoProvider Vimeo 平台，规范化 ID 格式: vimeo.{数字ID}
+type VimeoProvider struct{}
+
+func NewVimeoProvider() *VimeoProvider {
+	return &VimeoProvider{}
+}
+
+func (p *VimeoProvider) Name() string {
+	return ProviderVimeo
+}
+
+func (p *VimeoProvider) Match(u *url.URL) bool {
+	return hostMatches(u.Host, "vimeo.com")
+}
+
+// ParseURL 支持 vimeo.com/{id}、vimeo.com/channels/{name}/{id}、player.vimeo.com/video/{id}
+func (p *VimeoProvider) ParseURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if match := vimeoPathRegex.FindStringSubmatch(u.Path); len(match) > 1 {
+		return vimeoIDPrefix + match[1], nil
+	}
+	return "", fmt.Errorf("无效的 Vimeo 地址: %s", rawURL)
+}
+
+func (p *VimeoProvider) OwnsID(id string) bool {
+	return strings.HasPrefix(id, vimeoIDPrefix)
+}
+
+func (p *VimeoProvider) VideoURL(id string) string {
+	return "https://vimeo.com/" + strings.TrimPrefix(id, vimeoIDPrefix)
+}
+
+func (p *VimeoProvider) MapMetadata(raw []byte) (*Metadata, error) {
+	meta, err := decodeMetadata(raw, p.Name())
+	if err != nil {
+		return nil, err
+	}
+	if meta.Channel == "" {
+		meta.Channel = meta.Uploader
+	}
+	if meta.ChannelID == "" {
+		meta.ChannelID = meta.UploaderID
+	}
+	return meta, nil
+}
+
+func (p *VimeoProvider) DownloadThumbnail(ctx context.Context, id string, meta *Metadata, saveDir string) (string, error) {
+	if meta == nil {
+		return "", fmt.Errorf("缺少视频元数据，无法获取封面地址")
+	}
+	return downloadThumbnailURL(ctx, meta.Thumbnail, saveDir, "thumbnail")
+}
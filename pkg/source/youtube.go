@@ -0,0 +1,95 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/difyz9/ytb2bili/pkg/utils"
+)
+
+// 平台名称
+const (
+	ProviderYouTube  = "youtube"
+	ProviderBilibili = "bilibili"
+	ProviderVimeo    = "vimeo"
+	ProviderTwitter  = "x"
+	ProviderGeneric  = "generic"
+)
+
+const youtubeIDPrefix = "yt."
+
+var (
+	youtubeURLRegex = regexp.MustCompile(`(?:v=|/)([0-9A-Za-z_-]{11})(?:[?&#/]|$)`)
+	youtubeIDRegex  = regexp.MustCompile(`^[0-9A-Za-z_-]{11}$`)
+)
+
+// YouTubeProvider YouTube 平台
+// 规范化 ID 为 yt.{视频ID}；历史数据中没有前缀的 ID 由 Registry.ForID 兼容
+type YouTubeProvider struct{}
+
+func NewYouTubeProvider() *YouTubeProvider {
+	return &YouTubeProvider{}
+}
+
+func (p *YouTubeProvider) Name() string {
+	return ProviderYouTube
+}
+
+func (p *YouTubeProvider) Match(u *url.URL) bool {
+	return hostMatches(u.Host, "youtube.com", "youtu.be", "youtube-nocookie.com")
+}
+
+func (p *YouTubeProvider) ParseURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if v := u.Query().Get("v"); youtubeIDRegex.MatchString(v) {
+		return youtubeIDPrefix + v, nil
+	}
+	// youtu.be/ID、/shorts/ID、/live/ID、/embed/ID
+	if match := youtubeURLRegex.FindStringSubmatch(u.Path); len(match) > 1 {
+		return youtubeIDPrefix + match[1], nil
+	}
+	return "", fmt.Errorf("无效的 YouTube 地址: %s", rawURL)
+}
+
+func (p *YouTubeProvider) OwnsID(id string) bool {
+	rest, ok := strings.CutPrefix(id, youtubeIDPrefix)
+	return ok && youtubeIDRegex.MatchString(rest)
+}
+
+func (p *YouTubeProvider) VideoURL(id string) string {
+	return fmt.Sprintf("https://www.youtube.com/watch?v=%s", strings.TrimPrefix(id, youtubeIDPrefix))
+}
+
+func (p *YouTubeProvider) MapMetadata(raw []byte) (*Metadata, error) {
+	return decodeMetadata(raw, p.Name())
+}
+
+// DownloadThumbnail 优先下载 img.youtube.com 的高清封面，失败时使用 info JSON 中的封面地址
+func (p *YouTubeProvider) DownloadThumbnail(ctx context.Context, id string, meta *Metadata, saveDir string) (string, error) {
+	opt := utils.DownloadOptions{
+		SavePath:         saveDir,
+		FilenameTemplate: "{quality}",
+		Timeout:          10 * time.Second,
+		MaxRetries:       3,
+		QualityFallback:  true,
+		CreateDirs:       true,
+		Overwrite:        false,
+	}
+	// best: 按 maxres → sd → hq → mq → default 依次回退
+	result := utils.DownloadYouTubeThumbnail(strings.TrimPrefix(id, youtubeIDPrefix), "best", opt, "").(utils.DownloadResult)
+	if result.Success {
+		return result.FilePath, nil
+	}
+
+	if meta != nil && meta.Thumbnail != "" {
+		return downloadThumbnailURL(ctx, meta.Thumbnail, saveDir, "thumbnail")
+	}
+	return "", fmt.Errorf("下载 YouTube 封面失败: %s", result.ErrorMessage)
+}
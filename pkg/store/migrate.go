@@ -11,5 +11,10 @@ func MigrateDatabase(db *gorm.DB) error {
 		&model.User{},
 		&model.SavedVideo{},
 		&model.TaskStep{},
+		&model.VideoSourceMeta{},
+		&model.VideoFingerprint{},
+		&model.VideoMediaInfo{},
+		&model.SubtitleTrack{},
+		&model.SubtitleEdit{},
 	)
 }
@@ -90,9 +90,32 @@ type SavedVideo struct {
 	PlaylistID       string `gorm:"type:varchar(100);index" json:"playlist_id"`                // 播放列表ID
 	Timestamp        string `gorm:"type:varchar(50)" json:"timestamp"`                         // 时间戳
 	SavedAt          string `gorm:"type:varchar(50)" json:"saved_at"`                          // 保存时间
+	SubtitleSource   string `gorm:"type:varchar(30)" json:"subtitle_source"`                   // 原始字幕来源
+	SubtitleLang     string `gorm:"type:varchar(20)" json:"subtitle_lang"`                     // 原始字幕语言
+	SourceLang       string `gorm:"type:varchar(20)" json:"source_lang"`                       // 原视频语音语言（自动检测）
+	SourceLangFrom   string `gorm:"type:varchar(20)" json:"source_lang_from"`                  // 语音语言的检测来源
+	TargetLangs      string `gorm:"type:varchar(200)" json:"target_langs"`                     // 翻译目标语言（逗号分隔，为空时按配置）
+	WakeAt           *time.Time `gorm:"index" json:"wake_at,omitempty"`                        // 等待首播/直播结束时的下次检查时间
+	StatusReason     string `gorm:"type:varchar(500)" json:"status_reason,omitempty"`          // 状态原因（不可用、等待开播等）
 }
 
 // TableName 指定表名
 func (SavedVideo) TableName() string {
 	return "cw_saved_videos"
 }
+
+// 原始字幕来源
+const (
+	SubtitleSourceExtension      = "extension"       // 浏览器插件提交的字幕
+	SubtitleSourcePlatformManual = "platform_manual" // 视频平台人工字幕
+	SubtitleSourcePlatformAuto   = "platform_auto"   // 视频平台自动生成字幕
+	SubtitleSourceWhisper        = "whisper"         // Whisper 语音识别
+)
+
+// 语音语言检测来源
+const (
+	LanguageFromSubtitle = "subtitle" // 浏览器插件提交的字幕语言
+	LanguageFromMetadata = "metadata" // yt-dlp 元数据
+	LanguageFromWhisper  = "whisper"  // Whisper 语言检测
+	LanguageFromDefault  = "default"  // 检测失败时的默认语言
+)
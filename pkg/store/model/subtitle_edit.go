@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// SubtitleTrack 人工审核的字幕轨版本（乐观锁）
+type SubtitleTrack struct {
+	BaseModel
+	VideoID  string    `gorm:"type:varchar(100);uniqueIndex:idx_subtitle_track;not null" json:"video_id"` // 关联 SavedVideo.VideoID
+	Lang     string    `gorm:"type:varchar(20);uniqueIndex:idx_subtitle_track;not null" json:"lang"`      // 字幕语言代码（BCP-47）
+	Version  int       `gorm:"not null;default:0" json:"version"`                                         // 最近一次保存的版本号
+	Checksum string    `gorm:"type:varchar(64)" json:"-"`                                                 // 最近一次保存的文件哈希，不一致说明任务链重新生成过字幕
+	CueCount int       `json:"cue_count"`                                                                 // 字幕条数
+	EditedAt time.Time `json:"edited_at"`                                                                 // 最近一次编辑时间
+}
+
+// TableName 指定表名
+func (SubtitleTrack) TableName() string {
+	return "cw_subtitle_tracks"
+}
+
+// SubtitleEdit 单条字幕的修改记录
+type SubtitleEdit struct {
+	BaseModel
+	VideoID  string `gorm:"type:varchar(100);index:idx_subtitle_edit;not null" json:"video_id"` // 关联 SavedVideo.VideoID
+	Lang     string `gorm:"type:varchar(20);index:idx_subtitle_edit;not null" json:"lang"`      // 字幕语言代码
+	Version  int    `gorm:"not null" json:"version"`                                            // 修改后的版本号
+	CueIndex int    `gorm:"index" json:"cue_index"`                                             // 字幕序号（从 1 开始）
+	Action   string `gorm:"type:varchar(20)" json:"action"`                                     // edit / insert / delete / regenerate
+	OldStart int64  `json:"old_start_ms"`                                                       // 修改前开始时间（毫秒）
+	OldEnd   int64  `json:"old_end_ms"`                                                         // 修改前结束时间（毫秒）
+	OldText  string `gorm:"type:text" json:"old_text"`                                          // 修改前文本
+	NewStart int64  `json:"new_start_ms"`                                                       // 修改后开始时间（毫秒）
+	NewEnd   int64  `json:"new_end_ms"`                                                         // 修改后结束时间（毫秒）
+	NewText  string `gorm:"type:text" json:"new_text"`                                          // 修改后文本
+}
+
+// TableName 指定表名
+func (SubtitleEdit) TableName() string {
+	return "cw_subtitle_edits"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// VideoFingerprint 视频指纹（用于识别重新上传、搬运频道等重复内容）
+type VideoFingerprint struct {
+	BaseModel
+	VideoID      string     `gorm:"type:varchar(100);uniqueIndex;not null" json:"video_id"` // 关联 SavedVideo.VideoID
+	SavedVideoID uint       `gorm:"index" json:"saved_video_id"`                            // 关联 SavedVideo.ID
+	Duration     float64    `gorm:"index" json:"duration"`                                  // 时长（秒）
+	FrameHashes  string     `gorm:"type:text" json:"-"`                                     // 抽样帧 dHash（逗号分隔的十六进制）
+	AudioHash    string     `gorm:"type:text" json:"-"`                                     // 音频能量指纹（位数:十六进制）
+	DuplicateOf  string     `gorm:"type:varchar(100);index" json:"duplicate_of"`            // 疑似重复的视频ID
+	Similarity   float64    `json:"similarity"`                                             // 综合相似度
+	FrameScore   float64    `json:"frame_score"`                                            // 抽样帧相似度
+	AudioScore   float64    `json:"audio_score"`                                            // 音频相似度（-1 表示无法比较）
+	Overridden   bool       `gorm:"default:false" json:"overridden"`                        // 是否已人工放行
+	ReviewedAt   *time.Time `json:"reviewed_at"`                                            // 人工处理时间
+}
+
+// TableName 指定表名
+func (VideoFingerprint) TableName() string {
+	return "cw_video_fingerprints"
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MediaProblem 不符合投稿限制的检查项
+type MediaProblem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// VideoMediaInfo 视频文件媒体信息（ffprobe）及投稿限制检查结果
+type VideoMediaInfo struct {
+	BaseModel
+	VideoID      string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"video_id"` // 关联 SavedVideo.VideoID
+	SavedVideoID uint      `gorm:"index" json:"saved_video_id"`                            // 关联 SavedVideo.ID
+	FilePath     string    `gorm:"type:varchar(500)" json:"file_path"`                     // 检查的文件路径
+	Container    string    `gorm:"type:varchar(20)" json:"container"`                      // 容器（文件扩展名）
+	FormatName   string    `gorm:"type:varchar(100)" json:"format_name"`                   // ffprobe format_name
+	VideoCodec   string    `gorm:"type:varchar(50)" json:"video_codec"`                    // 视频编码
+	AudioCodec   string    `gorm:"type:varchar(50)" json:"audio_codec"`                    // 音频编码
+	Width        int       `json:"width"`                                                  // 宽度
+	Height       int       `json:"height"`                                                 // 高度
+	FPS          float64   `json:"fps"`                                                    // 帧率
+	Duration     float64   `json:"duration"`                                               // 时长（秒）
+	Size         int64     `json:"size"`                                                   // 文件大小（字节）
+	BitRate      int64     `json:"bit_rate"`                                               // 总码率（bit/s）
+	Streams      string    `gorm:"type:text" json:"streams"`                               // 所有流信息（JSON数组）
+	Problems     string    `gorm:"type:text" json:"problems"`                              // 不符合限制的检查项（JSON数组）
+	Transcoded   bool      `gorm:"default:false" json:"transcoded"`                        // 是否已自动转码
+	CheckedAt    time.Time `json:"checked_at"`                                             // 检查时间
+}
+
+// TableName 指定表名
+func (VideoMediaInfo) TableName() string {
+	return "cw_video_media_infos"
+}
+
+// GetProblems 解析检查项列表
+func (m *VideoMediaInfo) GetProblems() []MediaProblem {
+	var problems []MediaProblem
+	if m.Problems == "" {
+		return problems
+	}
+	if err := json.Unmarshal([]byte(m.Problems), &problems); err != nil {
+		return nil
+	}
+	return problems
+}
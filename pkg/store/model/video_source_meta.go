@@ -0,0 +1,81 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// VideoChapter 源视频章节信息（来自 yt-dlp chapters 字段）
+type VideoChapter struct {
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Title     string  `json:"title"`
+}
+
+// VideoSourceMeta 源视频元数据（从 yt-dlp info JSON 中提取的关键字段）
+type VideoSourceMeta struct {
+	BaseModel
+	VideoID      string  `gorm:"type:varchar(100);uniqueIndex;not null" json:"video_id"` // 关联 SavedVideo.VideoID
+	SavedVideoID uint    `gorm:"index" json:"saved_video_id"`                            // 关联 SavedVideo.ID
+	Platform     string  `gorm:"type:varchar(20);index" json:"platform"`                 // 来源平台（youtube/bilibili/vimeo/x/generic）
+	Extractor    string  `gorm:"type:varchar(50)" json:"extractor"`                      // yt-dlp 提取器（youtube/bilibili等）
+	Uploader     string  `gorm:"type:varchar(255)" json:"uploader"`                      // 上传者
+	UploaderID   string  `gorm:"type:varchar(255)" json:"uploader_id"`                   // 上传者ID
+	Channel      string  `gorm:"type:varchar(255)" json:"channel"`                       // 频道名称
+	ChannelID    string  `gorm:"type:varchar(100);index" json:"channel_id"`              // 频道ID
+	UploadDate   string  `gorm:"type:varchar(20)" json:"upload_date"`                    // 上传日期（YYYYMMDD）
+	Duration     float64 `json:"duration"`                                               // 时长（秒）
+	Tags         string  `gorm:"type:text" json:"tags"`                                  // 标签（JSON数组）
+	Categories   string  `gorm:"type:text" json:"categories"`                            // 分类（JSON数组）
+	Chapters     string  `gorm:"type:text" json:"chapters"`                              // 章节（JSON数组）
+	ViewCount    int64   `json:"view_count"`                                             // 播放量
+	LikeCount    int64   `json:"like_count"`                                             // 点赞数
+	Language     string  `gorm:"type:varchar(20)" json:"language"`                       // 视频语言
+	WebpageURL   string  `gorm:"type:varchar(500)" json:"webpage_url"`                   // 原始页面地址
+	InfoJSONPath string  `gorm:"type:varchar(500)" json:"info_json_path"`                // 完整 info JSON 文件路径
+}
+
+// TableName 指定表名
+func (VideoSourceMeta) TableName() string {
+	return "cw_video_source_metas"
+}
+
+// GetTags 解析标签列表
+func (m *VideoSourceMeta) GetTags() []string {
+	return decodeStringList(m.Tags)
+}
+
+// GetCategories 解析分类列表
+func (m *VideoSourceMeta) GetCategories() []string {
+	return decodeStringList(m.Categories)
+}
+
+// GetChapters 解析章节列表
+func (m *VideoSourceMeta) GetChapters() []VideoChapter {
+	var chapters []VideoChapter
+	if m.Chapters == "" {
+		return chapters
+	}
+	if err := json.Unmarshal([]byte(m.Chapters), &chapters); err != nil {
+		return nil
+	}
+	return chapters
+}
+
+// decodeStringList 解析 JSON 字符串数组，兼容逗号分隔格式
+func decodeStringList(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(raw), &list); err == nil {
+		return list
+	}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
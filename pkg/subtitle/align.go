@@ -0,0 +1,453 @@
+package subtitle
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AlignOptions 强制对齐参数
+type AlignOptions struct {
+	MinSimilarity float64       // 词相似度阈值（0-1），低于该值不视为同一个词
+	MaxDrift      time.Duration // 字幕带有粗略时间时，与识别时间相差超过该值的词不匹配，0 表示不限制
+}
+
+// DefaultAlignOptions 默认对齐参数
+func DefaultAlignOptions() AlignOptions {
+	return AlignOptions{
+		MinSimilarity: 0.6,
+		MaxDrift:      30 * time.Second,
+	}
+}
+
+// AlignedCue 对齐后的字幕
+type AlignedCue struct {
+	Cue
+	Confidence float64 `json:"confidence"` // 对齐置信度（0-1）: 与识别结果匹配的字符比例，按词相似度加权
+}
+
+// alignUnit 对齐的最小单位（拉丁文字为词，中日韩文字为单字）
+type alignUnit struct {
+	Word
+	norm  string // 归一化文本（小写，去掉标点）
+	cue   int    // 所属字幕（仅字幕一侧）
+	match int    // 匹配的识别词下标，-1 表示未匹配
+	sim   float64
+}
+
+// maxAlignCells 两个锚点之间逐词比对的最大规模，超出时该区间不匹配（按相邻时间插值）
+const maxAlignCells = 4_000_000
+
+// Align 将字幕文本与语音识别的词级时间戳对齐，重新计算每条字幕的时间
+// 先以两侧唯一出现的三词组作为锚点，再在锚点之间按词相似度逐词比对；
+// 未匹配的词在相邻已匹配词之间按字数插值，完全未匹配的字幕保留原时间（位于相邻字幕之间时）或按字数分配
+func Align(cues []Cue, words []Word, opts AlignOptions) []AlignedCue {
+	if opts.MinSimilarity <= 0 {
+		opts.MinSimilarity = DefaultAlignOptions().MinSimilarity
+	}
+
+	// 1. 拆分为对齐单位
+	var text []alignUnit
+	timed := false
+	for i, cue := range cues {
+		timed = timed || cue.End > 0
+		for _, tok := range tokenize([]Cue{cue}) {
+			text = append(text, alignUnit{Word: tok.Word, norm: normalizeWord(tok.Text), cue: i, match: -1})
+		}
+	}
+	var speech []alignUnit
+	for _, w := range words {
+		for _, tok := range tokenize([]Cue{{Start: w.Start, End: w.End, Text: w.Text}}) {
+			if norm := normalizeWord(tok.Text); norm != "" {
+				speech = append(speech, alignUnit{Word: tok.Word, norm: norm, match: -1})
+			}
+		}
+	}
+	if !timed {
+		opts.MaxDrift = 0
+	}
+
+	// 2. 锚点与区间比对
+	a := aligner{text: text, speech: speech, opts: opts}
+	a.anchor()
+	a.fillGaps()
+
+	// 3. 计算时间与置信度
+	return a.retime(cues)
+}
+
+// TimedWords 展开字幕中的词，没有词级时间戳的字幕按字数分配时间
+func TimedWords(cues []Cue) []Word {
+	var words []Word
+	for _, tok := range tokenize(cues) {
+		words = append(words, tok.Word)
+	}
+	return words
+}
+
+type aligner struct {
+	text   []alignUnit
+	speech []alignUnit
+	opts   AlignOptions
+}
+
+// similar 两个单位的相似度，不满足阈值或时间偏差过大时返回 0
+func (a *aligner) similar(i, j int) float64 {
+	t, s := &a.text[i], &a.speech[j]
+	if t.norm == "" {
+		return 0
+	}
+	if a.opts.MaxDrift > 0 {
+		if d := t.Start - s.Start; d > a.opts.MaxDrift || -d > a.opts.MaxDrift {
+			return 0
+		}
+	}
+	sim := similarity(t.norm, s.norm)
+	if sim < a.opts.MinSimilarity {
+		return 0
+	}
+	return sim
+}
+
+// link 记录匹配
+func (a *aligner) link(i, j int, sim float64) {
+	a.text[i].match, a.text[i].sim = j, sim
+	a.speech[j].match = i
+}
+
+// anchor 以两侧都只出现一次的三词组为锚点，取时间顺序一致的最长锚点序列
+func (a *aligner) anchor() {
+	const n = 3
+	key := func(units []alignUnit, i int) string {
+		parts := make([]string, n)
+		for k := 0; k < n; k++ {
+			if units[i+k].norm == "" {
+				return ""
+			}
+			parts[k] = units[i+k].norm
+		}
+		return strings.Join(parts, "\x00")
+	}
+	index := func(units []alignUnit) map[string]int {
+		seen := make(map[string]int)
+		for i := 0; i+n <= len(units); i++ {
+			if k := key(units, i); k != "" {
+				if _, ok := seen[k]; ok {
+					seen[k] = -1
+				} else {
+					seen[k] = i
+				}
+			}
+		}
+		return seen
+	}
+
+	speechIndex := index(a.speech)
+	var pairs [][2]int
+	for i := 0; i+n <= len(a.text); i++ {
+		k := key(a.text, i)
+		if k == "" {
+			continue
+		}
+		if j, ok := speechIndex[k]; ok && j >= 0 && a.similar(i, j) > 0 {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	// 文本一侧同样要求唯一
+	textIndex := index(a.text)
+	unique := pairs[:0]
+	for _, p := range pairs {
+		if textIndex[key(a.text, p[0])] == p[0] {
+			unique = append(unique, p)
+		}
+	}
+
+	lastI, lastJ := -1, -1
+	for _, p := range longestIncreasing(unique) {
+		for k := 0; k < n; k++ {
+			i, j := p[0]+k, p[1]+k
+			if i > lastI && j > lastJ {
+				a.link(i, j, 1)
+				lastI, lastJ = i, j
+			}
+		}
+	}
+}
+
+// longestIncreasing 按文本位置排序的锚点中，识别位置严格递增的最长子序列
+func longestIncreasing(pairs [][2]int) [][2]int {
+	var tails []int // tails[k]: 长度为 k+1 的子序列末尾锚点下标
+	prev := make([]int, len(pairs))
+	for i, p := range pairs {
+		k := sort.Search(len(tails), func(k int) bool { return pairs[tails[k]][1] >= p[1] })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	if len(tails) == 0 {
+		return nil
+	}
+	out := make([][2]int, len(tails))
+	k := tails[len(tails)-1]
+	for i := len(tails) - 1; i >= 0; i-- {
+		out[i] = pairs[k]
+		k = prev[k]
+	}
+	return out
+}
+
+// fillGaps 在相邻锚点之间逐词比对（按相似度加权的最长公共子序列）
+func (a *aligner) fillGaps() {
+	i0, j0 := 0, 0
+	for i := 0; i <= len(a.text); i++ {
+		if i < len(a.text) && a.text[i].match < 0 {
+			continue
+		}
+		j1 := len(a.speech)
+		if i < len(a.text) {
+			j1 = a.text[i].match
+		}
+		a.matchRange(i0, i, j0, j1)
+		if i < len(a.text) {
+			i0, j0 = i+1, j1+1
+		}
+	}
+}
+
+// matchRange 比对 text[i0:i1] 与 speech[j0:j1]
+func (a *aligner) matchRange(i0, i1, j0, j1 int) {
+	n, m := i1-i0, j1-j0
+	if n <= 0 || m <= 0 || n*m > maxAlignCells {
+		return
+	}
+	score := make([]float32, (n+1)*(m+1))
+	at := func(i, j int) *float32 { return &score[i*(m+1)+j] }
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			best := max(*at(i-1, j), *at(i, j-1))
+			if sim := a.similar(i0+i-1, j0+j-1); sim > 0 {
+				best = max(best, *at(i-1, j-1)+float32(sim))
+			}
+			*at(i, j) = best
+		}
+	}
+	for i, j := n, m; i > 0 && j > 0; {
+		switch {
+		case *at(i, j) == *at(i-1, j):
+			i--
+		case *at(i, j) == *at(i, j-1):
+			j--
+		default:
+			a.link(i0+i-1, j0+j-1, a.similar(i0+i-1, j0+j-1))
+			i--
+			j--
+		}
+	}
+}
+
+// retime 按匹配结果计算每条字幕及其中每个词的时间
+func (a *aligner) retime(cues []Cue) []AlignedCue {
+	out := make([]AlignedCue, len(cues))
+	units := make([][]alignUnit, len(cues))
+	for _, u := range a.text {
+		units[u.cue] = append(units[u.cue], u)
+	}
+
+	resolved := make([]bool, len(cues))
+	for c, cue := range cues {
+		out[c] = AlignedCue{Cue: Cue{Start: cue.Start, End: cue.End, Text: cue.Text}}
+		var matched, total float64
+		for k := range units[c] {
+			u := &units[c][k]
+			chars := float64(CharCount(u.norm))
+			total += chars
+			if u.match >= 0 {
+				u.Start, u.End = a.speech[u.match].Start, a.speech[u.match].End
+				matched += chars * u.sim
+			}
+		}
+		if total > 0 {
+			out[c].Confidence = matched / total
+		}
+		if matched > 0 {
+			resolved[c] = true
+			interpolateUnits(units[c])
+			out[c].Start, out[c].End = units[c][0].Start, units[c][len(units[c])-1].End
+		}
+	}
+
+	// 完全未匹配的字幕: 原时间位于相邻字幕之间时保留，否则按字数分配相邻字幕之间的时间
+	for c := 0; c < len(cues); {
+		if resolved[c] {
+			c++
+			continue
+		}
+		end := c
+		for end < len(cues) && !resolved[end] {
+			end++
+		}
+		from := time.Duration(0)
+		if c > 0 {
+			from = out[c-1].End
+		}
+		to := from
+		if end < len(cues) {
+			to = out[end].Start
+		} else if len(a.speech) > 0 {
+			to = max(from, a.speech[len(a.speech)-1].End)
+		}
+		a.placeUnresolved(out[c:end], units[c:end], from, to)
+		c = end
+	}
+
+	// 消除重叠
+	for c := range out {
+		cue := &out[c]
+		if c > 0 {
+			cue.Start = max(cue.Start, out[c-1].End)
+		}
+		cue.End = max(cue.End, cue.Start+100*time.Millisecond)
+		for _, u := range units[c] {
+			cue.Words = append(cue.Words, Word{
+				Start: min(max(u.Start, cue.Start), cue.End),
+				End:   min(max(u.End, cue.Start), cue.End),
+				Text:  u.Text,
+			})
+		}
+	}
+	return out
+}
+
+// placeUnresolved 为连续的未匹配字幕分配 [from, to] 之间的时间
+func (a *aligner) placeUnresolved(cues []AlignedCue, units [][]alignUnit, from, to time.Duration) {
+	keep := true
+	prev := from
+	for _, cue := range cues {
+		if cue.End <= cue.Start || cue.Start < prev || (to > from && cue.End > to) {
+			keep = false
+			break
+		}
+		prev = cue.End
+	}
+
+	chars := 0
+	for _, cue := range cues {
+		chars += max(CharCount(cue.Text), 1)
+	}
+	if to <= from {
+		// 没有可用区间（如位于末尾）时按每秒 15 字估算
+		to = from + time.Duration(chars)*time.Second/15
+	}
+	offset := 0
+	for k := range cues {
+		n := max(CharCount(cues[k].Text), 1)
+		if !keep {
+			cues[k].Start = from + (to-from)*time.Duration(offset)/time.Duration(chars)
+			cues[k].End = from + (to-from)*time.Duration(offset+n)/time.Duration(chars)
+		}
+		offset += n
+		spreadUnits(units[k], cues[k].Start, cues[k].End)
+	}
+}
+
+// interpolateUnits 未匹配的词在相邻已匹配词之间按字数插值，首尾按已匹配部分的语速外推
+func interpolateUnits(units []alignUnit) {
+	var matchedDur time.Duration
+	matchedChars := 0
+	for _, u := range units {
+		if u.match >= 0 {
+			matchedDur += u.End - u.Start
+			matchedChars += max(CharCount(u.Text), 1)
+		}
+	}
+	perChar := time.Duration(0)
+	if matchedChars > 0 {
+		perChar = matchedDur / time.Duration(matchedChars)
+	}
+
+	for k := 0; k < len(units); {
+		if units[k].match >= 0 {
+			k++
+			continue
+		}
+		end := k
+		chars := 0
+		for end < len(units) && units[end].match < 0 {
+			chars += max(CharCount(units[end].Text), 1)
+			end++
+		}
+		var from, to time.Duration
+		switch {
+		case k > 0 && end < len(units):
+			from, to = units[k-1].End, units[end].Start
+		case k > 0:
+			from = units[k-1].End
+			to = from + perChar*time.Duration(chars)
+		default:
+			to = units[end].Start
+			from = max(to-perChar*time.Duration(chars), 0)
+		}
+		spreadUnits(units[k:end], from, max(from, to))
+		k = end
+	}
+}
+
+// spreadUnits 在 [from, to] 内按字数分配时间
+func spreadUnits(units []alignUnit, from, to time.Duration) {
+	total := 0
+	for _, u := range units {
+		total += max(CharCount(u.Text), 1)
+	}
+	offset := 0
+	for k := range units {
+		n := max(CharCount(units[k].Text), 1)
+		units[k].Start = from + (to-from)*time.Duration(offset)/time.Duration(total)
+		units[k].End = from + (to-from)*time.Duration(offset+n)/time.Duration(total)
+		offset += n
+	}
+}
+
+// normalizeWord 比较用的词: 小写，只保留字母和数字
+func normalizeWord(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// similarity 按编辑距离计算的相似度（0-1）
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			diag, row[j] = row[j], min(row[j]+1, row[j-1]+1, diag+cost)
+		}
+	}
+	return 1 - float64(row[len(rb)])/float64(max(len(ra), len(rb)))
+}
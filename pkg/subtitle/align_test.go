@@ -0,0 +1,119 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// speechWords 按每词 0.4 秒生成识别结果，"|" 表示 2 秒停顿
+func speechWords(text string, start time.Duration) []Word {
+	var words []Word
+	at := start
+	for _, w := range strings.Fields(text) {
+		if w == "|" {
+			at += 2 * time.Second
+			continue
+		}
+		words = append(words, Word{Start: at, End: at + 400*time.Millisecond, Text: w})
+		at += 400 * time.Millisecond
+	}
+	return words
+}
+
+func near(a, b time.Duration) bool {
+	d := a - b
+	return d < 50*time.Millisecond && d > -50*time.Millisecond
+}
+
+func TestAlignWithoutTimings(t *testing.T) {
+	// 识别结果多了语气词、有拼写差异
+	words := speechWords("um hello everyone and welcome back | today we're gonna build a tiny compiler | it takes about ten minutes", sec(1))
+	cues := []Cue{
+		{Text: "Hello everyone, and welcome back."},
+		{Text: "Today we're going to build a tiny compiler."},
+		{Text: "It takes about 10 minutes."},
+	}
+	got := Align(cues, words, DefaultAlignOptions())
+	if len(got) != 3 {
+		t.Fatalf("got %+v", got)
+	}
+
+	// 第一条从 hello 开始（跳过 um），到 back 结束
+	if !near(got[0].Start, sec(1.4)) || !near(got[0].End, sec(3.4)) {
+		t.Errorf("cue 0 = %v-%v", got[0].Start, got[0].End)
+	}
+	if !near(got[1].Start, sec(5.4)) || !near(got[1].End, sec(8.2)) {
+		t.Errorf("cue 1 = %v-%v", got[1].Start, got[1].End)
+	}
+	if !near(got[2].Start, sec(10.2)) || !near(got[2].End, sec(12.2)) {
+		t.Errorf("cue 2 = %v-%v", got[2].Start, got[2].End)
+	}
+
+	if got[0].Confidence != 1 {
+		t.Errorf("cue 0 confidence = %v", got[0].Confidence)
+	}
+	// "gonna" 与 "going" 近似匹配、"10" 与 "ten" 不同，置信度下降但仍然较高
+	for _, c := range got[1:] {
+		if c.Confidence >= 1 || c.Confidence < 0.6 {
+			t.Errorf("confidence = %v for %q", c.Confidence, c.Text)
+		}
+	}
+	if len(got[1].Words) != 8 || got[1].Words[2].Text != "going" || !near(got[1].Words[2].Start, sec(6.2)) {
+		t.Errorf("words = %+v", got[1].Words)
+	}
+}
+
+func TestAlignRoughTimingsAndUnspokenCue(t *testing.T) {
+	words := speechWords("the quick brown fox | jumps over the lazy dog", sec(10))
+	cues := []Cue{
+		{Start: sec(8), End: sec(9), Text: "The quick brown fox"},
+		{Start: sec(11.7), End: sec(12.2), Text: "[music]"},
+		{Start: sec(12), End: sec(14), Text: "jumps over the lazy dog"},
+		{Start: sec(40), End: sec(41), Text: "Subscribe!"},
+	}
+	got := Align(cues, words, DefaultAlignOptions())
+
+	if !near(got[0].Start, sec(10)) || !near(got[0].End, sec(11.6)) {
+		t.Errorf("cue 0 = %v-%v", got[0].Start, got[0].End)
+	}
+	// 未匹配但原时间位于相邻字幕之间，保留原时间
+	if got[1].Confidence != 0 || got[1].Start != sec(11.7) || got[1].End != sec(12.2) {
+		t.Errorf("cue 1 = %+v", got[1])
+	}
+	if !near(got[2].Start, sec(13.6)) || !near(got[2].End, sec(15.6)) || got[2].Confidence != 1 {
+		t.Errorf("cue 2 = %+v", got[2])
+	}
+	// 末尾未匹配的字幕不早于上一条结束
+	if got[3].Confidence != 0 || got[3].Start < got[2].End {
+		t.Errorf("cue 3 = %+v", got[3])
+	}
+}
+
+func TestAlignRejectsDistantMatches(t *testing.T) {
+	// 重复出现的句子按粗略时间匹配到较近的一次
+	words := append(speechWords("thank you", sec(2)), speechWords("thank you", sec(100))...)
+	cues := []Cue{{Start: sec(99), End: sec(100), Text: "Thank you."}}
+	got := Align(cues, words, DefaultAlignOptions())
+	if !near(got[0].Start, sec(100)) || got[0].Confidence != 1 {
+		t.Errorf("got %+v", got[0])
+	}
+}
+
+func TestAlignCJK(t *testing.T) {
+	var words []Word
+	for i, r := range []rune("大家好欢迎回来今天我们聊聊编译器") {
+		words = append(words, Word{Start: sec(float64(i) * 0.25), End: sec(float64(i+1) * 0.25), Text: string(r)})
+	}
+	cues := []Cue{{Text: "大家好，欢迎回来。"}, {Text: "今天我们聊一聊编译器。"}}
+	got := Align(cues, words, DefaultAlignOptions())
+	if got[0].Start != 0 || !near(got[0].End, sec(1.75)) || got[0].Confidence != 1 {
+		t.Errorf("cue 0 = %+v", got[0])
+	}
+	if !near(got[1].Start, sec(1.75)) || !near(got[1].End, sec(4)) {
+		t.Errorf("cue 1 = %v-%v", got[1].Start, got[1].End)
+	}
+	if c := got[1].Confidence; c < 0.8 || c >= 1 {
+		t.Errorf("cue 1 confidence = %v", c)
+	}
+}
@@ -0,0 +1,187 @@
+package subtitle
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var assOverrideRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// assDefaultFormat 缺少 Format 行时的 [Events] 字段顺序（ASS v4+）
+var assDefaultFormat = []string{"layer", "start", "end", "style", "name", "marginl", "marginr", "marginv", "effect", "text"}
+
+// ASSStyle ASS 样式（颜色为 &HAABBGGRR 格式）
+type ASSStyle struct {
+	Name          string
+	FontName      string
+	FontSize      int
+	PrimaryColour string  // 文字颜色
+	OutlineColour string  // 描边颜色
+	BackColour    string  // 阴影/背景颜色
+	Bold          bool    // 粗体
+	BorderStyle   int     // 1=描边+阴影，3=不透明背景框
+	Outline       float64 // 描边宽度
+	Shadow        float64 // 阴影距离
+	Alignment     int     // 小键盘布局: 2=底部居中，8=顶部居中
+	MarginV       int     // 垂直边距
+}
+
+// ASSOptions ASS 输出参数
+type ASSOptions struct {
+	PlayResX int
+	PlayResY int
+	Styles   []ASSStyle // 第一个为默认样式，字幕的 Style 不在列表中时使用默认样式
+}
+
+// DefaultASSStyle 默认样式: 底部居中白字黑边（按 1080p 画布）
+func DefaultASSStyle() ASSStyle {
+	return ASSStyle{
+		Name:          "Default",
+		FontName:      "Noto Sans CJK SC",
+		FontSize:      56,
+		PrimaryColour: "&H00FFFFFF",
+		OutlineColour: "&H00000000",
+		BackColour:    "&H80000000",
+		BorderStyle:   1,
+		Outline:       2.5,
+		Shadow:        0,
+		Alignment:     2,
+		MarginV:       48,
+	}
+}
+
+// DefaultASSOptions 默认 ASS 输出参数（1920x1080 画布，单一默认样式）
+func DefaultASSOptions() ASSOptions {
+	return ASSOptions{PlayResX: 1920, PlayResY: 1080, Styles: []ASSStyle{DefaultASSStyle()}}
+}
+
+// ParseASS 解析 ASS / SSA 字幕的 [Events] 段
+// 按 Format 行确定字段顺序，去除 {} 覆盖标签，\N 转为换行；Name 字段写入 Speaker，Style 字段写入 Style
+func ParseASS(content string) ([]Cue, error) {
+	var cues []Cue
+	section := ""
+	format := assDefaultFormat
+	events := false
+
+	for _, line := range splitLines(content) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line)
+			events = events || section == "[events]"
+			continue
+		}
+		if section != "[events]" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "format":
+			format = nil
+			for _, field := range strings.Split(value, ",") {
+				format = append(format, strings.ToLower(strings.TrimSpace(field)))
+			}
+		case "dialogue":
+			fields := strings.SplitN(strings.TrimLeft(value, " "), ",", len(format))
+			if len(fields) < len(format) {
+				continue
+			}
+			var cue Cue
+			var startOK, endOK bool
+			for i, name := range format {
+				switch name {
+				case "start":
+					cue.Start, startOK = parseTimestamp(fields[i])
+				case "end":
+					cue.End, endOK = parseTimestamp(fields[i])
+				case "style":
+					cue.Style = strings.TrimPrefix(strings.TrimSpace(fields[i]), "*")
+				case "name", "actor":
+					cue.Speaker = strings.TrimSpace(fields[i])
+				case "text":
+					cue.Text = assText(fields[i])
+				}
+			}
+			if startOK && endOK && cue.Text != "" {
+				cues = append(cues, cue)
+			}
+		}
+	}
+
+	if !events {
+		return nil, fmt.Errorf("不是有效的 ASS 字幕: 缺少 [Events]")
+	}
+	// 事件可以乱序，按开始时间排序
+	sort.SliceStable(cues, func(i, j int) bool { return cues[i].Start < cues[j].Start })
+	return cues, nil
+}
+
+// assText 去除覆盖标签，转换换行与硬空格
+func assText(text string) string {
+	text = assOverrideRegex.ReplaceAllString(text, "")
+	return cleanLines(strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(text))
+}
+
+// FormatASS 生成 ASS 字幕内容
+func FormatASS(cues []Cue, opts ASSOptions) string {
+	if len(opts.Styles) == 0 {
+		opts.Styles = []ASSStyle{DefaultASSStyle()}
+	}
+	if opts.PlayResX <= 0 || opts.PlayResY <= 0 {
+		opts.PlayResX, opts.PlayResY = 1920, 1080
+	}
+	styles := make(map[string]bool)
+	for _, s := range opts.Styles {
+		styles[s.Name] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[Script Info]\nScriptType: v4.00+\nWrapStyle: 0\nScaledBorderAndShadow: yes\n")
+	fmt.Fprintf(&sb, "PlayResX: %d\nPlayResY: %d\n\n", opts.PlayResX, opts.PlayResY)
+
+	sb.WriteString("[V4+ Styles]\n")
+	sb.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	for _, s := range opts.Styles {
+		bold := 0
+		if s.Bold {
+			bold = -1
+		}
+		fmt.Fprintf(&sb, "Style: %s,%s,%d,%s,%s,%s,%s,%d,0,0,0,100,100,0,0,%d,%s,%s,%d,20,20,%d,1\n",
+			s.Name, s.FontName, s.FontSize, s.PrimaryColour, s.PrimaryColour, s.OutlineColour, s.BackColour,
+			bold, max(s.BorderStyle, 1), formatASSFloat(s.Outline), formatASSFloat(s.Shadow), s.Alignment, s.MarginV)
+	}
+
+	sb.WriteString("\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, cue := range cues {
+		style := cue.Style
+		if !styles[style] {
+			style = opts.Styles[0].Name
+		}
+		text := strings.ReplaceAll(strings.TrimSpace(cue.Text), "\n", `\N`)
+		fmt.Fprintf(&sb, "Dialogue: 0,%s,%s,%s,%s,0,0,0,,%s\n",
+			FormatASSTime(cue.Start), FormatASSTime(cue.End), style, strings.ReplaceAll(cue.Speaker, ",", " "), text)
+	}
+	return sb.String()
+}
+
+// WriteASS 写入 ASS 字幕文件
+func WriteASS(path string, cues []Cue, opts ASSOptions) error {
+	return os.WriteFile(path, []byte(FormatASS(cues, opts)), 0644)
+}
+
+// FormatASSTime 格式化为 ASS 时间格式 (H:MM:SS.cc)
+func FormatASSTime(d time.Duration) string {
+	cs := (max(d, 0) + 5*time.Millisecond) / (10 * time.Millisecond)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// formatASSFloat 去掉多余小数位
+func formatASSFloat(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
@@ -0,0 +1,87 @@
+package subtitle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// bccDocument B站 BCC 字幕（JSON）
+type bccDocument struct {
+	FontSize        float64   `json:"font_size"`
+	FontColor       string    `json:"font_color"`
+	BackgroundAlpha float64   `json:"background_alpha"`
+	BackgroundColor string    `json:"background_color"`
+	Stroke          string    `json:"Stroke"`
+	Body            []bccItem `json:"body"`
+}
+
+// bccItem BCC 字幕条目，时间单位为秒
+type bccItem struct {
+	From     float64 `json:"from"`
+	To       float64 `json:"to"`
+	Location int     `json:"location"` // 2=底部居中
+	Content  string  `json:"content"`
+}
+
+// ParseBCC 解析 B站 BCC 字幕
+func ParseBCC(data []byte) ([]Cue, error) {
+	var doc bccDocument
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\ufeff")), &doc); err != nil {
+		return nil, fmt.Errorf("解析 BCC 字幕失败: %w", err)
+	}
+	var cues []Cue
+	for _, item := range doc.Body {
+		text := cleanLines(item.Content)
+		if text == "" {
+			continue
+		}
+		cues = append(cues, Cue{Start: bccTime(item.From), End: bccTime(item.To), Text: text})
+	}
+	return cues, nil
+}
+
+// FormatBCC 生成 B站 BCC 字幕内容（默认样式: 字号 0.4，白字，半透明背景）
+func FormatBCC(cues []Cue) ([]byte, error) {
+	doc := bccDocument{
+		FontSize:        0.4,
+		FontColor:       "#FFFFFF",
+		BackgroundAlpha: 0.5,
+		BackgroundColor: "#9C27B0",
+		Stroke:          "none",
+		Body:            make([]bccItem, 0, len(cues)),
+	}
+	for _, cue := range cues {
+		doc.Body = append(doc.Body, bccItem{
+			From:     cue.Start.Seconds(),
+			To:       cue.End.Seconds(),
+			Location: 2,
+			Content:  cue.Text,
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteBCC 写入 BCC 字幕文件
+func WriteBCC(path string, cues []Cue) error {
+	data, err := FormatBCC(cues)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// bccTime 秒转换为时间（精确到毫秒）
+func bccTime(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds*1000)) * time.Millisecond
+}
@@ -0,0 +1,115 @@
+package subtitle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BilingualCue 双语字幕条目，Primary 为第一行，Secondary 为第二行
+type BilingualCue struct {
+	Start     time.Duration
+	End       time.Duration
+	Primary   string
+	Secondary string
+}
+
+// Bilingual 合并译文与原文字幕为双语字幕，时间轴以译文为准
+// 译文断句后与原文不再一一对应：每条原文归入重叠最多的译文，没有分到原文的译文取重叠最多的原文
+// sourceFirst 为 true 时原文在第一行
+func Bilingual(target, source []Cue, sourceFirst bool) []BilingualCue {
+	assigned := make([][]int, len(target))
+	for j, src := range source {
+		if best := maxOverlap(target, src); best >= 0 {
+			assigned[best] = append(assigned[best], j)
+		}
+	}
+
+	result := make([]BilingualCue, 0, len(target))
+	for i, tgt := range target {
+		indexes := assigned[i]
+		if len(indexes) == 0 {
+			if best := maxOverlap(source, tgt); best >= 0 {
+				indexes = []int{best}
+			}
+		}
+		secondary := ""
+		for _, j := range indexes {
+			secondary = JoinText(secondary, source[j].PlainText())
+		}
+
+		cue := BilingualCue{Start: tgt.Start, End: tgt.End, Primary: tgt.PlainText(), Secondary: secondary}
+		if sourceFirst && secondary != "" {
+			cue.Primary, cue.Secondary = cue.Secondary, cue.Primary
+		}
+		result = append(result, cue)
+	}
+	return result
+}
+
+// maxOverlap 返回与 cue 时间重叠最多的字幕下标，没有重叠时返回 -1
+func maxOverlap(cues []Cue, cue Cue) int {
+	best, bestOverlap := -1, time.Duration(0)
+	for i, c := range cues {
+		if c.Start >= cue.End {
+			break
+		}
+		if overlap := min(c.End, cue.End) - max(c.Start, cue.Start); overlap > bestOverlap {
+			best, bestOverlap = i, overlap
+		}
+	}
+	return best
+}
+
+// BilingualCues 转换为普通字幕（两种语言各占一行），用于 SRT / BCC 等不支持分行样式的格式
+func BilingualCues(cues []BilingualCue) []Cue {
+	result := make([]Cue, 0, len(cues))
+	for _, c := range cues {
+		text := c.Primary
+		if c.Secondary != "" {
+			text += "\n" + c.Secondary
+		}
+		result = append(result, Cue{Start: c.Start, End: c.End, Text: text})
+	}
+	return result
+}
+
+// SplitBilingual 将双语 SRT 字幕还原为双语条目：第一行为 Primary，其余为 Secondary
+func SplitBilingual(cues []Cue) []BilingualCue {
+	result := make([]BilingualCue, 0, len(cues))
+	for _, c := range cues {
+		primary, secondary, _ := strings.Cut(c.Text, "\n")
+		result = append(result, BilingualCue{Start: c.Start, End: c.End, Primary: primary, Secondary: strings.ReplaceAll(secondary, "\n", " ")})
+	}
+	return result
+}
+
+// FormatBilingualASS 生成双语 ASS 字幕：第一行使用 opts.Styles[0]，第二行使用 opts.Styles[1]（未提供时两行样式相同）
+func FormatBilingualASS(cues []BilingualCue, opts ASSOptions) string {
+	if len(opts.Styles) == 0 {
+		opts.Styles = []ASSStyle{DefaultASSStyle()}
+	}
+	assCues := make([]Cue, 0, len(cues))
+	for _, c := range cues {
+		text := c.Primary
+		if c.Secondary != "" {
+			secondary := c.Secondary
+			if len(opts.Styles) > 1 {
+				secondary = `{\r` + opts.Styles[1].Name + `}` + secondary
+			}
+			text += "\n" + secondary
+		}
+		assCues = append(assCues, Cue{Start: c.Start, End: c.End, Text: text, Style: opts.Styles[0].Name})
+	}
+	return FormatASS(assCues, opts)
+}
+
+// ASSColor 将 #RRGGBB 颜色转换为 ASS 颜色（&H00BBGGRR，不透明）
+func ASSColor(hex string) (string, error) {
+	rgb := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if _, err := strconv.ParseUint(rgb, 16, 32); err != nil || len(rgb) != 6 {
+		return "", fmt.Errorf("无效的颜色: %q", hex)
+	}
+	return "&H00" + strings.ToUpper(rgb[4:6]+rgb[2:4]+rgb[0:2]), nil
+}
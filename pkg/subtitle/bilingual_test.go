@@ -0,0 +1,66 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBilingualAfterTargetResegment(t *testing.T) {
+	source := []Cue{
+		{Start: sec(0), End: sec(4), Text: "Hello everyone,\nwelcome back."},
+		{Start: sec(4), End: sec(6), Text: "Let's begin."},
+	}
+	// 译文断句后第一条拆成两条，第二条与原文一一对应
+	target := []Cue{
+		{Start: sec(0), End: sec(1.5), Text: "大家好，"},
+		{Start: sec(1.5), End: sec(4), Text: "欢迎回来。"},
+		{Start: sec(4), End: sec(6), Text: "我们开始吧。"},
+	}
+
+	cues := Bilingual(target, source, false)
+	if len(cues) != 3 {
+		t.Fatalf("got %d cues", len(cues))
+	}
+	// 原文归入重叠最多的译文，另一半取重叠最多的原文
+	want := []string{"Hello everyone, welcome back.", "Hello everyone, welcome back.", "Let's begin."}
+	for i, cue := range cues {
+		if cue.Primary != target[i].Text || cue.Secondary != want[i] {
+			t.Errorf("cue %d = %q / %q", i, cue.Primary, cue.Secondary)
+		}
+	}
+
+	flipped := Bilingual(target, source, true)
+	if flipped[2].Primary != "Let's begin." || flipped[2].Secondary != "我们开始吧。" {
+		t.Errorf("source first = %+v", flipped[2])
+	}
+
+	srt := BilingualCues(cues)
+	if srt[2].Text != "我们开始吧。\nLet's begin." {
+		t.Errorf("srt text = %q", srt[2].Text)
+	}
+	if back := SplitBilingual(srt); back[2] != cues[2] {
+		t.Errorf("SplitBilingual = %+v", back[2])
+	}
+}
+
+func TestFormatBilingualASS(t *testing.T) {
+	secondary := DefaultASSStyle()
+	secondary.Name = "Secondary"
+	secondary.FontSize = 40
+	opts := ASSOptions{Styles: []ASSStyle{DefaultASSStyle(), secondary}}
+
+	ass := FormatBilingualASS([]BilingualCue{{Start: sec(1), End: sec(2), Primary: "你好", Secondary: "Hello"}}, opts)
+	if !strings.Contains(ass, `,Default,,0,0,0,,你好\N{\rSecondary}Hello`) {
+		t.Errorf("unexpected dialogue:\n%s", ass)
+	}
+	if !strings.Contains(ass, "Style: Secondary,Noto Sans CJK SC,40,") {
+		t.Errorf("missing secondary style:\n%s", ass)
+	}
+
+	if c, err := ASSColor("#FFCC00"); err != nil || c != "&H0000CCFF" {
+		t.Errorf("ASSColor = %q, %v", c, err)
+	}
+	if _, err := ASSColor("yellow"); err == nil {
+		t.Error("expected error for invalid color")
+	}
+}
@@ -0,0 +1,103 @@
+package subtitle
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cue 一条字幕
+type Cue struct {
+	Start time.Duration `json:"start"`           // 开始时间
+	End   time.Duration `json:"end"`             // 结束时间
+	Text  string        `json:"text"`            // 文本，多行以 \n 分隔
+	Words []Word        `json:"words,omitempty"` // 词级时间戳（语音识别提供时）
+
+	Speaker string `json:"speaker,omitempty"` // 说话人（WebVTT <v> 标签、ASS Name 字段）
+	Style   string `json:"style,omitempty"`   // 样式名（ASS Style 字段）
+}
+
+// Word 带时间戳的词（中日韩文字可能是单字或短语）
+type Word struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// Duration 显示时长
+func (c Cue) Duration() time.Duration {
+	return c.End - c.Start
+}
+
+// PlainText 去掉换行后的文本
+func (c Cue) PlainText() string {
+	lines := strings.Split(c.Text, "\n")
+	text := ""
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			text = JoinText(text, line)
+		}
+	}
+	return text
+}
+
+// Transcript 拼接所有字幕的文本（用于摘要、生成标题等）
+func Transcript(cues []Cue) string {
+	var sb strings.Builder
+	last := ""
+	for _, cue := range cues {
+		text := cue.PlainText()
+		if text == "" {
+			continue
+		}
+		// 只需根据上一段的结尾决定是否加空格
+		sb.WriteString(strings.TrimPrefix(JoinText(last, text), last))
+		last = text
+	}
+	return sb.String()
+}
+
+// JoinText 拼接两段文本，中日韩文字之间不加空格
+func JoinText(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	last, _ := utf8.DecodeLastRuneInString(a)
+	first, _ := utf8.DecodeRuneInString(b)
+	if IsCJK(last) || IsCJK(first) {
+		return a + b
+	}
+	return a + " " + b
+}
+
+// IsCJK 是否为中日韩文字或全角标点
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
+
+// IsCJKText 文本是否以中日韩文字为主
+func IsCJKText(text string) bool {
+	cjk, latin := 0, 0
+	for _, r := range text {
+		switch {
+		case IsCJK(r) && !unicode.IsPunct(r):
+			cjk++
+		case unicode.IsLetter(r):
+			latin++
+		}
+	}
+	return cjk > 0 && cjk >= latin
+}
+
+// CharCount 可读字符数（不计空白）
+func CharCount(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format 字幕文件格式
+type Format string
+
+const (
+	SRT        Format = "srt"
+	WebVTT     Format = "vtt"
+	ASS        Format = "ass"
+	BCC        Format = "bcc"  // B站 JSON 字幕
+	YouTubeXML Format = "srv3" // YouTube timedtext（srv3 或旧版 transcript）
+	Text       Format = "txt"  // 纯文本，无时间轴
+)
+
+// FormatFromPath 根据扩展名判断字幕格式，无法识别时返回空字符串
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		return SRT
+	case ".vtt":
+		return WebVTT
+	case ".ass", ".ssa":
+		return ASS
+	case ".bcc", ".json":
+		return BCC
+	case ".srv1", ".srv2", ".srv3", ".xml":
+		return YouTubeXML
+	case ".txt":
+		return Text
+	}
+	return ""
+}
+
+// Detect 根据内容判断字幕格式
+func Detect(data []byte) Format {
+	head := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	switch {
+	case bytes.HasPrefix(head, []byte("WEBVTT")):
+		return WebVTT
+	case bytes.HasPrefix(head, []byte("<")):
+		return YouTubeXML
+	case bytes.HasPrefix(head, []byte("{")):
+		return BCC
+	case bytes.Contains(head, []byte("[Script Info]")) || bytes.Contains(head, []byte("[Events]")):
+		return ASS
+	case bytes.Contains(head, []byte("-->")):
+		return SRT
+	}
+	return Text
+}
+
+// Parse 按指定格式解析字幕，format 为空时根据内容判断
+func Parse(data []byte, format Format) ([]Cue, error) {
+	if format == "" {
+		format = Detect(data)
+	}
+	switch format {
+	case SRT:
+		return ParseSRT(string(data))
+	case WebVTT:
+		return ParseVTT(string(data))
+	case ASS:
+		return ParseASS(string(data))
+	case BCC:
+		return ParseBCC(data)
+	case YouTubeXML:
+		return ParseYouTubeXML(data)
+	case Text:
+		return ParseText(string(data)), nil
+	}
+	return nil, fmt.Errorf("不支持的字幕格式: %s", format)
+}
+
+// Marshal 按指定格式生成字幕内容（ASS 使用默认样式）
+func Marshal(cues []Cue, format Format) ([]byte, error) {
+	switch format {
+	case SRT:
+		return []byte(FormatSRT(cues)), nil
+	case WebVTT:
+		return []byte(FormatVTT(cues)), nil
+	case ASS:
+		return []byte(FormatASS(cues, DefaultASSOptions())), nil
+	case BCC:
+		return FormatBCC(cues)
+	case YouTubeXML:
+		return []byte(FormatSrv3(cues)), nil
+	case Text:
+		return []byte(FormatText(cues)), nil
+	}
+	return nil, fmt.Errorf("不支持的字幕格式: %s", format)
+}
+
+// ReadFile 读取字幕文件，格式由扩展名决定，无法识别时根据内容判断
+func ReadFile(path string) ([]Cue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := FormatFromPath(path)
+	if format == "" {
+		format = Detect(data)
+	}
+	return Parse(data, format)
+}
+
+// WriteFile 写入字幕文件，格式由扩展名决定
+func WriteFile(path string, cues []Cue) error {
+	format := FormatFromPath(path)
+	if format == "" {
+		return fmt.Errorf("无法根据扩展名判断字幕格式: %s", path)
+	}
+	data, err := Marshal(cues, format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,212 @@
+package subtitle
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "更新 testdata/golden 下的期望输出")
+
+// dumpCues 生成便于审阅的字幕列表
+func dumpCues(cues []Cue) string {
+	var sb strings.Builder
+	for _, cue := range cues {
+		fmt.Fprintf(&sb, "%s --> %s", FormatSRTTime(cue.Start), FormatSRTTime(cue.End))
+		if cue.Speaker != "" {
+			fmt.Fprintf(&sb, " speaker=%q", cue.Speaker)
+		}
+		if cue.Style != "" {
+			fmt.Fprintf(&sb, " style=%q", cue.Style)
+		}
+		fmt.Fprintf(&sb, "\n%s\n\n", cue.Text)
+	}
+	return sb.String()
+}
+
+// checkGolden 对比 testdata/golden/name，-update 时写入
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 golden 文件失败（使用 -update 生成）: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s 不一致:\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestParseGolden(t *testing.T) {
+	files := []struct {
+		name   string
+		format Format
+	}{
+		{"sample.srt", SRT},
+		{"sample.vtt", WebVTT},
+		{"sample.ass", ASS},
+		{"sample.bcc.json", BCC},
+		{"sample.srv3.xml", YouTubeXML},
+		{"sample.transcript.xml", YouTubeXML},
+		{"sample.txt", Text},
+	}
+	for _, f := range files {
+		t.Run(f.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", f.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Detect(data); got != f.format {
+				t.Errorf("Detect = %q, want %q", got, f.format)
+			}
+			cues, err := ReadFile(filepath.Join("testdata", f.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, f.name+".cues", dumpCues(cues))
+		})
+	}
+}
+
+// writerCues 覆盖多行、说话人、样式、需转义字符和 CJK 文本
+var writerCues = []Cue{
+	{Start: 1000 * time.Millisecond, End: 3456 * time.Millisecond, Text: "Hello & <welcome>", Speaker: "Alice"},
+	{Start: 4 * time.Second, End: 6500 * time.Millisecond, Text: "第一行\n第二行", Style: "Sign"},
+	{Start: 3725 * time.Second, End: 3727 * time.Second, Text: "<i>an hour</i> later"},
+}
+
+func TestWriteGolden(t *testing.T) {
+	for _, ext := range []string{"srt", "vtt", "ass", "bcc", "srv3", "txt"} {
+		t.Run(ext, func(t *testing.T) {
+			data, err := Marshal(writerCues, Format(ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "writer."+ext, string(data))
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{SRT, WebVTT, ASS, BCC, YouTubeXML} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Marshal(writerCues, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cues, err := Parse(data, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cues) != len(writerCues) {
+				t.Fatalf("got %d cues, want %d", len(cues), len(writerCues))
+			}
+			for i, cue := range cues {
+				want := writerCues[i]
+				// ASS 精度为百分之一秒
+				if d := cue.Start - want.Start; d < -5*time.Millisecond || d > 5*time.Millisecond {
+					t.Errorf("cue %d start %v, want %v", i, cue.Start, want.Start)
+				}
+				if d := cue.End - want.End; d < -5*time.Millisecond || d > 5*time.Millisecond {
+					t.Errorf("cue %d end %v, want %v", i, cue.End, want.End)
+				}
+				// 解析时会去掉 WebVTT 样式标签
+				text := want.Text
+				if format == WebVTT {
+					text = strings.NewReplacer("<i>", "", "</i>", "").Replace(text)
+				}
+				if cue.Text != text {
+					t.Errorf("cue %d text %q, want %q", i, cue.Text, text)
+				}
+			}
+		})
+	}
+}
+
+func TestSliceAndShift(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 2 * time.Second, Text: "a"},
+		{Start: 9950 * time.Millisecond, End: 12 * time.Second, Text: "b"},
+		{Start: 15 * time.Second, End: 21 * time.Second, Text: "c"},
+	}
+	sliced := Slice(cues, 10*time.Second, 20*time.Second)
+	// b 裁剪为 [0, 2s)，c 裁剪为 [5s, 10s)
+	if len(sliced) != 2 || sliced[0].Start != 0 || sliced[0].End != 2*time.Second || sliced[1].End != 10*time.Second {
+		t.Errorf("Slice = %+v", sliced)
+	}
+
+	shifted := Shift(cues, 3*time.Second)
+	if shifted[0].Start != 3*time.Second || cues[0].Start != 0 {
+		t.Errorf("Shift should return shifted copy: %+v", shifted[0])
+	}
+}
+
+func TestDedupeRolling(t *testing.T) {
+	s := func(sec float64) time.Duration { return time.Duration(sec * float64(time.Second)) }
+	tests := []struct {
+		name string
+		in   []Cue
+		want []Cue
+	}{
+		{
+			name: "滚动行只保留新内容",
+			in: []Cue{
+				{Start: 0, End: s(2), Text: "hello world"},
+				{Start: s(2), End: s(4), Text: "hello world\nhow are you"},
+				{Start: s(4), End: s(6), Text: "how are you\ntoday"},
+			},
+			want: []Cue{
+				{Start: 0, End: s(2), Text: "hello world"},
+				{Start: s(2), End: s(4), Text: "how are you"},
+				{Start: s(4), End: s(6), Text: "today"},
+			},
+		},
+		{
+			name: "没有新内容时延长上一条",
+			in: []Cue{
+				{Start: 0, End: s(2), Text: "hello"},
+				{Start: s(2), End: s(2.5), Text: "hello"},
+				{Start: s(3), End: s(4), Text: "bye"},
+			},
+			want: []Cue{
+				{Start: 0, End: s(2.5), Text: "hello"},
+				{Start: s(3), End: s(4), Text: "bye"},
+			},
+		},
+		{
+			name: "修正重叠并丢弃无效时长",
+			in: []Cue{
+				{Start: 0, End: s(3), Text: "a"},
+				{Start: s(1), End: s(2), Text: "b"},
+				{Start: s(1), End: s(4), Text: "c"},
+			},
+			want: []Cue{
+				{Start: 0, End: s(1), Text: "a"},
+				{Start: s(1), End: s(4), Text: "c"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DedupeRolling(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DedupeRolling = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Start != tt.want[i].Start || got[i].End != tt.want[i].End || got[i].Text != tt.want[i].Text {
+					t.Errorf("cue %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package subtitle
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits 单一文字类型（中日韩 / 拉丁）的字幕可读性限制，0 表示不限制
+type Limits struct {
+	MaxLineChars int           // 每行最多字符数
+	MaxLines     int           // 最多行数
+	MinDuration  time.Duration // 最短显示时长
+	MaxDuration  time.Duration // 最长显示时长
+	MaxCPS       float64       // 每秒最多字符数（不计空白）
+}
+
+// SegmentOptions 字幕断句参数
+type SegmentOptions struct {
+	CJK         Limits        // 中日韩文字
+	Latin       Limits        // 拉丁文字等以空格分词的文字
+	MaxMergeGap time.Duration // 相邻字幕（或词）间隔不超过该值时允许合并，0 表示不合并原有字幕
+}
+
+// DefaultSegmentOptions 默认断句参数（参考常见字幕规范）
+func DefaultSegmentOptions() SegmentOptions {
+	return SegmentOptions{
+		CJK: Limits{
+			MaxLineChars: 16,
+			MaxLines:     2,
+			MinDuration:  time.Second,
+			MaxDuration:  7 * time.Second,
+			MaxCPS:       9,
+		},
+		Latin: Limits{
+			MaxLineChars: 42,
+			MaxLines:     2,
+			MinDuration:  time.Second,
+			MaxDuration:  7 * time.Second,
+			MaxCPS:       17,
+		},
+		MaxMergeGap: 500 * time.Millisecond,
+	}
+}
+
+// LimitsFor 按文本的主要文字类型选择限制
+func (o SegmentOptions) LimitsFor(text string) Limits {
+	if IsCJKText(text) {
+		return o.CJK
+	}
+	return o.Latin
+}
+
+// token 断句的最小单位
+type token struct {
+	Word
+	timed  bool // 时间来自词级时间戳（否则按字符数插值）
+	cueEnd bool // 原字幕的最后一个词
+}
+
+// Resegment 按可读性限制重新断句
+// 有词级时间戳时按词的时间切分，否则按字符数在原字幕时长内插值；
+// 优先在句末切分，超出长度或时长限制时依次选择句末、分句标点、停顿处切分；
+// 过短的字幕与相邻字幕合并，最后按最短时长和每秒字符数延长显示时间（不与相邻字幕重叠）
+func Resegment(cues []Cue, opts SegmentOptions) []Cue {
+	var out []Cue
+	var buf []token
+	flush := func(n int) {
+		out = append(out, opts.makeCue(buf[:n]))
+		buf = append([]token(nil), buf[n:]...)
+	}
+
+	for _, tok := range tokenize(cues) {
+		if n := len(buf); n > 0 {
+			prev := buf[n-1]
+			gap := tok.Start - prev.End
+			long := prev.End-buf[0].Start >= opts.LimitsFor(joinTokens(buf)).MinDuration
+			switch {
+			case prev.cueEnd && (opts.MaxMergeGap <= 0 || gap > opts.MaxMergeGap):
+				flush(n) // 不合并的字幕边界
+			case endsSentence(prev.Text) && long:
+				flush(n) // 句末
+			case opts.MaxMergeGap > 0 && gap > opts.MaxMergeGap && long:
+				flush(n) // 明显停顿
+			}
+		}
+
+		// 加入后超出限制时在最佳位置切分
+		for len(buf) > 0 && opts.overflows(append(buf[:len(buf):len(buf)], tok)) {
+			flush(opts.breakPoint(buf))
+		}
+		buf = append(buf, tok)
+	}
+	if len(buf) > 0 {
+		flush(len(buf))
+	}
+	return opts.adjustTiming(out)
+}
+
+// tokenize 将字幕拆分为词，没有词级时间戳的字幕按字符数分配时间
+func tokenize(cues []Cue) []token {
+	var tokens []token
+	for _, cue := range cues {
+		start := len(tokens)
+		if len(cue.Words) > 0 {
+			for _, w := range cue.Words {
+				if w.Text = strings.TrimSpace(w.Text); w.Text != "" {
+					tokens = append(tokens, token{Word: w, timed: true})
+				}
+			}
+		} else {
+			pieces := SplitWords(cue.PlainText())
+			total := 0
+			for _, p := range pieces {
+				total += max(CharCount(p), 1)
+			}
+			offset := 0
+			for _, p := range pieces {
+				n := max(CharCount(p), 1)
+				tokens = append(tokens, token{Word: Word{
+					Start: cue.Start + cue.Duration()*time.Duration(offset)/time.Duration(total),
+					End:   cue.Start + cue.Duration()*time.Duration(offset+n)/time.Duration(total),
+					Text:  p,
+				}})
+				offset += n
+			}
+		}
+		if len(tokens) > start {
+			tokens[len(tokens)-1].cueEnd = true
+		}
+	}
+	return tokens
+}
+
+// SplitWords 拆分为词: 拉丁文字按空格，中日韩文字逐字；标点附在前一个词后，开括号和引号附在后一个词前
+func SplitWords(text string) []string {
+	var pieces []string
+	var cur strings.Builder
+	pending := ""
+	flush := func() {
+		if cur.Len() > 0 {
+			pieces = append(pieces, cur.String())
+			cur.Reset()
+		}
+	}
+
+	afterSpace := true
+	for _, r := range text {
+		wordStart := afterSpace
+		afterSpace = unicode.IsSpace(r)
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case unicode.In(r, unicode.Ps, unicode.Pi) || (wordStart && (r == '"' || r == '\'')):
+			flush()
+			pending += string(r)
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			if cur.Len() == 0 && len(pieces) > 0 {
+				pieces[len(pieces)-1] += string(r)
+			} else {
+				cur.WriteRune(r)
+			}
+		case IsCJK(r):
+			flush()
+			cur.WriteString(pending + string(r))
+			pending = ""
+			flush()
+		default:
+			if cur.Len() == 0 {
+				cur.WriteString(pending)
+				pending = ""
+			}
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	if pending != "" {
+		pieces = append(pieces, pending)
+	}
+	return pieces
+}
+
+// joinTokens 拼接词
+func joinTokens(tokens []token) string {
+	text := ""
+	for _, t := range tokens {
+		text = JoinText(text, t.Text)
+	}
+	return text
+}
+
+// overflows 是否超出字数或时长限制
+func (o SegmentOptions) overflows(tokens []token) bool {
+	text := joinTokens(tokens)
+	lim := o.LimitsFor(text)
+	if lim.MaxLineChars > 0 && lim.MaxLines > 0 && utf8.RuneCountInString(text) > lim.MaxLineChars*lim.MaxLines {
+		return true
+	}
+	return lim.MaxDuration > 0 && tokens[len(tokens)-1].End-tokens[0].Start > lim.MaxDuration
+}
+
+// breakPoint 选择切分位置（返回前半部分的词数）
+// 优先级: 句末 > 分句标点 > 原字幕边界或停顿 > 任意词间；前半部分不少于三成字数，同等优先级取最靠后的位置
+func (o SegmentOptions) breakPoint(tokens []token) int {
+	if len(tokens) < 2 {
+		return len(tokens)
+	}
+	total := CharCount(joinTokens(tokens))
+	best, bestScore := len(tokens)-1, -1
+	for k := 1; k < len(tokens); k++ {
+		if CharCount(joinTokens(tokens[:k]))*10 < total*3 {
+			continue
+		}
+		prev := tokens[k-1]
+		score := 0
+		switch {
+		case endsSentence(prev.Text):
+			score = 3
+		case endsClause(prev.Text):
+			score = 2
+		case prev.cueEnd || tokens[k].Start-prev.End >= 300*time.Millisecond:
+			score = 1
+		}
+		if score >= bestScore {
+			best, bestScore = k, score
+		}
+	}
+	return best
+}
+
+// makeCue 由词生成字幕并按行宽换行
+func (o SegmentOptions) makeCue(tokens []token) Cue {
+	text := joinTokens(tokens)
+	cue := Cue{
+		Start: tokens[0].Start,
+		End:   tokens[len(tokens)-1].End,
+		Text:  WrapLines(text, o.LimitsFor(text)),
+	}
+	timed := true
+	for _, t := range tokens {
+		timed = timed && t.timed
+	}
+	if timed {
+		for _, t := range tokens {
+			cue.Words = append(cue.Words, t.Word)
+		}
+	}
+	return cue
+}
+
+// WrapLines 按每行字数换行，行数不超过限制时各行长度尽量均衡
+func WrapLines(text string, lim Limits) string {
+	n := utf8.RuneCountInString(text)
+	if lim.MaxLineChars <= 0 || n <= lim.MaxLineChars {
+		return text
+	}
+	lines := (n + lim.MaxLineChars - 1) / lim.MaxLineChars
+	if lim.MaxLines > 0 {
+		lines = min(lines, lim.MaxLines)
+	}
+	width := (n + lines - 1) / lines
+
+	var out []string
+	cur := ""
+	for _, p := range SplitWords(text) {
+		next := JoinText(cur, p)
+		if cur == "" || utf8.RuneCountInString(next) <= width || len(out) == lines-1 {
+			cur = next
+			continue
+		}
+		// 超出目标宽度时，在更接近目标宽度的一侧换行
+		over, under := utf8.RuneCountInString(next)-width, width-utf8.RuneCountInString(cur)
+		if over < under && utf8.RuneCountInString(next) <= lim.MaxLineChars {
+			out = append(out, next)
+			cur = ""
+		} else {
+			out = append(out, cur)
+			cur = p
+		}
+	}
+	if cur != "" {
+		out = append(out, cur)
+	}
+	return strings.Join(out, "\n")
+}
+
+// adjustTiming 按最短时长和每秒字符数延长显示时间，优先向后延长，不与相邻字幕重叠
+func (o SegmentOptions) adjustTiming(cues []Cue) []Cue {
+	for i := range cues {
+		cue := &cues[i]
+		lim := o.LimitsFor(cue.Text)
+		need := lim.MinDuration
+		if lim.MaxCPS > 0 {
+			need = max(need, time.Duration(float64(CharCount(cue.Text))/lim.MaxCPS*float64(time.Second)))
+		}
+		if lim.MaxDuration > 0 {
+			need = min(need, lim.MaxDuration)
+		}
+		if cue.Duration() >= need {
+			continue
+		}
+
+		end := cue.Start + need
+		if i+1 < len(cues) {
+			end = min(end, cues[i+1].Start)
+		}
+		cue.End = max(cue.End, end)
+
+		if cue.Duration() < need {
+			prevEnd := time.Duration(0)
+			if i > 0 {
+				prevEnd = cues[i-1].End
+			}
+			cue.Start = min(cue.Start, max(prevEnd, cue.End-need))
+		}
+	}
+	return cues
+}
+
+// endsSentence 是否以句末标点结尾
+func endsSentence(text string) bool {
+	text = strings.TrimRight(text, `"'”’」』）)]`)
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(".!?。！？…", r)
+}
+
+// endsClause 是否以分句标点结尾
+func endsClause(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(",;:，、；：—", r)
+}
@@ -0,0 +1,169 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func sec(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// checkLimits 检查每条字幕的行宽、行数、时长且不重叠
+func checkLimits(t *testing.T, cues []Cue, opts SegmentOptions) {
+	t.Helper()
+	for i, cue := range cues {
+		lim := opts.LimitsFor(cue.Text)
+		lines := strings.Split(cue.Text, "\n")
+		if len(lines) > lim.MaxLines {
+			t.Errorf("cue %d has %d lines: %q", i, len(lines), cue.Text)
+		}
+		for _, line := range lines {
+			if utf8.RuneCountInString(line) > lim.MaxLineChars {
+				t.Errorf("cue %d line too long (%d): %q", i, utf8.RuneCountInString(line), line)
+			}
+		}
+		if cue.Duration() > lim.MaxDuration || cue.Duration() <= 0 {
+			t.Errorf("cue %d duration %v: %q", i, cue.Duration(), cue.Text)
+		}
+		if i > 0 && cue.Start < cues[i-1].End {
+			t.Errorf("cue %d overlaps previous: %v < %v", i, cue.Start, cues[i-1].End)
+		}
+	}
+}
+
+func TestResegmentSplitsWallOfText(t *testing.T) {
+	opts := DefaultSegmentOptions()
+	cues := []Cue{{
+		Start: 0,
+		End:   sec(12),
+		Text:  "So today we are going to talk about the new engine, which is much faster than the old one. It also uses less memory, and the code is a lot simpler to read.",
+	}}
+	got := Resegment(cues, opts)
+
+	if len(got) < 3 {
+		t.Fatalf("got %d cues: %+v", len(got), got)
+	}
+	checkLimits(t, got, opts)
+	// 句末处必须切分
+	for _, cue := range got {
+		if text := cue.PlainText(); strings.Contains(text, "one. It") {
+			t.Errorf("sentence boundary not respected: %q", text)
+		}
+	}
+	if got[0].Start != 0 || got[len(got)-1].End != sec(12) {
+		t.Errorf("time range = %v-%v", got[0].Start, got[len(got)-1].End)
+	}
+}
+
+func TestResegmentUsesWordTimestamps(t *testing.T) {
+	opts := DefaultSegmentOptions()
+	words := []Word{
+		{sec(0), sec(0.4), "Hello"}, {sec(0.4), sec(1.0), "everyone."},
+		{sec(3.0), sec(3.3), "Welcome"}, {sec(3.3), sec(3.5), "back"}, {sec(3.5), sec(3.7), "to"}, {sec(3.7), sec(4.2), "the"}, {sec(4.2), sec(4.8), "channel."},
+	}
+	got := Resegment([]Cue{{Start: 0, End: sec(4.8), Text: "Hello everyone. Welcome back to the channel.", Words: words}}, opts)
+
+	if len(got) != 2 {
+		t.Fatalf("got %+v", got)
+	}
+	if got[0].Text != "Hello everyone." || got[1].Text != "Welcome back to the channel." {
+		t.Errorf("texts = %q, %q", got[0].Text, got[1].Text)
+	}
+	// 第二条从第一个词的时间开始，而不是按字数插值
+	if got[1].Start != sec(3.0) || got[1].End != sec(4.8) {
+		t.Errorf("cue 1 = %v-%v", got[1].Start, got[1].End)
+	}
+	if len(got[1].Words) != 5 {
+		t.Errorf("words = %+v", got[1].Words)
+	}
+}
+
+func TestResegmentMergesFragments(t *testing.T) {
+	opts := DefaultSegmentOptions()
+	cues := []Cue{
+		{Start: 0, End: sec(0.6), Text: "and then"},
+		{Start: sec(0.7), End: sec(1.4), Text: "we went"},
+		{Start: sec(1.5), End: sec(2.5), Text: "home."},
+		{Start: sec(5), End: sec(6), Text: "Next day."},
+	}
+	got := Resegment(cues, opts)
+	if len(got) != 2 || got[0].Text != "and then we went home." || got[1].Text != "Next day." {
+		t.Fatalf("got %+v", got)
+	}
+
+	// 不允许合并时保持原有边界
+	opts.MaxMergeGap = 0
+	if got := Resegment(cues, opts); len(got) != 4 {
+		t.Errorf("without merging got %d cues", len(got))
+	}
+}
+
+func TestResegmentCJK(t *testing.T) {
+	opts := DefaultSegmentOptions()
+	cues := []Cue{{
+		Start: 0,
+		End:   sec(10),
+		Text:  "今天我们来聊一聊新的引擎，它比旧的快很多，而且占用的内存也更少。代码也简单了很多，读起来非常轻松。",
+	}}
+	got := Resegment(cues, opts)
+	if len(got) < 2 {
+		t.Fatalf("got %+v", got)
+	}
+	checkLimits(t, got, opts)
+	for _, cue := range got {
+		if strings.Contains(cue.Text, " ") {
+			t.Errorf("CJK text joined with spaces: %q", cue.Text)
+		}
+	}
+	if !strings.HasSuffix(got[len(got)-1].PlainText(), "非常轻松。") {
+		t.Errorf("last cue = %q", got[len(got)-1].Text)
+	}
+}
+
+func TestResegmentExtendsShortCues(t *testing.T) {
+	opts := DefaultSegmentOptions()
+	cues := []Cue{
+		{Start: sec(1), End: sec(1.3), Text: "Wait."},
+		{Start: sec(1.5), End: sec(2.5), Text: "This sentence is far too long to read in one second."},
+		{Start: sec(8), End: sec(9), Text: "Done."},
+	}
+	opts.MaxMergeGap = 0
+	got := Resegment(cues, opts)
+	if len(got) != 3 {
+		t.Fatalf("got %+v", got)
+	}
+	// 不足最短时长时向后延长到下一条开始，仍不足时向前延长
+	if got[0].End != sec(1.5) || got[0].Start != sec(0.5) {
+		t.Errorf("cue 0 = %v-%v", got[0].Start, got[0].End)
+	}
+	// 按每秒字符数延长
+	need := sec(float64(CharCount(got[1].Text)) / opts.Latin.MaxCPS)
+	if got[1].Duration() < need {
+		t.Errorf("cue 1 duration %v < %v", got[1].Duration(), need)
+	}
+	checkLimits(t, got, opts)
+}
+
+func TestWrapLinesBalanced(t *testing.T) {
+	lim := Limits{MaxLineChars: 20, MaxLines: 2}
+	if got := WrapLines("one two three four five six", lim); got != "one two three\nfour five six" {
+		t.Errorf("got %q", got)
+	}
+	if got := WrapLines("short", lim); got != "short" {
+		t.Errorf("got %q", got)
+	}
+	if got := WrapLines("一二三四五六七八九十一二三四五六七八九十一二", lim); got != "一二三四五六七八九十一\n二三四五六七八九十一二" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	got := strings.Join(SplitWords(`He said "hi", 然后“走了”。OK?`), "|")
+	want := `He|said|"hi",|然|后|“走|了”。|OK?`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package subtitle
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// timingRegex 时间轴行，兼容 SRT（逗号）与 WebVTT（点号、省略小时）的时间格式，忽略其后的 WebVTT 设置
+	timingRegex = regexp.MustCompile(`^((?:\d+:)?\d{1,2}:\d{1,2}(?:[.,]\d+)?)\s*-->\s*((?:\d+:)?\d{1,2}:\d{1,2}(?:[.,]\d+)?)`)
+	indexRegex  = regexp.MustCompile(`^\d+$`)
+)
+
+// ParseSRT 解析 SRT 字幕
+// 容忍 BOM、CRLF、缺失或错误的序号、条目之间缺少空行；空文本的字幕会被跳过
+func ParseSRT(content string) ([]Cue, error) {
+	lines := splitLines(content)
+	var cues []Cue
+	var cur *Cue
+	var text []string
+	flush := func() {
+		if cur != nil {
+			if cur.Text = strings.Join(text, "\n"); cur.Text != "" {
+				cues = append(cues, *cur)
+			}
+		}
+		cur, text = nil, nil
+	}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if start, end, ok := parseTiming(line); ok {
+			flush()
+			cur = &Cue{Start: start, End: end}
+			continue
+		}
+		if line == "" || cur == nil || isIndex(lines, i) {
+			continue
+		}
+		text = append(text, line)
+	}
+	flush()
+
+	if len(cues) == 0 && strings.TrimSpace(content) != "" && !strings.Contains(content, "-->") {
+		return nil, fmt.Errorf("没有有效的 SRT 字幕")
+	}
+	return cues, nil
+}
+
+// ReadSRT 读取 SRT 字幕文件
+func ReadSRT(path string) ([]Cue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSRT(string(data))
+}
+
+// FormatSRT 生成 SRT 字幕内容（重新编号）
+func FormatSRT(cues []Cue) string {
+	var sb strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n", i+1, FormatSRTTime(cue.Start), FormatSRTTime(cue.End), cue.Text)
+	}
+	return sb.String()
+}
+
+// WriteSRT 写入 SRT 字幕文件
+func WriteSRT(path string, cues []Cue) error {
+	return os.WriteFile(path, []byte(FormatSRT(cues)), 0644)
+}
+
+// FormatSRTTime 格式化为 SRT 时间格式 (HH:MM:SS,mmm)
+func FormatSRTTime(d time.Duration) string {
+	ms := max(d, 0).Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// splitLines 去掉 BOM 并按 LF / CRLF / CR 拆分行
+func splitLines(content string) []string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.Split(strings.ReplaceAll(content, "\r", "\n"), "\n")
+}
+
+// isIndex 第 i 行是否为序号：紧接时间轴的数字行（即使上一条字幕后缺少空行），
+// 或位于空行与时间轴之间的任意行（错误的序号）
+func isIndex(lines []string, i int) bool {
+	if i+1 >= len(lines) || !isTiming(lines[i+1]) {
+		return false
+	}
+	line := strings.TrimSpace(lines[i])
+	return indexRegex.MatchString(line) || i == 0 || strings.TrimSpace(lines[i-1]) == ""
+}
+
+// isTiming 是否为时间轴行
+func isTiming(line string) bool {
+	_, _, ok := parseTiming(strings.TrimSpace(line))
+	return ok
+}
+
+// parseTiming 解析时间轴行
+func parseTiming(line string) (time.Duration, time.Duration, bool) {
+	m := timingRegex.FindStringSubmatch(line)
+	if m == nil {
+		return 0, 0, false
+	}
+	start, ok1 := parseTimestamp(m[1])
+	end, ok2 := parseTimestamp(m[2])
+	return start, end, ok1 && ok2
+}
+
+// parseTimestamp 解析 [H:]MM:SS[.fff] 格式的时间，小数部分可用逗号或点号、位数不限（ASS 为百分之一秒）
+func parseTimestamp(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	frac := ""
+	if i := strings.IndexAny(s, ".,"); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	var d time.Duration
+	for _, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return 0, false
+		}
+		d = d*60 + time.Duration(v)
+	}
+	d *= time.Second
+	if frac != "" {
+		f, err := strconv.ParseFloat("0."+frac, 64)
+		if err != nil {
+			return 0, false
+		}
+		d += time.Duration(f*float64(time.Second) + 0.5).Round(time.Millisecond)
+	}
+	return d, true
+}
@@ -0,0 +1,36 @@
+package subtitle
+
+import "strings"
+
+// ParseText 解析纯文本，每个非空行作为一条无时间轴的字幕
+func ParseText(content string) []Cue {
+	var cues []Cue
+	for _, line := range splitLines(content) {
+		if line = strings.TrimSpace(line); line != "" {
+			cues = append(cues, Cue{Text: line})
+		}
+	}
+	return cues
+}
+
+// FormatText 生成纯文本，每条字幕一行
+func FormatText(cues []Cue) string {
+	var sb strings.Builder
+	for _, cue := range cues {
+		if text := cue.PlainText(); text != "" {
+			sb.WriteString(text + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// cleanLines 替换不换行空格，去除每行首尾空白和空行
+func cleanLines(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\u00a0", " "), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+package subtitle
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Shift 将所有字幕平移 offset（如片头拼接后后移字幕），返回新切片
+func Shift(cues []Cue, offset time.Duration) []Cue {
+	shifted := make([]Cue, len(cues))
+	for i, cue := range cues {
+		cue.Start += offset
+		cue.End += offset
+		if len(cue.Words) > 0 {
+			cue.Words = slices.Clone(cue.Words)
+			for j := range cue.Words {
+				cue.Words[j].Start += offset
+				cue.Words[j].End += offset
+			}
+		}
+		shifted[i] = cue
+	}
+	return shifted
+}
+
+// Slice 截取 [start, end) 范围内的字幕并平移到以 start 为零点
+// 跨越边界的字幕会被裁剪到范围内，裁剪后不足 100ms 的丢弃
+func Slice(cues []Cue, start, end time.Duration) []Cue {
+	var sliced []Cue
+	for _, cue := range cues {
+		if cue.End <= start || cue.Start >= end {
+			continue
+		}
+		s, e := max(cue.Start, start), min(cue.End, end)
+		if e-s < 100*time.Millisecond {
+			continue
+		}
+		sliced = append(sliced, Cue{Start: s - start, End: e - start, Text: cue.Text, Speaker: cue.Speaker, Style: cue.Style})
+	}
+	return sliced
+}
+
+// DedupeRolling 合并 YouTube 自动字幕的滚动重复行
+// 自动字幕每条通常包含上一条的最后一行，只保留新出现的行；没有新内容时延长上一条的结束时间
+func DedupeRolling(cues []Cue) []Cue {
+	var result []Cue
+	var previousLines []string
+
+	for _, cue := range cues {
+		lines := strings.Split(cue.Text, "\n")
+
+		var newLines []string
+		for _, line := range lines {
+			if !slices.Contains(previousLines, line) {
+				newLines = append(newLines, line)
+			}
+		}
+		previousLines = lines
+
+		if len(newLines) == 0 {
+			// 没有新内容：延长上一条字幕的结束时间
+			if n := len(result); n > 0 && cue.End > result[n-1].End && cue.End-result[n-1].End < time.Second {
+				result[n-1].End = cue.End
+			}
+			continue
+		}
+
+		text := strings.Join(newLines, " ")
+		if n := len(result); n > 0 && result[n-1].Text == text {
+			result[n-1].End = cue.End
+			continue
+		}
+
+		result = append(result, Cue{Start: cue.Start, End: cue.End, Text: text, Speaker: cue.Speaker})
+	}
+
+	// 修正时间轴重叠
+	for i := 0; i < len(result)-1; i++ {
+		if result[i].End > result[i+1].Start {
+			result[i].End = result[i+1].Start
+		}
+	}
+
+	// 过滤时长无效的字幕
+	filtered := result[:0]
+	for _, cue := range result {
+		if cue.End > cue.Start {
+			filtered = append(filtered, cue)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,106 @@
+package subtitle
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	vttTagRegex   = regexp.MustCompile(`<[^>]*>`)
+	vttVoiceRegex = regexp.MustCompile(`<v(?:\.[^\s>]*)?\s+([^>]+)>`)
+	// vttKeepTags 写入时保留的样式标签（其余尖括号转义）
+	vttKeepTags = regexp.MustCompile(`</?[ibu]>`)
+)
+
+// ParseVTT 解析 WebVTT 字幕
+// 跳过头部、NOTE / STYLE / REGION 块和字幕标识，去除行内时间戳与样式标签，<v> 标签的说话人写入 Speaker
+func ParseVTT(content string) ([]Cue, error) {
+	var cues []Cue
+	var cur *Cue
+	var text []string
+	flush := func() {
+		if cur != nil {
+			if cur.Text = strings.Join(text, "\n"); cur.Text != "" {
+				cues = append(cues, *cur)
+			}
+		}
+		cur, text = nil, nil
+	}
+
+	for _, line := range splitLines(content) {
+		if start, end, ok := parseTiming(strings.TrimSpace(line)); ok {
+			flush()
+			cur = &Cue{Start: start, End: end}
+			continue
+		}
+		// 只有真正的空行才是字幕分隔符，YouTube 自动字幕中常见只含空格的占位行
+		if line == "" {
+			flush()
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if m := vttVoiceRegex.FindStringSubmatch(line); m != nil && cur.Speaker == "" {
+			cur.Speaker = strings.TrimSpace(m[1])
+		}
+		if line = strings.TrimSpace(html.UnescapeString(vttTagRegex.ReplaceAllString(line, ""))); line != "" {
+			text = append(text, line)
+		}
+	}
+	flush()
+
+	if len(cues) == 0 && !strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(content, "\ufeff")), "WEBVTT") {
+		return nil, fmt.Errorf("不是有效的 WebVTT 字幕")
+	}
+	return cues, nil
+}
+
+// FormatVTT 生成 WebVTT 字幕内容，headers 为 WEBVTT 之后的头部行（如 HLS 的 X-TIMESTAMP-MAP）
+func FormatVTT(cues []Cue, headers ...string) string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n")
+	for _, header := range headers {
+		sb.WriteString(header + "\n")
+	}
+	sb.WriteString("\n")
+	for _, cue := range cues {
+		text := escapeVTT(cue.Text)
+		if cue.Speaker != "" {
+			text = "<v " + cue.Speaker + ">" + text
+		}
+		fmt.Fprintf(&sb, "%s --> %s\n%s\n\n", FormatVTTTime(cue.Start), FormatVTTTime(cue.End), text)
+	}
+	return sb.String()
+}
+
+// WriteVTT 写入 WebVTT 字幕文件
+func WriteVTT(path string, cues []Cue, headers ...string) error {
+	return os.WriteFile(path, []byte(FormatVTT(cues, headers...)), 0644)
+}
+
+// FormatVTTTime 格式化为 WebVTT 时间格式 (HH:MM:SS.mmm)
+func FormatVTTTime(d time.Duration) string {
+	return strings.Replace(FormatSRTTime(d), ",", ".", 1)
+}
+
+// escapeVTT 转义 &、< 和 >（保留 <i>、<b>、<u> 标签），并避免文本中出现时间轴分隔符
+func escapeVTT(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range vttKeepTags.FindAllStringIndex(text, -1) {
+		sb.WriteString(escapeVTTText(text[last:loc[0]]))
+		sb.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(escapeVTTText(text[last:]))
+	return sb.String()
+}
+
+func escapeVTTText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
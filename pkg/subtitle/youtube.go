@@ -0,0 +1,93 @@
+package subtitle
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// youtubeDocument YouTube timedtext 字幕文档
+// 兼容旧版 <transcript><text start dur>（单位: 秒）与 srv3 <timedtext format="3"><body><p t d>（单位: 毫秒）
+type youtubeDocument struct {
+	XMLName    xml.Name        `xml:""`
+	Texts      []youtubeText   `xml:"text"`
+	Paragraphs []srv3Paragraph `xml:"body>p"`
+}
+
+// youtubeText 旧版 timedtext 条目
+type youtubeText struct {
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"dur,attr"`
+	Content  string `xml:",chardata"`
+}
+
+// srv3Paragraph srv3 段落，t/d 单位为毫秒
+type srv3Paragraph struct {
+	Start    int64         `xml:"t,attr"`
+	Duration int64         `xml:"d,attr"`
+	Text     string        `xml:",chardata"`
+	Segments []srv3Segment `xml:"s"`
+}
+
+// srv3Segment srv3 段落中的单词片段
+type srv3Segment struct {
+	Text string `xml:",chardata"`
+}
+
+// ParseYouTubeXML 解析 YouTube timedtext XML 字幕（旧版 transcript 或 srv3）
+func ParseYouTubeXML(data []byte) ([]Cue, error) {
+	var doc youtubeDocument
+	if err := xml.Unmarshal(bytes.TrimPrefix(data, []byte("\ufeff")), &doc); err != nil {
+		return nil, fmt.Errorf("解析 YouTube 字幕失败: %w", err)
+	}
+
+	var cues []Cue
+	for _, t := range doc.Texts {
+		start, err1 := strconv.ParseFloat(strings.TrimSpace(t.Start), 64)
+		duration, err2 := strconv.ParseFloat(strings.TrimSpace(t.Duration), 64)
+		if err1 != nil {
+			return nil, fmt.Errorf("无法解析起始时间: %q", t.Start)
+		}
+		if err2 != nil {
+			duration = 0
+		}
+		// 旧版字幕文本经过二次转义（如 &amp;#39;）
+		if text := cleanLines(html.UnescapeString(t.Content)); text != "" {
+			cues = append(cues, Cue{Start: bccTime(start), End: bccTime(start + duration), Text: text})
+		}
+	}
+
+	for _, p := range doc.Paragraphs {
+		text := p.Text
+		if len(p.Segments) > 0 {
+			var sb strings.Builder
+			for _, seg := range p.Segments {
+				sb.WriteString(seg.Text)
+			}
+			text = sb.String()
+		}
+		if text = cleanLines(text); text == "" {
+			continue
+		}
+		start := time.Duration(p.Start) * time.Millisecond
+		cues = append(cues, Cue{Start: start, End: start + time.Duration(p.Duration)*time.Millisecond, Text: text})
+	}
+	return cues, nil
+}
+
+// FormatSrv3 生成 YouTube srv3 字幕内容
+func FormatSrv3(cues []Cue) string {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\" ?>\n<timedtext format=\"3\">\n<body>\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&sb, "<p t=\"%d\" d=\"%d\">", cue.Start.Milliseconds(), (cue.End - cue.Start).Milliseconds())
+		xml.EscapeText(&sb, []byte(cue.Text))
+		sb.WriteString("</p>\n")
+	}
+	sb.WriteString("</body>\n</timedtext>\n")
+	return sb.String()
+}
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -13,33 +16,153 @@ import (
 
 // TranscodeVideo 使用 H.264 编码器转码视频文件
 func TranscodeVideo(inputVideoPath, outputVideoPath, preset string, crf int, audioBitrate string, fps int) error {
-	// 构建 ffmpeg 命令参数
-	cmd := []string{
-		"-y",
-		"-i", inputVideoPath,
-		"-c:v", "libx264",
-		"-preset", preset,
-		"-crf", strconv.Itoa(crf),
-		"-r", strconv.Itoa(fps),
-		"-c:a", "aac",
-		"-b:a", audioBitrate,
-		outputVideoPath,
+	err := TranscodeVideoWithOptions(context.Background(), inputVideoPath, outputVideoPath, TranscodeOptions{
+		Preset:       preset,
+		CRF:          crf,
+		AudioBitrate: audioBitrate,
+		FPS:          float64(fps),
+	}, nil)
+	if err != nil {
+		fmt.Printf("视频转码过程出现错误: %v\n", err)
+		return err
 	}
 
-	// 创建 ffmpeg 命令对象
-	ffmpegCmd := exec.Command("ffmpeg", cmd...)
+	fmt.Println("视频转码成功")
+	return nil
+}
 
-	// 执行命令并捕获输出
-	output, err := ffmpegCmd.CombinedOutput()
+// TranscodeOptions 转码参数，零值表示使用默认值或保持原样
+type TranscodeOptions struct {
+	VideoCodec   string  // 视频编码器，默认 libx264
+	AudioCodec   string  // 音频编码器，默认 aac
+	Preset       string  // 编码预设，默认 medium
+	CRF          int     // 质量参数，默认 23（设置 VideoBitrate 时忽略）
+	VideoBitrate int64   // 目标视频码率（bit/s），用于限制文件大小
+	AudioBitrate string  // 音频码率，默认 192k
+	FPS          float64 // 输出帧率，0 表示保持原帧率
+	MaxFPS       float64 // 帧率上限，超过时降帧（FPS 为 0 时生效）
+	MaxWidth     int     // 最大宽度，超过时等比缩小
+	MaxHeight    int     // 最大高度，超过时等比缩小
+	Duration     float64 // 输入时长（秒），用于计算进度百分比
+}
+
+// TranscodeProgress 转码进度（来自 ffmpeg -progress 输出）
+type TranscodeProgress struct {
+	OutTime float64 // 已处理的时长（秒）
+	Percent float64 // 进度百分比（0-100），未知时长时为 0
+	Speed   string  // 处理速度，如 "2.5x"
+	Done    bool    // 是否结束
+}
+
+// TranscodeVideoWithOptions 转码视频，onProgress 不为空时通过 -progress 输出回调进度
+func TranscodeVideoWithOptions(ctx context.Context, inputVideoPath, outputVideoPath string, opts TranscodeOptions, onProgress func(TranscodeProgress)) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", transcodeArgs(inputVideoPath, outputVideoPath, opts)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		fmt.Printf("视频转码过程出现错误: %v\n%s\n", err, string(output))
 		return err
 	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动 ffmpeg 失败: %w", err)
+	}
 
-	fmt.Println("视频转码成功")
+	parseFFmpegProgress(stdout, opts.Duration, onProgress)
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg 转码失败: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
 	return nil
 }
 
+// transcodeArgs 构建转码的 ffmpeg 参数（零值参数使用默认值）
+func transcodeArgs(inputVideoPath, outputVideoPath string, opts TranscodeOptions) []string {
+	if opts.VideoCodec == "" {
+		opts.VideoCodec = "libx264"
+	}
+	if opts.AudioCodec == "" {
+		opts.AudioCodec = "aac"
+	}
+	if opts.Preset == "" {
+		opts.Preset = "medium"
+	}
+	if opts.CRF <= 0 {
+		opts.CRF = 23
+	}
+	if opts.AudioBitrate == "" {
+		opts.AudioBitrate = "192k"
+	}
+
+	args := []string{"-y", "-v", "error", "-nostats", "-progress", "pipe:1", "-i", inputVideoPath, "-map", "0:v:0", "-map", "0:a:0?"}
+
+	var filters []string
+	if opts.MaxWidth > 0 || opts.MaxHeight > 0 {
+		// 只缩小不放大，保持偶数尺寸
+		maxW, maxH := "iw", "ih"
+		if opts.MaxWidth > 0 {
+			maxW = fmt.Sprintf("min(%d,iw)", opts.MaxWidth)
+		}
+		if opts.MaxHeight > 0 {
+			maxH = fmt.Sprintf("min(%d,ih)", opts.MaxHeight)
+		}
+		filters = append(filters, fmt.Sprintf("scale='%s':'%s':force_original_aspect_ratio=decrease:force_divisible_by=2", maxW, maxH))
+	}
+	if opts.FPS <= 0 && opts.MaxFPS > 0 {
+		filters = append(filters, fmt.Sprintf("fps='min(%s,source_fps)'", strconv.FormatFloat(opts.MaxFPS, 'f', -1, 64)))
+	}
+	if len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+	if opts.FPS > 0 {
+		args = append(args, "-r", strconv.FormatFloat(opts.FPS, 'f', -1, 64))
+	}
+
+	args = append(args, "-c:v", opts.VideoCodec, "-preset", opts.Preset, "-pix_fmt", "yuv420p")
+	if opts.VideoBitrate > 0 {
+		rate := strconv.FormatInt(opts.VideoBitrate, 10)
+		args = append(args, "-b:v", rate, "-maxrate", rate, "-bufsize", strconv.FormatInt(opts.VideoBitrate*2, 10))
+	} else {
+		args = append(args, "-crf", strconv.Itoa(opts.CRF))
+	}
+	return append(args,
+		"-c:a", opts.AudioCodec, "-b:a", opts.AudioBitrate,
+		"-movflags", "+faststart",
+		outputVideoPath,
+	)
+}
+
+// parseFFmpegProgress 解析 ffmpeg -progress 的 key=value 输出，每个进度块结束时回调
+func parseFFmpegProgress(r io.Reader, duration float64, onProgress func(TranscodeProgress)) {
+	var current TranscodeProgress
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "out_time_us", "out_time_ms":
+			// 两个字段的单位都是微秒（out_time_ms 是 ffmpeg 的历史命名）
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				current.OutTime = float64(us) / 1e6
+			}
+		case "speed":
+			current.Speed = strings.TrimSpace(value)
+		case "progress":
+			current.Done = value == "end"
+			if duration > 0 {
+				current.Percent = current.OutTime / duration * 100
+				if current.Percent > 100 || current.Done {
+					current.Percent = 100
+				}
+			}
+			if onProgress != nil {
+				onProgress(current)
+			}
+		}
+	}
+}
+
 // ExtractWaveAudio 从视频文件中分离出WAV格式的音频
 func ExtractWaveAudio(inputFile, outputFile string) error {
 	// 构造 ffmpeg 命令，提取音频并转换为WAV格式
@@ -68,6 +191,27 @@ func ExtractWaveAudio(inputFile, outputFile string) error {
 	return nil
 }
 
+// ExtractWaveClip 从 start 秒起截取 duration 秒音频，输出 16kHz 单声道 WAV（用于语言检测等短时识别）
+func ExtractWaveClip(ctx context.Context, inputFile, outputFile string, start, duration float64) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-y", "-v", "error",
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64),
+		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
+		"-i", inputFile,
+		"-vn",
+		"-acodec", "pcm_s16le",
+		"-ar", "16000",
+		"-ac", "1",
+		outputFile,
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg 截取WAV音频失败: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 // ExtractAudio 从视频文件中分离出音频
 func ExtractAudio(inputFile, outputFile string) error {
 	// 构造 ffmpeg 命令
@@ -181,40 +325,64 @@ func ExtractThumbnail(videoPath, outputPath string) error {
 	return nil
 }
 
+// ConvertToHLS 将 MP4 转为 HLS（output.m3u8 + vid_%04d.ts）
 func ConvertToHLS(inputPath, outputDir string) error {
+	return ConvertToHLSWithOptions(context.Background(), inputPath, outputDir, HLSOptions{SegmentSeconds: 5})
+}
+
+// HLSOptions HLS 转换参数，零值表示使用默认值或保持原样
+type HLSOptions struct {
+	SegmentSeconds int    // 切片时长（秒），默认 6
+	MaxHeight      int    // 最大高度，超过时等比缩小，0 表示不缩放
+	VideoBitrate   string // 视频码率（如 600k），为空时使用 CRF 23
+	AudioBitrate   string // 音频码率，默认 128k
+	Preset         string // 编码预设，默认 veryfast
+}
+
+// ConvertToHLSWithOptions 将视频转为单码率 HLS，输出 outputDir/output.m3u8 和 vid_%04d.ts 切片
+func ConvertToHLSWithOptions(ctx context.Context, inputPath, outputDir string, opts HLSOptions) error {
 	// 确保输出目录存在
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return err
 	}
+	if opts.SegmentSeconds <= 0 {
+		opts.SegmentSeconds = 6
+	}
+	if opts.AudioBitrate == "" {
+		opts.AudioBitrate = "128k"
+	}
+	if opts.Preset == "" {
+		opts.Preset = "veryfast"
+	}
 
-	//fileName, err := GetFileNameWithoutExtension(inputPath)
-	//if err != nil {
-	//	return err
-	//}
-
-	// FFmpeg 命令：将 MP4 转为 HLS
-	cmd := exec.Command(
-		"ffmpeg",
-		"-i", inputPath, // 输入文件
-		"-c:v", "libx264", // 视频编码 H.264
-		"-c:a", "aac", // 音频编码 AAC
-		"-f", "hls", // 输出格式 HLS
-		"-hls_time", "5", // 每个 TS 切片 6 秒
+	args := []string{"-y", "-v", "error", "-i", inputPath, "-map", "0:v:0", "-map", "0:a:0?"}
+	if opts.MaxHeight > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=-2:'min(%d,ih)'", opts.MaxHeight))
+	}
+	args = append(args, "-c:v", "libx264", "-preset", opts.Preset, "-pix_fmt", "yuv420p")
+	if opts.VideoBitrate != "" {
+		args = append(args, "-b:v", opts.VideoBitrate, "-maxrate", opts.VideoBitrate, "-bufsize", opts.VideoBitrate)
+	} else {
+		args = append(args, "-crf", "23")
+	}
+	// 固定关键帧间隔，保证切片时长均匀
+	args = append(args,
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", opts.SegmentSeconds),
+		"-c:a", "aac", "-b:a", opts.AudioBitrate, "-ac", "2",
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(opts.SegmentSeconds),
 		"-hls_list_size", "0", // M3U8 中保留所有分段
-		"-hls_segment_filename", outputDir+"/vid_%04d.ts", // TS 文件名格式
-		outputDir+"/output.m3u8", // 输出 M3U8 文件
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outputDir, "vid_%04d.ts"),
+		filepath.Join(outputDir, "output.m3u8"),
 	)
 
-	// 打印执行的命令（调试用）
-	log.Println("Executing:", cmd.String())
-
-	// 运行命令并捕获输出
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return err
+		return fmt.Errorf("转换 HLS 失败: %v: %s", err, strings.TrimSpace(stderr.String()))
 	}
-
 	return nil
 }
 
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranscodeArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   TranscodeOptions
+		want   map[string]string // flag -> 期望值，空字符串表示不应出现
+		wantVF string
+	}{
+		{
+			name: "默认参数",
+			opts: TranscodeOptions{},
+			want: map[string]string{"-c:v": "libx264", "-preset": "medium", "-crf": "23", "-c:a": "aac", "-b:a": "192k", "-r": "", "-b:v": ""},
+		},
+		{
+			name:   "限制分辨率",
+			opts:   TranscodeOptions{MaxWidth: 1920, MaxHeight: 1080, Preset: "slow", CRF: 20},
+			want:   map[string]string{"-preset": "slow", "-crf": "20"},
+			wantVF: "scale='min(1920,iw)':'min(1080,ih)':force_original_aspect_ratio=decrease:force_divisible_by=2",
+		},
+		{
+			name:   "只限制高度",
+			opts:   TranscodeOptions{MaxHeight: 720},
+			wantVF: "scale='iw':'min(720,ih)':force_original_aspect_ratio=decrease:force_divisible_by=2",
+		},
+		{
+			name:   "帧率上限",
+			opts:   TranscodeOptions{MaxFPS: 29.97},
+			want:   map[string]string{"-r": ""},
+			wantVF: "fps='min(29.97,source_fps)'",
+		},
+		{
+			name: "固定帧率优先于帧率上限",
+			opts: TranscodeOptions{FPS: 30, MaxFPS: 60},
+			want: map[string]string{"-r": "30"},
+		},
+		{
+			name: "目标码率替代 CRF",
+			opts: TranscodeOptions{VideoBitrate: 2500000, AudioBitrate: "128k"},
+			want: map[string]string{"-b:v": "2500000", "-maxrate": "2500000", "-bufsize": "5000000", "-crf": "", "-b:a": "128k"},
+		},
+		{
+			name:   "缩放与降帧合并为一个滤镜链",
+			opts:   TranscodeOptions{MaxWidth: 1280, MaxFPS: 30},
+			wantVF: "scale='min(1280,iw)':'ih':force_original_aspect_ratio=decrease:force_divisible_by=2,fps='min(30,source_fps)'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := transcodeArgs("in.mkv", "out.mp4", tt.opts)
+			for flag, want := range tt.want {
+				if got := argValue(args, flag); got != want {
+					t.Errorf("%s = %q, want %q", flag, got, want)
+				}
+			}
+			if got := argValue(args, "-vf"); got != tt.wantVF {
+				t.Errorf("-vf = %q, want %q", got, tt.wantVF)
+			}
+			if argValue(args, "-i") != "in.mkv" || args[len(args)-1] != "out.mp4" {
+				t.Errorf("unexpected input/output in args: %s", strings.Join(args, " "))
+			}
+		})
+	}
+}
+
+func TestParseFFmpegProgress(t *testing.T) {
+	output := strings.Join([]string{
+		"frame=10",
+		"out_time_us=2500000",
+		"speed=2.5x",
+		"progress=continue",
+		"out_time_ms=12000000",
+		"speed= 3x",
+		"progress=continue",
+		"out_time_us=N/A",
+		"progress=end",
+	}, "\n")
+
+	var got []TranscodeProgress
+	parseFFmpegProgress(strings.NewReader(output), 10, func(p TranscodeProgress) {
+		got = append(got, p)
+	})
+
+	want := []TranscodeProgress{
+		{OutTime: 2.5, Percent: 25, Speed: "2.5x"},
+		{OutTime: 12, Percent: 100, Speed: "3x"},
+		{OutTime: 12, Percent: 100, Speed: "3x", Done: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d progress updates, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("progress[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// 未知时长时不计算百分比
+	got = nil
+	parseFFmpegProgress(strings.NewReader("out_time_us=1000000\nprogress=continue\n"), 0, func(p TranscodeProgress) {
+		got = append(got, p)
+	})
+	if len(got) != 1 || got[0].Percent != 0 || got[0].OutTime != 1 {
+		t.Errorf("progress without duration = %+v", got)
+	}
+}
+
+// argValue 返回 ffmpeg 参数中 flag 之后的值，不存在时返回空字符串
+func argValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/difyz9/ytb2bili/pkg/subtitle"
 	"go.uber.org/zap"
 )
 
@@ -27,7 +28,8 @@ type SubtitleValidator struct {
 // SubtitleEntry 字幕条目
 type SubtitleEntry struct {
 	Index      int
-	TimeCode   string
+	Start      time.Duration
+	End        time.Duration
 	Original   string // 原始英文
 	Translated string // 翻译中文
 	Status     string // 状态: "ok", "missing", "incomplete", "error"
@@ -158,67 +160,26 @@ func (v *SubtitleValidator) ValidateAndFixSubtitles(originalSRTPath, translatedS
 	return result, nil
 }
 
-// parseSRTFile 解析SRT文件
+// parseSRTFile 解析SRT文件，字幕文本存入 Translated 字段
 func (v *SubtitleValidator) parseSRTFile(filePath string) ([]SubtitleEntry, error) {
-	file, err := os.Open(filePath)
+	cues, err := subtitle.ReadSRT(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	var entries []SubtitleEntry
-	scanner := bufio.NewScanner(file)
-
-	var currentEntry SubtitleEntry
-	var textLines []string
-	stage := 0 // 0=等待序号, 1=等待时间码, 2=读取文本
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if line == "" {
-			// 空行表示一个条目结束
-			if stage == 2 && len(textLines) > 0 {
-				currentEntry.Translated = strings.Join(textLines, "\n")
-				entries = append(entries, currentEntry)
-				textLines = nil
-				stage = 0
-			}
-			continue
-		}
-
-		switch stage {
-		case 0: // 读取序号
-			var index int
-			if _, err := fmt.Sscanf(line, "%d", &index); err == nil {
-				currentEntry = SubtitleEntry{Index: index}
-				stage = 1
-			}
-		case 1: // 读取时间码
-			if strings.Contains(line, "-->") {
-				currentEntry.TimeCode = line
-				stage = 2
-			}
-		case 2: // 读取文本
-			textLines = append(textLines, line)
-		}
-	}
-
-	// 处理最后一个条目
-	if stage == 2 && len(textLines) > 0 {
-		currentEntry.Translated = strings.Join(textLines, "\n")
-		entries = append(entries, currentEntry)
+	entries := make([]SubtitleEntry, 0, len(cues))
+	for i, cue := range cues {
+		entries = append(entries, SubtitleEntry{Index: i + 1, Start: cue.Start, End: cue.End, Translated: cue.Text})
 	}
-
-	return entries, scanner.Err()
+	return entries, nil
 }
 
 // mergeAndAnalyzeEntries 合并并分析原始和翻译字幕
 func (v *SubtitleValidator) mergeAndAnalyzeEntries(original, translated []SubtitleEntry) []SubtitleEntry {
-	// 创建原始字幕的映射
-	originalMap := make(map[int]SubtitleEntry)
+	// 创建原始字幕的映射（译文保持原时间轴，按开始时间对应，不受空字幕导致的序号偏移影响）
+	originalMap := make(map[time.Duration]SubtitleEntry)
 	for _, entry := range original {
-		originalMap[entry.Index] = entry
+		originalMap[entry.Start] = entry
 	}
 
 	var entries []SubtitleEntry
@@ -227,12 +188,13 @@ func (v *SubtitleValidator) mergeAndAnalyzeEntries(original, translated []Subtit
 	for _, translatedEntry := range translated {
 		entry := SubtitleEntry{
 			Index:      translatedEntry.Index,
-			TimeCode:   translatedEntry.TimeCode,
+			Start:      translatedEntry.Start,
+			End:        translatedEntry.End,
 			Translated: translatedEntry.Translated,
 		}
 
 		// 查找对应的原始英文
-		if originalEntry, exists := originalMap[translatedEntry.Index]; exists {
+		if originalEntry, exists := originalMap[translatedEntry.Start]; exists {
 			entry.Original = originalEntry.Translated // 在原始文件中，Translated字段存储的是英文
 		}
 
@@ -418,26 +380,13 @@ func (v *SubtitleValidator) generateOptimizedSRT(entries []SubtitleEntry, output
 		return fmt.Errorf("创建输出目录失败: %v", err)
 	}
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("创建输出文件失败: %v", err)
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
-
+	cues := make([]subtitle.Cue, 0, len(entries))
 	for _, entry := range entries {
-		// 写入序号
-		fmt.Fprintf(writer, "%d\n", entry.Index)
-
-		// 写入时间码
-		fmt.Fprintf(writer, "%s\n", entry.TimeCode)
-
-		// 写入翻译内容
-		fmt.Fprintf(writer, "%s\n\n", entry.Translated)
+		cues = append(cues, subtitle.Cue{Start: entry.Start, End: entry.End, Text: entry.Translated})
+	}
+	if err := subtitle.WriteSRT(outputPath, cues); err != nil {
+		return fmt.Errorf("创建输出文件失败: %v", err)
 	}
-
 	return nil
 }
 
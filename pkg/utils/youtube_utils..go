@@ -78,6 +78,10 @@ func extractYoutTuBeVideoID(url string) (string, error) {
 	return "", errors.New("Invalid YouTube URL")
 }
 
+// ExtractVideoID 从视频 URL 中提取视频 ID（仅支持 YouTube 和 Bilibili）
+//
+// Deprecated: 使用 source.ExtractVideoID，支持更多平台并返回带平台前缀的规范化 ID（如 yt.{ID}、bili.{BV号}）；
+// 本函数返回不带前缀的 ID，无法识别时返回随机 ID
 func ExtractVideoID(videoURL string) string {
 
 	parsedURL, err := url.Parse(videoURL)
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,14 +15,41 @@ import (
 	"strings"
 	"time"
 
+	"github.com/difyz9/ytb2bili/internal/core/types"
 	"go.uber.org/zap"
 )
 
+// yt-dlp 发布渠道
+const (
+	YtDlpChannelStable  = "stable"
+	YtDlpChannelNightly = "nightly"
+
+	// ytDlpChecksumAsset 发布中的 SHA256 校验文件名
+	ytDlpChecksumAsset = "SHA2-256SUMS"
+)
+
+// ytDlpChannelRepos 渠道对应的 GitHub 仓库
+var ytDlpChannelRepos = map[string]string{
+	YtDlpChannelStable:  "yt-dlp/yt-dlp",
+	YtDlpChannelNightly: "yt-dlp/yt-dlp-nightly-builds",
+}
+
+// YtDlpOptions yt-dlp 安装选项
+type YtDlpOptions struct {
+	Version        string       // 固定版本（发布 tag），为空时使用渠道最新版本
+	Channel        string       // 发布渠道: stable / nightly
+	VerifyChecksum bool         // 是否校验 SHA256
+	APIBaseURL     string       // GitHub API 地址，默认 https://api.github.com
+	HTTPClient     *http.Client // 自定义 HTTP 客户端（可选）
+}
+
 // YtDlpManager yt-dlp 管理器
 type YtDlpManager struct {
-	logger     *zap.SugaredLogger
-	installDir string
-	binaryPath string
+	logger      *zap.SugaredLogger
+	installDir  string
+	installPath string // 由本程序管理的安装路径
+	binaryPath  string // 实际使用的路径（可能是系统安装的 yt-dlp）
+	options     YtDlpOptions
 }
 
 // GitHubRelease GitHub发布信息
@@ -45,10 +75,52 @@ func NewYtDlpManager(logger *zap.SugaredLogger, installDir string) *YtDlpManager
 	}
 
 	return &YtDlpManager{
-		logger:     logger,
-		installDir: installDir,
-		binaryPath: binaryPath,
+		logger:      logger,
+		installDir:  installDir,
+		installPath: binaryPath,
+		binaryPath:  binaryPath,
+		options: YtDlpOptions{
+			Channel:        YtDlpChannelStable,
+			VerifyChecksum: true,
+		},
+	}
+}
+
+// NewConfiguredYtDlpManager 根据配置创建 yt-dlp 管理器（版本固定、渠道、校验）
+func NewConfiguredYtDlpManager(logger *zap.SugaredLogger, config *types.AppConfig) *YtDlpManager {
+	var installDir string
+	if config != nil && config.YtDlpPath != "" {
+		installDir = config.YtDlpPath
+	}
+
+	manager := NewYtDlpManager(logger, installDir)
+	if config != nil && config.YtDlpConfig != nil {
+		manager.SetOptions(YtDlpOptions{
+			Version:        config.YtDlpConfig.Version,
+			Channel:        config.YtDlpConfig.Channel,
+			VerifyChecksum: config.YtDlpConfig.VerifyChecksum,
+			APIBaseURL:     config.YtDlpConfig.ReleaseAPI,
+		})
+	}
+	return manager
+}
+
+// SetOptions 设置安装选项（版本固定、渠道、校验）
+func (m *YtDlpManager) SetOptions(opts YtDlpOptions) {
+	if opts.Channel == "" {
+		opts.Channel = YtDlpChannelStable
 	}
+	m.options = opts
+}
+
+// GetInstallPath 获取由本程序管理的安装路径
+func (m *YtDlpManager) GetInstallPath() string {
+	return m.installPath
+}
+
+// GetBackupPath 获取上一版本的备份路径（用于回滚）
+func (m *YtDlpManager) GetBackupPath() string {
+	return m.installPath + ".previous"
 }
 
 // CheckAndInstall 检查并自动安装 yt-dlp
@@ -58,6 +130,9 @@ func (m *YtDlpManager) CheckAndInstall() error {
 	// 1. 检查是否已安装
 	if m.IsInstalled() {
 		m.logger.Info("✅ yt-dlp 已安装")
+		if m.options.Version != "" {
+			return m.ensurePinnedVersion()
+		}
 		return m.checkVersion()
 	}
 
@@ -76,7 +151,7 @@ func (m *YtDlpManager) CheckAndInstall() error {
 func (m *YtDlpManager) IsInstalled() bool {
 	// 检查常见安装位置
 	possiblePaths := []string{
-		m.binaryPath,                            // 自定义安装路径
+		m.installPath,                           // 自定义安装路径
 		"/usr/local/bin/yt-dlp",                 // Homebrew macOS
 		"/opt/homebrew/bin/yt-dlp",              // Homebrew Apple Silicon
 		"/usr/bin/yt-dlp",                       // 系统安装
@@ -110,13 +185,13 @@ func (m *YtDlpManager) GetBinaryPath() string {
 func (m *YtDlpManager) Install() error {
 	m.logger.Info("📥 开始下载 yt-dlp...")
 
-	// 1. 获取最新版本信息
-	release, err := m.getLatestRelease()
+	// 1. 获取版本信息（固定版本或渠道最新版本）
+	release, err := m.getRelease()
 	if err != nil {
-		return fmt.Errorf("获取最新版本信息失败: %v", err)
+		return fmt.Errorf("获取版本信息失败: %v", err)
 	}
 
-	m.logger.Infof("🔄 最新版本: %s", release.TagName)
+	m.logger.Infof("🔄 目标版本: %s (渠道: %s)", release.TagName, m.options.Channel)
 
 	// 2. 选择合适的下载链接
 	downloadURL, err := m.getDownloadURL(release)
@@ -124,24 +199,33 @@ func (m *YtDlpManager) Install() error {
 		return fmt.Errorf("获取下载链接失败: %v", err)
 	}
 
-	// 3. 创建安装目录
+	// 3. 获取校验值
+	var expectedSum string
+	if m.options.VerifyChecksum {
+		expectedSum, err = m.getChecksum(release, ytDlpAssetName())
+		if err != nil {
+			return fmt.Errorf("获取校验值失败: %v", err)
+		}
+	}
+
+	// 4. 创建安装目录
 	if err := os.MkdirAll(m.installDir, 0755); err != nil {
 		return fmt.Errorf("创建安装目录失败: %v", err)
 	}
 
-	// 4. 下载文件
-	if err := m.downloadFile(downloadURL); err != nil {
+	// 5. 下载文件（校验通过后才会替换目标文件）
+	if err := m.downloadFile(downloadURL, expectedSum); err != nil {
 		return fmt.Errorf("下载文件失败: %v", err)
 	}
 
-	// 5. 设置执行权限 (非 Windows)
+	// 6. 设置执行权限 (非 Windows)
 	if runtime.GOOS != "windows" {
-		if err := os.Chmod(m.binaryPath, 0755); err != nil {
+		if err := os.Chmod(m.installPath, 0755); err != nil {
 			return fmt.Errorf("设置执行权限失败: %v", err)
 		}
 	}
 
-	// 6. 验证安装
+	// 7. 验证安装
 	if !m.IsInstalled() {
 		return fmt.Errorf("安装验证失败")
 	}
@@ -149,12 +233,24 @@ func (m *YtDlpManager) Install() error {
 	return nil
 }
 
-// getLatestRelease 获取最新版本信息
-func (m *YtDlpManager) getLatestRelease() (*GitHubRelease, error) {
-	url := "https://api.github.com/repos/yt-dlp/yt-dlp/releases/latest"
+// getRelease 获取目标版本信息
+func (m *YtDlpManager) getRelease() (*GitHubRelease, error) {
+	repo, ok := ytDlpChannelRepos[m.options.Channel]
+	if !ok {
+		return nil, fmt.Errorf("未知的发布渠道: %s", m.options.Channel)
+	}
+
+	apiBase := strings.TrimSuffix(m.options.APIBaseURL, "/")
+	if apiBase == "" {
+		apiBase = "https://api.github.com"
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", apiBase, repo)
+	if m.options.Version != "" {
+		url = fmt.Sprintf("%s/repos/%s/releases/tags/%s", apiBase, repo, m.options.Version)
+	}
+
+	resp, err := m.httpClient(30 * time.Second).Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -172,62 +268,112 @@ func (m *YtDlpManager) getLatestRelease() (*GitHubRelease, error) {
 	return &release, nil
 }
 
+// GetLatestVersion 获取渠道最新（或固定）版本号，不下载
+func (m *YtDlpManager) GetLatestVersion() (string, error) {
+	release, err := m.getRelease()
+	if err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// getChecksum 从发布的 SHA2-256SUMS 文件中获取指定文件的校验值
+func (m *YtDlpManager) getChecksum(release *GitHubRelease, assetName string) (string, error) {
+	var sumsURL string
+	for _, asset := range release.Assets {
+		if asset.Name == ytDlpChecksumAsset {
+			sumsURL = asset.BrowserDownloadURL
+			break
+		}
+	}
+	if sumsURL == "" {
+		return "", fmt.Errorf("发布 %s 中没有校验文件 %s", release.TagName, ytDlpChecksumAsset)
+	}
+
+	resp, err := m.httpClient(30 * time.Second).Get(sumsURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载校验文件失败，状态码: %d", resp.StatusCode)
+	}
+
+	// 格式: <sha256>  <文件名>
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("校验文件中没有 %s 的记录", assetName)
+}
+
+// httpClient 获取 HTTP 客户端
+func (m *YtDlpManager) httpClient(timeout time.Duration) *http.Client {
+	if m.options.HTTPClient != nil {
+		return m.options.HTTPClient
+	}
+	return &http.Client{Timeout: timeout}
+}
+
 // getDownloadURL 根据系统选择合适的下载链接
 func (m *YtDlpManager) getDownloadURL(release *GitHubRelease) (string, error) {
-	var targetName string
+	targetName := ytDlpAssetName()
+	if targetName == "" {
+		return "", fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
+	}
 
+	// 查找匹配的资源
+	for _, asset := range release.Assets {
+		if asset.Name == targetName {
+			return asset.BrowserDownloadURL, nil
+		}
+	}
+
+	return "", fmt.Errorf("未找到适合 %s/%s 的下载文件", runtime.GOOS, runtime.GOARCH)
+}
+
+// ytDlpAssetName 当前系统对应的发布文件名
+func ytDlpAssetName() string {
 	switch runtime.GOOS {
 	case "windows":
 		switch runtime.GOARCH {
-		case "amd64":
-			targetName = "yt-dlp.exe"
 		case "arm64":
-			targetName = "yt-dlp_win_arm64.exe"
+			return "yt-dlp_win_arm64.exe"
 		case "386":
-			targetName = "yt-dlp_win32.exe"
+			return "yt-dlp_win32.exe"
 		default:
-			targetName = "yt-dlp.exe" // 默认
+			return "yt-dlp.exe"
 		}
 	case "darwin":
-		switch runtime.GOARCH {
-		case "amd64":
-			targetName = "yt-dlp_macos"
-		case "arm64":
-			targetName = "yt-dlp_macos" // Apple Silicon 也使用同一个版本
-		default:
-			targetName = "yt-dlp_macos"
-		}
+		// Apple Silicon 也使用同一个版本
+		return "yt-dlp_macos"
 	case "linux":
 		switch runtime.GOARCH {
-		case "amd64":
-			targetName = "yt-dlp_linux"
 		case "arm64":
-			targetName = "yt-dlp_linux_aarch64"
+			return "yt-dlp_linux_aarch64"
 		case "arm":
-			targetName = "yt-dlp_linux_armv7l"
+			return "yt-dlp_linux_armv7l"
 		default:
-			targetName = "yt-dlp_linux"
+			return "yt-dlp_linux"
 		}
 	default:
-		return "", fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
-	}
-
-	// 查找匹配的资源
-	for _, asset := range release.Assets {
-		if asset.Name == targetName {
-			return asset.BrowserDownloadURL, nil
-		}
+		return ""
 	}
-
-	return "", fmt.Errorf("未找到适合 %s/%s 的下载文件", runtime.GOOS, runtime.GOARCH)
 }
 
-// downloadFile 下载文件
-func (m *YtDlpManager) downloadFile(url string) error {
+// downloadFile 下载文件，expectedSum 不为空时校验 SHA256
+func (m *YtDlpManager) downloadFile(url, expectedSum string) error {
 	m.logger.Infof("📥 下载中: %s", url)
 
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Get(url)
+	resp, err := m.httpClient(5 * time.Minute).Get(url)
 	if err != nil {
 		return err
 	}
@@ -238,22 +384,32 @@ func (m *YtDlpManager) downloadFile(url string) error {
 	}
 
 	// 创建临时文件
-	tempFile := m.binaryPath + ".tmp"
+	tempFile := m.installPath + ".tmp"
 	out, err := os.Create(tempFile)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
 
-	// 下载文件并显示进度
-	_, err = io.Copy(out, resp.Body)
+	// 下载的同时计算 SHA256
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hasher), resp.Body)
+	out.Close()
 	if err != nil {
 		os.Remove(tempFile)
 		return err
 	}
 
+	if expectedSum != "" {
+		actualSum := hex.EncodeToString(hasher.Sum(nil))
+		if actualSum != expectedSum {
+			os.Remove(tempFile)
+			return fmt.Errorf("SHA256 校验失败: 期望 %s, 实际 %s", expectedSum, actualSum)
+		}
+		m.logger.Infof("🔒 SHA256 校验通过: %s", actualSum)
+	}
+
 	// 移动到最终位置
-	if err := os.Rename(tempFile, m.binaryPath); err != nil {
+	if err := os.Rename(tempFile, m.installPath); err != nil {
 		os.Remove(tempFile)
 		return err
 	}
@@ -263,46 +419,124 @@ func (m *YtDlpManager) downloadFile(url string) error {
 
 // checkVersion 检查版本信息
 func (m *YtDlpManager) checkVersion() error {
-	cmd := exec.Command(m.binaryPath, "--version")
-	output, err := cmd.Output()
+	version, err := m.GetVersion()
 	if err != nil {
 		m.logger.Warnf("⚠️  无法获取 yt-dlp 版本信息: %v", err)
 		return nil
 	}
 
-	version := strings.TrimSpace(string(output))
 	m.logger.Infof("📋 当前 yt-dlp 版本: %s", version)
 	return nil
 }
 
-// Update 更新 yt-dlp 到最新版本
+// ensurePinnedVersion 确保本程序管理的安装与固定版本一致
+// 已安装的是其他版本（或只有系统安装的 yt-dlp）时，备份为固定版本则回滚，否则安装固定版本
+func (m *YtDlpManager) ensurePinnedVersion() error {
+	pinned := m.options.Version
+	if current, err := m.GetInstalledVersion(); err == nil && current == pinned {
+		m.binaryPath = m.installPath
+		m.logger.Infof("📋 当前 yt-dlp 版本: %s（固定版本）", current)
+		return nil
+	}
+
+	if backup, err := versionOf(m.GetBackupPath()); err == nil && backup == pinned {
+		m.logger.Infof("🔄 回滚 yt-dlp 到固定版本: %s", pinned)
+		return m.Rollback()
+	}
+
+	m.logger.Infof("🔄 安装 yt-dlp 固定版本: %s", pinned)
+	if err := m.Update(); err != nil {
+		return fmt.Errorf("安装固定版本 %s 失败: %v", pinned, err)
+	}
+	return nil
+}
+
+// GetVersion 获取当前 yt-dlp 版本
+func (m *YtDlpManager) GetVersion() (string, error) {
+	return versionOf(m.binaryPath)
+}
+
+// GetInstalledVersion 获取本程序管理的安装路径中的 yt-dlp 版本（与 Update 替换的文件一致）
+func (m *YtDlpManager) GetInstalledVersion() (string, error) {
+	return versionOf(m.installPath)
+}
+
+// versionOf 获取指定 yt-dlp 可执行文件的版本
+func versionOf(path string) (string, error) {
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Update 更新 yt-dlp 到目标版本，上一版本保留为 .previous 用于回滚
 func (m *YtDlpManager) Update() error {
 	m.logger.Info("🔄 更新 yt-dlp...")
 
-	// 备份当前版本
-	backupPath := m.binaryPath + ".backup"
-	if err := os.Rename(m.binaryPath, backupPath); err != nil {
-		m.logger.Warnf("⚠️  无法备份当前版本: %v", err)
+	backupPath := m.GetBackupPath()
+	hasBackup := false
+
+	// 备份当前版本（只处理本程序管理的安装路径）
+	if _, err := os.Stat(m.installPath); err == nil {
+		if err := os.Rename(m.installPath, backupPath); err != nil {
+			m.logger.Warnf("⚠️  无法备份当前版本: %v", err)
+		} else {
+			hasBackup = true
+		}
 	}
 
-	// 安装最新版本
+	// 安装目标版本
 	if err := m.Install(); err != nil {
 		// 恢复备份
-		if _, statErr := os.Stat(backupPath); statErr == nil {
-			os.Rename(backupPath, m.binaryPath)
+		if hasBackup {
+			os.Rename(backupPath, m.installPath)
 		}
 		return err
 	}
 
-	// 删除备份
-	if _, err := os.Stat(backupPath); err == nil {
-		os.Remove(backupPath)
+	// 新版本无法运行时自动回滚
+	if err := m.Validate(); err != nil {
+		if hasBackup {
+			m.logger.Warnf("⚠️  新版本验证失败，回滚到上一版本: %v", err)
+			if rollbackErr := m.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("新版本验证失败: %v，回滚失败: %v", err, rollbackErr)
+			}
+		}
+		return fmt.Errorf("新版本验证失败: %v", err)
 	}
 
 	m.logger.Info("✅ yt-dlp 更新完成")
 	return nil
 }
 
+// Rollback 回滚到上一版本
+func (m *YtDlpManager) Rollback() error {
+	backupPath := m.GetBackupPath()
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("没有可回滚的版本")
+	}
+
+	// 当前版本与备份互换，便于再次回滚
+	tempPath := m.installPath + ".rollback"
+	if _, err := os.Stat(m.installPath); err == nil {
+		if err := os.Rename(m.installPath, tempPath); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(backupPath, m.installPath); err != nil {
+		os.Rename(tempPath, m.installPath)
+		return err
+	}
+	if _, err := os.Stat(tempPath); err == nil {
+		os.Rename(tempPath, backupPath)
+	}
+
+	m.binaryPath = m.installPath
+	m.logger.Info("✅ yt-dlp 已回滚到上一版本")
+	return nil
+}
+
 // Validate 验证 yt-dlp 是否正常工作
 func (m *YtDlpManager) Validate() error {
 	if !m.IsInstalled() {
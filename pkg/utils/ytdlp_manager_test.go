@@ -0,0 +1,341 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fakeRelease 模拟的 GitHub 发布
+type fakeRelease struct {
+	tag      string
+	binary   []byte
+	checksum string // 为空时使用 binary 的真实校验值
+}
+
+// fakeReleaseServer 本地模拟的 GitHub 发布服务器
+type fakeReleaseServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	releases map[string]map[string]*fakeRelease // repo -> tag -> release
+	latest   map[string]string                  // repo -> 最新 tag
+	requests []string
+}
+
+func newFakeReleaseServer(t *testing.T) *fakeReleaseServer {
+	s := &fakeReleaseServer{
+		releases: make(map[string]map[string]*fakeRelease),
+		latest:   make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// addRelease 添加发布，最后添加的作为该仓库的最新版本
+func (s *fakeReleaseServer) addRelease(repo string, release *fakeRelease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.releases[repo] == nil {
+		s.releases[repo] = make(map[string]*fakeRelease)
+	}
+	s.releases[repo][release.tag] = release
+	s.latest[repo] = release.tag
+}
+
+func (s *fakeReleaseServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.URL.Path)
+
+	// /repos/{owner}/{repo}/releases/latest
+	// /repos/{owner}/{repo}/releases/tags/{tag}
+	// /download/{owner}/{repo}/{tag}/{asset}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 5 && parts[0] == "repos" && parts[3] == "releases":
+		repo := parts[1] + "/" + parts[2]
+		tag := s.latest[repo]
+		if parts[4] == "tags" && len(parts) == 6 {
+			tag = parts[5]
+		}
+		_, ok := s.releases[repo][tag]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		type asset struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		}
+		assets := []asset{}
+		for _, name := range []string{ytDlpAssetName(), ytDlpChecksumAsset} {
+			assets = append(assets, asset{
+				Name:               name,
+				BrowserDownloadURL: fmt.Sprintf("%s/download/%s/%s/%s", s.URL, repo, tag, name),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tag_name": tag,
+			"assets":   assets,
+		})
+
+	case len(parts) == 5 && parts[0] == "download":
+		repo := parts[1] + "/" + parts[2]
+		release, ok := s.releases[repo][parts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch parts[4] {
+		case ytDlpAssetName():
+			w.Write(release.binary)
+		case ytDlpChecksumAsset:
+			sum := release.checksum
+			if sum == "" {
+				h := sha256.Sum256(release.binary)
+				sum = hex.EncodeToString(h[:])
+			}
+			fmt.Fprintf(w, "%s  yt-dlp.tar.gz\n%s  %s\n", strings.Repeat("0", 64), sum, ytDlpAssetName())
+		default:
+			http.NotFound(w, r)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// fakeBinary 生成一个输出指定版本号的脚本，模拟 yt-dlp 可执行文件
+func fakeBinary(version string) []byte {
+	return []byte("#!/bin/sh\necho " + version + "\n")
+}
+
+func newTestManager(t *testing.T, server *fakeReleaseServer, opts YtDlpOptions) *YtDlpManager {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试使用 shell 脚本模拟 yt-dlp，不支持 Windows")
+	}
+	manager := NewYtDlpManager(zap.NewNop().Sugar(), t.TempDir())
+	opts.APIBaseURL = server.URL
+	manager.SetOptions(opts)
+	return manager
+}
+
+func TestYtDlpManagerInstallLatest(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.11.18", binary: fakeBinary("2024.11.18")})
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.12.13", binary: fakeBinary("2024.12.13")})
+
+	manager := newTestManager(t, server, YtDlpOptions{VerifyChecksum: true})
+	if err := manager.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	version, err := manager.GetVersion()
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if version != "2024.12.13" {
+		t.Errorf("version = %q, want %q", version, "2024.12.13")
+	}
+}
+
+func TestYtDlpManagerPinnedVersion(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.11.18", binary: fakeBinary("2024.11.18")})
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.12.13", binary: fakeBinary("2024.12.13")})
+
+	manager := newTestManager(t, server, YtDlpOptions{Version: "2024.11.18", VerifyChecksum: true})
+	if err := manager.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	version, _ := manager.GetVersion()
+	if version != "2024.11.18" {
+		t.Errorf("version = %q, want pinned %q", version, "2024.11.18")
+	}
+}
+
+func TestYtDlpManagerNightlyChannel(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.12.13", binary: fakeBinary("2024.12.13")})
+	server.addRelease("yt-dlp/yt-dlp-nightly-builds", &fakeRelease{tag: "2024.12.20.232814", binary: fakeBinary("2024.12.20.232814")})
+
+	manager := newTestManager(t, server, YtDlpOptions{Channel: YtDlpChannelNightly, VerifyChecksum: true})
+	latest, err := manager.GetLatestVersion()
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "2024.12.20.232814" {
+		t.Errorf("latest = %q, want nightly tag", latest)
+	}
+}
+
+func TestYtDlpManagerChecksumMismatch(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{
+		tag:      "2024.12.13",
+		binary:   fakeBinary("2024.12.13"),
+		checksum: strings.Repeat("a", 64),
+	})
+
+	manager := newTestManager(t, server, YtDlpOptions{VerifyChecksum: true})
+	err := manager.Install()
+	if err == nil || !strings.Contains(err.Error(), "SHA256") {
+		t.Fatalf("Install() error = %v, want SHA256 mismatch", err)
+	}
+
+	// 校验失败时不应留下二进制文件或临时文件
+	for _, path := range []string{manager.GetInstallPath(), manager.GetInstallPath() + ".tmp"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after checksum mismatch", filepath.Base(path))
+		}
+	}
+}
+
+func TestYtDlpManagerChecksumDisabled(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{
+		tag:      "2024.12.13",
+		binary:   fakeBinary("2024.12.13"),
+		checksum: strings.Repeat("a", 64),
+	})
+
+	manager := newTestManager(t, server, YtDlpOptions{VerifyChecksum: false})
+	if err := manager.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	for _, path := range server.requests {
+		if strings.HasSuffix(path, ytDlpChecksumAsset) {
+			t.Errorf("checksum file should not be requested when verification is disabled")
+		}
+	}
+}
+
+func TestYtDlpManagerUpdateAndRollback(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.11.18", binary: fakeBinary("2024.11.18")})
+
+	manager := newTestManager(t, server, YtDlpOptions{VerifyChecksum: true})
+	if err := manager.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.12.13", binary: fakeBinary("2024.12.13")})
+	if err := manager.Update(); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if version, _ := manager.GetVersion(); version != "2024.12.13" {
+		t.Fatalf("version after update = %q, want %q", version, "2024.12.13")
+	}
+
+	// 上一版本保留用于回滚
+	backup, err := os.ReadFile(manager.GetBackupPath())
+	if err != nil {
+		t.Fatalf("backup not kept after update: %v", err)
+	}
+	if string(backup) != string(fakeBinary("2024.11.18")) {
+		t.Errorf("backup content = %q, want previous binary", backup)
+	}
+
+	if err := manager.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if version, _ := manager.GetVersion(); version != "2024.11.18" {
+		t.Errorf("version after rollback = %q, want %q", version, "2024.11.18")
+	}
+
+	// 回滚后新版本成为备份，可以再次回滚
+	if err := manager.Rollback(); err != nil {
+		t.Fatalf("second Rollback() error = %v", err)
+	}
+	if version, _ := manager.GetVersion(); version != "2024.12.13" {
+		t.Errorf("version after second rollback = %q, want %q", version, "2024.12.13")
+	}
+}
+
+func TestYtDlpManagerUpdateFailureKeepsCurrent(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.11.18", binary: fakeBinary("2024.11.18")})
+
+	manager := newTestManager(t, server, YtDlpOptions{VerifyChecksum: true})
+	if err := manager.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{
+		tag:      "2024.12.13",
+		binary:   fakeBinary("2024.12.13"),
+		checksum: strings.Repeat("b", 64),
+	})
+	if err := manager.Update(); err == nil {
+		t.Fatal("Update() should fail on checksum mismatch")
+	}
+
+	if version, _ := manager.GetVersion(); version != "2024.11.18" {
+		t.Errorf("version after failed update = %q, want %q", version, "2024.11.18")
+	}
+}
+
+func TestYtDlpManagerRollbackWithoutBackup(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	manager := newTestManager(t, server, YtDlpOptions{})
+	if err := manager.Rollback(); err == nil {
+		t.Error("Rollback() should fail without a previous version")
+	}
+}
+
+func TestYtDlpManagerCheckAndInstallEnforcesPin(t *testing.T) {
+	server := newFakeReleaseServer(t)
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.11.18", binary: fakeBinary("2024.11.18")})
+	server.addRelease("yt-dlp/yt-dlp", &fakeRelease{tag: "2024.12.13", binary: fakeBinary("2024.12.13")})
+
+	manager := newTestManager(t, server, YtDlpOptions{VerifyChecksum: true})
+	if err := manager.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	// 已安装最新版本，固定到旧版本后 CheckAndInstall 应安装固定版本
+	manager.SetOptions(YtDlpOptions{Version: "2024.11.18", VerifyChecksum: true, APIBaseURL: server.URL})
+	if err := manager.CheckAndInstall(); err != nil {
+		t.Fatalf("CheckAndInstall() error = %v", err)
+	}
+	if version, _ := manager.GetInstalledVersion(); version != "2024.11.18" {
+		t.Fatalf("installed version = %q, want pinned %q", version, "2024.11.18")
+	}
+
+	// 固定回新版本时备份就是目标版本，应直接回滚而不重新下载
+	manager.SetOptions(YtDlpOptions{Version: "2024.12.13", VerifyChecksum: true, APIBaseURL: server.URL})
+	requests := len(server.requests)
+	if err := manager.CheckAndInstall(); err != nil {
+		t.Fatalf("CheckAndInstall() error = %v", err)
+	}
+	if version, _ := manager.GetVersion(); version != "2024.12.13" {
+		t.Errorf("version = %q, want pinned %q", version, "2024.12.13")
+	}
+	if len(server.requests) != requests {
+		t.Errorf("rollback to pinned backup should not download, got requests %v", server.requests[requests:])
+	}
+
+	// 已是固定版本时不做任何改动
+	if err := manager.CheckAndInstall(); err != nil {
+		t.Fatalf("CheckAndInstall() error = %v", err)
+	}
+	if len(server.requests) != requests {
+		t.Errorf("pinned version already installed should not download, got requests %v", server.requests[requests:])
+	}
+}
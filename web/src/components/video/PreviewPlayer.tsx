@@ -0,0 +1,180 @@
+'use client';
+
+import { useState, useEffect, useRef } from 'react';
+import { Play, KeyRound } from 'lucide-react';
+import { PreviewAssets } from '@/types';
+import { previewApi } from '@/lib/api';
+import StoryboardScrubber from './StoryboardScrubber';
+
+interface PreviewPlayerProps {
+  videoId: string;
+  assets: PreviewAssets;
+}
+
+export default function PreviewPlayer({ videoId, assets }: PreviewPlayerProps) {
+  const videoRef = useRef<HTMLVideoElement>(null);
+  const [token, setToken] = useState('');
+  const [authorized, setAuthorized] = useState(false);
+  const [hlsUnsupported, setHlsUnsupported] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [duration, setDuration] = useState(0);
+  const [currentTime, setCurrentTime] = useState(0);
+
+  // 使用访问令牌换取预览会话，会话 Cookie 只对当前视频有效
+  // 令牌只保存在组件状态中，不写入浏览器存储
+  const openSession = async (value: string) => {
+    try {
+      const response = await previewApi.createSession(videoId, value);
+      if (response.code === 200) {
+        setAuthorized(true);
+        setError(null);
+      } else {
+        setError(response.message || '预览认证失败');
+      }
+    } catch (err: any) {
+      setError(err.response?.data?.message || '预览认证失败');
+    }
+  };
+
+  useEffect(() => {
+    setAuthorized(false);
+    if (token) {
+      openSession(token);
+    }
+  }, [videoId]);
+
+  // 播放器挂载后加载 HLS：Safari 原生播放，其他浏览器使用 hls.js（请求携带会话 Cookie）
+  useEffect(() => {
+    const video = videoRef.current;
+    if (!authorized || !video || !assets.preview) {
+      return;
+    }
+    const source = previewApi.assetUrl(assets.preview);
+    setHlsUnsupported(false);
+
+    if (video.canPlayType('application/vnd.apple.mpegurl') !== '') {
+      video.src = source;
+      return () => {
+        video.removeAttribute('src');
+        video.load();
+      };
+    }
+
+    let hls: import('hls.js').default | null = null;
+    let cancelled = false;
+    import('hls.js').then(({ default: Hls }) => {
+      if (cancelled) return;
+      if (!Hls.isSupported()) {
+        setHlsUnsupported(true);
+        return;
+      }
+      hls = new Hls({
+        xhrSetup: (xhr) => {
+          xhr.withCredentials = true;
+        },
+      });
+      hls.on(Hls.Events.ERROR, (_event, data) => {
+        if (data.fatal) {
+          setError(`预览加载失败: ${data.details}`);
+        }
+      });
+      hls.loadSource(source);
+      hls.attachMedia(video);
+    });
+
+    return () => {
+      cancelled = true;
+      hls?.destroy();
+    };
+  }, [authorized, assets.preview]);
+
+  if (!assets.preview) {
+    return null;
+  }
+
+  return (
+    <div className="bg-white rounded-lg shadow-sm border border-gray-200 p-6">
+      <h3 className="text-lg font-semibold text-gray-900 mb-4 flex items-center space-x-2">
+        <Play className="w-5 h-5" />
+        <span>预览</span>
+      </h3>
+
+      {!authorized ? (
+        <form
+          onSubmit={(e) => {
+            e.preventDefault();
+            if (token) openSession(token);
+          }}
+          className="flex items-center space-x-2"
+        >
+          <KeyRound className="w-4 h-4 text-gray-400" />
+          <input
+            type="password"
+            value={token}
+            onChange={(e) => setToken(e.target.value)}
+            placeholder="预览访问令牌"
+            className="flex-1 px-3 py-2 text-sm border border-gray-300 rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
+          />
+          <button
+            type="submit"
+            className="px-3 py-2 text-sm bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition-colors"
+          >
+            打开预览
+          </button>
+        </form>
+      ) : (
+        <div className="space-y-2">
+          <video
+            ref={videoRef}
+            controls
+            preload="metadata"
+            onLoadedMetadata={(e) => setDuration(e.currentTarget.duration)}
+            onTimeUpdate={(e) => setCurrentTime(e.currentTarget.currentTime)}
+            className="w-full rounded-lg bg-black"
+          />
+          {/* 故事板：拖动进度条时显示雪碧图缩略图 */}
+          {assets.thumbnails && (
+            <StoryboardScrubber
+              thumbnailsUrl={previewApi.assetUrl(assets.thumbnails)}
+              duration={duration}
+              currentTime={currentTime}
+              onSeek={(time) => {
+                if (videoRef.current) videoRef.current.currentTime = time;
+              }}
+            />
+          )}
+          {hlsUnsupported && (
+            <p className="text-sm text-gray-500">
+              当前浏览器不支持播放 HLS，请在播放器中打开{' '}
+              <a
+                href={previewApi.assetUrl(assets.preview)}
+                target="_blank"
+                rel="noopener noreferrer"
+                className="text-blue-600 hover:text-blue-800"
+              >
+                master.m3u8
+              </a>
+            </p>
+          )}
+          {/* 缩略图总览 */}
+          {assets.contact_sheet && (
+            <a
+              href={previewApi.assetUrl(assets.contact_sheet)}
+              target="_blank"
+              rel="noopener noreferrer"
+              title="查看缩略图总览"
+            >
+              <img
+                src={previewApi.assetUrl(assets.contact_sheet)}
+                alt="缩略图总览"
+                className="w-full rounded-lg border border-gray-200"
+              />
+            </a>
+          )}
+        </div>
+      )}
+
+      {error && <p className="mt-2 text-sm text-red-600">{error}</p>}
+    </div>
+  );
+}
@@ -0,0 +1,113 @@
+'use client';
+
+import { useState, useEffect } from 'react';
+
+interface StoryboardCue {
+  start: number;
+  end: number;
+  image: string;
+  x: number;
+  y: number;
+  w: number;
+  h: number;
+}
+
+interface StoryboardScrubberProps {
+  thumbnailsUrl: string;
+  duration: number;
+  currentTime: number;
+  onSeek: (time: number) => void;
+}
+
+// 解析 WebVTT 时间戳（HH:MM:SS.mmm 或 MM:SS.mmm）
+const parseTimestamp = (value: string): number => {
+  return value
+    .trim()
+    .split(':')
+    .reduce((total, part) => total * 60 + parseFloat(part), 0);
+};
+
+// 解析故事板 thumbnails.vtt，cue 文本为 sprite.jpg#xywh=x,y,w,h（相对 VTT 地址）
+const parseStoryboard = (text: string, baseUrl: string): StoryboardCue[] => {
+  const cues: StoryboardCue[] = [];
+  for (const block of text.split(/\r?\n\r?\n/)) {
+    const lines = block.split(/\r?\n/).filter(Boolean);
+    const timing = lines.findIndex((line) => line.includes('-->'));
+    if (timing < 0 || timing + 1 >= lines.length) continue;
+
+    const [start, end] = lines[timing].split('-->');
+    const [image, fragment] = lines[timing + 1].split('#xywh=');
+    const [x, y, w, h] = (fragment || '').split(',').map(Number);
+    if ([x, y, w, h].some(isNaN)) continue;
+
+    cues.push({
+      start: parseTimestamp(start),
+      end: parseTimestamp(end),
+      image: new URL(image, baseUrl).toString(),
+      x, y, w, h,
+    });
+  }
+  return cues;
+};
+
+export default function StoryboardScrubber({ thumbnailsUrl, duration, currentTime, onSeek }: StoryboardScrubberProps) {
+  const [cues, setCues] = useState<StoryboardCue[]>([]);
+  const [hover, setHover] = useState<{ time: number; ratio: number } | null>(null);
+
+  useEffect(() => {
+    const url = new URL(thumbnailsUrl, window.location.href).toString();
+    fetch(url, { credentials: 'include' })
+      .then((response) => (response.ok ? response.text() : Promise.reject(response.status)))
+      .then((text) => setCues(parseStoryboard(text, url)))
+      .catch((err) => {
+        console.error('加载故事板失败:', err);
+        setCues([]);
+      });
+  }, [thumbnailsUrl]);
+
+  if (cues.length === 0 || duration <= 0) {
+    return null;
+  }
+
+  const ratioAt = (e: React.MouseEvent<HTMLDivElement>) => {
+    const rect = e.currentTarget.getBoundingClientRect();
+    return Math.min(Math.max((e.clientX - rect.left) / rect.width, 0), 1);
+  };
+
+  const cue = hover && (cues.find((c) => hover.time >= c.start && hover.time < c.end) || cues[cues.length - 1]);
+
+  return (
+    <div
+      className="relative h-3 bg-gray-200 rounded-full cursor-pointer"
+      onMouseMove={(e) => {
+        const ratio = ratioAt(e);
+        setHover({ time: ratio * duration, ratio });
+      }}
+      onMouseLeave={() => setHover(null)}
+      onClick={(e) => onSeek(ratioAt(e) * duration)}
+    >
+      <div
+        className="absolute inset-y-0 left-0 bg-blue-600 rounded-full"
+        style={{ width: `${(currentTime / duration) * 100}%` }}
+      />
+      {hover && cue && (
+        <div
+          className="absolute bottom-5 -translate-x-1/2 border border-white shadow-lg rounded overflow-hidden pointer-events-none"
+          style={{ left: `${hover.ratio * 100}%` }}
+        >
+          <div
+            style={{
+              width: cue.w,
+              height: cue.h,
+              backgroundImage: `url(${cue.image})`,
+              backgroundPosition: `-${cue.x}px -${cue.y}px`,
+            }}
+          />
+          <div className="text-center text-xs text-white bg-black/70">
+            {new Date(hover.time * 1000).toISOString().substring(11, 19)}
+          </div>
+        </div>
+      )}
+    </div>
+  );
+}
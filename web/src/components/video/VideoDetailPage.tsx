@@ -2,11 +2,12 @@
 
 import { useState, useEffect } from 'react';
 import { ArrowLeft, ExternalLink, RefreshCw, Download, Calendar, Clock, Image, FileText, Play, Eye } from 'lucide-react';
-import { VideoDetail, VideoFile, TASK_STEP_NAMES } from '@/types';
+import { VideoDetail, VideoFile, PreviewAssets, TASK_STEP_NAMES } from '@/types';
 import { videoApi } from '@/lib/api';
 import TaskStepList from './TaskStepList';
 import StatusBadge from '@/components/ui/StatusBadge';
 import VideoActions from './VideoActions';
+import PreviewPlayer from './PreviewPlayer';
 
 interface VideoDetailPageProps {
   videoId: string;
@@ -18,6 +19,7 @@ export default function VideoDetailPage({ videoId, onBack }: VideoDetailPageProp
   const [loading, setLoading] = useState(true);
   const [refreshing, setRefreshing] = useState(false);
   const [error, setError] = useState<string | null>(null);
+  const [previewAssets, setPreviewAssets] = useState<PreviewAssets>({});
 
   const fetchVideoDetail = async (showRefreshing = false) => {
     if (showRefreshing) setRefreshing(true);
@@ -28,6 +30,7 @@ export default function VideoDetailPage({ videoId, onBack }: VideoDetailPageProp
       if (response.code === 200 || response.code === 0) {
         setVideo(response.data);
         setError(null);
+        fetchPreviewAssets();
       } else {
         setError(response.message || '获取视频详情失败');
       }
@@ -40,6 +43,18 @@ export default function VideoDetailPage({ videoId, onBack }: VideoDetailPageProp
     }
   };
 
+  // 预览与故事板地址随文件列表返回，获取失败时不显示预览
+  const fetchPreviewAssets = async () => {
+    try {
+      const response = await videoApi.getVideoFiles(videoId);
+      if (response.code === 200) {
+        setPreviewAssets(response.data.preview || {});
+      }
+    } catch (err) {
+      console.error('获取预览地址失败:', err);
+    }
+  };
+
   useEffect(() => {
     fetchVideoDetail();
   }, [videoId]);
@@ -240,6 +255,9 @@ export default function VideoDetailPage({ videoId, onBack }: VideoDetailPageProp
               </div>
             </div>
 
+            {/* 预览 */}
+            <PreviewPlayer videoId={video.video_id} assets={previewAssets} />
+
             {/* 任务步骤 */}
             <TaskStepList
               steps={video.task_steps}
@@ -4,7 +4,7 @@ import type {
   Video, 
   VideoDetail,
   TaskStep,
-  VideoFile,
+  VideoFilesInfo,
   QRCodeResponse, 
   LoginStatus, 
   VideoSubmissionRequest,
@@ -86,7 +86,7 @@ export const videoApi = {
   },
 
   // 获取视频文件列表
-  getVideoFiles: (id: string): Promise<ApiResponse<VideoFile[]>> => {
+  getVideoFiles: (id: string): Promise<ApiResponse<VideoFilesInfo>> => {
     return api.get(`/videos/${id}/files`);
   },
 
@@ -111,6 +111,22 @@ export const videoApi = {
   },
 };
 
+// 预览相关 API
+export const previewApi = {
+  // 使用访问令牌换取预览会话（Cookie），之后播放器可直接请求切片
+  createSession: (videoId: string, token: string): Promise<ApiResponse<{ expires_at: string }>> => {
+    return api.post(`/videos/${videoId}/preview/session`, null, {
+      headers: { Authorization: `Bearer ${token}` },
+      withCredentials: true,
+    });
+  },
+
+  // 将后端返回的 /api/v1/... 地址转换为可访问的完整地址
+  assetUrl: (path: string): string => {
+    return API_BASE_URL.replace(/\/api\/v1$/, '') + path;
+  },
+};
+
 // 字幕相关 API
 export const subtitleApi = {
   // 获取视频字幕
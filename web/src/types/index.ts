@@ -55,6 +55,7 @@ export interface VideoDetail {
   generated_description?: string;
   generated_tags?: string;
   cover_image?: string;
+  preview_url?: string; // HLS 预览地址（需访问令牌）
   task_steps: TaskStep[];
   progress: TaskProgress;
   files: VideoFile[];
@@ -68,6 +69,21 @@ export interface VideoFile {
   created_at: string;
 }
 
+// 预览与故事板地址（需访问令牌）
+export interface PreviewAssets {
+  preview?: string;
+  thumbnails?: string;
+  sprite?: string;
+  contact_sheet?: string;
+}
+
+export interface VideoFilesInfo {
+  video_id: string;
+  directory: string;
+  files: VideoFile[];
+  preview: PreviewAssets;
+}
+
 export type TaskStepStatus = 'pending' | 'running' | 'completed' | 'failed' | 'skipped';
 
 export type VideoStatus = '001' | '002' | '200' | '999';